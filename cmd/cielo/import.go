@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+
+	"github.com/aellingwood/cielo/internal/config"
+	"github.com/aellingwood/cielo/internal/service"
+)
+
+// runImport handles "cielo import plan.yaml": it creates a board from a
+// plan file, or "-" for standard input, in the configured database and
+// prints what it created. "cielo import trello" is handed to
+// runImportTrello.
+func runImport(cfg *config.Config, args []string) error {
+	if len(args) > 0 && args[0] == "trello" {
+		return runImportTrello(cfg, args[1:])
+	}
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "validate the plan and print what it would create, without creating it")
+	actor := fs.String("actor", "cli", "actor the import is recorded as")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: cielo import [-dry-run] [-actor name] plan.yaml")
+		fmt.Fprintln(fs.Output(), "       cielo import trello [-reimport] [-actor name] export.json")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	data, err := readInput(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	plan, err := service.ParsePlan(data)
+	if err != nil {
+		return err
+	}
+	db, svc, _, err := openService(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	res, err := svc.ImportPlan(context.Background(), plan, *dryRun, *actor)
+	if err != nil {
+		return err
+	}
+	for _, line := range res.Diff {
+		fmt.Println(line)
+	}
+	if !res.DryRun {
+		fmt.Printf("created board %q (%s)\n", res.Board.Name, res.Board.ID)
+	}
+	return nil
+}
+
+// runImportTrello handles "cielo import trello export.json": it imports a
+// board from a Trello JSON export and prints how many of each entity it
+// created and updated.
+func runImportTrello(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("import trello", flag.ExitOnError)
+	reimport := fs.Bool("reimport", false, "update the board imported from this export before instead of refusing")
+	actor := fs.String("actor", "cli", "actor the import is recorded as")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: cielo import trello [-reimport] [-actor name] export.json")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	data, err := readInput(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	db, svc, _, err := openService(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	res, err := svc.ImportTrello(context.Background(), data, *reimport, *actor)
+	if err != nil {
+		return err
+	}
+	for _, kind := range slices.Sorted(maps.Keys(res.Created)) {
+		fmt.Printf("created %d %s\n", res.Created[kind], kind)
+	}
+	for _, kind := range slices.Sorted(maps.Keys(res.Updated)) {
+		fmt.Printf("updated %d %s\n", res.Updated[kind], kind)
+	}
+	fmt.Printf("imported board %q (%s)\n", res.Board.Name, res.Board.ID)
+	return nil
+}
+
+// readInput reads the named file, or standard input for "-".
+func readInput(name string) ([]byte, error) {
+	if name == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(name)
+}
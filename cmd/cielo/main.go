@@ -1,9 +1,12 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
 	"log"
 	"os"
+	"time"
 
 	_ "modernc.org/sqlite"
 
@@ -11,6 +14,7 @@ import (
 	"github.com/gofiber/fiber/v3/middleware/static"
 
 	"github.com/aellingwood/cielo/internal/api"
+	"github.com/aellingwood/cielo/internal/blob"
 	"github.com/aellingwood/cielo/internal/config"
 	"github.com/aellingwood/cielo/internal/event"
 	"github.com/aellingwood/cielo/internal/mcp"
@@ -21,26 +25,33 @@ import (
 func main() {
 	cfg := config.Load()
 
-	db, err := sql.Open("sqlite", cfg.DBPath)
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		if err := runImport(cfg, os.Args[2:]); err != nil {
+			log.Fatalf("import failed: %v", err)
+		}
+		return
+	}
+
+	db, svc, bus, err := openService(cfg)
 	if err != nil {
-		log.Fatalf("failed to open database: %v", err)
+		log.Fatal(err)
 	}
 	defer db.Close()
 
-	db.Exec("PRAGMA foreign_keys = ON")
-	db.Exec("PRAGMA journal_mode = WAL")
-
-	if err := store.RunMigrations(db); err != nil {
-		log.Fatalf("failed to run migrations: %v", err)
+	blobs, err := blob.NewFSStore(cfg.AttachmentDir)
+	if err != nil {
+		log.Fatalf("failed to open attachment directory: %v", err)
 	}
-
-	sqliteStore := store.NewSQLiteStore(db)
-	bus := event.NewBus()
-	svc := service.New(sqliteStore, bus)
+	svc.SetBlobStore(blobs, cfg.MaxAttachmentSize)
 	mcpServer := mcp.NewServer(svc)
 
+	go svc.RunTrashPurger(context.Background(), cfg.TrashRetention, time.Hour)
+	go svc.RunOverdueNotifier(context.Background(), time.Minute)
+
 	app := fiber.New(fiber.Config{
 		AppName: "Cielo",
+		// Leave room for multipart framing around the largest attachment.
+		BodyLimit: max(fiber.DefaultBodyLimit, int(cfg.MaxAttachmentSize)+1<<20),
 	})
 
 	api.SetupMiddleware(app)
@@ -61,3 +72,20 @@ func main() {
 		log.Fatalf("server error: %v", err)
 	}
 }
+
+// openService opens and migrates the database and returns a service on it,
+// with the configured options, and the bus it publishes events to.
+func openService(cfg *config.Config) (*sql.DB, *service.Service, *event.Bus, error) {
+	db, err := store.Open(cfg.DBPath)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	if err := store.RunMigrations(db); err != nil {
+		db.Close()
+		return nil, nil, nil, fmt.Errorf("failed to run migrations: %w", err)
+	}
+	bus := event.NewBus()
+	svc := service.New(store.NewSQLiteStore(db), bus)
+	svc.SetCrossBoardDependencies(cfg.CrossBoardDependencies)
+	return db, svc, bus, nil
+}
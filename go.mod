@@ -5,6 +5,7 @@ go 1.26
 require (
 	github.com/gofiber/fiber/v3 v3.0.0
 	github.com/google/uuid v1.6.0
+	gopkg.in/yaml.v3 v3.0.1
 	modernc.org/sqlite v1.46.1
 )
 
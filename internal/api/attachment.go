@@ -0,0 +1,102 @@
+package api
+
+import (
+	"fmt"
+	"mime"
+
+	"github.com/gofiber/fiber/v3"
+
+	"github.com/aellingwood/cielo/internal/service"
+)
+
+func listAttachments(svc *service.Service) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		attachments, err := svc.ListAttachments(c.Context(), c.Params("id"))
+		if err != nil {
+			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.JSON(attachments)
+	}
+}
+
+// uploadAttachment accepts a multipart form with the contents in a "file"
+// part, or a JSON body with a "url" to reference.
+func uploadAttachment(svc *service.Service) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		cardID := c.Params("id")
+		if c.Is("json") {
+			var body struct {
+				Name string `json:"name"`
+				URL  string `json:"url"`
+			}
+			if err := c.Bind().JSON(&body); err != nil {
+				return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+			}
+			a, err := svc.AddAttachmentURL(c.Context(), cardID, body.Name, body.URL, "user")
+			if err != nil {
+				return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+			}
+			return c.Status(201).JSON(a)
+		}
+		fh, err := c.FormFile("file")
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "expected a multipart form with a file part"})
+		}
+		f, err := fh.Open()
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		defer f.Close()
+		name := c.FormValue("name")
+		if name == "" {
+			name = fh.Filename
+		}
+		mimeType := fh.Header.Get("Content-Type")
+		if mimeType == "application/octet-stream" {
+			mimeType = ""
+		}
+		a, err := svc.AddAttachment(c.Context(), cardID, name, mimeType, f, "user")
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(201).JSON(a)
+	}
+}
+
+func getAttachment(svc *service.Service) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		a, err := svc.GetAttachment(c.Context(), c.Params("id"))
+		if err != nil {
+			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.JSON(a)
+	}
+}
+
+// downloadAttachment streams the attachment's contents, or redirects to
+// the resource an attachment references.
+func downloadAttachment(svc *service.Service) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		a, rc, err := svc.OpenAttachment(c.Context(), c.Params("id"))
+		if err != nil {
+			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+		}
+		if rc == nil {
+			return c.Redirect().To(a.URL)
+		}
+		c.Set(fiber.HeaderContentType, a.MimeType)
+		c.Set(fiber.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": a.Name}))
+		c.Set(fiber.HeaderETag, fmt.Sprintf("%q", a.SHA256))
+		// SendStream closes rc once the response has been written.
+		return c.SendStream(rc, int(a.Size))
+	}
+}
+
+func deleteAttachment(svc *service.Service) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		if err := svc.DeleteAttachment(c.Context(), c.Params("id"), "user"); err != nil {
+			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.SendStatus(204)
+	}
+}
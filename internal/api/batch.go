@@ -0,0 +1,24 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v3"
+
+	"github.com/aellingwood/cielo/internal/model"
+	"github.com/aellingwood/cielo/internal/service"
+)
+
+func runBatch(svc *service.Service) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		var body struct {
+			Operations []model.BatchOp `json:"operations"`
+		}
+		if err := c.Bind().JSON(&body); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+		}
+		results, err := svc.Batch(c.Context(), body.Operations, "user")
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.JSON(fiber.Map{"results": results})
+	}
+}
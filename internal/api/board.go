@@ -1,21 +1,22 @@
 package api
 
 import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/gofiber/fiber/v3"
 
+	"github.com/aellingwood/cielo/internal/model"
 	"github.com/aellingwood/cielo/internal/service"
 )
 
 func listBoards(svc *service.Service) fiber.Handler {
 	return func(c fiber.Ctx) error {
-		boards, err := svc.ListBoards(c.Context())
-		if err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
-		}
-		if boards == nil {
-			return c.JSON([]any{})
-		}
-		return c.JSON(boards)
+		boards, next, err := svc.ListBoards(c.Context(), pageQuery(c))
+		return sendPage(c, boards, next, err)
 	}
 }
 
@@ -39,11 +40,18 @@ func createBoard(svc *service.Service) fiber.Handler {
 func getBoard(svc *service.Service) fiber.Handler {
 	return func(c fiber.Ctx) error {
 		id := c.Params("id")
-		b, err := svc.GetBoard(c.Context(), id)
+		// Boards in the trash are only shown with include_deleted.
+		b, err := svc.GetBoard(c.Context(), id, c.Query("include_deleted") == "true")
 		if err != nil {
 			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
 		}
-		lists, err := svc.ListListsByBoard(c.Context(), id)
+		// cards_per_list trims each list to its first cards; by default the
+		// whole board is returned.
+		cardLimit, _ := strconv.Atoi(c.Query("cards_per_list"))
+		if cardLimit > 0 {
+			cardLimit = model.Page{Limit: cardLimit}.Clamp().Limit
+		}
+		lists, err := svc.ListListsByBoard(c.Context(), id, c.Query("include_archived") == "true", cardLimit)
 		if err != nil {
 			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 		}
@@ -64,6 +72,40 @@ func getBoard(svc *service.Service) fiber.Handler {
 	}
 }
 
+// getBoardSummary responds with the board's counts and, with
+// include_cards, card headlines. Clients that send the summary's ETag in
+// If-None-Match get 304 Not Modified while it is unchanged.
+func getBoardSummary(svc *service.Service) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		cardLimit, _ := strconv.Atoi(c.Query("cards_per_list"))
+		if cardLimit > 0 {
+			cardLimit = model.Page{Limit: cardLimit}.Clamp().Limit
+		}
+		sum, err := svc.SummarizeBoard(c.Context(), c.Params("id"), c.Query("include_cards") == "true", cardLimit)
+		if err != nil {
+			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+		}
+		c.Set(fiber.HeaderETag, sum.ETag)
+		c.Set(fiber.HeaderCacheControl, "no-cache")
+		if etagMatches(c.Get(fiber.HeaderIfNoneMatch), sum.ETag) {
+			return c.SendStatus(fiber.StatusNotModified)
+		}
+		return c.JSON(sum)
+	}
+}
+
+// etagMatches reports whether an If-None-Match header lists etag. Weak
+// validators compare equal to their strong form.
+func etagMatches(header, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+	return false
+}
+
 func updateBoard(svc *service.Service) fiber.Handler {
 	return func(c fiber.Ctx) error {
 		id := c.Params("id")
@@ -74,7 +116,7 @@ func updateBoard(svc *service.Service) fiber.Handler {
 		if err := c.Bind().JSON(&body); err != nil {
 			return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
 		}
-		b, err := svc.UpdateBoard(c.Context(), id, body.Name, body.Description)
+		b, err := svc.UpdateBoard(c.Context(), id, body.Name, body.Description, "user")
 		if err != nil {
 			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
 		}
@@ -85,9 +127,186 @@ func updateBoard(svc *service.Service) fiber.Handler {
 func deleteBoard(svc *service.Service) fiber.Handler {
 	return func(c fiber.Ctx) error {
 		id := c.Params("id")
-		if err := svc.DeleteBoard(c.Context(), id); err != nil {
+		if err := svc.DeleteBoard(c.Context(), id, "user"); err != nil {
 			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 		}
 		return c.SendStatus(204)
 	}
 }
+
+func restoreBoard(svc *service.Service) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		b, err := svc.RestoreBoard(c.Context(), c.Params("id"), "user")
+		if err != nil {
+			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.JSON(b)
+	}
+}
+
+// cloneBoard copies the board as the body's options ask; without a body
+// only its workflow, lists, and custom fields are copied.
+func cloneBoard(svc *service.Service) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		var opts model.BoardCloneOptions
+		if len(c.Body()) > 0 {
+			if err := c.Bind().JSON(&opts); err != nil {
+				return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+			}
+		}
+		b, err := svc.CloneBoard(c.Context(), c.Params("id"), opts, "user")
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(201).JSON(b)
+	}
+}
+
+// importPlan creates a board from a plan in the body, YAML or JSON. With
+// ?dry_run=true it only reports what it would create.
+func importPlan(svc *service.Service) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		plan, err := service.ParsePlan(c.Body())
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		dryRun := c.Query("dry_run") == "true"
+		res, err := svc.ImportPlan(c.Context(), plan, dryRun, "user")
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		if dryRun {
+			return c.JSON(res)
+		}
+		return c.Status(201).JSON(res)
+	}
+}
+
+// importTrello accepts a Trello board export as the "file" part of a
+// multipart form, or as the request body.
+func importTrello(svc *service.Service) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		data := c.Body()
+		if !c.Is("json") {
+			fh, err := c.FormFile("file")
+			if err != nil {
+				return c.Status(400).JSON(fiber.Map{"error": "expected a JSON body or a multipart form with a file part"})
+			}
+			f, err := fh.Open()
+			if err != nil {
+				return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+			}
+			defer f.Close()
+			if data, err = io.ReadAll(f); err != nil {
+				return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+			}
+		}
+		reimport := c.Query("reimport") == "true"
+		res, err := svc.ImportTrello(c.Context(), data, reimport, "user")
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		if res.Created[model.EntityBoard] == 0 {
+			return c.JSON(res)
+		}
+		return c.Status(201).JSON(res)
+	}
+}
+
+func listDeletedBoards(svc *service.Service) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		boards, err := svc.ListDeletedBoards(c.Context())
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+		if boards == nil {
+			return c.JSON([]any{})
+		}
+		return c.JSON(boards)
+	}
+}
+
+func getBoardTrash(svc *service.Service) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		lists, cards, err := svc.ListTrash(c.Context(), c.Params("boardId"))
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+		if lists == nil {
+			lists = []model.List{}
+		}
+		if cards == nil {
+			cards = []model.Card{}
+		}
+		return c.JSON(fiber.Map{"lists": lists, "cards": cards})
+	}
+}
+
+// timeQuery parses an optional RFC 3339 query parameter.
+func timeQuery(c fiber.Ctx, key string) (*time.Time, error) {
+	v := c.Query(key)
+	if v == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %s", key, v)
+	}
+	return &t, nil
+}
+
+func getBoardAudit(svc *service.Service) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		since, err := timeQuery(c, "since")
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		until, err := timeQuery(c, "until")
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		limit, _ := strconv.Atoi(c.Query("limit", "100"))
+		entries, err := svc.ListAudit(c.Context(), model.AuditFilter{
+			BoardID:    c.Params("boardId"),
+			EntityType: c.Query("entity_type"),
+			EntityID:   c.Query("entity_id"),
+			Actor:      c.Query("actor"),
+			Since:      since,
+			Until:      until,
+			Limit:      limit,
+		}, "user")
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+		if entries == nil {
+			return c.JSON([]any{})
+		}
+		return c.JSON(entries)
+	}
+}
+
+func getWorkflow(svc *service.Service) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		id := c.Params("id")
+		wf, err := svc.GetWorkflow(c.Context(), id)
+		if err != nil {
+			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.JSON(wf)
+	}
+}
+
+func setWorkflow(svc *service.Service) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		id := c.Params("id")
+		var body model.Workflow
+		if err := c.Bind().JSON(&body); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+		}
+		wf, err := svc.SetWorkflow(c.Context(), id, &body, "user")
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.JSON(wf)
+	}
+}
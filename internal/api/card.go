@@ -1,10 +1,9 @@
 package api
 
 import (
-	"strconv"
-
 	"github.com/gofiber/fiber/v3"
 
+	"github.com/aellingwood/cielo/internal/model"
 	"github.com/aellingwood/cielo/internal/service"
 )
 
@@ -16,12 +15,18 @@ func createCard(svc *service.Service) fiber.Handler {
 			Description string `json:"description"`
 			Assignee    string `json:"assignee"`
 			Priority    string `json:"priority"`
-			Position    int    `json:"position"`
+			ParentID    string `json:"parent_id"`
+			DueDate     string `json:"due_date"`
+			model.Placement
 		}
 		if err := c.Bind().JSON(&body); err != nil {
 			return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
 		}
-		card, err := svc.CreateCard(c.Context(), listID, body.Title, body.Description, body.Assignee, body.Priority, "user", body.Position)
+		due, err := model.ParseDueDate(body.DueDate)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		card, err := svc.CreateCard(c.Context(), listID, body.Title, body.Description, body.Assignee, body.Priority, body.ParentID, due, "user", body.Placement)
 		if err != nil {
 			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 		}
@@ -40,6 +45,26 @@ func getCard(svc *service.Service) fiber.Handler {
 	}
 }
 
+func listChildren(svc *service.Service) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		cards, err := svc.ListChildren(c.Context(), c.Params("id"))
+		if err != nil {
+			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.JSON(cards)
+	}
+}
+
+func getRollup(svc *service.Service) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		r, err := svc.GetRollup(c.Context(), c.Params("id"))
+		if err != nil {
+			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.JSON(r)
+	}
+}
+
 func updateCard(svc *service.Service) fiber.Handler {
 	return func(c fiber.Ctx) error {
 		id := c.Params("id")
@@ -65,17 +90,47 @@ func deleteCard(svc *service.Service) fiber.Handler {
 	}
 }
 
+func archiveCard(svc *service.Service) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		card, err := svc.ArchiveCard(c.Context(), c.Params("id"), "user")
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.JSON(card)
+	}
+}
+
+func unarchiveCard(svc *service.Service) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		card, err := svc.UnarchiveCard(c.Context(), c.Params("id"), "user")
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.JSON(card)
+	}
+}
+
+func restoreCard(svc *service.Service) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		card, err := svc.RestoreCard(c.Context(), c.Params("id"), "user")
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.JSON(card)
+	}
+}
+
 func moveCard(svc *service.Service) fiber.Handler {
 	return func(c fiber.Ctx) error {
 		id := c.Params("id")
 		var body struct {
-			ListID   string `json:"list_id"`
-			Position int    `json:"position"`
+			ListID string `json:"list_id"`
+			model.CardMoveOptions
 		}
 		if err := c.Bind().JSON(&body); err != nil {
 			return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
 		}
-		card, err := svc.MoveCard(c.Context(), id, body.ListID, body.Position, "user")
+		card, err := svc.MoveCard(c.Context(), id, body.ListID, body.CardMoveOptions, "user")
 		if err != nil {
 			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 		}
@@ -83,6 +138,23 @@ func moveCard(svc *service.Service) fiber.Handler {
 	}
 }
 
+func cloneCard(svc *service.Service) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		var body struct {
+			ListID string `json:"list_id"`
+			model.CardCloneOptions
+		}
+		if err := c.Bind().JSON(&body); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+		}
+		card, err := svc.CloneCard(c.Context(), c.Params("id"), body.ListID, body.CardCloneOptions, "user")
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(201).JSON(card)
+	}
+}
+
 func assignCard(svc *service.Service) fiber.Handler {
 	return func(c fiber.Ctx) error {
 		id := c.Params("id")
@@ -151,7 +223,7 @@ func createLabel(svc *service.Service) fiber.Handler {
 		if err := c.Bind().JSON(&body); err != nil {
 			return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
 		}
-		l, err := svc.CreateLabel(c.Context(), boardID, body.Name, body.Color)
+		l, err := svc.CreateLabel(c.Context(), boardID, body.Name, body.Color, "user")
 		if err != nil {
 			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 		}
@@ -169,7 +241,7 @@ func updateLabel(svc *service.Service) fiber.Handler {
 		if err := c.Bind().JSON(&body); err != nil {
 			return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
 		}
-		l, err := svc.UpdateLabel(c.Context(), id, body.Name, body.Color)
+		l, err := svc.UpdateLabel(c.Context(), id, body.Name, body.Color, "user")
 		if err != nil {
 			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
 		}
@@ -180,7 +252,7 @@ func updateLabel(svc *service.Service) fiber.Handler {
 func deleteLabel(svc *service.Service) fiber.Handler {
 	return func(c fiber.Ctx) error {
 		id := c.Params("id")
-		if err := svc.DeleteLabel(c.Context(), id); err != nil {
+		if err := svc.DeleteLabel(c.Context(), id, "user"); err != nil {
 			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 		}
 		return c.SendStatus(204)
@@ -216,31 +288,15 @@ func removeLabelFromCard(svc *service.Service) fiber.Handler {
 
 func getCardActivity(svc *service.Service) fiber.Handler {
 	return func(c fiber.Ctx) error {
-		id := c.Params("id")
-		limit, _ := strconv.Atoi(c.Query("limit", "50"))
-		entries, err := svc.ListActivityByCard(c.Context(), id, limit)
-		if err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
-		}
-		if entries == nil {
-			return c.JSON([]any{})
-		}
-		return c.JSON(entries)
+		entries, next, err := svc.ListActivityByCard(c.Context(), c.Params("id"), pageQuery(c))
+		return sendPage(c, entries, next, err)
 	}
 }
 
 func getBoardActivity(svc *service.Service) fiber.Handler {
 	return func(c fiber.Ctx) error {
-		boardID := c.Params("boardId")
-		limit, _ := strconv.Atoi(c.Query("limit", "50"))
-		entries, err := svc.ListActivityByBoard(c.Context(), boardID, limit)
-		if err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
-		}
-		if entries == nil {
-			return c.JSON([]any{})
-		}
-		return c.JSON(entries)
+		entries, next, err := svc.ListActivityByBoard(c.Context(), c.Params("boardId"), pageQuery(c))
+		return sendPage(c, entries, next, err)
 	}
 }
 
@@ -251,13 +307,12 @@ func searchCards(svc *service.Service) fiber.Handler {
 		assignee := c.Query("assignee")
 		status := c.Query("status")
 		label := c.Query("label")
-		cards, err := svc.SearchCards(c.Context(), boardID, q, assignee, status, label)
+		expr := c.Query("filter")
+		includeArchived := c.Query("include_archived") == "true"
+		cards, next, err := svc.SearchCards(c.Context(), boardID, q, assignee, status, label, expr, includeArchived, pageQuery(c))
 		if err != nil {
-			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
-		}
-		if cards == nil {
-			return c.JSON([]any{})
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 		}
-		return c.JSON(cards)
+		return sendPage(c, cards, next, nil)
 	}
 }
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v3"
+
+	"github.com/aellingwood/cielo/internal/model"
+	"github.com/aellingwood/cielo/internal/service"
+)
+
+func listChecklist(svc *service.Service) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		items, err := svc.ListChecklist(c.Context(), c.Params("id"))
+		if err != nil {
+			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.JSON(items)
+	}
+}
+
+func addChecklistItem(svc *service.Service) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		var body struct {
+			Text     string `json:"text"`
+			Assignee string `json:"assignee"`
+			model.Placement
+		}
+		if err := c.Bind().JSON(&body); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+		}
+		it, err := svc.AddChecklistItem(c.Context(), c.Params("id"), body.Text, body.Assignee, body.Placement, "user")
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(201).JSON(it)
+	}
+}
+
+func updateChecklistItem(svc *service.Service) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		var spec model.ChecklistItemSpec
+		if err := c.Bind().JSON(&spec); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+		}
+		it, err := svc.UpdateChecklistItem(c.Context(), c.Params("id"), spec, "user")
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.JSON(it)
+	}
+}
+
+func deleteChecklistItem(svc *service.Service) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		if err := svc.DeleteChecklistItem(c.Context(), c.Params("id"), "user"); err != nil {
+			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.SendStatus(204)
+	}
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v3"
+
+	"github.com/aellingwood/cielo/internal/service"
+)
+
+func listComments(svc *service.Service) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		comments, next, err := svc.ListComments(c.Context(), c.Params("id"), pageQuery(c))
+		return sendPage(c, comments, next, err)
+	}
+}
+
+func addComment(svc *service.Service) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		var body struct {
+			Body     string `json:"body"`
+			ParentID string `json:"parent_id"`
+		}
+		if err := c.Bind().JSON(&body); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+		}
+		comment, err := svc.AddComment(c.Context(), c.Params("id"), body.ParentID, body.Body, "user")
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(201).JSON(comment)
+	}
+}
+
+func getComment(svc *service.Service) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		comment, err := svc.GetComment(c.Context(), c.Params("id"))
+		if err != nil {
+			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.JSON(comment)
+	}
+}
+
+func editComment(svc *service.Service) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		var body struct {
+			Body string `json:"body"`
+		}
+		if err := c.Bind().JSON(&body); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+		}
+		comment, err := svc.EditComment(c.Context(), c.Params("id"), body.Body, "user")
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.JSON(comment)
+	}
+}
+
+func deleteComment(svc *service.Service) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		if err := svc.DeleteComment(c.Context(), c.Params("id"), "user"); err != nil {
+			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.SendStatus(204)
+	}
+}
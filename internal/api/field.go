@@ -0,0 +1,60 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v3"
+
+	"github.com/aellingwood/cielo/internal/model"
+	"github.com/aellingwood/cielo/internal/service"
+)
+
+func listCustomFields(svc *service.Service) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		fields, err := svc.ListCustomFields(c.Context(), c.Params("boardId"))
+		if err != nil {
+			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.JSON(fields)
+	}
+}
+
+func createCustomField(svc *service.Service) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		var body struct {
+			Name    string   `json:"name"`
+			Type    string   `json:"type"`
+			Options []string `json:"options"`
+			model.Placement
+		}
+		if err := c.Bind().JSON(&body); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+		}
+		f, err := svc.CreateCustomField(c.Context(), c.Params("boardId"), body.Name, body.Type, body.Options, body.Placement, "user")
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(201).JSON(f)
+	}
+}
+
+func updateCustomField(svc *service.Service) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		var spec model.CustomFieldSpec
+		if err := c.Bind().JSON(&spec); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+		}
+		f, err := svc.UpdateCustomField(c.Context(), c.Params("id"), spec, "user")
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.JSON(f)
+	}
+}
+
+func deleteCustomField(svc *service.Service) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		if err := svc.DeleteCustomField(c.Context(), c.Params("id"), "user"); err != nil {
+			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.SendStatus(204)
+	}
+}
@@ -3,6 +3,7 @@ package api
 import (
 	"github.com/gofiber/fiber/v3"
 
+	"github.com/aellingwood/cielo/internal/model"
 	"github.com/aellingwood/cielo/internal/service"
 )
 
@@ -10,13 +11,14 @@ func createList(svc *service.Service) fiber.Handler {
 	return func(c fiber.Ctx) error {
 		boardID := c.Params("boardId")
 		var body struct {
-			Name     string `json:"name"`
-			Position int    `json:"position"`
+			Name   string `json:"name"`
+			Status string `json:"status"`
+			model.Placement
 		}
 		if err := c.Bind().JSON(&body); err != nil {
 			return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
 		}
-		l, err := svc.CreateList(c.Context(), boardID, body.Name, body.Position, "user")
+		l, err := svc.CreateList(c.Context(), boardID, body.Name, body.Status, body.Placement, "user")
 		if err != nil {
 			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 		}
@@ -28,13 +30,18 @@ func updateList(svc *service.Service) fiber.Handler {
 	return func(c fiber.Ctx) error {
 		id := c.Params("id")
 		var body struct {
-			Name     string `json:"name"`
-			Position int    `json:"position"`
+			Name   string  `json:"name"`
+			Status *string `json:"status"`
+			model.Placement
 		}
 		if err := c.Bind().JSON(&body); err != nil {
 			return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
 		}
-		l, err := svc.UpdateList(c.Context(), id, body.Name, body.Position)
+		var pos *model.Placement
+		if !body.Placement.IsZero() {
+			pos = &body.Placement
+		}
+		l, err := svc.UpdateList(c.Context(), id, body.Name, body.Status, pos, "user")
 		if err != nil {
 			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
 		}
@@ -45,9 +52,46 @@ func updateList(svc *service.Service) fiber.Handler {
 func deleteList(svc *service.Service) fiber.Handler {
 	return func(c fiber.Ctx) error {
 		id := c.Params("id")
-		if err := svc.DeleteList(c.Context(), id); err != nil {
+		if err := svc.DeleteList(c.Context(), id, "user"); err != nil {
 			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 		}
 		return c.SendStatus(204)
 	}
 }
+
+func archiveList(svc *service.Service) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		l, err := svc.ArchiveList(c.Context(), c.Params("id"), "user")
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.JSON(l)
+	}
+}
+
+func unarchiveList(svc *service.Service) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		l, err := svc.UnarchiveList(c.Context(), c.Params("id"), "user")
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.JSON(l)
+	}
+}
+
+func restoreList(svc *service.Service) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		l, err := svc.RestoreList(c.Context(), c.Params("id"), "user")
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.JSON(l)
+	}
+}
+
+func listCards(svc *service.Service) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		cards, next, err := svc.ListCardsByList(c.Context(), c.Params("listId"), c.Query("include_archived") == "true", pageQuery(c))
+		return sendPage(c, cards, next, err)
+	}
+}
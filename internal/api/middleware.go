@@ -6,15 +6,27 @@ import (
 
 	"github.com/gofiber/fiber/v3"
 	"github.com/gofiber/fiber/v3/middleware/cors"
+	"github.com/gofiber/fiber/v3/middleware/requestid"
+
+	"github.com/aellingwood/cielo/internal/service"
 )
 
 func SetupMiddleware(app *fiber.App) {
 	app.Use(cors.New(cors.Config{
-		AllowOrigins: []string{"*"},
-		AllowMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders: []string{"Content-Type", "Accept"},
+		AllowOrigins:  []string{"*"},
+		AllowMethods:  []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowHeaders:  []string{"Content-Type", "Accept", fiber.HeaderXRequestID, fiber.HeaderIfNoneMatch},
+		ExposeHeaders: []string{fiber.HeaderXRequestID, fiber.HeaderETag},
 	}))
 
+	// Every request carries an ID, taken from X-Request-ID when the client
+	// sends one, which is recorded as the source of any audited mutation.
+	app.Use(requestid.New())
+	app.Use(func(c fiber.Ctx) error {
+		c.SetContext(service.WithSource(c.Context(), "rest:"+requestid.FromContext(c)))
+		return c.Next()
+	})
+
 	app.Use(func(c fiber.Ctx) error {
 		start := time.Now()
 		err := c.Next()
@@ -0,0 +1,40 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v3"
+
+	"github.com/aellingwood/cielo/internal/service"
+)
+
+// recipientQuery returns whose inbox a request is about: the recipient
+// query parameter, or the REST actor.
+func recipientQuery(c fiber.Ctx) string {
+	return c.Query("recipient", "user")
+}
+
+func listNotifications(svc *service.Service) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		notifications, next, err := svc.ListNotifications(c.Context(), recipientQuery(c), c.Query("unread") == "true", pageQuery(c))
+		return sendPage(c, notifications, next, err)
+	}
+}
+
+// ackNotifications marks the notifications in ids as read, or all of the
+// recipient's notifications when ids is empty.
+func ackNotifications(svc *service.Service) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		var body struct {
+			IDs []string `json:"ids"`
+		}
+		if len(c.Body()) > 0 {
+			if err := c.Bind().JSON(&body); err != nil {
+				return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+			}
+		}
+		n, err := svc.AckNotifications(c.Context(), recipientQuery(c), body.IDs)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.JSON(fiber.Map{"acknowledged": n})
+	}
+}
@@ -0,0 +1,37 @@
+package api
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+
+	"github.com/aellingwood/cielo/internal/model"
+)
+
+// pageQuery reads the cursor and limit query parameters, applying the
+// default and maximum page sizes.
+func pageQuery(c fiber.Ctx) model.Page {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	return model.Page{Cursor: c.Query("cursor"), Limit: limit}.Clamp()
+}
+
+// sendPage responds with one page of items and, if another page follows,
+// its cursor.
+func sendPage[T any](c fiber.Ctx, items []T, next string, err error) error {
+	if err != nil {
+		status := 500
+		if errors.Is(err, model.ErrInvalidCursor) {
+			status = 400
+		}
+		return c.Status(status).JSON(fiber.Map{"error": err.Error()})
+	}
+	if items == nil {
+		items = []T{}
+	}
+	body := fiber.Map{"items": items}
+	if next != "" {
+		body["next_cursor"] = next
+	}
+	return c.JSON(body)
+}
@@ -13,20 +13,39 @@ func SetupRouter(app *fiber.App, svc *service.Service, bus *event.Bus, mcpServer
 
 	api.Get("/boards", listBoards(svc))
 	api.Post("/boards", createBoard(svc))
+	api.Post("/boards/import-plan", importPlan(svc))
+	api.Post("/boards/import-trello", importTrello(svc))
 	api.Get("/boards/:id", getBoard(svc))
+	api.Get("/boards/:id/summary", getBoardSummary(svc))
 	api.Put("/boards/:id", updateBoard(svc))
 	api.Delete("/boards/:id", deleteBoard(svc))
+	api.Post("/boards/:id/restore", restoreBoard(svc))
+	api.Post("/boards/:id/clone", cloneBoard(svc))
+	api.Get("/trash/boards", listDeletedBoards(svc))
+	api.Get("/boards/:boardId/trash", getBoardTrash(svc))
+	api.Get("/boards/:id/workflow", getWorkflow(svc))
+	api.Put("/boards/:id/workflow", setWorkflow(svc))
 
 	api.Post("/boards/:boardId/lists", createList(svc))
+	api.Get("/lists/:listId/cards", listCards(svc))
 	api.Put("/lists/:id", updateList(svc))
 	api.Delete("/lists/:id", deleteList(svc))
+	api.Post("/lists/:id/archive", archiveList(svc))
+	api.Post("/lists/:id/unarchive", unarchiveList(svc))
+	api.Post("/lists/:id/restore", restoreList(svc))
 
 	api.Post("/lists/:listId/cards", createCard(svc))
 	api.Get("/cards/:id", getCard(svc))
 	api.Put("/cards/:id", updateCard(svc))
+	api.Get("/cards/:id/children", listChildren(svc))
+	api.Get("/cards/:id/rollup", getRollup(svc))
 	api.Delete("/cards/:id", deleteCard(svc))
 	api.Put("/cards/:id/move", moveCard(svc))
 	api.Put("/cards/:id/assign", assignCard(svc))
+	api.Post("/cards/:id/clone", cloneCard(svc))
+	api.Post("/cards/:id/archive", archiveCard(svc))
+	api.Post("/cards/:id/unarchive", unarchiveCard(svc))
+	api.Post("/cards/:id/restore", restoreCard(svc))
 
 	api.Post("/cards/:id/dependencies", addDependency(svc))
 	api.Delete("/cards/:id/dependencies/:depId", removeDependency(svc))
@@ -38,10 +57,57 @@ func SetupRouter(app *fiber.App, svc *service.Service, bus *event.Bus, mcpServer
 	api.Post("/cards/:id/labels", addLabelToCard(svc))
 	api.Delete("/cards/:id/labels/:labelId", removeLabelFromCard(svc))
 
+	api.Get("/boards/:boardId/fields", listCustomFields(svc))
+	api.Post("/boards/:boardId/fields", createCustomField(svc))
+	api.Put("/fields/:id", updateCustomField(svc))
+	api.Delete("/fields/:id", deleteCustomField(svc))
+
+	api.Get("/cards/:id/checklist", listChecklist(svc))
+	api.Post("/cards/:id/checklist", addChecklistItem(svc))
+	api.Put("/checklist/:id", updateChecklistItem(svc))
+	api.Delete("/checklist/:id", deleteChecklistItem(svc))
+
+	api.Get("/cards/:id/attachments", listAttachments(svc))
+	api.Post("/cards/:id/attachments", uploadAttachment(svc))
+	api.Get("/attachments/:id", getAttachment(svc))
+	api.Get("/attachments/:id/content", downloadAttachment(svc))
+	api.Delete("/attachments/:id", deleteAttachment(svc))
+
+	api.Get("/cards/:id/comments", listComments(svc))
+	api.Post("/cards/:id/comments", addComment(svc))
+	api.Get("/comments/:id", getComment(svc))
+	api.Put("/comments/:id", editComment(svc))
+	api.Delete("/comments/:id", deleteComment(svc))
+
+	api.Get("/cards/:id/watchers", listWatchers(svc))
+	api.Post("/cards/:id/watchers", watchCard(svc))
+	api.Delete("/cards/:id/watchers/:watcher", unwatchCard(svc))
+
+	api.Get("/notifications", listNotifications(svc))
+	api.Post("/notifications/ack", ackNotifications(svc))
+
 	api.Get("/cards/:id/activity", getCardActivity(svc))
 	api.Get("/boards/:boardId/activity", getBoardActivity(svc))
+	api.Get("/boards/:boardId/audit", getBoardAudit(svc))
 	api.Get("/boards/:boardId/search", searchCards(svc))
 
+	api.Get("/boards/:boardId/views", listViews(svc))
+	api.Post("/boards/:boardId/views", createView(svc))
+	api.Get("/views/:id", getView(svc))
+	api.Put("/views/:id", updateView(svc))
+	api.Delete("/views/:id", deleteView(svc))
+	api.Get("/views/:id/cards", runView(svc))
+
+	api.Get("/templates", listTemplates(svc))
+	api.Post("/templates", createTemplate(svc))
+	api.Get("/templates/:id", getTemplate(svc))
+	api.Put("/templates/:id", updateTemplate(svc))
+	api.Delete("/templates/:id", deleteTemplate(svc))
+	api.Post("/templates/:id/boards", createBoardFromTemplate(svc))
+	api.Post("/templates/:id/cards", createCardFromTemplate(svc))
+
+	api.Post("/batch", runBatch(svc))
+
 	api.Get("/boards/:boardId/events", boardSSE(bus))
 
 	app.Post("/mcp", mcpHandler(mcpServer))
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v3"
+
+	"github.com/aellingwood/cielo/internal/model"
+	"github.com/aellingwood/cielo/internal/service"
+)
+
+func listTemplates(svc *service.Service) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		templates, err := svc.ListTemplates(c.Context(), c.Query("kind"))
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.JSON(templates)
+	}
+}
+
+func createTemplate(svc *service.Service) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		var spec model.TemplateSpec
+		if err := c.Bind().JSON(&spec); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+		}
+		t, err := svc.CreateTemplate(c.Context(), spec, "user")
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(201).JSON(t)
+	}
+}
+
+func getTemplate(svc *service.Service) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		t, err := svc.GetTemplate(c.Context(), c.Params("id"))
+		if err != nil {
+			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.JSON(t)
+	}
+}
+
+func updateTemplate(svc *service.Service) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		var spec model.TemplateSpec
+		if err := c.Bind().JSON(&spec); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+		}
+		t, err := svc.UpdateTemplate(c.Context(), c.Params("id"), spec, "user")
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.JSON(t)
+	}
+}
+
+func deleteTemplate(svc *service.Service) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		if err := svc.DeleteTemplate(c.Context(), c.Params("id"), "user"); err != nil {
+			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.SendStatus(204)
+	}
+}
+
+func createBoardFromTemplate(svc *service.Service) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		var body struct {
+			Name      string            `json:"name"`
+			Variables map[string]string `json:"variables"`
+		}
+		if err := c.Bind().JSON(&body); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+		}
+		b, err := svc.CreateBoardFromTemplate(c.Context(), c.Params("id"), body.Name, body.Variables, "user")
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(201).JSON(b)
+	}
+}
+
+func createCardFromTemplate(svc *service.Service) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		var body struct {
+			ListID    string            `json:"list_id"`
+			Variables map[string]string `json:"variables"`
+			Assignee  string            `json:"assignee"`
+			model.Placement
+		}
+		if err := c.Bind().JSON(&body); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+		}
+		card, err := svc.CreateCardFromTemplate(c.Context(), c.Params("id"), body.ListID, body.Variables, body.Assignee, body.Placement, "user")
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(201).JSON(card)
+	}
+}
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v3"
+
+	"github.com/aellingwood/cielo/internal/model"
+	"github.com/aellingwood/cielo/internal/service"
+)
+
+func listViews(svc *service.Service) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		views, err := svc.ListViews(c.Context(), c.Params("boardId"), "user")
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+		if views == nil {
+			return c.JSON([]any{})
+		}
+		return c.JSON(views)
+	}
+}
+
+func createView(svc *service.Service) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		var spec model.ViewSpec
+		if err := c.Bind().JSON(&spec); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+		}
+		v, err := svc.CreateView(c.Context(), c.Params("boardId"), spec, "user")
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(201).JSON(v)
+	}
+}
+
+func getView(svc *service.Service) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		v, err := svc.GetView(c.Context(), c.Params("id"), "user")
+		if err != nil {
+			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.JSON(v)
+	}
+}
+
+func updateView(svc *service.Service) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		var spec model.ViewSpec
+		if err := c.Bind().JSON(&spec); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+		}
+		v, err := svc.UpdateView(c.Context(), c.Params("id"), spec, "user")
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.JSON(v)
+	}
+}
+
+func deleteView(svc *service.Service) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		if err := svc.DeleteView(c.Context(), c.Params("id"), "user"); err != nil {
+			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.SendStatus(204)
+	}
+}
+
+// runView responds with the view's matching cards in the same shape as
+// search.
+func runView(svc *service.Service) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		id := c.Params("id")
+		if _, err := svc.GetView(c.Context(), id, "user"); err != nil {
+			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+		}
+		cards, next, err := svc.RunView(c.Context(), id, "user", c.Query("include_archived") == "true", pageQuery(c))
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		return sendPage(c, cards, next, nil)
+	}
+}
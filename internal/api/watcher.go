@@ -0,0 +1,49 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v3"
+
+	"github.com/aellingwood/cielo/internal/service"
+)
+
+func listWatchers(svc *service.Service) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		watchers, err := svc.ListWatchers(c.Context(), c.Params("id"))
+		if err != nil {
+			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.JSON(watchers)
+	}
+}
+
+// watchCard adds the watcher named in the body, or the REST actor.
+func watchCard(svc *service.Service) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		var body struct {
+			Watcher string `json:"watcher"`
+		}
+		if len(c.Body()) > 0 {
+			if err := c.Bind().JSON(&body); err != nil {
+				return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+			}
+		}
+		if body.Watcher == "" {
+			body.Watcher = "user"
+		}
+		watchers, err := svc.WatchCard(c.Context(), c.Params("id"), body.Watcher, "user")
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.JSON(watchers)
+	}
+}
+
+func unwatchCard(svc *service.Service) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		watchers, err := svc.UnwatchCard(c.Context(), c.Params("id"), c.Params("watcher"), "user")
+		if err != nil {
+			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.JSON(watchers)
+	}
+}
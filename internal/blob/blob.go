@@ -0,0 +1,104 @@
+// Package blob stores the contents of card attachments outside the
+// database.
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNotFound is returned when a key has no stored contents.
+var ErrNotFound = errors.New("blob not found")
+
+// Store keeps blobs by key. Keys are chosen by the caller and must not
+// contain path separators.
+type Store interface {
+	// Put stores everything read from r under key, replacing any previous
+	// contents. A failed Put leaves nothing behind.
+	Put(ctx context.Context, key string, r io.Reader) error
+	// Open returns a reader over the contents stored under key.
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the contents stored under key. Deleting a missing key
+	// is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// FSStore keeps blobs as files in a directory on the local filesystem.
+type FSStore struct {
+	dir string
+}
+
+// NewFSStore returns a store rooted at dir, creating the directory if it
+// does not exist.
+func NewFSStore(dir string) (*FSStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FSStore{dir: dir}, nil
+}
+
+func (s *FSStore) path(key string) (string, error) {
+	if key == "" || key == "." || key == ".." || strings.ContainsAny(key, `/\`) {
+		return "", fmt.Errorf("invalid blob key: %q", key)
+	}
+	return filepath.Join(s.dir, key), nil
+}
+
+// Put writes to a temporary file and renames it into place, so that
+// readers never see partial contents.
+func (s *FSStore) Put(ctx context.Context, key string, r io.Reader) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(s.dir, ".upload-*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+func (s *FSStore) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (s *FSStore) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
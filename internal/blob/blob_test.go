@@ -0,0 +1,64 @@
+package blob_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/aellingwood/cielo/internal/blob"
+)
+
+func TestFSStore(t *testing.T) {
+	dir := t.TempDir()
+	s, err := blob.NewFSStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	if err := s.Put(ctx, "a", strings.NewReader("hello")); err != nil {
+		t.Fatal(err)
+	}
+	r, err := s.Open(ctx, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(r)
+	r.Close()
+	if string(data) != "hello" {
+		t.Errorf("read %q, want hello", data)
+	}
+
+	if err := s.Put(ctx, "b", io.MultiReader(strings.NewReader("partial"), failingReader{})); err == nil {
+		t.Error("expected a failed read to fail the put")
+	}
+	if _, err := s.Open(ctx, "b"); !errors.Is(err, blob.ErrNotFound) {
+		t.Errorf("expected a failed put to leave nothing, got %v", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("expected only the stored blob in the directory, got %d entries", len(entries))
+	}
+
+	for _, key := range []string{"", "..", "../x", `a\b`} {
+		if err := s.Put(ctx, key, strings.NewReader("x")); err == nil {
+			t.Errorf("expected key %q to be rejected", key)
+		}
+	}
+
+	if err := s.Delete(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete(ctx, "a"); err != nil {
+		t.Errorf("expected deleting a missing blob to succeed, got %v", err)
+	}
+	if _, err := s.Open(ctx, "a"); !errors.Is(err, blob.ErrNotFound) {
+		t.Errorf("expected ErrNotFound after delete, got %v", err)
+	}
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) { return 0, errors.New("connection reset") }
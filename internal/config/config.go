@@ -1,16 +1,35 @@
 package config
 
-import "os"
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
 
 type Config struct {
-	HTTPAddr string
-	DBPath   string
+	HTTPAddr       string
+	DBPath         string
+	TrashRetention time.Duration
+	// AttachmentDir holds the contents of card attachments.
+	AttachmentDir string
+	// MaxAttachmentSize is the largest attachment accepted, in bytes.
+	MaxAttachmentSize int64
+	// CrossBoardDependencies allows cards to depend on cards on other
+	// boards.
+	CrossBoardDependencies bool
 }
 
 func Load() *Config {
 	return &Config{
-		HTTPAddr: envOr("CIELO_HTTP_ADDR", ":8080"),
-		DBPath:   envOr("CIELO_DB_PATH", "cielo.db"),
+		HTTPAddr:       envOr("CIELO_HTTP_ADDR", ":8080"),
+		DBPath:         envOr("CIELO_DB_PATH", "cielo.db"),
+		TrashRetention: envDuration("CIELO_TRASH_RETENTION", 30*24*time.Hour),
+
+		AttachmentDir:     envOr("CIELO_ATTACHMENT_DIR", "attachments"),
+		MaxAttachmentSize: envInt("CIELO_MAX_ATTACHMENT_SIZE", 25<<20),
+
+		CrossBoardDependencies: envBool("CIELO_CROSS_BOARD_DEPENDENCIES", true),
 	}
 }
 
@@ -20,3 +39,42 @@ func envOr(key, fallback string) string {
 	}
 	return fallback
 }
+
+func envDuration(key string, fallback time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		log.Printf("invalid %s %q, using %s", key, v, fallback)
+		return fallback
+	}
+	return d
+}
+
+func envInt(key string, fallback int64) int64 {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n <= 0 {
+		log.Printf("invalid %s %q, using %d", key, v, fallback)
+		return fallback
+	}
+	return n
+}
+
+func envBool(key string, fallback bool) bool {
+	v := os.Getenv(key)
+	if v == "" {
+		return fallback
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		log.Printf("invalid %s %q, using %t", key, v, fallback)
+		return fallback
+	}
+	return b
+}
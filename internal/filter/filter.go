@@ -0,0 +1,433 @@
+// Package filter parses the card filter language and compiles it to
+// parameterized SQL over the cards (alias c) and lists (alias l) tables.
+//
+// A filter is a whitespace-separated list of terms:
+//
+//	status:in_progress,blocked priority>=high label:coding -assignee:bob
+//	due<7d updated>2026-01-01 has:due sort:-priority,due limit:20 login bug
+//	overdue:true due_within:3d field.estimate_points>=3 has:field.pr_url
+//
+// A term is an optional "-" to negate it, a field, an operator, and a value.
+// The ":" operator matches any of a comma-separated list of values; the
+// comparison operators (<, <=, >, >=, =) apply to priorities and dates.
+// Custom fields are named field.<name>; number and date fields take the
+// comparison operators too.
+// Values may be double-quoted to include spaces or commas. Words that are
+// not terms form a full-text query and are left for the caller to match.
+package filter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/aellingwood/cielo/internal/model"
+)
+
+// MaxLimit caps the number of results a filter may request.
+const MaxLimit = 500
+
+// Query is a parsed filter.
+type Query struct {
+	Terms []Term
+	// Text holds the words that are not terms, in FTS5 query syntax.
+	Text  string
+	Sort  []Sort
+	Limit int
+	// Fields gives the type of each of the board's custom fields by name.
+	// It must be set before compiling a query that uses custom fields.
+	Fields map[string]string
+}
+
+// Term restricts one field. Values are alternatives for the ":" operator
+// and hold a single value for comparisons.
+type Term struct {
+	Field  string
+	Op     string
+	Values []string
+	Negate bool
+}
+
+// Sort orders results by one field.
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+// Add appends a ":" term matching any of values. It is a no-op when values
+// is empty.
+func (q *Query) Add(field string, values ...string) {
+	if len(values) == 0 {
+		return
+	}
+	q.Terms = append(q.Terms, Term{Field: field, Op: ":", Values: values})
+}
+
+// AddText appends plain search words to the full-text query. Phrases,
+// prefix* words, AND / OR / NOT, and parentheses keep their FTS5 meaning;
+// other words that FTS5 would reject as syntax, such as v1.2 or foo-bar,
+// are quoted to be matched as they are.
+func (q *Query) AddText(text string) {
+	text = quoteText(strings.TrimSpace(text))
+	if text == "" {
+		return
+	}
+	if q.Text == "" {
+		q.Text = text
+		return
+	}
+	q.Text += " " + text
+}
+
+type kind int
+
+const (
+	kindText kind = iota
+	kindPriority
+	kindDate
+	kindHas
+	kindBool
+	kindOffset
+)
+
+// fields lists the filterable fields and the kind of value each takes.
+var fields = map[string]kind{
+	"status":   kindText,
+	"assignee": kindText,
+	"label":    kindText,
+	"list":     kindText,
+	"priority": kindPriority,
+	"due":      kindDate,
+	"created":  kindDate,
+	"updated":  kindDate,
+	"has":      kindHas,
+	// overdue and due_within only match cards that are not done.
+	"overdue":    kindBool,
+	"due_within": kindOffset,
+}
+
+// fieldPrefix introduces custom field names, as in field.estimate_points.
+const fieldPrefix = "field."
+
+// hasValues are the attributes the has: field can test for, besides custom
+// fields.
+var hasValues = map[string]bool{"assignee": true, "due": true, "label": true}
+
+// sortFields are the fields results can be sorted by.
+var sortFields = map[string]bool{
+	"position": true, "priority": true, "due": true, "created": true,
+	"updated": true, "title": true, "status": true,
+}
+
+var priorityRank = map[string]int{
+	model.PriorityLow:      0,
+	model.PriorityMedium:   1,
+	model.PriorityHigh:     2,
+	model.PriorityCritical: 3,
+}
+
+var priorities = []string{model.PriorityLow, model.PriorityMedium, model.PriorityHigh, model.PriorityCritical}
+
+// operators are tried longest first so that ">=" is not read as ">".
+var operators = []string{">=", "<=", ":", ">", "<", "="}
+
+// Parse parses a filter expression. An empty expression yields an empty
+// query.
+func Parse(expr string) (*Query, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+	q := &Query{}
+	var text []string
+	for _, tok := range tokens {
+		field, op, value, negate, ok := splitTerm(tok)
+		if !ok {
+			if strings.HasPrefix(tok, "-") {
+				return nil, fmt.Errorf("filter: cannot negate %q; negation applies to field terms", tok)
+			}
+			text = append(text, quoteWord(tok))
+			continue
+		}
+		switch field {
+		case "sort":
+			if op != ":" || negate {
+				return nil, fmt.Errorf("filter: sort takes the form sort:field,-field")
+			}
+			if err := q.parseSort(value); err != nil {
+				return nil, err
+			}
+		case "limit":
+			if op != ":" || negate {
+				return nil, fmt.Errorf("filter: limit takes the form limit:N")
+			}
+			n, err := strconv.Atoi(value)
+			if err != nil || n <= 0 || n > MaxLimit {
+				return nil, fmt.Errorf("filter: limit must be between 1 and %d", MaxLimit)
+			}
+			q.Limit = n
+		default:
+			values, err := splitValues(value)
+			if err != nil {
+				return nil, err
+			}
+			t := Term{Field: field, Op: op, Values: values, Negate: negate}
+			if err := t.validate(); err != nil {
+				return nil, err
+			}
+			q.Terms = append(q.Terms, t)
+		}
+	}
+	q.Text = strings.Join(text, " ")
+	return q, nil
+}
+
+// quoteText quotes the words of text that are not FTS5 syntax or
+// barewords.
+func quoteText(text string) string {
+	words, err := tokenize(text)
+	if err != nil {
+		// An unterminated quote is just a character to match.
+		words = strings.Fields(text)
+	}
+	for i, w := range words {
+		words[i] = quoteWord(w)
+	}
+	return strings.Join(words, " ")
+}
+
+func quoteWord(w string) string {
+	switch w {
+	case "AND", "OR", "NOT":
+		return w
+	}
+	core := strings.TrimLeft(w, "(")
+	open := w[:len(w)-len(core)]
+	trimmed := strings.TrimRight(core, ")")
+	closing := core[len(trimmed):]
+	core, star := strings.CutSuffix(trimmed, "*")
+	if core == "" {
+		return w
+	}
+	phrase := len(core) >= 2 && core[0] == '"' && core[len(core)-1] == '"' && !strings.Contains(core[1:len(core)-1], `"`)
+	if !phrase && strings.IndexFunc(core, notBareword) >= 0 {
+		core = `"` + strings.ReplaceAll(core, `"`, `""`) + `"`
+	}
+	if star {
+		core += "*"
+	}
+	return open + core + closing
+}
+
+// notBareword reports whether r may not appear in an FTS5 bareword.
+func notBareword(r rune) bool {
+	return r < 0x80 && r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// tokenize splits expr on whitespace outside double quotes. Quotes are kept
+// so that quoted words remain phrases in the text query.
+func tokenize(expr string) ([]string, error) {
+	var tokens []string
+	var cur strings.Builder
+	quoted := false
+	for _, r := range expr {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			cur.WriteRune(r)
+		case unicode.IsSpace(r) && !quoted:
+			if cur.Len() > 0 {
+				tokens = append(tokens, cur.String())
+				cur.Reset()
+			}
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	if quoted {
+		return nil, fmt.Errorf("filter: unterminated quote")
+	}
+	if cur.Len() > 0 {
+		tokens = append(tokens, cur.String())
+	}
+	return tokens, nil
+}
+
+// splitTerm splits a token of the form [-]field<op>value. It reports false
+// for tokens that are not terms.
+func splitTerm(tok string) (field, op, value string, negate, ok bool) {
+	s := tok
+	if strings.HasPrefix(s, "-") {
+		negate = true
+		s = s[1:]
+	}
+	i := 0
+	for i < len(s) && (s[i] >= 'a' && s[i] <= 'z' || s[i] == '_') {
+		i++
+	}
+	if strings.HasPrefix(s[i:], ".") && s[:i]+"." == fieldPrefix {
+		i++
+		for i < len(s) && (s[i] >= 'a' && s[i] <= 'z' || s[i] >= '0' && s[i] <= '9' || s[i] == '_') {
+			i++
+		}
+	}
+	if i == 0 || i == len(s) {
+		return "", "", "", false, false
+	}
+	field = s[:i]
+	for _, o := range operators {
+		if strings.HasPrefix(s[i:], o) {
+			op = o
+			break
+		}
+	}
+	if op == "" {
+		return "", "", "", false, false
+	}
+	// Unknown fields are still reported as terms so that validation rejects
+	// typos instead of silently searching for them as text.
+	return field, op, s[i+len(op):], negate, true
+}
+
+// splitValues splits a comma-separated value list, honouring and removing
+// double quotes.
+func splitValues(value string) ([]string, error) {
+	var values []string
+	var cur strings.Builder
+	quoted := false
+	for _, r := range value {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == ',' && !quoted:
+			values = append(values, cur.String())
+			cur.Reset()
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	values = append(values, cur.String())
+	for _, v := range values {
+		if v == "" {
+			return nil, fmt.Errorf("filter: empty value in %q", value)
+		}
+	}
+	return values, nil
+}
+
+func (q *Query) parseSort(value string) error {
+	for _, key := range strings.Split(value, ",") {
+		desc := strings.HasPrefix(key, "-")
+		key = strings.TrimPrefix(key, "-")
+		if !sortFields[key] {
+			return fmt.Errorf("filter: cannot sort by %q", key)
+		}
+		q.Sort = append(q.Sort, Sort{Field: key, Desc: desc})
+	}
+	return nil
+}
+
+func (t Term) validate() error {
+	k, ok := fields[t.Field]
+	if !ok && !strings.HasPrefix(t.Field, fieldPrefix) {
+		return fmt.Errorf("filter: unknown field %q", t.Field)
+	}
+	if len(t.Values) == 0 {
+		return fmt.Errorf("filter: %s needs a value", t.Field)
+	}
+	if t.Op != ":" && len(t.Values) > 1 {
+		return fmt.Errorf("filter: %s%s takes a single value", t.Field, t.Op)
+	}
+	if !ok {
+		// Custom field values are checked against the field's type when
+		// the query is compiled.
+		if t.Field == fieldPrefix {
+			return fmt.Errorf("filter: %s needs a custom field name", t.Field)
+		}
+		return nil
+	}
+	switch k {
+	case kindText, kindHas:
+		if t.Op != ":" {
+			return fmt.Errorf("filter: %s only supports ':'", t.Field)
+		}
+		if k == kindHas {
+			for _, v := range t.Values {
+				if !hasValues[v] && (!strings.HasPrefix(v, fieldPrefix) || v == fieldPrefix) {
+					return fmt.Errorf("filter: unknown attribute has:%s", v)
+				}
+			}
+		}
+	case kindPriority:
+		for _, v := range t.Values {
+			if _, ok := priorityRank[v]; !ok {
+				return fmt.Errorf("filter: invalid priority %q", v)
+			}
+		}
+	case kindDate:
+		for _, v := range t.Values {
+			if _, _, err := resolveDate(v, time.Now()); err != nil {
+				return err
+			}
+		}
+	case kindBool:
+		if t.Op != ":" || len(t.Values) > 1 {
+			return fmt.Errorf("filter: %s takes the form %s:true or %s:false", t.Field, t.Field, t.Field)
+		}
+		if _, err := strconv.ParseBool(t.Values[0]); err != nil {
+			return fmt.Errorf("filter: invalid %s value %q; use true or false", t.Field, t.Values[0])
+		}
+	case kindOffset:
+		if t.Op != ":" || len(t.Values) > 1 {
+			return fmt.Errorf("filter: %s takes the form %s:<offset>, e.g. %s:3d", t.Field, t.Field, t.Field)
+		}
+		if off, ok := parseOffset(t.Values[0]); !ok || off <= 0 {
+			return fmt.Errorf("filter: invalid %s value %q; use a positive offset like 12h, 3d, or 2w", t.Field, t.Values[0])
+		}
+	}
+	return nil
+}
+
+// resolveDate turns a date value into the half-open interval [from, to) it
+// denotes. Calendar dates and "today" cover a whole UTC day; timestamps,
+// "now", and offsets such as 7d, -2w, or 12h denote an instant, for which
+// from equals to.
+func resolveDate(v string, now time.Time) (from, to time.Time, err error) {
+	now = now.UTC()
+	switch v {
+	case "now":
+		return now, now, nil
+	case "today":
+		day := now.Truncate(24 * time.Hour)
+		return day, day.Add(24 * time.Hour), nil
+	}
+	if d, err := time.Parse("2006-01-02", v); err == nil {
+		return d, d.Add(24 * time.Hour), nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t.UTC(), t.UTC(), nil
+	}
+	if off, ok := parseOffset(v); ok {
+		t := now.Add(off)
+		return t, t, nil
+	}
+	return time.Time{}, time.Time{}, fmt.Errorf("filter: invalid date %q; use YYYY-MM-DD, RFC 3339, now, today, or an offset like 7d or -2w", v)
+}
+
+// parseOffset parses a signed offset in hours, days, or weeks.
+func parseOffset(v string) (time.Duration, bool) {
+	if len(v) < 2 {
+		return 0, false
+	}
+	unit := map[byte]time.Duration{'h': time.Hour, 'd': 24 * time.Hour, 'w': 7 * 24 * time.Hour}[v[len(v)-1]]
+	if unit == 0 {
+		return 0, false
+	}
+	n, err := strconv.Atoi(v[:len(v)-1])
+	if err != nil {
+		return 0, false
+	}
+	return time.Duration(n) * unit, true
+}
@@ -0,0 +1,424 @@
+package filter_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aellingwood/cielo/internal/filter"
+)
+
+var now = time.Date(2026, 3, 10, 15, 30, 0, 0, time.UTC)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		expr  string
+		terms []filter.Term
+		text  string
+		sort  []filter.Sort
+		limit int
+	}{
+		{expr: ""},
+		{expr: "  login   bug ", text: "login bug"},
+		{
+			expr:  "status:in_progress,blocked",
+			terms: []filter.Term{{Field: "status", Op: ":", Values: []string{"in_progress", "blocked"}}},
+		},
+		{
+			expr:  "-assignee:bob",
+			terms: []filter.Term{{Field: "assignee", Op: ":", Values: []string{"bob"}, Negate: true}},
+		},
+		{
+			expr:  "priority>=high",
+			terms: []filter.Term{{Field: "priority", Op: ">=", Values: []string{"high"}}},
+		},
+		{
+			expr:  `label:"needs review",coding`,
+			terms: []filter.Term{{Field: "label", Op: ":", Values: []string{"needs review", "coding"}}},
+		},
+		{
+			expr: `list:"In Progress" "exact phrase" crash`,
+			terms: []filter.Term{
+				{Field: "list", Op: ":", Values: []string{"In Progress"}},
+			},
+			text: `"exact phrase" crash`,
+		},
+		{
+			expr: "due<7d updated>2026-01-01 created<=now",
+			terms: []filter.Term{
+				{Field: "due", Op: "<", Values: []string{"7d"}},
+				{Field: "updated", Op: ">", Values: []string{"2026-01-01"}},
+				{Field: "created", Op: "<=", Values: []string{"now"}},
+			},
+		},
+		{
+			expr:  "has:due,label -has:assignee",
+			terms: []filter.Term{{Field: "has", Op: ":", Values: []string{"due", "label"}}, {Field: "has", Op: ":", Values: []string{"assignee"}, Negate: true}},
+		},
+		{
+			expr: "overdue:true due_within:3d",
+			terms: []filter.Term{
+				{Field: "overdue", Op: ":", Values: []string{"true"}},
+				{Field: "due_within", Op: ":", Values: []string{"3d"}},
+			},
+		},
+		{
+			expr: "field.estimate_points>=3 -field.model_used:small,large has:field.pr_url",
+			terms: []filter.Term{
+				{Field: "field.estimate_points", Op: ">=", Values: []string{"3"}},
+				{Field: "field.model_used", Op: ":", Values: []string{"small", "large"}, Negate: true},
+				{Field: "has", Op: ":", Values: []string{"field.pr_url"}},
+			},
+		},
+		{
+			expr:  "sort:-priority,due limit:20",
+			sort:  []filter.Sort{{Field: "priority", Desc: true}, {Field: "due"}},
+			limit: 20,
+		},
+		{expr: "bug OR crash NOT flaky", text: "bug OR crash NOT flaky"},
+		{expr: "prefix* status", text: "prefix* status"},
+		{expr: "v1.2 foo-bar c++ status:done", text: `"v1.2" "foo-bar" "c++"`,
+			terms: []filter.Term{{Field: "status", Op: ":", Values: []string{"done"}}}},
+	}
+	for _, tt := range tests {
+		q, err := filter.Parse(tt.expr)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.expr, err)
+			continue
+		}
+		if !reflect.DeepEqual(q.Terms, tt.terms) {
+			t.Errorf("Parse(%q) terms = %+v, want %+v", tt.expr, q.Terms, tt.terms)
+		}
+		if q.Text != tt.text {
+			t.Errorf("Parse(%q) text = %q, want %q", tt.expr, q.Text, tt.text)
+		}
+		if !reflect.DeepEqual(q.Sort, tt.sort) {
+			t.Errorf("Parse(%q) sort = %+v, want %+v", tt.expr, q.Sort, tt.sort)
+		}
+		if q.Limit != tt.limit {
+			t.Errorf("Parse(%q) limit = %d, want %d", tt.expr, q.Limit, tt.limit)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{`label:"unterminated`, "unterminated quote"},
+		{"colour:red", `unknown field "colour"`},
+		{"priority:urgent", `invalid priority "urgent"`},
+		{"priority>high,low", "single value"},
+		{"status>done", "only supports ':'"},
+		{"due<tomorrow", `invalid date "tomorrow"`},
+		{"due<7x", `invalid date "7x"`},
+		{"has:owner", "unknown attribute has:owner"},
+		{"status:a,,b", "empty value"},
+		{"status:", "empty value"},
+		{"sort:color", `cannot sort by "color"`},
+		{"sort>due", "sort takes the form"},
+		{"limit:0", "limit must be between"},
+		{"limit:9999", "limit must be between"},
+		{"limit:ten", "limit must be between"},
+		{"-flaky", "cannot negate"},
+		{"overdue:maybe", `invalid overdue value "maybe"`},
+		{"overdue:true,false", "overdue takes the form"},
+		{"overdue>true", "overdue takes the form"},
+		{"due_within:-3d", `invalid due_within value "-3d"`},
+		{"due_within:soon", `invalid due_within value "soon"`},
+		{"due_within<3d", "due_within takes the form"},
+		{"field.:x", "needs a custom field name"},
+		{"has:field.", "unknown attribute has:field."},
+		{"field.points>1,2", "single value"},
+	}
+	for _, tt := range tests {
+		_, err := filter.Parse(tt.expr)
+		if err == nil {
+			t.Errorf("Parse(%q): expected error containing %q", tt.expr, tt.want)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Parse(%q) error = %q, want it to contain %q", tt.expr, err, tt.want)
+		}
+	}
+}
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		expr  string
+		where []string
+		args  []any
+		order string
+	}{
+		{expr: "login"},
+		{
+			expr:  "status:in_progress,blocked",
+			where: []string{"c.status IN (?, ?)"},
+			args:  []any{"in_progress", "blocked"},
+		},
+		{
+			expr:  "-assignee:bob,none",
+			where: []string{"NOT c.assignee IN (?, ?)"},
+			args:  []any{"bob", ""},
+		},
+		{
+			expr:  "list:Todo",
+			where: []string{"l.name IN (?)"},
+			args:  []any{"Todo"},
+		},
+		{
+			expr:  "label:coding",
+			where: []string{"EXISTS (SELECT 1 FROM card_labels cl JOIN labels lb ON cl.label_id = lb.id WHERE cl.card_id = c.id AND lb.name IN (?))"},
+			args:  []any{"coding"},
+		},
+		{
+			expr:  "priority>=high",
+			where: []string{"c.priority IN (?, ?)"},
+			args:  []any{"high", "critical"},
+		},
+		{
+			expr:  "priority<medium",
+			where: []string{"c.priority IN (?)"},
+			args:  []any{"low"},
+		},
+		{
+			expr:  "priority>critical",
+			where: []string{"0"},
+		},
+		{
+			expr:  "priority:low,critical",
+			where: []string{"c.priority IN (?, ?)"},
+			args:  []any{"low", "critical"},
+		},
+		{
+			expr:  "due<7d",
+			where: []string{"(c.due_date < ?)"},
+			args:  []any{"2026-03-17T15:30:00.000Z"},
+		},
+		{
+			expr:  "-due<7d",
+			where: []string{"(c.due_date IS NULL OR NOT (c.due_date < ?))"},
+			args:  []any{"2026-03-17T15:30:00.000Z"},
+		},
+		{
+			expr:  "updated>-2w",
+			where: []string{"(c.updated_at > ?)"},
+			args:  []any{"2026-02-24T15:30:00.000Z"},
+		},
+		{
+			expr:  "updated>2026-01-01",
+			where: []string{"(c.updated_at >= ?)"},
+			args:  []any{"2026-01-02T00:00:00.000Z"},
+		},
+		{
+			expr:  "created<=2026-01-01",
+			where: []string{"(c.created_at < ?)"},
+			args:  []any{"2026-01-02T00:00:00.000Z"},
+		},
+		{
+			expr:  "created>=2026-01-01T12:00:00+02:00",
+			where: []string{"(c.created_at >= ?)"},
+			args:  []any{"2026-01-01T10:00:00.000Z"},
+		},
+		{
+			expr:  "due:today,2026-03-12",
+			where: []string{"((c.due_date >= ? AND c.due_date < ?) OR (c.due_date >= ? AND c.due_date < ?))"},
+			args:  []any{"2026-03-10T00:00:00.000Z", "2026-03-11T00:00:00.000Z", "2026-03-12T00:00:00.000Z", "2026-03-13T00:00:00.000Z"},
+		},
+		{
+			expr:  "due=1d",
+			where: []string{"(c.due_date >= ? AND c.due_date < ?)"},
+			args:  []any{"2026-03-11T00:00:00.000Z", "2026-03-12T00:00:00.000Z"},
+		},
+		{
+			expr:  "has:due,assignee",
+			where: []string{"(c.due_date IS NOT NULL OR c.assignee != '')"},
+		},
+		{
+			expr:  "-has:label",
+			where: []string{"NOT (EXISTS (SELECT 1 FROM card_labels cl JOIN labels lb ON cl.label_id = lb.id WHERE cl.card_id = c.id))"},
+		},
+		{
+			expr:  "overdue:true",
+			where: []string{"(c.due_date IS NOT NULL AND c.due_date < ? AND c.status != 'done')"},
+			args:  []any{"2026-03-10T15:30:00.000Z"},
+		},
+		{
+			expr:  "overdue:false",
+			where: []string{"NOT (c.due_date IS NOT NULL AND c.due_date < ? AND c.status != 'done')"},
+			args:  []any{"2026-03-10T15:30:00.000Z"},
+		},
+		{
+			expr:  "due_within:2d",
+			where: []string{"(c.due_date IS NOT NULL AND c.due_date >= ? AND c.due_date < ? AND c.status != 'done')"},
+			args:  []any{"2026-03-10T15:30:00.000Z", "2026-03-12T15:30:00.000Z"},
+		},
+		{
+			expr:  "-due_within:12h",
+			where: []string{"NOT (c.due_date IS NOT NULL AND c.due_date >= ? AND c.due_date < ? AND c.status != 'done')"},
+			args:  []any{"2026-03-10T15:30:00.000Z", "2026-03-11T03:30:00.000Z"},
+		},
+		{
+			expr:  "sort:-priority,due,title",
+			order: "CASE c.priority WHEN 'low' THEN 0 WHEN 'medium' THEN 1 WHEN 'high' THEN 2 WHEN 'critical' THEN 3 END DESC, c.due_date IS NULL ASC, COALESCE(c.due_date, '') ASC, c.title ASC",
+		},
+		{
+			expr:  "status:done -label:wontfix sort:-updated",
+			where: []string{"c.status IN (?)", "NOT EXISTS (SELECT 1 FROM card_labels cl JOIN labels lb ON cl.label_id = lb.id WHERE cl.card_id = c.id AND lb.name IN (?))"},
+			args:  []any{"done", "wontfix"},
+			order: "c.updated_at DESC",
+		},
+	}
+	for _, tt := range tests {
+		q, err := filter.Parse(tt.expr)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.expr, err)
+			continue
+		}
+		sql, err := filter.Compile(q, now)
+		if err != nil {
+			t.Errorf("Compile(%q): %v", tt.expr, err)
+			continue
+		}
+		if !reflect.DeepEqual(sql.Where, tt.where) {
+			t.Errorf("Compile(%q) where = %q, want %q", tt.expr, sql.Where, tt.where)
+		}
+		if !reflect.DeepEqual(sql.Args, tt.args) {
+			t.Errorf("Compile(%q) args = %v, want %v", tt.expr, sql.Args, tt.args)
+		}
+		if sql.OrderBy != tt.order {
+			t.Errorf("Compile(%q) order = %q, want %q", tt.expr, sql.OrderBy, tt.order)
+		}
+	}
+}
+
+func TestCompileKeepsValuesOutOfSQL(t *testing.T) {
+	q, err := filter.Parse(`label:"x') OR 1=1 --" status:"done'; DROP TABLE cards; --"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sql, err := filter.Compile(q, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, w := range sql.Where {
+		if strings.Contains(w, "DROP") || strings.Contains(w, "1=1") {
+			t.Errorf("value leaked into SQL: %s", w)
+		}
+	}
+	if len(sql.Args) != 2 {
+		t.Errorf("expected values as args, got %v", sql.Args)
+	}
+}
+
+func TestAddTextQuotesPunctuation(t *testing.T) {
+	tests := []struct{ text, want string }{
+		{"login bug", "login bug"},
+		{"v1.2 foo-bar pr_url:", `"v1.2" "foo-bar" "pr_url:"`},
+		{`"exact phrase" log* AND (a OR b)`, `"exact phrase" log* AND (a OR b)`},
+		{"api.v2* NOT x", `"api.v2"* NOT x`},
+		{`say"hi`, `"say""hi"`},
+		{`"unterminated`, `"""unterminated"`},
+	}
+	for _, tt := range tests {
+		var q filter.Query
+		q.AddText(tt.text)
+		if q.Text != tt.want {
+			t.Errorf("AddText(%q) = %q, want %q", tt.text, q.Text, tt.want)
+		}
+	}
+}
+
+func TestCompileValidatesBuiltQueries(t *testing.T) {
+	q := &filter.Query{}
+	q.Add("assignee", "alice")
+	q.Add("status")
+	q.AddText("login")
+	q.AddText("bug")
+	if len(q.Terms) != 1 || q.Text != "login bug" {
+		t.Fatalf("unexpected query: %+v", q)
+	}
+	if _, err := filter.Compile(q, now); err != nil {
+		t.Fatal(err)
+	}
+	q.Terms = append(q.Terms, filter.Term{Field: "colour", Op: ":", Values: []string{"red"}})
+	if _, err := filter.Compile(q, now); err == nil {
+		t.Error("expected unknown field to be rejected")
+	}
+}
+
+func TestCompileCustomFields(t *testing.T) {
+	const exists = "EXISTS (SELECT 1 FROM card_field_values fv JOIN custom_fields cf ON cf.id = fv.field_id" +
+		" WHERE fv.card_id = c.id AND cf.board_id = l.board_id AND cf.name = ?"
+	fields := map[string]string{"points": "number", "shipped": "date", "model": "enum", "pr_url": "url"}
+	tests := []struct {
+		expr  string
+		where []string
+		args  []any
+	}{
+		{
+			expr:  "field.points>=3",
+			where: []string{exists + " AND (CAST(fv.value AS REAL) >= ?))"},
+			args:  []any{"points", 3.0},
+		},
+		{
+			expr:  "field.points:1,2.5",
+			where: []string{exists + " AND (CAST(fv.value AS REAL) = ? OR CAST(fv.value AS REAL) = ?))"},
+			args:  []any{"points", 1.0, 2.5},
+		},
+		{
+			expr:  "field.shipped<2026-01-01",
+			where: []string{exists + " AND (fv.value < ?))"},
+			args:  []any{"shipped", "2026-01-01T00:00:00.000Z"},
+		},
+		{
+			expr:  "-field.model:small,large",
+			where: []string{"NOT " + exists + " AND fv.value IN (?, ?))"},
+			args:  []any{"model", "small", "large"},
+		},
+		{
+			expr:  "has:due,field.pr_url",
+			where: []string{"(c.due_date IS NOT NULL OR " + exists + "))"},
+			args:  []any{"pr_url"},
+		},
+	}
+	for _, tt := range tests {
+		q, err := filter.Parse(tt.expr)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.expr, err)
+			continue
+		}
+		q.Fields = fields
+		sql, err := filter.Compile(q, now)
+		if err != nil {
+			t.Errorf("Compile(%q): %v", tt.expr, err)
+			continue
+		}
+		if !reflect.DeepEqual(sql.Where, tt.where) {
+			t.Errorf("Compile(%q) where = %q, want %q", tt.expr, sql.Where, tt.where)
+		}
+		if !reflect.DeepEqual(sql.Args, tt.args) {
+			t.Errorf("Compile(%q) args = %v, want %v", tt.expr, sql.Args, tt.args)
+		}
+	}
+
+	for expr, want := range map[string]string{
+		"field.colour:red":      `unknown custom field "colour"`,
+		"has:field.colour":      `unknown custom field "colour"`,
+		"field.points>many":     "number field",
+		"field.model>small":     "only supports ':'",
+		"field.shipped<someday": `invalid date "someday"`,
+	} {
+		q, err := filter.Parse(expr)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", expr, err)
+			continue
+		}
+		q.Fields = fields
+		if _, err := filter.Compile(q, now); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Compile(%q) error = %v, want it to contain %q", expr, err, want)
+		}
+	}
+}
@@ -0,0 +1,305 @@
+package filter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aellingwood/cielo/internal/model"
+)
+
+// timeLayout matches the store's encoding of timestamps, which compares
+// correctly as text.
+const timeLayout = "2006-01-02T15:04:05.000Z"
+
+// SQL is a compiled filter. Where holds conditions to be ANDed together and
+// Args their parameters in order. OrderBy is empty when the query does not
+// specify a sort; otherwise it orders by Keys.
+type SQL struct {
+	Where   []string
+	Args    []any
+	OrderBy string
+	Keys    []SortKey
+}
+
+// SortKey is an expression results are sorted by. Key expressions are
+// never NULL, so that callers can resume after a row by comparing them.
+type SortKey struct {
+	Expr string
+	Desc bool
+}
+
+var columns = map[string]string{
+	"status":   "c.status",
+	"assignee": "c.assignee",
+	"list":     "l.name",
+	"priority": "c.priority",
+	"due":      "c.due_date",
+	"created":  "c.created_at",
+	"updated":  "c.updated_at",
+	"title":    "c.title",
+	"position": "c.position",
+}
+
+const labelExists = "EXISTS (SELECT 1 FROM card_labels cl JOIN labels lb ON cl.label_id = lb.id WHERE cl.card_id = c.id"
+
+// fieldExists is completed with a condition on the custom field value fv.
+const fieldExists = "EXISTS (SELECT 1 FROM card_field_values fv JOIN custom_fields cf ON cf.id = fv.field_id" +
+	" WHERE fv.card_id = c.id AND cf.board_id = l.board_id AND cf.name = ?"
+
+const priorityOrder = "CASE c.priority WHEN 'low' THEN 0 WHEN 'medium' THEN 1 WHEN 'high' THEN 2 WHEN 'critical' THEN 3 END"
+
+// Compile translates q into SQL conditions. Relative dates are resolved
+// against now. Custom fields are looked up in q.Fields.
+func Compile(q *Query, now time.Time) (*SQL, error) {
+	out := &SQL{}
+	for _, t := range q.Terms {
+		if err := t.validate(); err != nil {
+			return nil, err
+		}
+		cond, args, err := t.compile(now, q.Fields)
+		if err != nil {
+			return nil, err
+		}
+		if t.Negate {
+			if t.Field == "due" {
+				// Cards without a due date do not match a due term, so they
+				// do match its negation.
+				cond = "(c.due_date IS NULL OR NOT " + cond + ")"
+			} else {
+				cond = "NOT " + cond
+			}
+		}
+		out.Where = append(out.Where, cond)
+		out.Args = append(out.Args, args...)
+	}
+	for _, s := range q.Sort {
+		switch s.Field {
+		case "priority":
+			out.Keys = append(out.Keys, SortKey{priorityOrder, s.Desc})
+		case "due":
+			// Cards without a due date come last either way.
+			out.Keys = append(out.Keys, SortKey{"c.due_date IS NULL", false}, SortKey{"COALESCE(c.due_date, '')", s.Desc})
+		default:
+			out.Keys = append(out.Keys, SortKey{columns[s.Field], s.Desc})
+		}
+	}
+	out.OrderBy = OrderBy(out.Keys)
+	return out, nil
+}
+
+// OrderBy returns the ORDER BY list for keys.
+func OrderBy(keys []SortKey) string {
+	order := make([]string, len(keys))
+	for i, k := range keys {
+		order[i] = k.Expr + " ASC"
+		if k.Desc {
+			order[i] = k.Expr + " DESC"
+		}
+	}
+	return strings.Join(order, ", ")
+}
+
+// After returns a condition, and its arguments, that holds for the rows
+// that sort after the row whose keys have the given values.
+func After(keys []SortKey, values []any) (string, []any) {
+	var alts []string
+	var args []any
+	for i, k := range keys {
+		var conds []string
+		for j := range i {
+			conds = append(conds, keys[j].Expr+" = ?")
+			args = append(args, values[j])
+		}
+		op := " > ?"
+		if k.Desc {
+			op = " < ?"
+		}
+		conds = append(conds, k.Expr+op)
+		args = append(args, values[i])
+		alts = append(alts, "("+strings.Join(conds, " AND ")+")")
+	}
+	return "(" + strings.Join(alts, " OR ") + ")", args
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+func stringArgs(values []string) []any {
+	args := make([]any, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+	return args
+}
+
+func (t Term) compile(now time.Time, types map[string]string) (string, []any, error) {
+	if strings.HasPrefix(t.Field, fieldPrefix) {
+		return compileField(t, types, now)
+	}
+	switch t.Field {
+	case "label":
+		return labelExists + " AND lb.name IN (" + placeholders(len(t.Values)) + "))", stringArgs(t.Values), nil
+	case "assignee":
+		// "none" matches unassigned cards.
+		values := make([]string, len(t.Values))
+		for i, v := range t.Values {
+			if v != "none" {
+				values[i] = v
+			}
+		}
+		return "c.assignee IN (" + placeholders(len(values)) + ")", stringArgs(values), nil
+	case "status", "list":
+		return columns[t.Field] + " IN (" + placeholders(len(t.Values)) + ")", stringArgs(t.Values), nil
+	case "priority":
+		return compilePriority(t)
+	case "due", "created", "updated":
+		return compileDate(columns[t.Field], t, now)
+	case "overdue":
+		cond := "(c.due_date IS NOT NULL AND c.due_date < ? AND c.status != 'done')"
+		if v, _ := strconv.ParseBool(t.Values[0]); !v {
+			cond = "NOT " + cond
+		}
+		return cond, []any{now.UTC().Format(timeLayout)}, nil
+	case "due_within":
+		off, _ := parseOffset(t.Values[0])
+		now = now.UTC()
+		return "(c.due_date IS NOT NULL AND c.due_date >= ? AND c.due_date < ? AND c.status != 'done')",
+			[]any{now.Format(timeLayout), now.Add(off).Format(timeLayout)}, nil
+	case "has":
+		var conds []string
+		var args []any
+		for _, v := range t.Values {
+			if strings.HasPrefix(v, fieldPrefix) {
+				name := strings.TrimPrefix(v, fieldPrefix)
+				if _, ok := types[name]; !ok {
+					return "", nil, fmt.Errorf("filter: unknown custom field %q", name)
+				}
+				conds = append(conds, fieldExists+")")
+				args = append(args, name)
+				continue
+			}
+			switch v {
+			case "assignee":
+				conds = append(conds, "c.assignee != ''")
+			case "due":
+				conds = append(conds, "c.due_date IS NOT NULL")
+			case "label":
+				conds = append(conds, labelExists+")")
+			}
+		}
+		return "(" + strings.Join(conds, " OR ") + ")", args, nil
+	}
+	return "", nil, fmt.Errorf("filter: unknown field %q", t.Field)
+}
+
+// compileField matches cards with a value for a custom field that
+// satisfies the term. Numbers compare numerically and dates like due dates;
+// other types only match exact values.
+func compileField(t Term, types map[string]string, now time.Time) (string, []any, error) {
+	name := strings.TrimPrefix(t.Field, fieldPrefix)
+	typ, ok := types[name]
+	if !ok {
+		return "", nil, fmt.Errorf("filter: unknown custom field %q", name)
+	}
+	args := []any{name}
+	switch typ {
+	case model.FieldNumber:
+		op := t.Op
+		if op == ":" {
+			op = "="
+		}
+		var conds []string
+		for _, v := range t.Values {
+			n, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return "", nil, fmt.Errorf("filter: %s is a number field; invalid value %q", t.Field, v)
+			}
+			conds = append(conds, "CAST(fv.value AS REAL) "+op+" ?")
+			args = append(args, n)
+		}
+		return fieldExists + " AND (" + strings.Join(conds, " OR ") + "))", args, nil
+	case model.FieldDate:
+		cond, dateArgs, err := compileDate("fv.value", t, now)
+		if err != nil {
+			return "", nil, err
+		}
+		return fieldExists + " AND " + cond + ")", append(args, dateArgs...), nil
+	}
+	if t.Op != ":" {
+		return "", nil, fmt.Errorf("filter: %s only supports ':'", t.Field)
+	}
+	args = append(args, stringArgs(t.Values)...)
+	return fieldExists + " AND fv.value IN (" + placeholders(len(t.Values)) + "))", args, nil
+}
+
+// compilePriority expands comparisons into the set of priorities they
+// admit, since priorities are stored by name.
+func compilePriority(t Term) (string, []any, error) {
+	values := t.Values
+	if t.Op != ":" && t.Op != "=" {
+		pivot := priorityRank[t.Values[0]]
+		values = nil
+		for _, p := range priorities {
+			r := priorityRank[p]
+			if t.Op == ">" && r > pivot || t.Op == ">=" && r >= pivot ||
+				t.Op == "<" && r < pivot || t.Op == "<=" && r <= pivot {
+				values = append(values, p)
+			}
+		}
+		if len(values) == 0 {
+			// Nothing is above critical or below low.
+			return "0", nil, nil
+		}
+	}
+	return "c.priority IN (" + placeholders(len(values)) + ")", stringArgs(values), nil
+}
+
+// compileDate compares a timestamp column against the interval a date value
+// denotes. Equality matches the whole day containing the value.
+func compileDate(col string, t Term, now time.Time) (string, []any, error) {
+	var conds []string
+	var args []any
+	for _, v := range t.Values {
+		from, to, err := resolveDate(v, now)
+		if err != nil {
+			return "", nil, err
+		}
+		if from.Equal(to) && (t.Op == ":" || t.Op == "=") {
+			from = from.Truncate(24 * time.Hour)
+			to = from.Add(24 * time.Hour)
+		}
+		f, e := from.Format(timeLayout), to.Format(timeLayout)
+		switch t.Op {
+		case ":", "=":
+			conds = append(conds, "("+col+" >= ? AND "+col+" < ?)")
+			args = append(args, f, e)
+		case "<":
+			conds = append(conds, col+" < ?")
+			args = append(args, f)
+		case ">=":
+			conds = append(conds, col+" >= ?")
+			args = append(args, f)
+		case ">":
+			if from.Equal(to) {
+				conds = append(conds, col+" > ?")
+			} else {
+				conds = append(conds, col+" >= ?")
+			}
+			args = append(args, e)
+		case "<=":
+			if from.Equal(to) {
+				conds = append(conds, col+" <= ?")
+			} else {
+				conds = append(conds, col+" < ?")
+			}
+			args = append(args, e)
+		}
+	}
+	if len(conds) == 1 && strings.HasPrefix(conds[0], "(") {
+		return conds[0], args, nil
+	}
+	return "(" + strings.Join(conds, " OR ") + ")", args, nil
+}
@@ -2,7 +2,10 @@ package mcp
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"fmt"
+	"strconv"
 
 	"github.com/aellingwood/cielo/internal/service"
 )
@@ -59,12 +62,24 @@ func (s *Server) HandleRequest(ctx context.Context, req JSONRPCRequest) JSONRPCR
 		return JSONRPCResponse{JSONRPC: "2.0", ID: req.ID}
 
 	case "tools/list":
+		var params struct {
+			Cursor string `json:"cursor"`
+		}
+		if len(req.Params) > 0 {
+			json.Unmarshal(req.Params, &params)
+		}
+		result, err := s.listTools(params.Cursor)
+		if err != nil {
+			return JSONRPCResponse{
+				JSONRPC: "2.0",
+				ID:      req.ID,
+				Error:   &RPCError{Code: -32602, Message: err.Error()},
+			}
+		}
 		return JSONRPCResponse{
 			JSONRPC: "2.0",
 			ID:      req.ID,
-			Result: map[string]any{
-				"tools": s.tools,
-			},
+			Result:  result,
 		}
 
 	case "tools/call":
@@ -89,3 +104,27 @@ func (s *Server) HandleRequest(ctx context.Context, req JSONRPCRequest) JSONRPCR
 		}
 	}
 }
+
+// toolsPageSize is the number of tools returned per tools/list page.
+const toolsPageSize = 50
+
+// listTools returns the page of tool definitions starting at the offset
+// encoded in cursor, with a nextCursor if more follow.
+func (s *Server) listTools(cursor string) (map[string]any, error) {
+	start := 0
+	if cursor != "" {
+		data, err := base64.RawURLEncoding.DecodeString(cursor)
+		if err == nil {
+			start, err = strconv.Atoi(string(data))
+		}
+		if err != nil || start < 0 || start > len(s.tools) {
+			return nil, fmt.Errorf("invalid cursor: %s", cursor)
+		}
+	}
+	end := min(start+toolsPageSize, len(s.tools))
+	result := map[string]any{"tools": s.tools[start:end]}
+	if end < len(s.tools) {
+		result["nextCursor"] = base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(end)))
+	}
+	return result, nil
+}
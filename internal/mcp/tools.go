@@ -2,8 +2,14 @@ package mcp
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aellingwood/cielo/internal/model"
+	"github.com/aellingwood/cielo/internal/service"
 )
 
 type ToolDef struct {
@@ -19,6 +25,27 @@ func strArg(args map[string]any, key string) string {
 	return ""
 }
 
+// decodeArg re-encodes a structured argument into the target type.
+func decodeArg(args map[string]any, key string, v any) error {
+	raw, ok := args[key]
+	if !ok {
+		return fmt.Errorf("%s is required", key)
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return nil
+}
+
+func boolArg(args map[string]any, key string) bool {
+	v, _ := args[key].(bool)
+	return v
+}
+
 func intArg(args map[string]any, key string) int {
 	if v, ok := args[key].(float64); ok {
 		return int(v)
@@ -26,7 +53,144 @@ func intArg(args map[string]any, key string) int {
 	return 0
 }
 
+// pageArg reads the optional cursor and limit arguments, applying the
+// default and maximum page sizes.
+func pageArg(args map[string]any) model.Page {
+	return model.Page{Cursor: strArg(args, "cursor"), Limit: intArg(args, "limit")}.Clamp()
+}
+
+// paged wraps one page of results with the cursor of the next page.
+func paged[T any](items []T, next string, err error) (any, error) {
+	if err != nil {
+		return nil, err
+	}
+	if items == nil {
+		items = []T{}
+	}
+	result := map[string]any{"items": items}
+	if next != "" {
+		result["next_cursor"] = next
+	}
+	return result, nil
+}
+
+// viewSpecArg reads the view fields present in args, leaving absent ones
+// unset so that updates only touch what was passed.
+func viewSpecArg(args map[string]any) (model.ViewSpec, error) {
+	var spec model.ViewSpec
+	for key, dst := range map[string]**string{"name": &spec.Name, "filter": &spec.Filter, "sort": &spec.Sort} {
+		if v, ok := args[key].(string); ok {
+			*dst = &v
+		}
+	}
+	if _, ok := args["columns"]; ok {
+		var columns []string
+		if err := decodeArg(args, "columns", &columns); err != nil {
+			return spec, err
+		}
+		spec.Columns = &columns
+	}
+	if v, ok := args["private"].(bool); ok {
+		spec.Private = &v
+	}
+	return spec, nil
+}
+
+// fieldSpecArg reads the optional custom field attributes of update_field.
+func fieldSpecArg(args map[string]any) (model.CustomFieldSpec, error) {
+	spec := model.CustomFieldSpec{Placement: placementArg(args)}
+	if v, ok := args["name"].(string); ok {
+		spec.Name = &v
+	}
+	if _, ok := args["options"]; ok {
+		var options []string
+		if err := decodeArg(args, "options", &options); err != nil {
+			return spec, err
+		}
+		spec.Options = &options
+	}
+	return spec, nil
+}
+
+// templateSpecArg reads the template fields present in args, leaving
+// absent ones unset so that updates only touch what was passed.
+func templateSpecArg(args map[string]any) (model.TemplateSpec, error) {
+	var spec model.TemplateSpec
+	if v, ok := args["name"].(string); ok {
+		spec.Name = &v
+	}
+	if v, ok := args["description"].(string); ok {
+		spec.Description = &v
+	}
+	if _, ok := args["board"]; ok {
+		if err := decodeArg(args, "board", &spec.Board); err != nil {
+			return spec, err
+		}
+	}
+	if _, ok := args["card"]; ok {
+		if err := decodeArg(args, "card", &spec.Card); err != nil {
+			return spec, err
+		}
+	}
+	return spec, nil
+}
+
+// varsArg reads the optional variables argument of the template tools.
+func varsArg(args map[string]any) (map[string]string, error) {
+	var vars map[string]string
+	if _, ok := args["variables"]; ok {
+		if err := decodeArg(args, "variables", &vars); err != nil {
+			return nil, err
+		}
+	}
+	return vars, nil
+}
+
+// checklistSpecArg reads the optional checklist item fields of
+// update_checklist_item.
+func checklistSpecArg(args map[string]any) model.ChecklistItemSpec {
+	spec := model.ChecklistItemSpec{Placement: placementArg(args)}
+	if v, ok := args["text"].(string); ok {
+		spec.Text = &v
+	}
+	if v, ok := args["assignee"].(string); ok {
+		spec.Assignee = &v
+	}
+	if v, ok := args["done"].(bool); ok {
+		spec.Done = &v
+	}
+	return spec
+}
+
+// defaultCardsPerList bounds list_lists output so that large boards do not
+// flood the caller's context; the remaining cards are paged with list_cards.
+const defaultCardsPerList = 20
+
+// timeArg reads an optional RFC 3339 timestamp argument.
+func timeArg(args map[string]any, key string) (*time.Time, error) {
+	v := strArg(args, key)
+	if v == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %s", key, v)
+	}
+	return &t, nil
+}
+
+// placementArg reads the optional position, before, and after arguments.
+func placementArg(args map[string]any) model.Placement {
+	p := model.Placement{Before: strArg(args, "before"), After: strArg(args, "after")}
+	if v, ok := args["position"].(float64); ok {
+		pos := int(v)
+		p.Position = &pos
+	}
+	return p
+}
+
 func (s *Server) callTool(ctx context.Context, reqID any, name string, args map[string]any) JSONRPCResponse {
+	ctx = service.WithSource(ctx, fmt.Sprintf("mcp:%v", reqID))
 	result, err := s.executeTool(ctx, name, args)
 	if err != nil {
 		return JSONRPCResponse{
@@ -60,19 +224,46 @@ func (s *Server) executeTool(ctx context.Context, name string, args map[string]a
 
 	switch name {
 	case "list_boards":
-		return s.svc.ListBoards(ctx)
+		return paged(s.svc.ListBoards(ctx, pageArg(args)))
 
 	case "get_board":
-		return s.svc.GetBoard(ctx, strArg(args, "board_id"))
+		sum, err := s.svc.SummarizeBoard(ctx, strArg(args, "board_id"), boolArg(args, "include_cards"), model.Page{Limit: intArg(args, "cards_per_list")}.Clamp().Limit)
+		if err != nil {
+			return nil, err
+		}
+		if etag := strArg(args, "etag"); etag != "" && etag == sum.ETag {
+			return map[string]any{"etag": sum.ETag, "not_modified": true}, nil
+		}
+		return sum, nil
+
+	case "get_workflow":
+		return s.svc.GetWorkflow(ctx, strArg(args, "board_id"))
+
+	case "set_workflow":
+		var wf model.Workflow
+		if err := decodeArg(args, "workflow", &wf); err != nil {
+			return nil, err
+		}
+		return s.svc.SetWorkflow(ctx, strArg(args, "board_id"), &wf, actor)
 
 	case "list_lists":
-		return s.svc.ListListsByBoard(ctx, strArg(args, "board_id"))
+		limit := intArg(args, "cards_per_list")
+		if limit == 0 {
+			limit = defaultCardsPerList
+		}
+		if _, err := s.svc.GetBoard(ctx, strArg(args, "board_id"), false); err != nil {
+			return nil, err
+		}
+		return s.svc.ListListsByBoard(ctx, strArg(args, "board_id"), boolArg(args, "include_archived"), model.Page{Limit: limit}.Clamp().Limit)
+
+	case "list_cards":
+		return paged(s.svc.ListCardsByList(ctx, strArg(args, "list_id"), boolArg(args, "include_archived"), pageArg(args)))
 
 	case "get_card":
 		return s.svc.GetCard(ctx, strArg(args, "card_id"))
 
 	case "search_cards":
-		return s.svc.SearchCards(ctx, strArg(args, "board_id"), strArg(args, "query"), strArg(args, "assignee"), strArg(args, "status"), strArg(args, "label"))
+		return paged(s.svc.SearchCards(ctx, strArg(args, "board_id"), strArg(args, "query"), strArg(args, "assignee"), strArg(args, "status"), strArg(args, "label"), strArg(args, "filter"), boolArg(args, "include_archived"), pageArg(args)))
 
 	case "get_card_dependencies":
 		deps, err := s.svc.GetDependencies(ctx, strArg(args, "card_id"))
@@ -85,29 +276,193 @@ func (s *Server) executeTool(ctx context.Context, name string, args map[string]a
 		}
 		return map[string]any{"blockers": deps, "dependents": dependents}, nil
 
+	case "list_comments":
+		return paged(s.svc.ListComments(ctx, strArg(args, "card_id"), pageArg(args)))
+
+	case "get_notifications":
+		recipient := strArg(args, "recipient")
+		if recipient == "" {
+			recipient = actor
+		}
+		unreadOnly := true
+		if v, ok := args["unread_only"].(bool); ok {
+			unreadOnly = v
+		}
+		return paged(s.svc.ListNotifications(ctx, recipient, unreadOnly, pageArg(args)))
+
 	case "get_activity_log":
-		cardID := strArg(args, "card_id")
+		if cardID := strArg(args, "card_id"); cardID != "" {
+			return paged(s.svc.ListActivityByCard(ctx, cardID, pageArg(args)))
+		}
+		return paged(s.svc.ListActivityByBoard(ctx, strArg(args, "board_id"), pageArg(args)))
+
+	case "get_audit_log":
+		since, err := timeArg(args, "since")
+		if err != nil {
+			return nil, err
+		}
+		until, err := timeArg(args, "until")
+		if err != nil {
+			return nil, err
+		}
 		boardID := strArg(args, "board_id")
+		if boardID == "" {
+			return nil, fmt.Errorf("board_id is required")
+		}
 		limit := intArg(args, "limit")
 		if limit == 0 {
-			limit = 50
+			limit = 100
 		}
-		if cardID != "" {
-			return s.svc.ListActivityByCard(ctx, cardID, limit)
+		return s.svc.ListAudit(ctx, model.AuditFilter{
+			BoardID:    boardID,
+			EntityType: strArg(args, "entity_type"),
+			EntityID:   strArg(args, "entity_id"),
+			Actor:      strArg(args, "filter_actor"),
+			Since:      since,
+			Until:      until,
+			Limit:      limit,
+		}, actor)
+
+	case "list_children":
+		return s.svc.ListChildren(ctx, strArg(args, "card_id"))
+
+	case "get_rollup":
+		return s.svc.GetRollup(ctx, strArg(args, "card_id"))
+
+	case "list_attachments":
+		return s.svc.ListAttachments(ctx, strArg(args, "card_id"))
+
+	case "get_checklist":
+		return s.svc.ListChecklist(ctx, strArg(args, "card_id"))
+
+	case "list_views":
+		return s.svc.ListViews(ctx, strArg(args, "board_id"), actor)
+
+	case "run_view":
+		return paged(s.svc.RunView(ctx, strArg(args, "view_id"), actor, boolArg(args, "include_archived"), pageArg(args)))
+
+	case "create_view":
+		spec, err := viewSpecArg(args)
+		if err != nil {
+			return nil, err
 		}
-		return s.svc.ListActivityByBoard(ctx, boardID, limit)
+		return s.svc.CreateView(ctx, strArg(args, "board_id"), spec, actor)
+
+	case "update_view":
+		spec, err := viewSpecArg(args)
+		if err != nil {
+			return nil, err
+		}
+		return s.svc.UpdateView(ctx, strArg(args, "view_id"), spec, actor)
+
+	case "delete_view":
+		return nil, s.svc.DeleteView(ctx, strArg(args, "view_id"), actor)
+
+	case "list_templates":
+		return s.svc.ListTemplates(ctx, strArg(args, "kind"))
+
+	case "create_template":
+		spec, err := templateSpecArg(args)
+		if err != nil {
+			return nil, err
+		}
+		return s.svc.CreateTemplate(ctx, spec, actor)
+
+	case "update_template":
+		spec, err := templateSpecArg(args)
+		if err != nil {
+			return nil, err
+		}
+		return s.svc.UpdateTemplate(ctx, strArg(args, "template_id"), spec, actor)
+
+	case "delete_template":
+		return nil, s.svc.DeleteTemplate(ctx, strArg(args, "template_id"), actor)
+
+	case "create_board_from_template":
+		vars, err := varsArg(args)
+		if err != nil {
+			return nil, err
+		}
+		return s.svc.CreateBoardFromTemplate(ctx, strArg(args, "template_id"), strArg(args, "name"), vars, actor)
+
+	case "create_card_from_template":
+		vars, err := varsArg(args)
+		if err != nil {
+			return nil, err
+		}
+		return s.svc.CreateCardFromTemplate(ctx, strArg(args, "template_id"), strArg(args, "list_id"), vars, strArg(args, "assignee"), placementArg(args), actor)
+
+	case "list_fields":
+		return s.svc.ListCustomFields(ctx, strArg(args, "board_id"))
+
+	case "create_field":
+		var options []string
+		if _, ok := args["options"]; ok {
+			if err := decodeArg(args, "options", &options); err != nil {
+				return nil, err
+			}
+		}
+		return s.svc.CreateCustomField(ctx, strArg(args, "board_id"), strArg(args, "name"), strArg(args, "type"), options, placementArg(args), actor)
+
+	case "update_field":
+		spec, err := fieldSpecArg(args)
+		if err != nil {
+			return nil, err
+		}
+		return s.svc.UpdateCustomField(ctx, strArg(args, "field_id"), spec, actor)
+
+	case "delete_field":
+		return nil, s.svc.DeleteCustomField(ctx, strArg(args, "field_id"), actor)
 
 	case "create_board":
 		return s.svc.CreateBoard(ctx, strArg(args, "name"), strArg(args, "description"), actor)
 
+	case "create_board_from_plan":
+		var plan model.Plan
+		if text, ok := args["plan"].(string); ok {
+			p, err := service.ParsePlan([]byte(text))
+			if err != nil {
+				return nil, err
+			}
+			plan = *p
+		} else if err := decodeArg(args, "plan", &plan); err != nil {
+			return nil, err
+		}
+		return s.svc.ImportPlan(ctx, &plan, boolArg(args, "dry_run"), actor)
+
+	case "clone_board":
+		return s.svc.CloneBoard(ctx, strArg(args, "board_id"), model.BoardCloneOptions{
+			Name: strArg(args, "name"), Cards: boolArg(args, "cards"), Labels: boolArg(args, "labels"),
+			Dependencies: boolArg(args, "dependencies"), Activity: boolArg(args, "activity"),
+		}, actor)
+
 	case "create_list":
-		return s.svc.CreateList(ctx, strArg(args, "board_id"), strArg(args, "name"), intArg(args, "position"), actor)
+		return s.svc.CreateList(ctx, strArg(args, "board_id"), strArg(args, "name"), strArg(args, "status"), placementArg(args), actor)
 
 	case "create_card":
-		return s.svc.CreateCard(ctx, strArg(args, "list_id"), strArg(args, "title"), strArg(args, "description"), strArg(args, "assignee"), strArg(args, "priority"), actor, intArg(args, "position"))
+		due, err := model.ParseDueDate(strArg(args, "due_date"))
+		if err != nil {
+			return nil, err
+		}
+		return s.svc.CreateCard(ctx, strArg(args, "list_id"), strArg(args, "title"), strArg(args, "description"), strArg(args, "assignee"), strArg(args, "priority"), strArg(args, "parent_id"), due, actor, placementArg(args))
+
+	case "clone_card":
+		opts := model.CardCloneOptions{Title: strArg(args, "title"), Dependencies: boolArg(args, "dependencies"), Placement: placementArg(args)}
+		if _, ok := args["label_map"]; ok {
+			if err := decodeArg(args, "label_map", &opts.LabelMap); err != nil {
+				return nil, err
+			}
+		}
+		return s.svc.CloneCard(ctx, strArg(args, "card_id"), strArg(args, "list_id"), opts, actor)
 
 	case "move_card":
-		return s.svc.MoveCard(ctx, strArg(args, "card_id"), strArg(args, "list_id"), intArg(args, "position"), actor)
+		opts := model.CardMoveOptions{Labels: strArg(args, "labels"), Placement: placementArg(args)}
+		if _, ok := args["label_map"]; ok {
+			if err := decodeArg(args, "label_map", &opts.LabelMap); err != nil {
+				return nil, err
+			}
+		}
+		return s.svc.MoveCard(ctx, strArg(args, "card_id"), strArg(args, "list_id"), opts, actor)
 
 	case "update_card":
 		return s.svc.UpdateCard(ctx, strArg(args, "card_id"), args, actor)
@@ -116,7 +471,47 @@ func (s *Server) executeTool(ctx context.Context, name string, args map[string]a
 		return s.svc.AssignCard(ctx, strArg(args, "card_id"), strArg(args, "assignee"), actor)
 
 	case "add_comment":
-		return nil, s.svc.AddComment(ctx, strArg(args, "card_id"), actor, strArg(args, "text"))
+		return s.svc.AddComment(ctx, strArg(args, "card_id"), strArg(args, "parent_id"), strArg(args, "text"), actor)
+
+	case "edit_comment":
+		return s.svc.EditComment(ctx, strArg(args, "comment_id"), strArg(args, "text"), actor)
+
+	case "delete_comment":
+		return nil, s.svc.DeleteComment(ctx, strArg(args, "comment_id"), actor)
+
+	case "watch_card", "unwatch_card":
+		watcher := strArg(args, "watcher")
+		if watcher == "" {
+			watcher = actor
+		}
+		var watchers []string
+		var err error
+		if name == "watch_card" {
+			watchers, err = s.svc.WatchCard(ctx, strArg(args, "card_id"), watcher, actor)
+		} else {
+			watchers, err = s.svc.UnwatchCard(ctx, strArg(args, "card_id"), watcher, actor)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"watchers": watchers}, nil
+
+	case "ack_notifications":
+		recipient := strArg(args, "recipient")
+		if recipient == "" {
+			recipient = actor
+		}
+		var ids []string
+		if _, ok := args["ids"]; ok {
+			if err := decodeArg(args, "ids", &ids); err != nil {
+				return nil, err
+			}
+		}
+		n, err := s.svc.AckNotifications(ctx, recipient, ids)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"acknowledged": n}, nil
 
 	case "add_dependency":
 		return nil, s.svc.AddDependency(ctx, strArg(args, "card_id"), strArg(args, "depends_on_card_id"), actor)
@@ -124,6 +519,32 @@ func (s *Server) executeTool(ctx context.Context, name string, args map[string]a
 	case "remove_dependency":
 		return nil, s.svc.RemoveDependency(ctx, strArg(args, "card_id"), strArg(args, "depends_on_card_id"), actor)
 
+	case "add_checklist_item":
+		return s.svc.AddChecklistItem(ctx, strArg(args, "card_id"), strArg(args, "text"), strArg(args, "assignee"), placementArg(args), actor)
+
+	case "update_checklist_item":
+		return s.svc.UpdateChecklistItem(ctx, strArg(args, "item_id"), checklistSpecArg(args), actor)
+
+	case "delete_checklist_item":
+		return nil, s.svc.DeleteChecklistItem(ctx, strArg(args, "item_id"), actor)
+
+	case "add_attachment":
+		cardID, name := strArg(args, "card_id"), strArg(args, "name")
+		content, url := strArg(args, "content_base64"), strArg(args, "url")
+		switch {
+		case content != "" && url != "":
+			return nil, fmt.Errorf("pass either content_base64 or url, not both")
+		case url != "":
+			return s.svc.AddAttachmentURL(ctx, cardID, name, url, actor)
+		case content != "":
+			return s.svc.AddAttachment(ctx, cardID, name, strArg(args, "mime_type"),
+				base64.NewDecoder(base64.StdEncoding, strings.NewReader(content)), actor)
+		}
+		return nil, fmt.Errorf("content_base64 or url is required")
+
+	case "delete_attachment":
+		return nil, s.svc.DeleteAttachment(ctx, strArg(args, "attachment_id"), actor)
+
 	case "add_label_to_card":
 		return nil, s.svc.AddLabelToCard(ctx, strArg(args, "card_id"), strArg(args, "label_id"), actor)
 
@@ -134,7 +555,46 @@ func (s *Server) executeTool(ctx context.Context, name string, args map[string]a
 		return nil, s.svc.DeleteCard(ctx, strArg(args, "card_id"), actor)
 
 	case "delete_list":
-		return nil, s.svc.DeleteList(ctx, strArg(args, "card_id"))
+		return nil, s.svc.DeleteList(ctx, strArg(args, "list_id"), actor)
+
+	case "archive_card":
+		return s.svc.ArchiveCard(ctx, strArg(args, "card_id"), actor)
+
+	case "unarchive_card":
+		return s.svc.UnarchiveCard(ctx, strArg(args, "card_id"), actor)
+
+	case "restore_card":
+		return s.svc.RestoreCard(ctx, strArg(args, "card_id"), actor)
+
+	case "archive_list":
+		return s.svc.ArchiveList(ctx, strArg(args, "list_id"), actor)
+
+	case "unarchive_list":
+		return s.svc.UnarchiveList(ctx, strArg(args, "list_id"), actor)
+
+	case "restore_list":
+		return s.svc.RestoreList(ctx, strArg(args, "list_id"), actor)
+
+	case "restore_board":
+		return s.svc.RestoreBoard(ctx, strArg(args, "board_id"), actor)
+
+	case "batch":
+		var ops []model.BatchOp
+		if err := decodeArg(args, "operations", &ops); err != nil {
+			return nil, err
+		}
+		results, err := s.svc.Batch(ctx, ops, actor)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"results": results}, nil
+
+	case "list_trash":
+		lists, cards, err := s.svc.ListTrash(ctx, strArg(args, "board_id"))
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"lists": lists, "cards": cards}, nil
 
 	default:
 		return nil, fmt.Errorf("unknown tool: %s", name)
@@ -143,26 +603,73 @@ func (s *Server) executeTool(ctx context.Context, name string, args map[string]a
 
 func (s *Server) buildToolDefs() []ToolDef {
 	return []ToolDef{
-		{Name: "list_boards", Description: "List all boards", InputSchema: obj()},
-		{Name: "get_board", Description: "Get board with lists and card counts", InputSchema: obj(prop("board_id", "string", "Board ID"))},
-		{Name: "list_lists", Description: "Get all lists for a board with their cards", InputSchema: obj(prop("board_id", "string", "Board ID"))},
-		{Name: "get_card", Description: "Get full card detail including labels, dependencies, and activity", InputSchema: obj(prop("card_id", "string", "Card ID"))},
-		{Name: "search_cards", Description: "Search cards by title, assignee, status, or label", InputSchema: obj(prop("board_id", "string", "Board ID"), optProp("query", "string", "Search text"), optProp("assignee", "string", "Filter by assignee"), optProp("status", "string", "Filter by status"), optProp("label", "string", "Filter by label name"))},
-		{Name: "get_card_dependencies", Description: "Get blockers and dependents for a card", InputSchema: obj(prop("card_id", "string", "Card ID"))},
-		{Name: "get_activity_log", Description: "Get activity history for a card or board", InputSchema: obj(optProp("card_id", "string", "Card ID"), optProp("board_id", "string", "Board ID"), optProp("limit", "integer", "Max entries to return"))},
+		{Name: "list_boards", Description: "List boards, one page at a time", InputSchema: obj(optProp("cursor", "string", "next_cursor from the previous page"), optProp("limit", "integer", "Page size (default: 50, max: 200)"))},
+		{Name: "get_board", Description: "Get a compact board summary: per-list and total card counts by status and priority, overdue and blocked counts, and optionally card headlines. Pass the returned etag back to learn cheaply whether anything changed", InputSchema: obj(prop("board_id", "string", "Board ID"), optProp("include_cards", "boolean", "Include card headlines (id, title, status, priority, assignee, due date, labels) per list"), optProp("cards_per_list", "integer", "Max headlines per list (default: 50, max: 200)"), optProp("etag", "string", "etag from a previous call; if the board is unchanged only {etag, not_modified: true} is returned"))},
+		{Name: "get_workflow", Description: "Get a board's statuses, allowed transitions, and guards", InputSchema: obj(prop("board_id", "string", "Board ID"))},
+		{Name: "set_workflow", Description: "Replace a board's status workflow", InputSchema: obj(prop("board_id", "string", "Board ID"), prop("workflow", "object", "Workflow with statuses, transitions (status to allowed next statuses), guards (status to has_assignee/dependencies_done/checklist_complete), and complete_parents (move a parent card to done when all its children are done)"))},
+		{Name: "list_lists", Description: "Get all lists for a board with the first cards of each; lists with more cards carry cards_next_cursor for list_cards", InputSchema: obj(prop("board_id", "string", "Board ID"), optProp("include_archived", "boolean", "Include archived lists and cards"), optProp("cards_per_list", "integer", "Max cards per list (default: 20, max: 200)"))},
+		{Name: "list_cards", Description: "List the cards in a list, one page at a time", InputSchema: obj(prop("list_id", "string", "List ID"), optProp("include_archived", "boolean", "Include archived cards"), optProp("cursor", "string", "next_cursor from the previous page"), optProp("limit", "integer", "Page size (default: 50, max: 200)"))},
+		{Name: "get_card", Description: "Get full card detail including labels, dependencies, parent and children, checklist, watchers, and activity", InputSchema: obj(prop("card_id", "string", "Card ID"))},
+		{Name: "search_cards", Description: "Full-text search over card titles, descriptions, and comments, ranked by relevance with highlighted snippets; optionally filtered by assignee, status, or label", InputSchema: obj(prop("board_id", "string", "Board ID"), optProp("query", "string", "Search query: words, \"exact phrases\", prefix* terms, combined with AND, OR, NOT"), optProp("assignee", "string", "Filter by assignee"), optProp("status", "string", "Filter by status"), optProp("label", "string", "Filter by label name"), optProp("filter", "string", "Filter expression, e.g. 'status:in_progress,blocked priority>=high label:coding -assignee:bob due<7d updated>2026-01-01 sort:-priority limit:20'. Fields: status, assignee (none for unassigned), label, list, priority, due, created, updated, has:due|assignee|label|field.<name>, and field.<name> for custom fields (number and date fields compare with < <= > >=). Dates: YYYY-MM-DD, RFC 3339, now, today, or offsets like 7d, -2w, 12h. Prefix a term with - to negate it; other words are searched as text"), optProp("include_archived", "boolean", "Include archived cards"), optProp("cursor", "string", "next_cursor from the previous page"), optProp("limit", "integer", "Page size (default: 50, max: 200); a limit in filter takes precedence"))},
+		{Name: "list_trash", Description: "List a board's deleted lists and cards that can still be restored", InputSchema: obj(prop("board_id", "string", "Board ID"))},
+		{Name: "get_card_dependencies", Description: "Get blockers and dependents for a card; those on another board name it in board", InputSchema: obj(prop("card_id", "string", "Card ID"))},
+		{Name: "list_comments", Description: "List a card's comments oldest first; replies carry the parent_id of the comment they answer, and deleted comments keep their place with an empty body", InputSchema: obj(prop("card_id", "string", "Card ID"), optProp("cursor", "string", "next_cursor from the previous page"), optProp("limit", "integer", "Page size (default: 50, max: 200)"))},
+		{Name: "get_notifications", Description: "Read your notification inbox, newest first: mentions, assignments, changes and comments on cards you are assigned or watch, and cards of yours whose dependencies are all done", InputSchema: obj(optProp("recipient", "string", "Whose inbox to read (default: the calling actor)"), optProp("unread_only", "boolean", "Only unread notifications (default: true)"), optProp("cursor", "string", "next_cursor from the previous page"), optProp("limit", "integer", "Page size (default: 50, max: 200)"))},
+		{Name: "get_activity_log", Description: "Get activity history for a card or board", InputSchema: obj(optProp("card_id", "string", "Card ID"), optProp("board_id", "string", "Board ID"), optProp("cursor", "string", "next_cursor from the previous page"), optProp("limit", "integer", "Page size (default: 50, max: 200)"))},
+		{Name: "get_audit_log", Description: "Get the audit trail of changes to a board, including deleted entities", InputSchema: obj(prop("board_id", "string", "Board ID"), optProp("entity_type", "string", "Filter by entity: board, list, card, label"), optProp("entity_id", "string", "Filter by entity ID"), optProp("filter_actor", "string", "Only changes made by this actor"), optProp("since", "string", "RFC 3339 start time (inclusive)"), optProp("until", "string", "RFC 3339 end time (exclusive)"), optProp("limit", "integer", "Max entries to return (default: 100)"))},
+		{Name: "list_children", Description: "List an epic's child cards, wherever they are on the board", InputSchema: obj(prop("card_id", "string", "Parent card ID"))},
+		{Name: "get_rollup", Description: "Get the progress of all of a card's descendants: counts by status, done, and percent complete", InputSchema: obj(prop("card_id", "string", "Parent card ID"))},
+		{Name: "list_attachments", Description: "List a card's attachments: name, size, MIME type, sha256, and uploader. Download contents over REST at /api/v1/attachments/{id}/content", InputSchema: obj(prop("card_id", "string", "Card ID"))},
+		{Name: "get_checklist", Description: "Get a card's checklist items in order", InputSchema: obj(prop("card_id", "string", "Card ID"))},
+		{Name: "list_views", Description: "List a board's saved views: shared ones and those private to you", InputSchema: obj(prop("board_id", "string", "Board ID"))},
+		{Name: "run_view", Description: "Run a saved view, returning its cards like search_cards", InputSchema: obj(prop("view_id", "string", "View ID"), optProp("include_archived", "boolean", "Include archived cards"), optProp("cursor", "string", "next_cursor from the previous page"), optProp("limit", "integer", "Page size (default: 50, max: 200); a limit in the view's filter takes precedence"))},
+		{Name: "create_view", Description: "Save a named filter on a board", InputSchema: obj(prop("board_id", "string", "Board ID"), prop("name", "string", "View name"), optProp("filter", "string", "Filter expression, as for search_cards"), optProp("sort", "string", "Sort keys, e.g. '-priority,due'"), optProp("columns", "array", "Card fields to show: title, status, priority, assignee, labels, list, due, created, updated"), optProp("private", "boolean", "Only visible to you"))},
+		{Name: "update_view", Description: "Change a saved view; omitted fields are kept", InputSchema: obj(prop("view_id", "string", "View ID"), optProp("name", "string", "View name"), optProp("filter", "string", "Filter expression, as for search_cards"), optProp("sort", "string", "Sort keys, e.g. '-priority,due'"), optProp("columns", "array", "Card fields to show"), optProp("private", "boolean", "Only visible to you"))},
+		{Name: "delete_view", Description: "Delete a saved view", InputSchema: obj(prop("view_id", "string", "View ID"))},
+		{Name: "list_templates", Description: "List board and card templates with the {{variables}} each one needs", InputSchema: obj(optProp("kind", "string", "Only templates of this kind: board or card"))},
+		{Name: "create_template", Description: "Save a board template (lists, labels, workflow, and starting cards) or a card template (title, description, priority, labels, and checklist). Text may hold {{name}} placeholders, filled in when the template is used", InputSchema: obj(prop("name", "string", "Template name"), optProp("description", "string", "What the template is for"), optProp("board", "object", "Board template: {description, workflow, lists: [{name, status}], labels: [{name, color}], cards: [{list, title, description, priority, labels: [label names], checklist: [item texts]}]}"), optProp("card", "object", "Card template: {title, description, priority, labels: [label names], checklist: [item texts]}"))},
+		{Name: "update_template", Description: "Change a template; omitted fields are kept and a template's kind cannot change", InputSchema: obj(prop("template_id", "string", "Template ID"), optProp("name", "string", "Template name"), optProp("description", "string", "What the template is for"), optProp("board", "object", "Replacement board template"), optProp("card", "object", "Replacement card template"))},
+		{Name: "delete_template", Description: "Delete a template; boards and cards made from it are kept", InputSchema: obj(prop("template_id", "string", "Template ID"))},
+		{Name: "create_board_from_template", Description: "Create a board with its workflow, lists, labels, and cards from a board template, all at once", InputSchema: obj(prop("template_id", "string", "Board template ID"), optProp("name", "string", "Board name (default: the template's name)"), optProp("variables", "object", "Values for the template's placeholders, e.g. {\"project\": \"Apollo\"}"))},
+		{Name: "create_card_from_template", Description: "Create a card with its labels and checklist from a card template; labels the board lacks are created", InputSchema: obj(prop("template_id", "string", "Card template ID"), prop("list_id", "string", "List ID"), optProp("variables", "object", "Values for the template's placeholders, e.g. {\"service\": \"billing\"}"), optProp("assignee", "string", "Assignee name"), optProp("position", "integer", "Zero-based position in list (default: end)"), optProp("before", "string", "Place before this card ID"), optProp("after", "string", "Place after this card ID"))},
+		{Name: "list_fields", Description: "List a board's custom fields and their types", InputSchema: obj(prop("board_id", "string", "Board ID"))},
+		{Name: "create_field", Description: "Define a custom field on a board; set values with update_card and filter with field.<name> terms", InputSchema: obj(prop("board_id", "string", "Board ID"), prop("name", "string", "Field name: lowercase letters, digits, and underscores, e.g. estimate_points"), prop("type", "string", "Field type: text, number, date, enum, url"), optProp("options", "array", "Allowed values of an enum field"), optProp("position", "integer", "Zero-based position among the board's fields (default: end)"), optProp("before", "string", "Place before this field ID"), optProp("after", "string", "Place after this field ID"))},
+		{Name: "update_field", Description: "Rename or reorder a custom field, or change an enum field's options; omitted attributes are kept", InputSchema: obj(prop("field_id", "string", "Custom field ID"), optProp("name", "string", "New name"), optProp("options", "array", "Allowed values of an enum field"), optProp("position", "integer", "Zero-based position among the board's fields"), optProp("before", "string", "Place before this field ID"), optProp("after", "string", "Place after this field ID"))},
+		{Name: "delete_field", Description: "Delete a custom field and every card's value for it", InputSchema: obj(prop("field_id", "string", "Custom field ID"))},
 		{Name: "create_board", Description: "Create a new board", InputSchema: obj(prop("name", "string", "Board name"), optProp("description", "string", "Board description"))},
-		{Name: "create_list", Description: "Add a list to a board", InputSchema: obj(prop("board_id", "string", "Board ID"), prop("name", "string", "List name"), optProp("position", "integer", "Position in board"))},
-		{Name: "create_card", Description: "Create a card in a list", InputSchema: obj(prop("list_id", "string", "List ID"), prop("title", "string", "Card title"), optProp("description", "string", "Card description"), optProp("assignee", "string", "Assignee name"), optProp("priority", "string", "Priority: low, medium, high, critical"))},
-		{Name: "move_card", Description: "Move a card to a different list and/or position", InputSchema: obj(prop("card_id", "string", "Card ID"), prop("list_id", "string", "Target list ID"), optProp("position", "integer", "Position in target list"))},
-		{Name: "update_card", Description: "Update card fields", InputSchema: obj(prop("card_id", "string", "Card ID"), optProp("title", "string", "New title"), optProp("description", "string", "New description"), optProp("assignee", "string", "New assignee"), optProp("status", "string", "New status"), optProp("priority", "string", "New priority"))},
+		{Name: "create_board_from_plan", Description: "Create a whole board from a plan in one transaction: name, description, optional workflow, lists (name, status; default a single Backlog list), labels (name, color), epics (cards with nested tasks, which become their children), and standalone tasks. Each card has a title and optionally key, list, description, priority, assignee, due_date, labels, checklist, and depends_on (keys of other cards). The plan is checked for unknown lists, labels, and keys and for dependency cycles first. Returns the board, the card ID of each key, and a diff of what was created", InputSchema: obj(prop("plan", "object", "The plan, as an object or as YAML or JSON text"), optProp("dry_run", "boolean", "Only validate the plan and return the diff of what it would create"))},
+		{Name: "clone_board", Description: "Copy a board to try a variant plan: its workflow, lists, and custom fields, plus optionally its labels, cards (with checklists and field values), dependencies between the cards, and card history. Everything copied gets new IDs", InputSchema: obj(prop("board_id", "string", "Board ID"), optProp("name", "string", "Name of the copy (default: the board's name with ' (copy)')"), optProp("labels", "boolean", "Copy labels"), optProp("cards", "boolean", "Copy cards, with their checklists, field values, and parent links"), optProp("dependencies", "boolean", "Copy dependencies between cards (needs cards)"), optProp("activity", "boolean", "Copy card activity and comments with their original authors and times (needs cards)"))},
+		{Name: "create_list", Description: "Add a list to a board", InputSchema: obj(prop("board_id", "string", "Board ID"), prop("name", "string", "List name"), optProp("position", "integer", "Zero-based position in board (default: end)"), optProp("before", "string", "Place before this list ID"), optProp("after", "string", "Place after this list ID"), optProp("status", "string", "Status bound to this list; cards moved here take this status"))},
+		{Name: "create_card", Description: "Create a card in a list", InputSchema: obj(prop("list_id", "string", "List ID"), prop("title", "string", "Card title"), optProp("description", "string", "Card description"), optProp("assignee", "string", "Assignee name"), optProp("priority", "string", "Priority: low, medium, high, critical"), optProp("due_date", "string", "Due date, RFC 3339 (e.g. 2026-03-01T17:00:00Z)"), optProp("parent_id", "string", "Parent (epic) card ID"), optProp("position", "integer", "Zero-based position in list (default: end)"), optProp("before", "string", "Place before this card ID"), optProp("after", "string", "Place after this card ID"))},
+		{Name: "clone_card", Description: "Copy a card, with its labels, checklist, and custom field values, into any list, on the same board or another", InputSchema: obj(prop("card_id", "string", "Card ID"), prop("list_id", "string", "Target list ID"), optProp("title", "string", "Title of the copy (default: the original's)"), optProp("label_map", "object", "Original label ID to target board label ID, or to \"\" to drop it; unmapped labels are matched by name on another board and created if missing"), optProp("dependencies", "boolean", "Make the copy depend on the original's blockers that are on the target board"), optProp("position", "integer", "Zero-based position in list (default: end)"), optProp("before", "string", "Place before this card ID"), optProp("after", "string", "Place after this card ID"))},
+		{Name: "move_card", Description: "Move a card to a different list and/or position, on the same board or another. A card moving boards keeps the custom field values the target board has matching fields for, and takes the target workflow's first status if its own is not in it", InputSchema: obj(prop("card_id", "string", "Card ID"), prop("list_id", "string", "Target list ID"), optProp("labels", "string", "On a move to another board, what to do with the card's labels: create (match by name, creating missing ones; default), match (match by name, dropping missing ones), or drop"), optProp("label_map", "object", "On a move to another board, card label ID to target board label ID, or to \"\" to drop it; overrides labels"), optProp("position", "integer", "Zero-based position in target list (default: end)"), optProp("before", "string", "Place before this card ID"), optProp("after", "string", "Place after this card ID"))},
+		{Name: "update_card", Description: "Update card fields", InputSchema: obj(prop("card_id", "string", "Card ID"), optProp("title", "string", "New title"), optProp("description", "string", "New description"), optProp("assignee", "string", "New assignee"), optProp("status", "string", "New status"), optProp("priority", "string", "New priority"), optProp("due_date", "string", "New due date, RFC 3339; empty string clears it"), optProp("parent_id", "string", "New parent (epic) card ID; empty string detaches the card"), optProp("fields", "object", "Custom field values by field name, e.g. {\"estimate_points\": 3}; null or empty string clears a value"))},
 		{Name: "assign_card", Description: "Assign or unassign a card to an agent", InputSchema: obj(prop("card_id", "string", "Card ID"), prop("assignee", "string", "Agent name (empty to unassign)"))},
-		{Name: "add_comment", Description: "Add a comment to a card's activity log", InputSchema: obj(prop("card_id", "string", "Card ID"), prop("text", "string", "Comment text"))},
-		{Name: "add_dependency", Description: "Create a dependency between cards", InputSchema: obj(prop("card_id", "string", "The blocked card ID"), prop("depends_on_card_id", "string", "The blocking card ID"))},
+		{Name: "add_comment", Description: "Comment on a card, or reply to one of its comments; mention people or agents with @name", InputSchema: obj(prop("card_id", "string", "Card ID"), prop("text", "string", "Comment text (markdown)"), optProp("parent_id", "string", "ID of the comment to reply to"))},
+		{Name: "edit_comment", Description: "Replace the text of a comment", InputSchema: obj(prop("comment_id", "string", "Comment ID"), prop("text", "string", "New comment text (markdown)"))},
+		{Name: "delete_comment", Description: "Delete a comment; replies to it are kept", InputSchema: obj(prop("comment_id", "string", "Comment ID"))},
+		{Name: "watch_card", Description: "Follow a card: get notifications when it is updated, moved, or commented on", InputSchema: obj(prop("card_id", "string", "Card ID"), optProp("watcher", "string", "Who should watch (default: the calling actor)"))},
+		{Name: "unwatch_card", Description: "Stop following a card", InputSchema: obj(prop("card_id", "string", "Card ID"), optProp("watcher", "string", "Who should stop watching (default: the calling actor)"))},
+		{Name: "ack_notifications", Description: "Mark notifications as read", InputSchema: obj(optProp("ids", "array", "IDs of the notifications to mark read (default: all unread)"), optProp("recipient", "string", "Whose inbox (default: the calling actor)"))},
+		{Name: "add_dependency", Description: "Create a dependency between cards, which may be on different boards unless the server disables cross-board dependencies", InputSchema: obj(prop("card_id", "string", "The blocked card ID"), prop("depends_on_card_id", "string", "The blocking card ID"))},
 		{Name: "remove_dependency", Description: "Remove a dependency between cards", InputSchema: obj(prop("card_id", "string", "The blocked card ID"), prop("depends_on_card_id", "string", "The blocking card ID"))},
+		{Name: "add_checklist_item", Description: "Add a step to a card's checklist", InputSchema: obj(prop("card_id", "string", "Card ID"), prop("text", "string", "Item text"), optProp("assignee", "string", "Who the step is for"), optProp("position", "integer", "Zero-based position in the checklist (default: end)"), optProp("before", "string", "Place before this item ID"), optProp("after", "string", "Place after this item ID"))},
+		{Name: "update_checklist_item", Description: "Check off, reopen, edit, or reorder a checklist item; omitted fields are kept", InputSchema: obj(prop("item_id", "string", "Checklist item ID"), optProp("done", "boolean", "Mark the item done or open"), optProp("text", "string", "New text"), optProp("assignee", "string", "New assignee (empty to clear)"), optProp("position", "integer", "Zero-based position in the checklist"), optProp("before", "string", "Place before this item ID"), optProp("after", "string", "Place after this item ID"))},
+		{Name: "delete_checklist_item", Description: "Remove an item from a card's checklist", InputSchema: obj(prop("item_id", "string", "Checklist item ID"))},
+		{Name: "add_attachment", Description: "Attach an artifact such as a log, patch, report, or screenshot to a card, either as base64 contents or as a reference to a URL", InputSchema: obj(prop("card_id", "string", "Card ID"), optProp("name", "string", "File name, e.g. build.log; required with content_base64"), optProp("content_base64", "string", "File contents, base64-encoded"), optProp("url", "string", "http(s) URL to reference instead of uploading contents"), optProp("mime_type", "string", "MIME type; guessed from the name or contents when omitted"))},
+		{Name: "delete_attachment", Description: "Remove an attachment and its stored contents", InputSchema: obj(prop("attachment_id", "string", "Attachment ID"))},
 		{Name: "add_label_to_card", Description: "Tag a card with a label", InputSchema: obj(prop("card_id", "string", "Card ID"), prop("label_id", "string", "Label ID"))},
 		{Name: "remove_label_from_card", Description: "Remove a label from a card", InputSchema: obj(prop("card_id", "string", "Card ID"), prop("label_id", "string", "Label ID"))},
-		{Name: "delete_card", Description: "Delete a card", InputSchema: obj(prop("card_id", "string", "Card ID"))},
-		{Name: "delete_list", Description: "Delete a list and its cards", InputSchema: obj(prop("list_id", "string", "List ID"))},
+		{Name: "archive_card", Description: "Archive a card, hiding it from lists and search", InputSchema: obj(prop("card_id", "string", "Card ID"))},
+		{Name: "unarchive_card", Description: "Bring an archived card back", InputSchema: obj(prop("card_id", "string", "Card ID"))},
+		{Name: "archive_list", Description: "Archive a list and hide its cards", InputSchema: obj(prop("list_id", "string", "List ID"))},
+		{Name: "unarchive_list", Description: "Bring an archived list back", InputSchema: obj(prop("list_id", "string", "List ID"))},
+		{Name: "delete_card", Description: "Move a card to the trash", InputSchema: obj(prop("card_id", "string", "Card ID"))},
+		{Name: "delete_list", Description: "Move a list and its cards to the trash", InputSchema: obj(prop("list_id", "string", "List ID"))},
+		{Name: "restore_card", Description: "Restore a card from the trash", InputSchema: obj(prop("card_id", "string", "Card ID"))},
+		{Name: "restore_list", Description: "Restore a list from the trash", InputSchema: obj(prop("list_id", "string", "List ID"))},
+		{Name: "restore_board", Description: "Restore a board from the trash", InputSchema: obj(prop("board_id", "string", "Board ID"))},
+		{Name: "batch", Description: "Run many operations in one transaction: all of them take effect, or, on the first error, none. Each operation is {op, args, ref}: op is one of " + strings.Join(service.BatchOps, ", ") + ", taking the arguments of the tool of that name (create_label takes board_id, name, color); ref names the ID the operation creates so later operations can pass it as \"$ref\" in any argument. Returns each operation's result and created ID", InputSchema: obj(prop("operations", "array", fmt.Sprintf("Operations to run in order, at most %d", service.MaxBatchOps)))},
 	}
 }
 
@@ -1,6 +1,12 @@
 package model
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,21 +17,41 @@ func NewID() string {
 }
 
 type Board struct {
-	ID          string    `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Workflow    *Workflow  `json:"workflow,omitempty"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// Workflow describes the statuses a board's cards may take and how they may
+// move between them. A status missing from Transitions may move to any other
+// status; Guards lists the checks a card must pass to enter a status.
+// CompleteParents moves a parent card to done once all of its children are
+// done, provided the workflow allows it.
+type Workflow struct {
+	Statuses        []string            `json:"statuses"`
+	Transitions     map[string][]string `json:"transitions,omitempty"`
+	Guards          map[string][]string `json:"guards,omitempty"`
+	CompleteParents bool                `json:"complete_parents,omitempty"`
 }
 
 type List struct {
-	ID        string    `json:"id"`
-	BoardID   string    `json:"board_id"`
-	Name      string    `json:"name"`
-	Position  int       `json:"position"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	Cards     []Card    `json:"cards,omitempty"`
+	ID         string     `json:"id"`
+	BoardID    string     `json:"board_id"`
+	Name       string     `json:"name"`
+	Position   int        `json:"position"`
+	Status     string     `json:"status,omitempty"`
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+	DeletedAt  *time.Time `json:"deleted_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	Cards      []Card     `json:"cards,omitempty"`
+	// CardsCursor is set when Cards holds only the first page of the
+	// list's cards; it continues the listing.
+	CardsCursor string `json:"cards_next_cursor,omitempty"`
 }
 
 type Card struct {
@@ -37,13 +63,226 @@ type Card struct {
 	Assignee     string        `json:"assignee"`
 	Status       string        `json:"status"`
 	Priority     string        `json:"priority"`
+	ParentID     string        `json:"parent_id,omitempty"`
 	DueDate      *time.Time    `json:"due_date,omitempty"`
+	ArchivedAt   *time.Time    `json:"archived_at,omitempty"`
+	DeletedAt    *time.Time    `json:"deleted_at,omitempty"`
 	CreatedAt    time.Time     `json:"created_at"`
 	UpdatedAt    time.Time     `json:"updated_at"`
 	Labels       []Label       `json:"labels,omitempty"`
 	Dependencies []Card        `json:"dependencies,omitempty"`
 	Dependents   []Card        `json:"dependents,omitempty"`
 	Activity     []ActivityLog `json:"activity,omitempty"`
+	// Board is set on dependencies and dependents that are on another
+	// board than the card they are linked to.
+	Board *BoardRef `json:"board,omitempty"`
+	// Parent and Children are set on card details.
+	Parent   *CardHeadline  `json:"parent,omitempty"`
+	Children []CardHeadline `json:"children,omitempty"`
+	// DependencyCount and DependentCount are set when cards are listed,
+	// in place of the full Dependencies and Dependents.
+	DependencyCount int `json:"dependency_count,omitempty"`
+	DependentCount  int `json:"dependent_count,omitempty"`
+	// Checklist is set on card details. ChecklistDone and ChecklistTotal
+	// give the checklist's progress wherever cards are returned.
+	Checklist      []ChecklistItem `json:"checklist,omitempty"`
+	ChecklistDone  int             `json:"checklist_done,omitempty"`
+	ChecklistTotal int             `json:"checklist_total,omitempty"`
+	// Attachments is set on card details.
+	Attachments []Attachment `json:"attachments,omitempty"`
+	// Watchers is set on card details: the actors following the card
+	// besides its assignee.
+	Watchers []string `json:"watchers,omitempty"`
+	// Fields holds the card's custom field values by field name: strings
+	// for text, url, and enum fields, float64 for numbers, and times for
+	// dates.
+	Fields map[string]any `json:"fields,omitempty"`
+	// Score and Snippet are set on full-text search results. Lower scores
+	// rank higher.
+	Score   float64 `json:"score,omitempty"`
+	Snippet string  `json:"snippet,omitempty"`
+}
+
+// Page requests one page of a listing. Cursor is the opaque next_cursor of
+// the previous page, or empty for the first page. A Limit of zero or less
+// means no limit.
+type Page struct {
+	Cursor string
+	Limit  int
+}
+
+// ErrInvalidCursor is returned for page cursors that were not issued by a
+// previous page.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Page size bounds applied to client requests.
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 200
+)
+
+// Clamp applies the default and maximum page sizes to a client-supplied
+// limit.
+func (p Page) Clamp() Page {
+	if p.Limit <= 0 {
+		p.Limit = DefaultPageLimit
+	}
+	if p.Limit > MaxPageLimit {
+		p.Limit = MaxPageLimit
+	}
+	return p
+}
+
+// Placement says where to put a card or list among its siblings. Before and
+// After name a sibling; otherwise Position is a zero-based index. The zero
+// value places the item at the end.
+type Placement struct {
+	Position *int   `json:"position,omitempty"`
+	Before   string `json:"before,omitempty"`
+	After    string `json:"after,omitempty"`
+}
+
+func (p Placement) IsZero() bool {
+	return p.Position == nil && p.Before == "" && p.After == ""
+}
+
+// ChecklistItem is one step of a card's checklist.
+type ChecklistItem struct {
+	ID          string     `json:"id"`
+	CardID      string     `json:"card_id"`
+	Text        string     `json:"text"`
+	Done        bool       `json:"done"`
+	Assignee    string     `json:"assignee,omitempty"`
+	Position    int        `json:"position"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// ChecklistItemSpec holds the checklist item fields to set; nil fields are
+// left unchanged. A non-zero Placement moves the item.
+type ChecklistItemSpec struct {
+	Text     *string `json:"text"`
+	Done     *bool   `json:"done"`
+	Assignee *string `json:"assignee"`
+	Placement
+}
+
+// Attachment is a file attached to a card. Its contents are kept in the
+// blob store, except for attachments that reference a URL, which have none.
+type Attachment struct {
+	ID         string    `json:"id"`
+	CardID     string    `json:"card_id"`
+	Name       string    `json:"name"`
+	Size       int64     `json:"size"`
+	MimeType   string    `json:"mime_type"`
+	SHA256     string    `json:"sha256,omitempty"`
+	URL        string    `json:"url,omitempty"`
+	UploadedBy string    `json:"uploaded_by"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Comment is a markdown comment on a card, optionally replying to another
+// comment on the same card. Mentions lists the names the body mentions with
+// @name. A deleted comment keeps its place in the thread with an empty body.
+type Comment struct {
+	ID        string     `json:"id"`
+	CardID    string     `json:"card_id"`
+	ParentID  string     `json:"parent_id,omitempty"`
+	Author    string     `json:"author"`
+	Body      string     `json:"body"`
+	Mentions  []string   `json:"mentions,omitempty"`
+	EditedAt  *time.Time `json:"edited_at,omitempty"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// Notification tells its recipient about a change to a card that concerns
+// them. ReadAt is set once the recipient has acknowledged it.
+type Notification struct {
+	ID        string     `json:"id"`
+	Recipient string     `json:"recipient"`
+	Type      string     `json:"type"`
+	BoardID   string     `json:"board_id"`
+	CardID    string     `json:"card_id"`
+	CommentID string     `json:"comment_id,omitempty"`
+	Actor     string     `json:"actor"`
+	Message   string     `json:"message"`
+	ReadAt    *time.Time `json:"read_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// Notification types.
+const (
+	NotifyMention       = "mention"
+	NotifyAssigned      = "assigned"
+	NotifyStatusChanged = "status_changed"
+	NotifyUnblocked     = "unblocked"
+	NotifyUpdated       = "card_updated"
+	NotifyMoved         = "card_moved"
+	NotifyCommented     = "commented"
+)
+
+// CustomField is a field defined on a board that its cards can carry a
+// typed value for. Options lists the allowed values of an enum field.
+type CustomField struct {
+	ID        string    `json:"id"`
+	BoardID   string    `json:"board_id"`
+	Name      string    `json:"name"`
+	Type      string    `json:"type"`
+	Options   []string  `json:"options,omitempty"`
+	Position  int       `json:"position"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CustomFieldSpec holds the custom field attributes to change; nil fields
+// are left unchanged. A field's type cannot be changed. A non-zero
+// Placement moves the field.
+type CustomFieldSpec struct {
+	Name    *string   `json:"name"`
+	Options *[]string `json:"options"`
+	Placement
+}
+
+const (
+	FieldText   = "text"
+	FieldNumber = "number"
+	FieldDate   = "date"
+	FieldEnum   = "enum"
+	FieldURL    = "url"
+)
+
+// Validate checks the field's name, type, and options. Names follow the
+// rules for status names so that they can be used in filters.
+func (f *CustomField) Validate() error {
+	if !validName(f.Name) {
+		return fmt.Errorf("invalid custom field name %q: use up to 32 lowercase letters, digits, and underscores", f.Name)
+	}
+	switch f.Type {
+	case FieldText, FieldNumber, FieldDate, FieldURL:
+		if len(f.Options) > 0 {
+			return fmt.Errorf("only enum fields take options")
+		}
+	case FieldEnum:
+		if len(f.Options) == 0 {
+			return fmt.Errorf("enum field %s needs at least one option", f.Name)
+		}
+		seen := map[string]bool{}
+		for _, o := range f.Options {
+			if o == "" {
+				return fmt.Errorf("enum field %s has an empty option", f.Name)
+			}
+			if seen[o] {
+				return fmt.Errorf("enum field %s has a duplicate option: %s", f.Name, o)
+			}
+			seen[o] = true
+		}
+	default:
+		return fmt.Errorf("invalid custom field type %q: use text, number, date, enum, or url", f.Type)
+	}
+	return nil
 }
 
 type CardDependency struct {
@@ -69,6 +308,570 @@ type ActivityLog struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
+// AuditEntry records one mutation of any entity on a board. Before and
+// After hold JSON snapshots of the entity; Before is empty for creations
+// and After is empty for removals.
+type AuditEntry struct {
+	ID         string          `json:"id"`
+	BoardID    string          `json:"board_id"`
+	EntityType string          `json:"entity_type"`
+	EntityID   string          `json:"entity_id"`
+	Action     string          `json:"action"`
+	Actor      string          `json:"actor"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+	Source     string          `json:"source"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// AuditFilter narrows an audit query. Zero-valued fields are ignored, but
+// changes to a private view are only ever returned to the view's owner, the
+// Viewer.
+type AuditFilter struct {
+	BoardID    string
+	EntityType string
+	EntityID   string
+	Actor      string
+	Viewer     string
+	Since      *time.Time
+	Until      *time.Time
+	Limit      int
+}
+
+// CardCounts tallies a set of active cards. Overdue cards are past their
+// due date and not done; blocked cards have the blocked status or wait on a
+// dependency that is not done.
+type CardCounts struct {
+	Total      int            `json:"card_count"`
+	ByStatus   map[string]int `json:"by_status"`
+	ByPriority map[string]int `json:"by_priority"`
+	Overdue    int            `json:"overdue"`
+	Blocked    int            `json:"blocked"`
+}
+
+// NewCardCounts returns empty counts ready to be added to.
+func NewCardCounts() CardCounts {
+	return CardCounts{ByStatus: map[string]int{}, ByPriority: map[string]int{}}
+}
+
+// Add merges o into c.
+func (c *CardCounts) Add(o CardCounts) {
+	c.Total += o.Total
+	for k, n := range o.ByStatus {
+		c.ByStatus[k] += n
+	}
+	for k, n := range o.ByPriority {
+		c.ByPriority[k] += n
+	}
+	c.Overdue += o.Overdue
+	c.Blocked += o.Blocked
+}
+
+// BoardRef identifies a board by ID and name.
+type BoardRef struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// CardHeadline is the short form of a card used in board summaries.
+type CardHeadline struct {
+	ID       string     `json:"id"`
+	Title    string     `json:"title"`
+	Status   string     `json:"status"`
+	Priority string     `json:"priority"`
+	Assignee string     `json:"assignee,omitempty"`
+	DueDate  *time.Time `json:"due_date,omitempty"`
+	Labels   []string   `json:"labels,omitempty"`
+	// ChecklistDone and ChecklistTotal give the checklist's progress.
+	ChecklistDone  int `json:"checklist_done,omitempty"`
+	ChecklistTotal int `json:"checklist_total,omitempty"`
+}
+
+// Headline returns the short form of the card. Labels are included if they
+// have been loaded.
+func (c *Card) Headline() CardHeadline {
+	h := CardHeadline{
+		ID: c.ID, Title: c.Title, Status: c.Status, Priority: c.Priority, Assignee: c.Assignee, DueDate: c.DueDate,
+		ChecklistDone: c.ChecklistDone, ChecklistTotal: c.ChecklistTotal,
+	}
+	for _, l := range c.Labels {
+		h.Labels = append(h.Labels, l.Name)
+	}
+	return h
+}
+
+// Rollup summarizes the progress of a card's descendants: its children,
+// their children, and so on. Cards in the trash are not counted.
+type Rollup struct {
+	CardID   string         `json:"card_id"`
+	Children int            `json:"children"`
+	Total    int            `json:"total"`
+	Done     int            `json:"done"`
+	ByStatus map[string]int `json:"by_status"`
+	// Percent is Done as a whole percentage of Total, or 0 without
+	// descendants.
+	Percent int `json:"percent"`
+}
+
+// ListSummary describes a list by its card counts and, optionally, the
+// headlines of its first cards.
+type ListSummary struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Position int    `json:"position"`
+	Status   string `json:"status,omitempty"`
+	CardCounts
+	Cards       []CardHeadline `json:"cards,omitempty"`
+	CardsCursor string         `json:"cards_next_cursor,omitempty"`
+}
+
+// BoardSummary is a compact view of a board's active lists and cards. ETag
+// identifies its content, so that pollers can tell when it changes.
+type BoardSummary struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	CardCounts
+	Lists []ListSummary `json:"lists"`
+	ETag  string        `json:"etag,omitempty"`
+}
+
+// View is a named, saved card search on a board. Filter is an expression
+// in the filter language and Sort a comma-separated list of sort keys, with
+// "-" for descending. Columns lists the card fields a client should show.
+// Views with an Owner are private to that actor.
+type View struct {
+	ID        string    `json:"id"`
+	BoardID   string    `json:"board_id"`
+	Name      string    `json:"name"`
+	Filter    string    `json:"filter"`
+	Sort      string    `json:"sort"`
+	Columns   []string  `json:"columns"`
+	Owner     string    `json:"owner,omitempty"`
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ViewSpec holds the fields of a view to set on create or update. Nil
+// fields are left unchanged.
+type ViewSpec struct {
+	Name    *string   `json:"name"`
+	Filter  *string   `json:"filter"`
+	Sort    *string   `json:"sort"`
+	Columns *[]string `json:"columns"`
+	Private *bool     `json:"private"`
+}
+
+// BoardCloneOptions says what CloneBoard copies besides the board's
+// workflow, lists, and custom fields. Dependencies and Activity need Cards.
+// Activity copies cards' history and comments with their original authors
+// and times.
+type BoardCloneOptions struct {
+	Name         string `json:"name"`
+	Cards        bool   `json:"cards"`
+	Labels       bool   `json:"labels"`
+	Dependencies bool   `json:"dependencies"`
+	Activity     bool   `json:"activity"`
+}
+
+// CardCloneOptions says how CloneCard copies a card. An empty Title keeps
+// the original's. LabelMap maps the original's labels, by ID, to labels of
+// the target board, or to "" to drop them; other labels are matched by
+// name on the target board and created there if it has none of that name.
+// Dependencies makes the copy depend on the original's blockers on the
+// target board.
+type CardCloneOptions struct {
+	Title        string            `json:"title"`
+	LabelMap     map[string]string `json:"label_map"`
+	Dependencies bool              `json:"dependencies"`
+	Placement
+}
+
+// Label policies say what becomes of a card's labels when it moves to
+// another board, whose labels are its own.
+const (
+	// LabelsCreate matches labels by name on the target board and creates
+	// those it has none of.
+	LabelsCreate = "create"
+	// LabelsMatch matches labels by name and drops those without a match.
+	LabelsMatch = "match"
+	// LabelsDrop drops all of the card's labels.
+	LabelsDrop = "drop"
+)
+
+// CardMoveOptions says where MoveCard places a card and, if it moves to
+// another board, what becomes of its labels: LabelMap maps them, by ID, to
+// labels of the target board, or to "" to drop them, and Labels, one of
+// the label policies, deals with the rest. It defaults to LabelsCreate.
+type CardMoveOptions struct {
+	Labels   string            `json:"labels"`
+	LabelMap map[string]string `json:"label_map"`
+	Placement
+}
+
+func (o CardMoveOptions) Validate() error {
+	switch o.Labels {
+	case "", LabelsCreate, LabelsMatch, LabelsDrop:
+		return nil
+	}
+	return fmt.Errorf("invalid label policy %q: want %s, %s, or %s", o.Labels, LabelsCreate, LabelsMatch, LabelsDrop)
+}
+
+// BatchOp is one operation of a batch. Op names the operation and Args
+// holds its arguments, named as for the MCP tools. Ref names the ID of what
+// the operation creates, so that later operations of the batch may pass it
+// as "$ref".
+type BatchOp struct {
+	Op   string         `json:"op"`
+	Ref  string         `json:"ref,omitempty"`
+	Args map[string]any `json:"args"`
+}
+
+// BatchResult is the outcome of one operation of a batch: the ID of what
+// it created, if anything, and what it returned.
+type BatchResult struct {
+	Op     string `json:"op"`
+	Ref    string `json:"ref,omitempty"`
+	ID     string `json:"id,omitempty"`
+	Result any    `json:"result,omitempty"`
+}
+
+// Template is a reusable blueprint for a new board or card; Kind says
+// which, and the matching one of Board and Card holds it. Its text may hold
+// {{name}} placeholders, which are filled in from variables when the
+// template is used. Variables lists the placeholder names in use.
+type Template struct {
+	ID          string         `json:"id"`
+	Name        string         `json:"name"`
+	Kind        string         `json:"kind"`
+	Description string         `json:"description"`
+	Board       *BoardTemplate `json:"board,omitempty"`
+	Card        *CardTemplate  `json:"card,omitempty"`
+	Variables   []string       `json:"variables"`
+	CreatedBy   string         `json:"created_by"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
+
+// TemplateSpec holds the fields of a template to set on create or update.
+// Nil fields are left unchanged. The kind follows from whether Board or
+// Card is set, and cannot change.
+type TemplateSpec struct {
+	Name        *string        `json:"name"`
+	Description *string        `json:"description"`
+	Board       *BoardTemplate `json:"board"`
+	Card        *CardTemplate  `json:"card"`
+}
+
+const (
+	TemplateBoard = "board"
+	TemplateCard  = "card"
+)
+
+// BoardTemplate lays out a new board: its lists in order, its labels, an
+// optional workflow, and the cards it starts with.
+type BoardTemplate struct {
+	Description string              `json:"description,omitempty"`
+	Workflow    *Workflow           `json:"workflow,omitempty"`
+	Lists       []ListTemplate      `json:"lists"`
+	Labels      []LabelTemplate     `json:"labels,omitempty"`
+	Cards       []BoardCardTemplate `json:"cards,omitempty"`
+}
+
+type ListTemplate struct {
+	Name   string `json:"name"`
+	Status string `json:"status,omitempty"`
+}
+
+type LabelTemplate struct {
+	Name  string `json:"name"`
+	Color string `json:"color,omitempty"`
+}
+
+// BoardCardTemplate is a card a board template starts with, placed in the
+// template list named List.
+type BoardCardTemplate struct {
+	List string `json:"list"`
+	CardTemplate
+}
+
+// CardTemplate describes a new card. Labels are named rather than
+// referenced by ID so that the template can be used on any board.
+type CardTemplate struct {
+	Title       string   `json:"title"`
+	Description string   `json:"description,omitempty"`
+	Priority    string   `json:"priority,omitempty"`
+	Labels      []string `json:"labels,omitempty"`
+	Checklist   []string `json:"checklist,omitempty"`
+}
+
+// Validate checks the template's name and that it holds the blueprint its
+// kind calls for.
+func (t *Template) Validate() error {
+	if t.Name == "" {
+		return fmt.Errorf("template name is required")
+	}
+	switch t.Kind {
+	case TemplateBoard:
+		if t.Board == nil || t.Card != nil {
+			return fmt.Errorf("board templates take a board and no card")
+		}
+		return t.Board.Validate()
+	case TemplateCard:
+		if t.Card == nil || t.Board != nil {
+			return fmt.Errorf("card templates take a card and no board")
+		}
+		return t.Card.Validate()
+	}
+	return fmt.Errorf("invalid template kind %q: use board or card", t.Kind)
+}
+
+// Validate checks that the lists and labels are named uniquely, that list
+// statuses belong to the workflow, and that every card names a list and
+// only labels of the template.
+func (b *BoardTemplate) Validate() error {
+	wf := b.Workflow
+	if wf == nil {
+		wf = DefaultWorkflow()
+	} else if err := wf.Validate(); err != nil {
+		return err
+	}
+	if len(b.Lists) == 0 {
+		return fmt.Errorf("board template needs at least one list")
+	}
+	lists := map[string]bool{}
+	for _, l := range b.Lists {
+		if l.Name == "" {
+			return fmt.Errorf("list name is required")
+		}
+		if lists[l.Name] {
+			return fmt.Errorf("duplicate list: %s", l.Name)
+		}
+		lists[l.Name] = true
+		if l.Status != "" && !wf.HasStatus(l.Status) {
+			return fmt.Errorf("list %q is bound to unknown status %s", l.Name, l.Status)
+		}
+	}
+	labels := map[string]bool{}
+	for _, l := range b.Labels {
+		if l.Name == "" {
+			return fmt.Errorf("label name is required")
+		}
+		if labels[l.Name] {
+			return fmt.Errorf("duplicate label: %s", l.Name)
+		}
+		labels[l.Name] = true
+	}
+	for _, c := range b.Cards {
+		if !lists[c.List] {
+			return fmt.Errorf("card %q is placed in unknown list %q", c.Title, c.List)
+		}
+		if err := c.CardTemplate.Validate(); err != nil {
+			return err
+		}
+		for _, name := range c.Labels {
+			if !labels[name] {
+				return fmt.Errorf("card %q has unknown label %q", c.Title, name)
+			}
+		}
+	}
+	return nil
+}
+
+// Validate checks the card's title, priority, and checklist.
+func (c *CardTemplate) Validate() error {
+	if c.Title == "" {
+		return fmt.Errorf("card title is required")
+	}
+	if c.Priority != "" && !ValidPriority(c.Priority) {
+		return fmt.Errorf("invalid priority: %s", c.Priority)
+	}
+	for _, name := range c.Labels {
+		if name == "" {
+			return fmt.Errorf("card %q has an empty label name", c.Title)
+		}
+	}
+	for _, text := range c.Checklist {
+		if text == "" {
+			return fmt.Errorf("card %q has an empty checklist item", c.Title)
+		}
+	}
+	return nil
+}
+
+// Plan lays out a new board and the work on it: an optional workflow, its
+// lists and labels as in a board template, and its cards, as epics with
+// their tasks or as standalone tasks. Cards refer to one another by key.
+type Plan struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Workflow    *Workflow       `json:"workflow,omitempty"`
+	Lists       []ListTemplate  `json:"lists,omitempty"`
+	Labels      []LabelTemplate `json:"labels,omitempty"`
+	Epics       []PlanEpic      `json:"epics,omitempty"`
+	Tasks       []PlanCard      `json:"tasks,omitempty"`
+}
+
+// PlanCard is a card of a plan, placed in the list named List or else the
+// plan's first list. Key names it for other cards' DependsOn, which lists
+// the keys of the cards it depends on.
+type PlanCard struct {
+	Key       string   `json:"key,omitempty"`
+	List      string   `json:"list,omitempty"`
+	Assignee  string   `json:"assignee,omitempty"`
+	DueDate   string   `json:"due_date,omitempty"`
+	DependsOn []string `json:"depends_on,omitempty"`
+	CardTemplate
+}
+
+// PlanEpic is a card of a plan that is the parent of its Tasks.
+type PlanEpic struct {
+	PlanCard
+	Tasks []PlanCard `json:"tasks,omitempty"`
+}
+
+// PlanResult is the outcome of importing a plan. Diff lists what the import
+// creates, or on a dry run would create, one line each. Board and Cards,
+// which maps card keys to IDs, are set once it has.
+type PlanResult struct {
+	DryRun bool              `json:"dry_run"`
+	Diff   []string          `json:"diff"`
+	Board  *Board            `json:"board,omitempty"`
+	Cards  map[string]string `json:"cards,omitempty"`
+}
+
+// Validate checks the plan as a board template, then that card keys are
+// unique, that cards name only the plan's lists, labels, and keys, that
+// due dates parse, and that no cards depend on each other in a cycle.
+func (p *Plan) Validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("plan name is required")
+	}
+	bt := BoardTemplate{Workflow: p.Workflow, Lists: p.Lists, Labels: p.Labels}
+	if err := bt.Validate(); err != nil {
+		return err
+	}
+	lists := map[string]bool{}
+	for _, l := range p.Lists {
+		lists[l.Name] = true
+	}
+	labels := map[string]bool{}
+	for _, l := range p.Labels {
+		labels[l.Name] = true
+	}
+	deps := map[string][]string{}
+	var keys []string
+	for c := range p.Cards() {
+		if err := c.Validate(); err != nil {
+			return err
+		}
+		if c.List != "" && !lists[c.List] {
+			return fmt.Errorf("card %q is placed in unknown list %q", c.Title, c.List)
+		}
+		for _, name := range c.Labels {
+			if !labels[name] {
+				return fmt.Errorf("card %q has unknown label %q", c.Title, name)
+			}
+		}
+		if _, err := ParseDueDate(c.DueDate); err != nil {
+			return fmt.Errorf("card %q: %w", c.Title, err)
+		}
+		if c.Key == "" {
+			if len(c.DependsOn) > 0 {
+				return fmt.Errorf("card %q has dependencies but no key", c.Title)
+			}
+			continue
+		}
+		if _, dup := deps[c.Key]; dup {
+			return fmt.Errorf("duplicate card key: %s", c.Key)
+		}
+		deps[c.Key] = c.DependsOn
+		keys = append(keys, c.Key)
+	}
+	for _, key := range keys {
+		for _, dep := range deps[key] {
+			if _, ok := deps[dep]; !ok {
+				return fmt.Errorf("card %s depends on unknown key %q", key, dep)
+			}
+		}
+	}
+	return checkCycles(keys, deps)
+}
+
+// Cards yields the plan's cards in creation order: each epic followed by
+// its tasks, then the standalone tasks.
+func (p *Plan) Cards() iter.Seq[*PlanCard] {
+	return func(yield func(*PlanCard) bool) {
+		for i := range p.Epics {
+			if !yield(&p.Epics[i].PlanCard) {
+				return
+			}
+			for j := range p.Epics[i].Tasks {
+				if !yield(&p.Epics[i].Tasks[j]) {
+					return
+				}
+			}
+		}
+		for i := range p.Tasks {
+			if !yield(&p.Tasks[i]) {
+				return
+			}
+		}
+	}
+}
+
+// checkCycles reports the first cycle in the dependency graph deps, which
+// maps each of keys to the keys it depends on.
+func checkCycles(keys []string, deps map[string][]string) error {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := map[string]int{}
+	var path []string
+	var visit func(key string) error
+	visit = func(key string) error {
+		switch state[key] {
+		case visiting:
+			cycle := append(path[slices.Index(path, key):], key)
+			return fmt.Errorf("dependency cycle: %s", strings.Join(cycle, " -> "))
+		case visited:
+			return nil
+		}
+		state[key] = visiting
+		path = append(path, key)
+		for _, dep := range deps[key] {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[key] = visited
+		return nil
+	}
+	for _, key := range keys {
+		if err := visit(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ImportResult is the outcome of importing a board from another tool: the
+// board imported into, and how many entities of each type were created and
+// updated. Re-importing an unchanged export creates and updates nothing.
+type ImportResult struct {
+	Board   *Board         `json:"board"`
+	Created map[string]int `json:"created"`
+	Updated map[string]int `json:"updated"`
+}
+
+// ViewColumns are the card fields a view can show.
+var ViewColumns = []string{"title", "status", "priority", "assignee", "labels", "list", "due", "created", "updated"}
+
 const (
 	StatusUnassigned = "unassigned"
 	StatusAssigned   = "assigned"
@@ -77,6 +880,13 @@ const (
 	StatusDone       = "done"
 )
 
+const (
+	GuardHasAssignee      = "has_assignee"
+	GuardDependenciesDone = "dependencies_done"
+	// GuardChecklistComplete requires every checklist item to be done.
+	GuardChecklistComplete = "checklist_complete"
+)
+
 const (
 	PriorityLow      = "low"
 	PriorityMedium   = "medium"
@@ -84,6 +894,20 @@ const (
 	PriorityCritical = "critical"
 )
 
+const (
+	EntityBoard         = "board"
+	EntityList          = "list"
+	EntityCard          = "card"
+	EntityLabel         = "label"
+	EntityView          = "view"
+	EntityChecklistItem = "checklist_item"
+	EntityCustomField   = "custom_field"
+	EntityAttachment    = "attachment"
+	EntityComment       = "comment"
+	EntityTemplate      = "template"
+	EntityTrash         = "trash"
+)
+
 const (
 	ActionCreated           = "created"
 	ActionMoved             = "moved"
@@ -95,16 +919,138 @@ const (
 	ActionDependencyRemoved = "dependency_removed"
 	ActionLabelAdded        = "label_added"
 	ActionLabelRemoved      = "label_removed"
+	ActionArchived          = "archived"
+	ActionUnarchived        = "unarchived"
+	ActionDeleted           = "deleted"
+	ActionRestored          = "restored"
+	ActionUpdated           = "updated"
+	ActionWorkflowChanged   = "workflow_changed"
+	ActionPurged            = "purged"
+	ActionDueDateChanged    = "due_date_changed"
+	ActionChecklistAdded    = "checklist_item_added"
+	ActionChecklistDone     = "checklist_item_done"
+	ActionChecklistReopened = "checklist_item_reopened"
+	ActionChecklistUpdated  = "checklist_item_updated"
+	ActionChecklistRemoved  = "checklist_item_removed"
+	ActionParentChanged     = "parent_changed"
+	ActionFieldChanged      = "field_changed"
+	ActionAttachmentAdded   = "attachment_added"
+	ActionAttachmentRemoved = "attachment_removed"
+	ActionCommentEdited     = "comment_edited"
+	ActionCommentDeleted    = "comment_deleted"
+	ActionCloned            = "cloned"
+	ActionWatched           = "watched"
+	ActionUnwatched         = "unwatched"
 )
 
-func ValidStatus(s string) bool {
-	switch s {
-	case StatusUnassigned, StatusAssigned, StatusInProgress, StatusBlocked, StatusDone:
+func validGuard(g string) bool {
+	switch g {
+	case GuardHasAssignee, GuardDependenciesDone, GuardChecklistComplete:
 		return true
 	}
 	return false
 }
 
+// DefaultWorkflow is used by boards that have not configured their own. It
+// allows the built-in statuses with unrestricted transitions.
+func DefaultWorkflow() *Workflow {
+	return &Workflow{
+		Statuses: []string{StatusUnassigned, StatusAssigned, StatusInProgress, StatusBlocked, StatusDone},
+	}
+}
+
+func (w *Workflow) HasStatus(s string) bool {
+	for _, st := range w.Statuses {
+		if st == s {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *Workflow) CanTransition(from, to string) bool {
+	if !w.HasStatus(to) {
+		return false
+	}
+	if from == to {
+		return true
+	}
+	allowed, ok := w.Transitions[from]
+	if !ok {
+		return true
+	}
+	for _, st := range allowed {
+		if st == to {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *Workflow) Validate() error {
+	if len(w.Statuses) == 0 {
+		return fmt.Errorf("workflow must define at least one status")
+	}
+	seen := map[string]bool{}
+	for _, st := range w.Statuses {
+		if !validName(st) {
+			return fmt.Errorf("invalid status name: %q", st)
+		}
+		if seen[st] {
+			return fmt.Errorf("duplicate status: %s", st)
+		}
+		seen[st] = true
+	}
+	for from, tos := range w.Transitions {
+		if !seen[from] {
+			return fmt.Errorf("transition from unknown status: %s", from)
+		}
+		for _, to := range tos {
+			if !seen[to] {
+				return fmt.Errorf("transition to unknown status: %s", to)
+			}
+		}
+	}
+	for st, guards := range w.Guards {
+		if !seen[st] {
+			return fmt.Errorf("guard on unknown status: %s", st)
+		}
+		for _, g := range guards {
+			if !validGuard(g) {
+				return fmt.Errorf("unknown guard: %s", g)
+			}
+		}
+	}
+	return nil
+}
+
+// validName reports whether s may name a status or a custom field.
+func validName(s string) bool {
+	if s == "" || len(s) > 32 {
+		return false
+	}
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseDueDate parses an RFC 3339 due date. An empty string means no due
+// date and yields nil.
+func ParseDueDate(v string) (*time.Time, error) {
+	if v == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid due_date %q: must be RFC 3339, e.g. 2026-03-01T17:00:00Z", v)
+	}
+	t = t.UTC()
+	return &t, nil
+}
+
 func ValidPriority(p string) bool {
 	switch p {
 	case PriorityLow, PriorityMedium, PriorityHigh, PriorityCritical:
@@ -0,0 +1,204 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/aellingwood/cielo/internal/model"
+)
+
+// ListAttachments returns the card's attachments, oldest first.
+func (s *Service) ListAttachments(ctx context.Context, cardID string) ([]model.Attachment, error) {
+	if _, err := s.store.GetCard(ctx, cardID); err != nil {
+		return nil, err
+	}
+	return s.store.ListAttachments(ctx, cardID)
+}
+
+func (s *Service) GetAttachment(ctx context.Context, id string) (*model.Attachment, error) {
+	return s.store.GetAttachment(ctx, id)
+}
+
+// attachmentName reduces a client-supplied file name to its base name.
+func attachmentName(name string) (string, error) {
+	name = strings.TrimSpace(filepath.Base(strings.ReplaceAll(name, `\`, "/")))
+	if name == "" || name == "." || name == "/" {
+		return "", fmt.Errorf("attachment name is required")
+	}
+	if len(name) > 255 {
+		return "", fmt.Errorf("attachment name is longer than 255 bytes")
+	}
+	return name, nil
+}
+
+// countingWriter counts the bytes written through it.
+type countingWriter struct{ n int64 }
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
+
+// AddAttachment stores the contents read from r as an attachment of the
+// card. An empty mimeType is guessed from the name's extension, then from
+// the contents. Uploads larger than the configured limit are refused and
+// nothing is kept.
+func (s *Service) AddAttachment(ctx context.Context, cardID, name, mimeType string, r io.Reader, actor string) (*model.Attachment, error) {
+	if s.blobs == nil {
+		return nil, fmt.Errorf("attachments are not enabled")
+	}
+	name, err := attachmentName(name)
+	if err != nil {
+		return nil, err
+	}
+	_, boardID, err := s.editableCard(ctx, cardID)
+	if err != nil {
+		return nil, err
+	}
+
+	br := bufio.NewReader(r)
+	if mimeType == "" {
+		mimeType = mime.TypeByExtension(filepath.Ext(name))
+	}
+	if mimeType == "" {
+		head, _ := br.Peek(512)
+		mimeType = http.DetectContentType(head)
+	}
+	a := &model.Attachment{ID: model.NewID(), CardID: cardID, Name: name, MimeType: mimeType, UploadedBy: actor}
+	// Read one byte past the limit so that oversized uploads can be told
+	// apart from ones of exactly the maximum size.
+	hash := sha256.New()
+	count := &countingWriter{}
+	body := io.TeeReader(io.LimitReader(br, s.maxAttachment+1), io.MultiWriter(hash, count))
+	if err := s.blobs.Put(ctx, a.ID, body); err != nil {
+		return nil, fmt.Errorf("storing attachment: %w", err)
+	}
+	if count.n > s.maxAttachment {
+		s.deleteBlobs(ctx, []string{a.ID})
+		return nil, fmt.Errorf("attachment is larger than the %d byte limit", s.maxAttachment)
+	}
+	a.Size = count.n
+	a.SHA256 = hex.EncodeToString(hash.Sum(nil))
+	if err := s.createAttachment(ctx, boardID, a, actor); err != nil {
+		s.deleteBlobs(ctx, []string{a.ID})
+		return nil, err
+	}
+	return a, nil
+}
+
+// AddAttachmentURL attaches a reference to an external http or https
+// resource. Nothing is downloaded; the attachment records the URL.
+func (s *Service) AddAttachmentURL(ctx context.Context, cardID, name, rawURL, actor string) (*model.Attachment, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid attachment url %q: must be an http or https URL", rawURL)
+	}
+	if name == "" {
+		name = path.Base(u.Path)
+		if name == "." || name == "/" {
+			name = u.Host
+		}
+	}
+	if name, err = attachmentName(name); err != nil {
+		return nil, err
+	}
+	_, boardID, err := s.editableCard(ctx, cardID)
+	if err != nil {
+		return nil, err
+	}
+	a := &model.Attachment{
+		ID: model.NewID(), CardID: cardID, Name: name, MimeType: mime.TypeByExtension(filepath.Ext(name)),
+		URL: u.String(), UploadedBy: actor,
+	}
+	if err := s.createAttachment(ctx, boardID, a, actor); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// createAttachment records the attachment and logs, audits, and announces
+// it.
+func (s *Service) createAttachment(ctx context.Context, boardID string, a *model.Attachment, actor string) error {
+	return s.inTx(ctx, func(tx *Service) error {
+		if err := tx.store.CreateAttachment(ctx, a); err != nil {
+			return err
+		}
+		tx.logActivity(ctx, a.CardID, actor, model.ActionAttachmentAdded, map[string]any{
+			"attachment_id": a.ID, "name": a.Name, "size": a.Size,
+		})
+		tx.publish("attachment.created", boardID, a)
+		return tx.audit(ctx, boardID, model.EntityAttachment, a.ID, model.ActionCreated, actor, nil, a)
+	})
+}
+
+// OpenAttachment returns the attachment and a reader over its contents,
+// which the caller must close. Attachments that reference a URL have no
+// contents; for them the reader is nil.
+func (s *Service) OpenAttachment(ctx context.Context, id string) (*model.Attachment, io.ReadCloser, error) {
+	a, err := s.store.GetAttachment(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if a.URL != "" {
+		return a, nil, nil
+	}
+	if s.blobs == nil {
+		return nil, nil, fmt.Errorf("attachments are not enabled")
+	}
+	rc, err := s.blobs.Open(ctx, a.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("attachment %s: %w", id, err)
+	}
+	return a, rc, nil
+}
+
+// DeleteAttachment removes the attachment and its contents.
+func (s *Service) DeleteAttachment(ctx context.Context, id, actor string) error {
+	a, err := s.store.GetAttachment(ctx, id)
+	if err != nil {
+		return err
+	}
+	_, boardID, err := s.editableCard(ctx, a.CardID)
+	if err != nil {
+		return err
+	}
+	err = s.inTx(ctx, func(tx *Service) error {
+		if err := tx.store.DeleteAttachment(ctx, id); err != nil {
+			return err
+		}
+		tx.logActivity(ctx, a.CardID, actor, model.ActionAttachmentRemoved, map[string]string{"attachment_id": id, "name": a.Name})
+		tx.publish("attachment.deleted", boardID, map[string]string{"id": id, "card_id": a.CardID})
+		return tx.audit(ctx, boardID, model.EntityAttachment, id, model.ActionDeleted, actor, a, nil)
+	})
+	if err != nil {
+		return err
+	}
+	if a.URL == "" {
+		s.deleteBlobs(ctx, []string{id})
+	}
+	return nil
+}
+
+// deleteBlobs removes attachment contents whose records are gone. Failures
+// are logged; they leave unreferenced files behind but lose no data.
+func (s *Service) deleteBlobs(ctx context.Context, keys []string) {
+	if s.blobs == nil {
+		return
+	}
+	for _, key := range keys {
+		if err := s.blobs.Delete(ctx, key); err != nil {
+			log.Printf("could not delete attachment contents %s: %v", key, err)
+		}
+	}
+}
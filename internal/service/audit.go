@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/aellingwood/cielo/internal/model"
+)
+
+type sourceKey struct{}
+
+// WithSource tags ctx with the origin of a mutation, such as a REST request
+// ID or an MCP call ID. It is recorded on every audit entry written under
+// ctx.
+func WithSource(ctx context.Context, source string) context.Context {
+	return context.WithValue(ctx, sourceKey{}, source)
+}
+
+// SourceFrom returns the source set by WithSource, or "" if there is none.
+func SourceFrom(ctx context.Context) string {
+	s, _ := ctx.Value(sourceKey{}).(string)
+	return s
+}
+
+// snapshot encodes v for an audit entry. Nil values yield no snapshot.
+func snapshot(v any) json.RawMessage {
+	b, err := json.Marshal(v)
+	if err != nil || string(b) == "null" {
+		return nil
+	}
+	return b
+}
+
+// audit records a mutation in the board's audit trail. It is called inside
+// the mutation's transaction, so a change that cannot be audited is not
+// made.
+func (s *Service) audit(ctx context.Context, boardID, entityType, entityID, action, actor string, before, after any) error {
+	return s.store.CreateAuditEntry(ctx, &model.AuditEntry{
+		ID:         model.NewID(),
+		BoardID:    boardID,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Action:     action,
+		Actor:      actor,
+		Before:     snapshot(before),
+		After:      snapshot(after),
+		Source:     SourceFrom(ctx),
+	})
+}
+
+// ListAudit returns audit entries matching f, newest first, as actor may see
+// them: changes to other actors' private views are left out.
+func (s *Service) ListAudit(ctx context.Context, f model.AuditFilter, actor string) ([]model.AuditEntry, error) {
+	f.Viewer = actor
+	return s.store.ListAudit(ctx, f)
+}
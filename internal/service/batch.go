@@ -0,0 +1,339 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/aellingwood/cielo/internal/model"
+)
+
+// MaxBatchOps is the most operations one batch may hold.
+const MaxBatchOps = 500
+
+// Batch runs the operations in order in one transaction and returns their
+// results, or the first error, in which case none of them take effect. A
+// string argument "$name" stands for the ID created by the earlier
+// operation whose Ref is name.
+func (s *Service) Batch(ctx context.Context, ops []model.BatchOp, actor string) ([]model.BatchResult, error) {
+	if len(ops) == 0 {
+		return nil, fmt.Errorf("batch has no operations")
+	}
+	if len(ops) > MaxBatchOps {
+		return nil, fmt.Errorf("batch has %d operations, more than %d", len(ops), MaxBatchOps)
+	}
+	declared := map[string]bool{}
+	for i, op := range ops {
+		if op.Ref == "" {
+			continue
+		}
+		if declared[op.Ref] {
+			return nil, fmt.Errorf("operation %d (%s): ref %q is already taken", i, op.Op, op.Ref)
+		}
+		declared[op.Ref] = true
+	}
+	var results []model.BatchResult
+	err := s.inTx(ctx, func(tx *Service) error {
+		results = make([]model.BatchResult, 0, len(ops))
+		refs := map[string]string{}
+		for i, op := range ops {
+			args, err := resolveRefs(op.Args, declared, refs)
+			if err != nil {
+				return fmt.Errorf("operation %d (%s): %w", i, op.Op, err)
+			}
+			result, id, err := tx.runBatchOp(ctx, op.Op, args.(map[string]any), actor)
+			if err != nil {
+				return fmt.Errorf("operation %d (%s): %w", i, op.Op, err)
+			}
+			if op.Ref != "" {
+				if id == "" {
+					return fmt.Errorf("operation %d (%s): creates nothing for ref %q to name", i, op.Op, op.Ref)
+				}
+				refs[op.Ref] = id
+			}
+			results = append(results, model.BatchResult{Op: op.Op, Ref: op.Ref, ID: id, Result: result})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// resolveRefs returns a copy of v with every string "$name", where name is
+// one of the batch's refs, replaced by the ID it names. A ref used before
+// the operation that sets it is an error.
+func resolveRefs(v any, declared map[string]bool, refs map[string]string) (any, error) {
+	switch v := v.(type) {
+	case nil:
+		return map[string]any{}, nil
+	case string:
+		name, ok := strings.CutPrefix(v, "$")
+		if !ok || !declared[name] {
+			return v, nil
+		}
+		id, ok := refs[name]
+		if !ok {
+			return nil, fmt.Errorf("ref %q is used before the operation that sets it", name)
+		}
+		return id, nil
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, e := range v {
+			r, err := resolveRefs(e, declared, refs)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = r
+		}
+		return out, nil
+	case []any:
+		out := make([]any, len(v))
+		for i, e := range v {
+			r, err := resolveRefs(e, declared, refs)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = r
+		}
+		return out, nil
+	}
+	return v, nil
+}
+
+// decodeBatchArgs re-encodes an operation's arguments into v, refusing
+// arguments v has no field for.
+func decodeBatchArgs(args map[string]any, v any) error {
+	data, err := json.Marshal(args)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+	return nil
+}
+
+type cardArgs struct {
+	CardID string `json:"card_id"`
+}
+
+// runBatchOp runs one operation of a batch and returns its result and the
+// ID of what it created, if anything.
+func (s *Service) runBatchOp(ctx context.Context, op string, args map[string]any, actor string) (any, string, error) {
+	switch op {
+	case "create_board":
+		var a struct {
+			Name        string `json:"name"`
+			Description string `json:"description"`
+		}
+		if err := decodeBatchArgs(args, &a); err != nil {
+			return nil, "", err
+		}
+		b, err := s.CreateBoard(ctx, a.Name, a.Description, actor)
+		if err != nil {
+			return nil, "", err
+		}
+		return b, b.ID, nil
+
+	case "set_workflow":
+		var a struct {
+			BoardID  string          `json:"board_id"`
+			Workflow *model.Workflow `json:"workflow"`
+		}
+		if err := decodeBatchArgs(args, &a); err != nil {
+			return nil, "", err
+		}
+		if a.Workflow == nil {
+			return nil, "", fmt.Errorf("workflow is required")
+		}
+		wf, err := s.SetWorkflow(ctx, a.BoardID, a.Workflow, actor)
+		return wf, "", err
+
+	case "create_list":
+		var a struct {
+			BoardID string `json:"board_id"`
+			Name    string `json:"name"`
+			Status  string `json:"status"`
+			model.Placement
+		}
+		if err := decodeBatchArgs(args, &a); err != nil {
+			return nil, "", err
+		}
+		l, err := s.CreateList(ctx, a.BoardID, a.Name, a.Status, a.Placement, actor)
+		if err != nil {
+			return nil, "", err
+		}
+		return l, l.ID, nil
+
+	case "create_label":
+		var a struct {
+			BoardID string `json:"board_id"`
+			Name    string `json:"name"`
+			Color   string `json:"color"`
+		}
+		if err := decodeBatchArgs(args, &a); err != nil {
+			return nil, "", err
+		}
+		l, err := s.CreateLabel(ctx, a.BoardID, a.Name, a.Color, actor)
+		if err != nil {
+			return nil, "", err
+		}
+		return l, l.ID, nil
+
+	case "create_field":
+		var a struct {
+			BoardID string   `json:"board_id"`
+			Name    string   `json:"name"`
+			Type    string   `json:"type"`
+			Options []string `json:"options"`
+			model.Placement
+		}
+		if err := decodeBatchArgs(args, &a); err != nil {
+			return nil, "", err
+		}
+		f, err := s.CreateCustomField(ctx, a.BoardID, a.Name, a.Type, a.Options, a.Placement, actor)
+		if err != nil {
+			return nil, "", err
+		}
+		return f, f.ID, nil
+
+	case "create_card":
+		var a struct {
+			ListID      string `json:"list_id"`
+			Title       string `json:"title"`
+			Description string `json:"description"`
+			Assignee    string `json:"assignee"`
+			Priority    string `json:"priority"`
+			ParentID    string `json:"parent_id"`
+			DueDate     string `json:"due_date"`
+			model.Placement
+		}
+		if err := decodeBatchArgs(args, &a); err != nil {
+			return nil, "", err
+		}
+		due, err := model.ParseDueDate(a.DueDate)
+		if err != nil {
+			return nil, "", err
+		}
+		c, err := s.CreateCard(ctx, a.ListID, a.Title, a.Description, a.Assignee, a.Priority, a.ParentID, due, actor, a.Placement)
+		if err != nil {
+			return nil, "", err
+		}
+		return c, c.ID, nil
+
+	case "update_card":
+		id, _ := args["card_id"].(string)
+		c, err := s.UpdateCard(ctx, id, args, actor)
+		return c, "", err
+
+	case "move_card":
+		var a struct {
+			CardID string `json:"card_id"`
+			ListID string `json:"list_id"`
+			model.CardMoveOptions
+		}
+		if err := decodeBatchArgs(args, &a); err != nil {
+			return nil, "", err
+		}
+		c, err := s.MoveCard(ctx, a.CardID, a.ListID, a.CardMoveOptions, actor)
+		return c, "", err
+
+	case "assign_card":
+		var a struct {
+			CardID   string `json:"card_id"`
+			Assignee string `json:"assignee"`
+		}
+		if err := decodeBatchArgs(args, &a); err != nil {
+			return nil, "", err
+		}
+		c, err := s.AssignCard(ctx, a.CardID, a.Assignee, actor)
+		return c, "", err
+
+	case "archive_card":
+		var a cardArgs
+		if err := decodeBatchArgs(args, &a); err != nil {
+			return nil, "", err
+		}
+		c, err := s.ArchiveCard(ctx, a.CardID, actor)
+		return c, "", err
+
+	case "delete_card":
+		var a cardArgs
+		if err := decodeBatchArgs(args, &a); err != nil {
+			return nil, "", err
+		}
+		return nil, "", s.DeleteCard(ctx, a.CardID, actor)
+
+	case "add_dependency", "remove_dependency":
+		var a struct {
+			CardID          string `json:"card_id"`
+			DependsOnCardID string `json:"depends_on_card_id"`
+		}
+		if err := decodeBatchArgs(args, &a); err != nil {
+			return nil, "", err
+		}
+		if op == "add_dependency" {
+			return nil, "", s.AddDependency(ctx, a.CardID, a.DependsOnCardID, actor)
+		}
+		return nil, "", s.RemoveDependency(ctx, a.CardID, a.DependsOnCardID, actor)
+
+	case "add_label_to_card", "remove_label_from_card":
+		var a struct {
+			CardID  string `json:"card_id"`
+			LabelID string `json:"label_id"`
+		}
+		if err := decodeBatchArgs(args, &a); err != nil {
+			return nil, "", err
+		}
+		if op == "add_label_to_card" {
+			return nil, "", s.AddLabelToCard(ctx, a.CardID, a.LabelID, actor)
+		}
+		return nil, "", s.RemoveLabelFromCard(ctx, a.CardID, a.LabelID, actor)
+
+	case "add_checklist_item":
+		var a struct {
+			CardID   string `json:"card_id"`
+			Text     string `json:"text"`
+			Assignee string `json:"assignee"`
+			model.Placement
+		}
+		if err := decodeBatchArgs(args, &a); err != nil {
+			return nil, "", err
+		}
+		it, err := s.AddChecklistItem(ctx, a.CardID, a.Text, a.Assignee, a.Placement, actor)
+		if err != nil {
+			return nil, "", err
+		}
+		return it, it.ID, nil
+
+	case "add_comment":
+		var a struct {
+			CardID   string `json:"card_id"`
+			ParentID string `json:"parent_id"`
+			Text     string `json:"text"`
+		}
+		if err := decodeBatchArgs(args, &a); err != nil {
+			return nil, "", err
+		}
+		cm, err := s.AddComment(ctx, a.CardID, a.ParentID, a.Text, actor)
+		if err != nil {
+			return nil, "", err
+		}
+		return cm, cm.ID, nil
+	}
+	return nil, "", fmt.Errorf("unknown batch operation %q", op)
+}
+
+// BatchOps lists the operations a batch may hold.
+var BatchOps = []string{
+	"create_board", "set_workflow", "create_list", "create_label", "create_field",
+	"create_card", "update_card", "move_card", "assign_card", "archive_card", "delete_card",
+	"add_dependency", "remove_dependency", "add_label_to_card", "remove_label_from_card",
+	"add_checklist_item", "add_comment",
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aellingwood/cielo/internal/model"
+)
+
+// editableCard returns the card whose checklist or attachments are being
+// changed and its board. Archived cards and cards in the trash cannot be
+// changed.
+func (s *Service) editableCard(ctx context.Context, cardID string) (*model.Card, string, error) {
+	c, err := s.store.GetCard(ctx, cardID)
+	if err != nil {
+		return nil, "", err
+	}
+	if c.ArchivedAt != nil || c.DeletedAt != nil {
+		return nil, "", fmt.Errorf("card is archived or deleted: %s", cardID)
+	}
+	_, boardID, err := s.workflowForList(ctx, c.ListID)
+	if err != nil {
+		return nil, "", err
+	}
+	return c, boardID, nil
+}
+
+// ListChecklist returns the card's checklist in order.
+func (s *Service) ListChecklist(ctx context.Context, cardID string) ([]model.ChecklistItem, error) {
+	if _, err := s.store.GetCard(ctx, cardID); err != nil {
+		return nil, err
+	}
+	return s.store.ListChecklistItems(ctx, cardID)
+}
+
+// AddChecklistItem adds an item to the card's checklist, at the end unless
+// pos says otherwise.
+func (s *Service) AddChecklistItem(ctx context.Context, cardID, text, assignee string, pos model.Placement, actor string) (*model.ChecklistItem, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, fmt.Errorf("checklist item text is required")
+	}
+	_, boardID, err := s.editableCard(ctx, cardID)
+	if err != nil {
+		return nil, err
+	}
+	it := &model.ChecklistItem{ID: model.NewID(), CardID: cardID, Text: text, Assignee: assignee}
+	err = s.inTx(ctx, func(tx *Service) error {
+		if err := tx.store.CreateChecklistItem(ctx, it, pos); err != nil {
+			return err
+		}
+		tx.logActivity(ctx, cardID, actor, model.ActionChecklistAdded, map[string]string{"item_id": it.ID, "text": it.Text})
+		tx.publish("checklist_item.created", boardID, it)
+		return tx.audit(ctx, boardID, model.EntityChecklistItem, it.ID, model.ActionCreated, actor, nil, it)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return it, nil
+}
+
+// UpdateChecklistItem edits, checks off, reopens, or reorders an item.
+// Checking an item off records when it was completed; reopening it clears
+// that time.
+func (s *Service) UpdateChecklistItem(ctx context.Context, id string, spec model.ChecklistItemSpec, actor string) (*model.ChecklistItem, error) {
+	it, err := s.store.GetChecklistItem(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	_, boardID, err := s.editableCard(ctx, it.CardID)
+	if err != nil {
+		return nil, err
+	}
+	before := *it
+	if spec.Text != nil {
+		text := strings.TrimSpace(*spec.Text)
+		if text == "" {
+			return nil, fmt.Errorf("checklist item text is required")
+		}
+		it.Text = text
+	}
+	if spec.Assignee != nil {
+		it.Assignee = *spec.Assignee
+	}
+	if spec.Done != nil && *spec.Done != it.Done {
+		it.Done = *spec.Done
+		it.CompletedAt = nil
+		if it.Done {
+			t := time.Now().UTC()
+			it.CompletedAt = &t
+		}
+	}
+	err = s.inTx(ctx, func(tx *Service) error {
+		if err := tx.store.UpdateChecklistItem(ctx, it); err != nil {
+			return err
+		}
+		if !spec.Placement.IsZero() {
+			if err := tx.store.MoveChecklistItem(ctx, id, spec.Placement); err != nil {
+				return err
+			}
+		}
+		var err error
+		if it, err = tx.store.GetChecklistItem(ctx, id); err != nil {
+			return err
+		}
+
+		if it.Done != before.Done {
+			action := model.ActionChecklistReopened
+			if it.Done {
+				action = model.ActionChecklistDone
+			}
+			tx.logActivity(ctx, it.CardID, actor, action, map[string]string{"item_id": id, "text": it.Text})
+		}
+		if it.Text != before.Text || it.Assignee != before.Assignee || it.Position != before.Position {
+			detail := map[string]any{"item_id": id}
+			if it.Text != before.Text {
+				detail["text"] = it.Text
+			}
+			if it.Assignee != before.Assignee {
+				detail["assignee"] = it.Assignee
+			}
+			if it.Position != before.Position {
+				detail["position"] = it.Position
+			}
+			tx.logActivity(ctx, it.CardID, actor, model.ActionChecklistUpdated, detail)
+		}
+		tx.publish("checklist_item.updated", boardID, it)
+		return tx.audit(ctx, boardID, model.EntityChecklistItem, id, model.ActionUpdated, actor, &before, it)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return it, nil
+}
+
+func (s *Service) DeleteChecklistItem(ctx context.Context, id, actor string) error {
+	it, err := s.store.GetChecklistItem(ctx, id)
+	if err != nil {
+		return err
+	}
+	_, boardID, err := s.editableCard(ctx, it.CardID)
+	if err != nil {
+		return err
+	}
+	return s.inTx(ctx, func(tx *Service) error {
+		if err := tx.store.DeleteChecklistItem(ctx, id); err != nil {
+			return err
+		}
+		tx.logActivity(ctx, it.CardID, actor, model.ActionChecklistRemoved, map[string]string{"item_id": id, "text": it.Text})
+		tx.publish("checklist_item.deleted", boardID, map[string]string{"id": id, "card_id": it.CardID})
+		return tx.audit(ctx, boardID, model.EntityChecklistItem, id, model.ActionDeleted, actor, it, nil)
+	})
+}
@@ -0,0 +1,451 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/aellingwood/cielo/internal/model"
+)
+
+// CloneBoard copies a board with its workflow, lists, and custom fields,
+// and whatever else opts asks for, into a new board. Everything copied gets
+// a new ID, and the parent links and dependencies among the copied cards
+// point at the copies. Attachments and watchers are not copied. The copy is
+// made in one transaction.
+func (s *Service) CloneBoard(ctx context.Context, boardID string, opts model.BoardCloneOptions, actor string) (*model.Board, error) {
+	src, err := s.store.GetBoard(ctx, boardID)
+	if err != nil {
+		return nil, err
+	}
+	if src.DeletedAt != nil {
+		return nil, fmt.Errorf("board is in the trash: %s", boardID)
+	}
+	if (opts.Dependencies || opts.Activity) && !opts.Cards {
+		return nil, fmt.Errorf("dependencies and activity can only be cloned with cards")
+	}
+	name := opts.Name
+	if name == "" {
+		name = src.Name + " (copy)"
+	}
+	b := &model.Board{ID: model.NewID(), Name: name, Description: src.Description, Workflow: src.Workflow}
+	err = s.inTx(ctx, func(tx *Service) error {
+		if err := tx.store.CreateBoard(ctx, b); err != nil {
+			return err
+		}
+		lists, err := tx.store.ListListsByBoard(ctx, boardID, true)
+		if err != nil {
+			return err
+		}
+		// Archived lists go last so that the open ones keep their order.
+		slices.SortStableFunc(lists, func(a, b model.List) int {
+			return boolCmp(a.ArchivedAt != nil, b.ArchivedAt != nil)
+		})
+		listIDs := map[string]string{}
+		for _, l := range lists {
+			nl := &model.List{ID: model.NewID(), BoardID: b.ID, Name: l.Name, Status: l.Status}
+			if err := tx.store.CreateList(ctx, nl); err != nil {
+				return err
+			}
+			if l.ArchivedAt != nil {
+				if err := tx.store.ArchiveList(ctx, nl.ID); err != nil {
+					return err
+				}
+			}
+			listIDs[l.ID] = nl.ID
+		}
+		fields, err := tx.store.ListCustomFields(ctx, boardID)
+		if err != nil {
+			return err
+		}
+		var newFields []model.CustomField
+		for _, f := range fields {
+			nf := &model.CustomField{ID: model.NewID(), BoardID: b.ID, Name: f.Name, Type: f.Type, Options: f.Options}
+			if err := tx.store.CreateCustomField(ctx, nf, model.Placement{}); err != nil {
+				return err
+			}
+			newFields = append(newFields, *nf)
+		}
+		labelIDs := map[string]string{}
+		if opts.Labels {
+			labels, err := tx.store.ListLabelsByBoard(ctx, boardID)
+			if err != nil {
+				return err
+			}
+			for _, l := range labels {
+				nl := &model.Label{ID: model.NewID(), BoardID: b.ID, Name: l.Name, Color: l.Color}
+				if err := tx.store.CreateLabel(ctx, nl); err != nil {
+					return err
+				}
+				labelIDs[l.ID] = nl.ID
+			}
+		}
+		if opts.Cards {
+			if err := tx.cloneCards(ctx, boardID, listIDs, labelIDs, newFields, opts, actor); err != nil {
+				return err
+			}
+		}
+		return tx.audit(ctx, b.ID, model.EntityBoard, b.ID, model.ActionCloned, actor, nil, clonedBoard{b, boardID})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// clonedBoard is the audit snapshot of a board copy.
+type clonedBoard struct {
+	*model.Board
+	ClonedFrom string `json:"cloned_from"`
+}
+
+func boolCmp(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return 1
+	}
+	return -1
+}
+
+// cloneCards copies the cards of boardID that are not in the trash into
+// the lists that listIDs maps theirs to, with the labels labelIDs maps,
+// their checklists, and their values for fields. Parent links, and the
+// dependencies and activity opts asks for, are copied between the copies.
+func (s *Service) cloneCards(ctx context.Context, boardID string, listIDs, labelIDs map[string]string, fields []model.CustomField, opts model.BoardCloneOptions, actor string) error {
+	cards, _, err := s.store.ListCardsByBoard(ctx, boardID, true, 0)
+	if err != nil {
+		return err
+	}
+	ids := make([]string, len(cards))
+	for i, c := range cards {
+		ids[i] = c.ID
+	}
+	values, err := s.store.GetFieldValuesForCards(ctx, ids)
+	if err != nil {
+		return err
+	}
+	// Cards are listed with each list's archived cards last, so creating
+	// them in order keeps the open ones' positions.
+	cardIDs := map[string]string{}
+	for _, c := range cards {
+		nc, err := s.copyCard(ctx, &c, listIDs[c.ListID], c.Title, c.Status, model.Placement{})
+		if err != nil {
+			return err
+		}
+		cardIDs[c.ID] = nc.ID
+		for _, l := range c.Labels {
+			if id, ok := labelIDs[l.ID]; ok {
+				if err := s.store.AddLabelToCard(ctx, nc.ID, id); err != nil {
+					return err
+				}
+			}
+		}
+		if err := s.copyFieldValues(ctx, nc.ID, values[c.ID], fields); err != nil {
+			return err
+		}
+	}
+	for _, c := range cards {
+		if c.ParentID == "" {
+			continue
+		}
+		if parent, ok := cardIDs[c.ParentID]; ok {
+			if err := s.store.SetCardParent(ctx, cardIDs[c.ID], parent); err != nil {
+				return err
+			}
+		}
+	}
+	for _, c := range cards {
+		if opts.Dependencies {
+			deps, err := s.store.GetDependencies(ctx, c.ID)
+			if err != nil {
+				return err
+			}
+			for _, d := range deps {
+				if id, ok := cardIDs[d.ID]; ok {
+					dep := &model.CardDependency{ID: model.NewID(), CardID: cardIDs[c.ID], DependsOnCardID: id}
+					if err := s.store.AddDependency(ctx, dep); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		if opts.Activity {
+			if err := s.copyHistory(ctx, c.ID, cardIDs[c.ID]); err != nil {
+				return err
+			}
+		}
+		s.logActivity(ctx, cardIDs[c.ID], actor, model.ActionCloned, map[string]string{"from": c.ID})
+	}
+	return nil
+}
+
+// copyCard creates a copy of src in the list with the given title and
+// status, along with its checklist, and archives it if src is archived.
+func (s *Service) copyCard(ctx context.Context, src *model.Card, listID, title, status string, pos model.Placement) (*model.Card, error) {
+	c := &model.Card{
+		ID: model.NewID(), ListID: listID, Title: title, Description: src.Description,
+		Assignee: src.Assignee, Status: status, Priority: src.Priority, DueDate: src.DueDate,
+	}
+	if err := s.store.CreateCard(ctx, c); err != nil {
+		return nil, err
+	}
+	if !pos.IsZero() {
+		if err := s.store.MoveCard(ctx, c.ID, listID, pos); err != nil {
+			return nil, err
+		}
+	}
+	if src.ArchivedAt != nil {
+		if err := s.store.ArchiveCard(ctx, c.ID); err != nil {
+			return nil, err
+		}
+	}
+	items, err := s.store.ListChecklistItems(ctx, src.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, it := range items {
+		copied := &model.ChecklistItem{
+			ID: model.NewID(), CardID: c.ID, Text: it.Text, Done: it.Done,
+			Assignee: it.Assignee, CompletedAt: it.CompletedAt,
+		}
+		if err := s.store.CreateChecklistItem(ctx, copied, model.Placement{}); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// copyFieldValues gives the card the values, keyed by field name, that fit
+// the fields of the same name: those of the same type, and for enum fields
+// only the options the field has.
+func (s *Service) copyFieldValues(ctx context.Context, cardID string, values map[string]any, fields []model.CustomField) error {
+	byID := map[string]any{}
+	for _, f := range fields {
+		v, ok := values[f.Name]
+		if !ok || !fieldAccepts(&f, v) {
+			continue
+		}
+		byID[f.ID] = v
+	}
+	if len(byID) == 0 {
+		return nil
+	}
+	return s.store.SetCardFieldValues(ctx, cardID, byID)
+}
+
+// fieldAccepts reports whether v, a stored value of a field of the same
+// name on another board, is valid for f.
+func fieldAccepts(f *model.CustomField, v any) bool {
+	switch v := v.(type) {
+	case float64:
+		return f.Type == model.FieldNumber
+	case time.Time:
+		return f.Type == model.FieldDate
+	case string:
+		parsed, err := parseFieldValue(f, v)
+		_, ok := parsed.(string)
+		return err == nil && ok
+	}
+	return false
+}
+
+// copyHistory copies the activity and comments of one card to another,
+// keeping their actors and times. Replies stay attached to the copies of
+// the comments they answer.
+func (s *Service) copyHistory(ctx context.Context, fromID, toID string) error {
+	activity, _, err := s.store.ListActivityByCard(ctx, fromID, model.Page{})
+	if err != nil {
+		return err
+	}
+	// Activity is listed newest first; copy it oldest first.
+	for _, a := range slices.Backward(activity) {
+		a.ID, a.CardID = model.NewID(), toID
+		if err := s.store.CreateActivity(ctx, &a); err != nil {
+			return err
+		}
+	}
+	comments, _, err := s.store.ListComments(ctx, fromID, model.Page{})
+	if err != nil {
+		return err
+	}
+	commentIDs := map[string]string{}
+	for _, c := range comments {
+		id := model.NewID()
+		commentIDs[c.ID] = id
+		c.ID, c.CardID, c.ParentID = id, toID, commentIDs[c.ParentID]
+		if err := s.store.CreateComment(ctx, &c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CloneCard copies a card into a list on the same or another board, with
+// its labels, checklist, and custom field values, and the dependencies
+// opts asks for. Its parent is kept within the same board. Children,
+// comments, attachments, and watchers are not copied. The copy keeps the
+// original's status if the target workflow has it and takes the workflow's
+// first status otherwise; a list bound to a status then moves it on to
+// that status, which it must be allowed to enter as a new card would be.
+func (s *Service) CloneCard(ctx context.Context, cardID, listID string, opts model.CardCloneOptions, actor string) (*model.Card, error) {
+	src, err := s.store.GetCard(ctx, cardID)
+	if err != nil {
+		return nil, err
+	}
+	if src.DeletedAt != nil {
+		return nil, fmt.Errorf("card is in the trash: %s", cardID)
+	}
+	l, err := s.store.GetList(ctx, listID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkListOpen(l); err != nil {
+		return nil, err
+	}
+	wf, boardID, err := s.workflowForList(ctx, listID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.activeBoard(ctx, boardID); err != nil {
+		return nil, err
+	}
+	_, srcBoardID, err := s.workflowForList(ctx, src.ListID)
+	if err != nil {
+		return nil, err
+	}
+	status := src.Status
+	if !wf.HasStatus(status) {
+		status = wf.Statuses[0]
+	}
+	if l.Status != "" && l.Status != status {
+		// The copy has the original's checklist, so the original stands in
+		// for it against the guards.
+		entering := *src
+		entering.Status = status
+		if err := s.checkTransition(ctx, wf, &entering, l.Status); err != nil {
+			return nil, err
+		}
+		status = l.Status
+	}
+	title := opts.Title
+	if title == "" {
+		title = src.Title
+	}
+	var c *model.Card
+	err = s.inTx(ctx, func(tx *Service) error {
+		// The copy is open even if the original is archived.
+		open := *src
+		open.ArchivedAt = nil
+		nc, err := tx.copyCard(ctx, &open, listID, title, status, opts.Placement)
+		if err != nil {
+			return err
+		}
+		if src.ParentID != "" && boardID == srcBoardID {
+			if err := tx.store.SetCardParent(ctx, nc.ID, src.ParentID); err != nil {
+				return err
+			}
+		}
+		labelIDs, err := tx.mapLabels(ctx, src.ID, boardID, boardID == srcBoardID, opts.LabelMap, model.LabelsCreate, actor)
+		if err != nil {
+			return err
+		}
+		for _, id := range labelIDs {
+			if err := tx.store.AddLabelToCard(ctx, nc.ID, id); err != nil {
+				return err
+			}
+		}
+		values, err := tx.store.GetFieldValuesForCards(ctx, []string{src.ID})
+		if err != nil {
+			return err
+		}
+		fields, err := tx.store.ListCustomFields(ctx, boardID)
+		if err != nil {
+			return err
+		}
+		if err := tx.copyFieldValues(ctx, nc.ID, values[src.ID], fields); err != nil {
+			return err
+		}
+		if opts.Dependencies {
+			deps, err := tx.store.GetDependencies(ctx, src.ID)
+			if err != nil {
+				return err
+			}
+			for _, d := range deps {
+				if _, depBoard, err := tx.workflowForList(ctx, d.ListID); err != nil || depBoard != boardID {
+					continue
+				}
+				dep := &model.CardDependency{ID: model.NewID(), CardID: nc.ID, DependsOnCardID: d.ID}
+				if err := tx.store.AddDependency(ctx, dep); err != nil {
+					return err
+				}
+			}
+		}
+		if c, err = tx.store.GetCardDetail(ctx, nc.ID, 0); err != nil {
+			return err
+		}
+		tx.logActivity(ctx, c.ID, actor, model.ActionCloned, map[string]string{"from": src.ID})
+		if err := tx.audit(ctx, boardID, model.EntityCard, c.ID, model.ActionCloned, actor, nil, c); err != nil {
+			return err
+		}
+		tx.publish("card.created", boardID, c)
+		tx.notifyAssigned(ctx, boardID, c, actor)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// mapLabels returns the IDs of the target board's labels for the card's
+// labels. Labels in labelMap map to the label it names, or to none for "";
+// the others stay as they are on the same board and, on another, are dealt
+// with by policy, one of the label policies, LabelsCreate if empty.
+func (s *Service) mapLabels(ctx context.Context, cardID, boardID string, sameBoard bool, labelMap map[string]string, policy, actor string) ([]string, error) {
+	labels, err := s.store.GetLabelsForCard(ctx, cardID)
+	if err != nil {
+		return nil, err
+	}
+	targets, err := s.store.ListLabelsByBoard(ctx, boardID)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, l := range labels {
+		if id, ok := labelMap[l.ID]; ok {
+			if id == "" {
+				continue
+			}
+			if !slices.ContainsFunc(targets, func(t model.Label) bool { return t.ID == id }) {
+				return nil, fmt.Errorf("label %s is not on the target board", id)
+			}
+			ids = append(ids, id)
+			continue
+		}
+		if sameBoard {
+			ids = append(ids, l.ID)
+			continue
+		}
+		if policy == model.LabelsDrop {
+			continue
+		}
+		i := slices.IndexFunc(targets, func(t model.Label) bool { return t.Name == l.Name })
+		if i >= 0 {
+			ids = append(ids, targets[i].ID)
+			continue
+		}
+		if policy == model.LabelsMatch {
+			continue
+		}
+		created, err := s.CreateLabel(ctx, boardID, l.Name, l.Color, actor)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, *created)
+		ids = append(ids, created.ID)
+	}
+	return ids, nil
+}
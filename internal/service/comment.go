@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aellingwood/cielo/internal/model"
+)
+
+// commentCard returns the card for a new comment and its board ID. Archived
+// cards can still be discussed; cards in the trash cannot.
+func (s *Service) commentCard(ctx context.Context, cardID string) (*model.Card, string, error) {
+	c, err := s.store.GetCard(ctx, cardID)
+	if err != nil {
+		return nil, "", err
+	}
+	if c.DeletedAt != nil {
+		return nil, "", fmt.Errorf("card is in the trash: %s", cardID)
+	}
+	_, boardID, err := s.workflowForList(ctx, c.ListID)
+	if err != nil {
+		return nil, "", err
+	}
+	return c, boardID, nil
+}
+
+// ListComments returns a page of the card's comments, oldest first. Replies
+// name their parent in parent_id.
+func (s *Service) ListComments(ctx context.Context, cardID string, page model.Page) ([]model.Comment, string, error) {
+	if _, err := s.store.GetCard(ctx, cardID); err != nil {
+		return nil, "", err
+	}
+	return s.store.ListComments(ctx, cardID, page)
+}
+
+func (s *Service) GetComment(ctx context.Context, id string) (*model.Comment, error) {
+	return s.store.GetComment(ctx, id)
+}
+
+// AddComment comments on the card, replying to parentID if it is set. The
+// comment is also recorded in the card's activity.
+func (s *Service) AddComment(ctx context.Context, cardID, parentID, body, actor string) (*model.Comment, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return nil, fmt.Errorf("comment text is required")
+	}
+	card, boardID, err := s.commentCard(ctx, cardID)
+	if err != nil {
+		return nil, err
+	}
+	if parentID != "" {
+		p, err := s.store.GetComment(ctx, parentID)
+		if err != nil {
+			return nil, err
+		}
+		if p.CardID != cardID {
+			return nil, fmt.Errorf("comment %s is on another card", parentID)
+		}
+		if p.DeletedAt != nil {
+			return nil, fmt.Errorf("cannot reply to a deleted comment: %s", parentID)
+		}
+	}
+	c := &model.Comment{
+		ID: model.NewID(), CardID: cardID, ParentID: parentID, Author: actor,
+		Body: body, Mentions: parseMentions(body),
+	}
+	err = s.inTx(ctx, func(tx *Service) error {
+		if err := tx.store.CreateComment(ctx, c); err != nil {
+			return err
+		}
+		detail := map[string]string{"comment_id": c.ID, "text": body}
+		if parentID != "" {
+			detail["parent_id"] = parentID
+		}
+		tx.logActivity(ctx, cardID, actor, model.ActionComment, detail)
+		tx.publish("comment.created", boardID, c)
+		return tx.audit(ctx, boardID, model.EntityComment, c.ID, model.ActionCreated, actor, nil, c)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.notifyMentioned(ctx, boardID, card, c, c.Mentions, actor)
+	s.notifyCommented(ctx, boardID, card, c, actor)
+	return c, nil
+}
+
+// EditComment replaces the comment's body and marks it edited.
+func (s *Service) EditComment(ctx context.Context, id, body, actor string) (*model.Comment, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return nil, fmt.Errorf("comment text is required")
+	}
+	c, err := s.store.GetComment(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if c.DeletedAt != nil {
+		return nil, fmt.Errorf("comment is deleted: %s", id)
+	}
+	card, boardID, err := s.commentCard(ctx, c.CardID)
+	if err != nil {
+		return nil, err
+	}
+	if body == c.Body {
+		return c, nil
+	}
+	before := *c
+	t := time.Now().UTC()
+	c.Body, c.Mentions, c.EditedAt = body, parseMentions(body), &t
+	err = s.inTx(ctx, func(tx *Service) error {
+		if err := tx.store.UpdateComment(ctx, c); err != nil {
+			return err
+		}
+		tx.logActivity(ctx, c.CardID, actor, model.ActionCommentEdited, map[string]string{"comment_id": id, "text": body})
+		tx.publish("comment.updated", boardID, c)
+		return tx.audit(ctx, boardID, model.EntityComment, id, model.ActionUpdated, actor, &before, c)
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Only names the edit adds are told; the others were told already.
+	var added []string
+	for _, name := range c.Mentions {
+		if !slices.Contains(before.Mentions, name) {
+			added = append(added, name)
+		}
+	}
+	s.notifyMentioned(ctx, boardID, card, c, added, actor)
+	return c, nil
+}
+
+// DeleteComment clears the comment's body and marks it deleted. Replies to
+// it are kept.
+func (s *Service) DeleteComment(ctx context.Context, id, actor string) error {
+	c, err := s.store.GetComment(ctx, id)
+	if err != nil {
+		return err
+	}
+	if c.DeletedAt != nil {
+		return nil
+	}
+	_, boardID, err := s.commentCard(ctx, c.CardID)
+	if err != nil {
+		return err
+	}
+	before := *c
+	t := time.Now().UTC()
+	c.Body, c.Mentions, c.DeletedAt = "", nil, &t
+	return s.inTx(ctx, func(tx *Service) error {
+		if err := tx.store.UpdateComment(ctx, c); err != nil {
+			return err
+		}
+		tx.logActivity(ctx, c.CardID, actor, model.ActionCommentDeleted, map[string]string{"comment_id": id})
+		tx.publish("comment.deleted", boardID, map[string]string{"id": id, "card_id": c.CardID})
+		return tx.audit(ctx, boardID, model.EntityComment, id, model.ActionDeleted, actor, &before, nil)
+	})
+}
+
+var (
+	// codePattern matches fenced code blocks and inline code spans, in
+	// which @ does not mention anyone.
+	codePattern = regexp.MustCompile("(?s)```.*?```|`[^`\n]*`")
+	// mentionPattern matches @name where the @ does not follow a word
+	// character, so that email addresses are not taken for mentions.
+	mentionPattern = regexp.MustCompile(`(?:^|[^\w@./-])@([A-Za-z0-9][\w.-]*)`)
+)
+
+// parseMentions returns the distinct names mentioned in a markdown body, in
+// the order they first appear.
+func parseMentions(body string) []string {
+	body = codePattern.ReplaceAllString(body, " ")
+	var names []string
+	for _, m := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		// Trailing punctuation ends the sentence, not the name.
+		name := strings.TrimRight(m[1], ".-")
+		if name != "" && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
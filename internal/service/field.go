@@ -0,0 +1,258 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/aellingwood/cielo/internal/model"
+)
+
+// ListCustomFields returns the board's custom fields in order.
+func (s *Service) ListCustomFields(ctx context.Context, boardID string) ([]model.CustomField, error) {
+	if _, err := s.store.GetBoard(ctx, boardID); err != nil {
+		return nil, err
+	}
+	return s.store.ListCustomFields(ctx, boardID)
+}
+
+// CreateCustomField defines a field on the board, at the end of its fields
+// unless pos says otherwise.
+func (s *Service) CreateCustomField(ctx context.Context, boardID, name, typ string, options []string, pos model.Placement, actor string) (*model.CustomField, error) {
+	if _, err := s.activeBoard(ctx, boardID); err != nil {
+		return nil, err
+	}
+	f := &model.CustomField{ID: model.NewID(), BoardID: boardID, Name: name, Type: typ, Options: options}
+	if err := f.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.checkFieldName(ctx, boardID, "", name); err != nil {
+		return nil, err
+	}
+	err := s.inTx(ctx, func(tx *Service) error {
+		if err := tx.store.CreateCustomField(ctx, f, pos); err != nil {
+			return err
+		}
+		tx.publish("custom_field.created", boardID, f)
+		return tx.audit(ctx, boardID, model.EntityCustomField, f.ID, model.ActionCreated, actor, nil, f)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// UpdateCustomField renames or reorders a field, or changes an enum
+// field's options. Cards keep values for options that are removed until
+// they are changed.
+func (s *Service) UpdateCustomField(ctx context.Context, id string, spec model.CustomFieldSpec, actor string) (*model.CustomField, error) {
+	f, err := s.store.GetCustomField(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.activeBoard(ctx, f.BoardID); err != nil {
+		return nil, err
+	}
+	before := *f
+	if spec.Name != nil && *spec.Name != f.Name {
+		if err := s.checkFieldName(ctx, f.BoardID, id, *spec.Name); err != nil {
+			return nil, err
+		}
+		f.Name = *spec.Name
+	}
+	if spec.Options != nil {
+		f.Options = *spec.Options
+	}
+	if err := f.Validate(); err != nil {
+		return nil, err
+	}
+	err = s.inTx(ctx, func(tx *Service) error {
+		if err := tx.store.UpdateCustomField(ctx, f); err != nil {
+			return err
+		}
+		if !spec.Placement.IsZero() {
+			if err := tx.store.MoveCustomField(ctx, id, spec.Placement); err != nil {
+				return err
+			}
+		}
+		var err error
+		if f, err = tx.store.GetCustomField(ctx, id); err != nil {
+			return err
+		}
+		tx.publish("custom_field.updated", f.BoardID, f)
+		return tx.audit(ctx, f.BoardID, model.EntityCustomField, id, model.ActionUpdated, actor, &before, f)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// DeleteCustomField removes a field and every card's value for it.
+func (s *Service) DeleteCustomField(ctx context.Context, id, actor string) error {
+	f, err := s.store.GetCustomField(ctx, id)
+	if err != nil {
+		return err
+	}
+	if _, err := s.activeBoard(ctx, f.BoardID); err != nil {
+		return err
+	}
+	return s.inTx(ctx, func(tx *Service) error {
+		if err := tx.store.DeleteCustomField(ctx, id); err != nil {
+			return err
+		}
+		tx.publish("custom_field.deleted", f.BoardID, map[string]string{"id": id})
+		return tx.audit(ctx, f.BoardID, model.EntityCustomField, id, model.ActionDeleted, actor, f, nil)
+	})
+}
+
+// checkFieldName reports an error if another field on the board, other
+// than excludeID, already has the name.
+func (s *Service) checkFieldName(ctx context.Context, boardID, excludeID, name string) error {
+	fields, err := s.store.ListCustomFields(ctx, boardID)
+	if err != nil {
+		return err
+	}
+	for _, f := range fields {
+		if f.Name == name && f.ID != excludeID {
+			return fmt.Errorf("custom field already exists: %s", name)
+		}
+	}
+	return nil
+}
+
+// fieldValue is a validated value for one of a card's custom fields. A nil
+// value clears it.
+type fieldValue struct {
+	field model.CustomField
+	value any
+}
+
+// parseFieldValues checks a map of custom field names to values against the
+// board's fields.
+func (s *Service) parseFieldValues(ctx context.Context, boardID string, raw any) ([]fieldValue, error) {
+	values, ok := raw.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("invalid fields: must be an object of custom field names to values")
+	}
+	fields, err := s.store.ListCustomFields(ctx, boardID)
+	if err != nil {
+		return nil, err
+	}
+	var out []fieldValue
+	for _, f := range fields {
+		v, ok := values[f.Name]
+		if !ok {
+			continue
+		}
+		parsed, err := parseFieldValue(&f, v)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, fieldValue{field: f, value: parsed})
+	}
+	if len(out) < len(values) {
+		for name := range values {
+			if !hasField(fields, name) {
+				return nil, fmt.Errorf("unknown custom field: %s", name)
+			}
+		}
+	}
+	return out, nil
+}
+
+func hasField(fields []model.CustomField, name string) bool {
+	for _, f := range fields {
+		if f.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// parseFieldValue checks v against the field's type and returns it as the
+// store keeps it: a string, a float64, or a time. Null and the empty string
+// yield nil, which clears the value.
+func parseFieldValue(f *model.CustomField, v any) (any, error) {
+	if v == nil || v == "" {
+		return nil, nil
+	}
+	if n, ok := v.(float64); ok && f.Type == model.FieldNumber {
+		return n, nil
+	}
+	str, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid value for %s field %s: %v", f.Type, f.Name, v)
+	}
+	switch f.Type {
+	case model.FieldNumber:
+		n, err := strconv.ParseFloat(str, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for number field %s: %q", f.Name, str)
+		}
+		return n, nil
+	case model.FieldDate:
+		if t, err := time.Parse("2006-01-02", str); err == nil {
+			return t, nil
+		}
+		t, err := time.Parse(time.RFC3339, str)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for date field %s: %q; use YYYY-MM-DD or RFC 3339", f.Name, str)
+		}
+		return t.UTC(), nil
+	case model.FieldEnum:
+		for _, o := range f.Options {
+			if o == str {
+				return str, nil
+			}
+		}
+		return nil, fmt.Errorf("invalid value for enum field %s: %q; must be one of %v", f.Name, str, f.Options)
+	case model.FieldURL:
+		u, err := url.Parse(str)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("invalid value for url field %s: %q; must be an http or https URL", f.Name, str)
+		}
+	}
+	return str, nil
+}
+
+// setFieldValues saves the card's new custom field values and logs each one
+// that changed. It returns the names of the fields that changed.
+func (s *Service) setFieldValues(ctx context.Context, cardID string, values []fieldValue, actor string) ([]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	current, err := s.store.GetFieldValuesForCards(ctx, []string{cardID})
+	if err != nil {
+		return nil, err
+	}
+	old := current[cardID]
+	byID := map[string]any{}
+	for _, v := range values {
+		byID[v.field.ID] = v.value
+	}
+	if err := s.store.SetCardFieldValues(ctx, cardID, byID); err != nil {
+		return nil, err
+	}
+	var changed []string
+	for _, v := range values {
+		if equalFieldValue(old[v.field.Name], v.value) {
+			continue
+		}
+		changed = append(changed, v.field.Name)
+		s.logActivity(ctx, cardID, actor, model.ActionFieldChanged, map[string]any{
+			"field": v.field.Name, "from": old[v.field.Name], "to": v.value,
+		})
+	}
+	return changed, nil
+}
+
+func equalFieldValue(a, b any) bool {
+	if ta, ok := a.(time.Time); ok {
+		tb, ok := b.(time.Time)
+		return ok && ta.Equal(tb)
+	}
+	return a == b
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/aellingwood/cielo/internal/model"
+)
+
+// checkParent verifies that a card may become the parent of a card on the
+// board. Parents and children share a board, and so its workflow.
+func (s *Service) checkParent(ctx context.Context, boardID, parentID string) error {
+	p, err := s.store.GetCard(ctx, parentID)
+	if err != nil {
+		return fmt.Errorf("parent %w", err)
+	}
+	if p.DeletedAt != nil {
+		return fmt.Errorf("parent card is in the trash: %s", parentID)
+	}
+	_, parentBoardID, err := s.workflowForList(ctx, p.ListID)
+	if err != nil {
+		return err
+	}
+	if parentBoardID != boardID {
+		return fmt.Errorf("parent card is on another board: %s", parentID)
+	}
+	return nil
+}
+
+// checkFamilyBoard refuses to move a card with a parent or children to
+// another board, which would split the family across boards.
+func (s *Service) checkFamilyBoard(ctx context.Context, c *model.Card) error {
+	if c.ParentID != "" {
+		return fmt.Errorf("card %s has a parent; detach it before moving it to another board", c.ID)
+	}
+	children, err := s.store.ListChildren(ctx, c.ID)
+	if err != nil {
+		return err
+	}
+	if len(children) > 0 {
+		return fmt.Errorf("card %s has children; detach them before moving it to another board", c.ID)
+	}
+	return nil
+}
+
+// ListChildren returns the card's children, wherever they are on the board,
+// with their labels and counts.
+func (s *Service) ListChildren(ctx context.Context, cardID string) ([]model.Card, error) {
+	if _, err := s.store.GetCard(ctx, cardID); err != nil {
+		return nil, err
+	}
+	cards, err := s.store.ListChildren(ctx, cardID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.attachSummaries(ctx, cards); err != nil {
+		return nil, err
+	}
+	if cards == nil {
+		cards = []model.Card{}
+	}
+	return cards, nil
+}
+
+// GetRollup summarizes the progress of all of the card's descendants.
+func (s *Service) GetRollup(ctx context.Context, cardID string) (*model.Rollup, error) {
+	if _, err := s.store.GetCard(ctx, cardID); err != nil {
+		return nil, err
+	}
+	return s.store.GetRollup(ctx, cardID)
+}
+
+// completeParent moves the card's parent to done once the card and all of
+// its siblings are done, if the parent's workflow asks for it. The parent
+// must be able to enter done on its own terms; if its workflow or guards
+// refuse, it is left as it is. Completing the parent may in turn complete
+// its own parent.
+func (s *Service) completeParent(ctx context.Context, c *model.Card) {
+	if c.ParentID == "" || c.Status != model.StatusDone {
+		return
+	}
+	p, err := s.store.GetCard(ctx, c.ParentID)
+	if err != nil || p.Status == model.StatusDone || p.ArchivedAt != nil || p.DeletedAt != nil {
+		return
+	}
+	wf, _, err := s.workflowForList(ctx, p.ListID)
+	if err != nil || !wf.CompleteParents || !wf.HasStatus(model.StatusDone) {
+		return
+	}
+	children, err := s.store.ListChildren(ctx, p.ID)
+	if err != nil {
+		return
+	}
+	for _, child := range children {
+		if child.Status != model.StatusDone {
+			return
+		}
+	}
+	if _, err := s.UpdateCard(ctx, p.ID, map[string]any{"status": model.StatusDone}, "system"); err != nil {
+		log.Printf("could not complete parent card %s: %v", p.ID, err)
+	}
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/aellingwood/cielo/internal/model"
+)
+
+// ListNotifications returns a page of the recipient's notifications, newest
+// first.
+func (s *Service) ListNotifications(ctx context.Context, recipient string, unreadOnly bool, page model.Page) ([]model.Notification, string, error) {
+	if recipient == "" {
+		return nil, "", fmt.Errorf("recipient is required")
+	}
+	return s.store.ListNotifications(ctx, recipient, unreadOnly, page)
+}
+
+// AckNotifications marks the recipient's notifications as read: those with
+// the given IDs, or all of them when ids is empty. It returns how many
+// notifications were unread.
+func (s *Service) AckNotifications(ctx context.Context, recipient string, ids []string) (int64, error) {
+	if recipient == "" {
+		return 0, fmt.Errorf("recipient is required")
+	}
+	return s.store.AckNotifications(ctx, recipient, ids)
+}
+
+// notify tells each recipient about the card, except the actor, who already
+// knows. Notifications report a change rather than make it: if they cannot
+// be stored, that is logged and the change goes ahead without them, even
+// when it is part of a batch or import that has yet to commit.
+func (s *Service) notify(ctx context.Context, recipients []string, typ, boardID string, c *model.Card, commentID, actor, message string) {
+	var notifications []*model.Notification
+	var seen []string
+	for _, r := range recipients {
+		if r == "" || r == actor || slices.Contains(seen, r) {
+			continue
+		}
+		seen = append(seen, r)
+		notifications = append(notifications, &model.Notification{
+			ID: model.NewID(), Recipient: r, Type: typ, BoardID: boardID, CardID: c.ID,
+			CommentID: commentID, Actor: actor, Message: message,
+		})
+	}
+	if len(notifications) == 0 {
+		return
+	}
+	if err := s.store.CreateNotifications(ctx, notifications); err != nil {
+		log.Printf("notify %s about card %s: %v", typ, c.ID, err)
+		return
+	}
+	for _, n := range notifications {
+		s.publish("notification.created", boardID, n)
+	}
+}
+
+// notifyAssigned tells the card's new assignee about the assignment.
+func (s *Service) notifyAssigned(ctx context.Context, boardID string, c *model.Card, actor string) {
+	if c.Assignee == "" {
+		return
+	}
+	s.notify(ctx, []string{c.Assignee}, model.NotifyAssigned, boardID, c, "", actor,
+		fmt.Sprintf("%s assigned you %q", actor, c.Title))
+}
+
+// notifyStatusChanged tells the card's watchers that its status changed.
+// When the card is done, the assignees of cards that it was the last open
+// dependency of are told they are unblocked.
+func (s *Service) notifyStatusChanged(ctx context.Context, boardID string, c *model.Card, from, actor string) {
+	s.notify(ctx, s.watchers(ctx, c), model.NotifyStatusChanged, boardID, c, "", actor,
+		fmt.Sprintf("%s changed %q from %s to %s", actor, c.Title, from, c.Status))
+	if c.Status != model.StatusDone {
+		return
+	}
+	dependents, err := s.store.GetDependents(ctx, c.ID)
+	if err != nil {
+		log.Printf("notify unblocked dependents of card %s: %v", c.ID, err)
+		return
+	}
+	for _, d := range dependents {
+		if d.Assignee == "" || d.Status == model.StatusDone || d.ArchivedAt != nil {
+			continue
+		}
+		deps, err := s.store.GetDependencies(ctx, d.ID)
+		if err != nil {
+			log.Printf("notify unblocked card %s: %v", d.ID, err)
+			continue
+		}
+		if slices.ContainsFunc(deps, func(dep model.Card) bool { return dep.Status != model.StatusDone }) {
+			continue
+		}
+		dBoardID := boardID
+		if d.Board != nil {
+			dBoardID = d.Board.ID
+		}
+		s.notify(ctx, []string{d.Assignee}, model.NotifyUnblocked, dBoardID, &d, "", actor,
+			fmt.Sprintf("%q is unblocked: %q was the last of its dependencies to be done", d.Title, c.Title))
+	}
+}
+
+// notifyUpdated tells the card's watchers which of its attributes changed,
+// except those in skip, who were told otherwise.
+func (s *Service) notifyUpdated(ctx context.Context, boardID string, c *model.Card, changes []string, actor string, skip ...string) {
+	if len(changes) == 0 {
+		return
+	}
+	recipients := slices.DeleteFunc(s.watchers(ctx, c), func(w string) bool { return slices.Contains(skip, w) })
+	s.notify(ctx, recipients, model.NotifyUpdated, boardID, c, "", actor,
+		fmt.Sprintf("%s updated %s of %q", actor, strings.Join(changes, ", "), c.Title))
+}
+
+// notifyMoved tells the card's watchers that it moved to another list
+// without changing status.
+func (s *Service) notifyMoved(ctx context.Context, boardID string, c *model.Card, l *model.List, actor string) {
+	s.notify(ctx, s.watchers(ctx, c), model.NotifyMoved, boardID, c, "", actor,
+		fmt.Sprintf("%s moved %q to %s", actor, c.Title, l.Name))
+}
+
+// notifyCommented tells the card's watchers about a new comment, except
+// those it mentions, who are told by notifyMentioned.
+func (s *Service) notifyCommented(ctx context.Context, boardID string, c *model.Card, comment *model.Comment, actor string) {
+	recipients := slices.DeleteFunc(s.watchers(ctx, c), func(w string) bool { return slices.Contains(comment.Mentions, w) })
+	s.notify(ctx, recipients, model.NotifyCommented, boardID, c, comment.ID, actor,
+		fmt.Sprintf("%s commented on %q: %s", actor, c.Title, excerpt(comment.Body, 120)))
+}
+
+// notifyMentioned tells the names mentioned in a comment about it.
+func (s *Service) notifyMentioned(ctx context.Context, boardID string, c *model.Card, comment *model.Comment, names []string, actor string) {
+	s.notify(ctx, names, model.NotifyMention, boardID, c, comment.ID, actor,
+		fmt.Sprintf("%s mentioned you on %q: %s", actor, c.Title, excerpt(comment.Body, 120)))
+}
+
+// excerpt shortens text to at most n runes.
+func excerpt(text string, n int) string {
+	r := []rune(text)
+	if len(r) <= n {
+		return text
+	}
+	return string(r[:n-1]) + "…"
+}
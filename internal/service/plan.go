@@ -0,0 +1,187 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/aellingwood/cielo/internal/model"
+)
+
+// defaultPlanList is the list a plan that names none is given.
+const defaultPlanList = "Backlog"
+
+// ParsePlan reads a plan written in YAML or, since YAML is a superset of
+// it, JSON. Fields the plan has no place for are refused, to catch typos.
+func ParsePlan(data []byte) (*model.Plan, error) {
+	var doc any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid plan: %w", err)
+	}
+	// Go through JSON so that the plan's JSON field names serve for YAML.
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid plan: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	var p model.Plan
+	if err := dec.Decode(&p); err != nil {
+		return nil, fmt.Errorf("invalid plan: %w", err)
+	}
+	return &p, nil
+}
+
+// ImportPlan validates the plan and creates its board, lists, labels,
+// cards, and dependencies, all in one transaction. A plan without lists
+// gets a single Backlog list. On a dry run, nothing is created and the
+// result only lists what would be.
+func (s *Service) ImportPlan(ctx context.Context, p *model.Plan, dryRun bool, actor string) (*model.PlanResult, error) {
+	plan := *p
+	if len(plan.Lists) == 0 {
+		plan.Lists = []model.ListTemplate{{Name: defaultPlanList}}
+	}
+	if err := plan.Validate(); err != nil {
+		return nil, err
+	}
+	res := &model.PlanResult{DryRun: dryRun, Diff: planDiff(&plan)}
+	if dryRun {
+		return res, nil
+	}
+	err := s.inTx(ctx, func(tx *Service) error {
+		b, err := tx.CreateBoard(ctx, plan.Name, plan.Description, actor)
+		if err != nil {
+			return err
+		}
+		if plan.Workflow != nil {
+			if _, err := tx.SetWorkflow(ctx, b.ID, plan.Workflow, actor); err != nil {
+				return err
+			}
+		}
+		lists := map[string]string{}
+		for _, lt := range plan.Lists {
+			l, err := tx.CreateList(ctx, b.ID, lt.Name, lt.Status, model.Placement{}, actor)
+			if err != nil {
+				return err
+			}
+			lists[lt.Name] = l.ID
+		}
+		labels := map[string]string{}
+		for _, lt := range plan.Labels {
+			l, err := tx.CreateLabel(ctx, b.ID, lt.Name, lt.Color, actor)
+			if err != nil {
+				return err
+			}
+			labels[lt.Name] = l.ID
+		}
+		res.Cards = map[string]string{}
+		create := func(pc *model.PlanCard, parentID string) (string, error) {
+			list := pc.List
+			if list == "" {
+				list = plan.Lists[0].Name
+			}
+			// Validate has parsed the due date already.
+			due, _ := model.ParseDueDate(pc.DueDate)
+			c, err := tx.CreateCard(ctx, lists[list], pc.Title, pc.Description, pc.Assignee, pc.Priority, parentID, due, actor, model.Placement{})
+			if err != nil {
+				return "", fmt.Errorf("card %q: %w", pc.Title, err)
+			}
+			for _, name := range pc.Labels {
+				if err := tx.AddLabelToCard(ctx, c.ID, labels[name], actor); err != nil {
+					return "", err
+				}
+			}
+			for _, text := range pc.Checklist {
+				if _, err := tx.AddChecklistItem(ctx, c.ID, text, "", model.Placement{}, actor); err != nil {
+					return "", err
+				}
+			}
+			if pc.Key != "" {
+				res.Cards[pc.Key] = c.ID
+			}
+			return c.ID, nil
+		}
+		for i := range plan.Epics {
+			epic := &plan.Epics[i]
+			id, err := create(&epic.PlanCard, "")
+			if err != nil {
+				return err
+			}
+			for j := range epic.Tasks {
+				if _, err := create(&epic.Tasks[j], id); err != nil {
+					return err
+				}
+			}
+		}
+		for i := range plan.Tasks {
+			if _, err := create(&plan.Tasks[i], ""); err != nil {
+				return err
+			}
+		}
+		for pc := range plan.Cards() {
+			for _, dep := range pc.DependsOn {
+				if err := tx.AddDependency(ctx, res.Cards[pc.Key], res.Cards[dep], actor); err != nil {
+					return fmt.Errorf("dependency %s -> %s: %w", pc.Key, dep, err)
+				}
+			}
+		}
+		res.Board, err = tx.store.GetBoard(ctx, b.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// planDiff lists what importing the plan creates, one "+" line each.
+func planDiff(p *model.Plan) []string {
+	diff := []string{fmt.Sprintf("+ board %q", p.Name)}
+	if p.Workflow != nil {
+		diff = append(diff, "+ workflow "+strings.Join(p.Workflow.Statuses, ", "))
+	}
+	for _, l := range p.Lists {
+		line := fmt.Sprintf("+ list %q", l.Name)
+		if l.Status != "" {
+			line += " bound to " + l.Status
+		}
+		diff = append(diff, line)
+	}
+	for _, l := range p.Labels {
+		diff = append(diff, fmt.Sprintf("+ label %q", l.Name))
+	}
+	card := func(pc *model.PlanCard, parent string) {
+		list := pc.List
+		if list == "" {
+			list = p.Lists[0].Name
+		}
+		line := fmt.Sprintf("+ card %q", pc.Title)
+		if pc.Key != "" {
+			line += " (" + pc.Key + ")"
+		}
+		line += fmt.Sprintf(" in %q", list)
+		if parent != "" {
+			line += fmt.Sprintf(" under %q", parent)
+		}
+		diff = append(diff, line)
+	}
+	for i := range p.Epics {
+		card(&p.Epics[i].PlanCard, "")
+		for j := range p.Epics[i].Tasks {
+			card(&p.Epics[i].Tasks[j], p.Epics[i].Title)
+		}
+	}
+	for i := range p.Tasks {
+		card(&p.Tasks[i], "")
+	}
+	for pc := range p.Cards() {
+		for _, dep := range pc.DependsOn {
+			diff = append(diff, fmt.Sprintf("+ dependency %s -> %s", pc.Key, dep))
+		}
+	}
+	return diff
+}
@@ -4,8 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log"
+	"time"
 
+	"github.com/aellingwood/cielo/internal/blob"
 	"github.com/aellingwood/cielo/internal/event"
+	"github.com/aellingwood/cielo/internal/filter"
 	"github.com/aellingwood/cielo/internal/model"
 	"github.com/aellingwood/cielo/internal/store"
 )
@@ -13,14 +17,66 @@ import (
 type Service struct {
 	store store.Store
 	bus   *event.Bus
+	// blobs holds attachment contents; attachments are refused while it
+	// is nil.
+	blobs         blob.Store
+	maxAttachment int64
+	// noCrossBoardDeps refuses dependencies between cards on different
+	// boards.
+	noCrossBoardDeps bool
+	// pending collects the events published inside a transaction; they
+	// are sent once it commits. It is nil outside transactions.
+	pending *[]event.Event
 }
 
 func New(s store.Store, bus *event.Bus) *Service {
 	return &Service{store: s, bus: bus}
 }
 
+// SetBlobStore enables attachments, keeping their contents in blobs and
+// refusing uploads larger than maxSize bytes.
+func (s *Service) SetBlobStore(blobs blob.Store, maxSize int64) {
+	s.blobs = blobs
+	s.maxAttachment = maxSize
+}
+
+// SetCrossBoardDependencies says whether a card may depend on a card on
+// another board. They may unless told otherwise; when they may not, cards
+// with dependencies cannot move boards away from them either.
+func (s *Service) SetCrossBoardDependencies(allowed bool) {
+	s.noCrossBoardDeps = !allowed
+}
+
 func (s *Service) publish(typ, boardID string, payload any) {
-	s.bus.Publish(event.Event{Type: typ, BoardID: boardID, Payload: payload})
+	e := event.Event{Type: typ, BoardID: boardID, Payload: payload}
+	if s.pending != nil {
+		*s.pending = append(*s.pending, e)
+		return
+	}
+	s.bus.Publish(e)
+}
+
+// inTx runs fn against a service bound to a store transaction, so that the
+// changes fn makes are kept or undone together. Events are held back until
+// the transaction commits and dropped if it rolls back. Calls on a service
+// that is already in a transaction join it.
+func (s *Service) inTx(ctx context.Context, fn func(tx *Service) error) error {
+	if s.pending != nil {
+		return fn(s)
+	}
+	var pending []event.Event
+	err := s.store.InTx(ctx, func(st store.Store) error {
+		tx := *s
+		tx.store, tx.pending = st, &pending
+		return fn(&tx)
+	})
+	if err != nil {
+		return err
+	}
+	for _, e := range pending {
+		s.bus.Publish(e)
+	}
+	return nil
 }
 
 func (s *Service) logActivity(ctx context.Context, cardID, actor, action string, detail any) {
@@ -41,290 +97,1231 @@ func (s *Service) CreateBoard(ctx context.Context, name, description, actor stri
 		return nil, fmt.Errorf("board name is required")
 	}
 	b := &model.Board{ID: model.NewID(), Name: name, Description: description}
-	if err := s.store.CreateBoard(ctx, b); err != nil {
+	err := s.inTx(ctx, func(tx *Service) error {
+		if err := tx.store.CreateBoard(ctx, b); err != nil {
+			return err
+		}
+		return tx.audit(ctx, b.ID, model.EntityBoard, b.ID, model.ActionCreated, actor, nil, b)
+	})
+	if err != nil {
 		return nil, err
 	}
 	return b, nil
 }
 
-func (s *Service) GetBoard(ctx context.Context, id string) (*model.Board, error) {
-	return s.store.GetBoard(ctx, id)
+// GetBoard returns the board. Boards in the trash are only returned with
+// includeDeleted.
+func (s *Service) GetBoard(ctx context.Context, id string, includeDeleted bool) (*model.Board, error) {
+	if includeDeleted {
+		return s.store.GetBoard(ctx, id)
+	}
+	return s.activeBoard(ctx, id)
 }
 
-func (s *Service) ListBoards(ctx context.Context) ([]model.Board, error) {
-	return s.store.ListBoards(ctx)
+// activeBoard returns the board, refusing boards in the trash: they can
+// neither be changed nor read until they are restored.
+func (s *Service) activeBoard(ctx context.Context, id string) (*model.Board, error) {
+	b, err := s.store.GetBoard(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if b.DeletedAt != nil {
+		return nil, fmt.Errorf("board is in the trash: %s", id)
+	}
+	return b, nil
 }
 
-func (s *Service) UpdateBoard(ctx context.Context, id, name, description string) (*model.Board, error) {
-	b, err := s.store.GetBoard(ctx, id)
+// activeCard returns the card and its board's ID, refusing cards that are in
+// the trash or on a board that is.
+func (s *Service) activeCard(ctx context.Context, id string) (*model.Card, string, error) {
+	c, err := s.store.GetCard(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+	if c.DeletedAt != nil {
+		return nil, "", fmt.Errorf("card is in the trash: %s", id)
+	}
+	_, boardID, err := s.workflowForList(ctx, c.ListID)
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err := s.activeBoard(ctx, boardID); err != nil {
+		return nil, "", err
+	}
+	return c, boardID, nil
+}
+
+func (s *Service) ListBoards(ctx context.Context, page model.Page) ([]model.Board, string, error) {
+	return s.store.ListBoards(ctx, page)
+}
+
+func (s *Service) UpdateBoard(ctx context.Context, id, name, description, actor string) (*model.Board, error) {
+	b, err := s.activeBoard(ctx, id)
 	if err != nil {
 		return nil, err
 	}
+	before := *b
 	if name != "" {
 		b.Name = name
 	}
 	b.Description = description
-	if err := s.store.UpdateBoard(ctx, b); err != nil {
+	err = s.inTx(ctx, func(tx *Service) error {
+		if err := tx.store.UpdateBoard(ctx, b); err != nil {
+			return err
+		}
+		return tx.audit(ctx, id, model.EntityBoard, id, model.ActionUpdated, actor, &before, b)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// DeleteBoard moves the board to the trash. It is purged once the trash
+// retention period has passed.
+func (s *Service) DeleteBoard(ctx context.Context, id, actor string) error {
+	b, err := s.store.GetBoard(ctx, id)
+	if err != nil {
+		return err
+	}
+	return s.inTx(ctx, func(tx *Service) error {
+		if err := tx.store.DeleteBoard(ctx, id); err != nil {
+			return err
+		}
+		tx.publish("board.deleted", id, map[string]string{"id": id})
+		return tx.audit(ctx, id, model.EntityBoard, id, model.ActionDeleted, actor, b, nil)
+	})
+}
+
+func (s *Service) RestoreBoard(ctx context.Context, id, actor string) (*model.Board, error) {
+	var b *model.Board
+	err := s.inTx(ctx, func(tx *Service) error {
+		if err := tx.store.RestoreBoard(ctx, id); err != nil {
+			return err
+		}
+		var err error
+		if b, err = tx.store.GetBoard(ctx, id); err != nil {
+			return err
+		}
+		tx.publish("board.restored", id, b)
+		return tx.audit(ctx, id, model.EntityBoard, id, model.ActionRestored, actor, nil, b)
+	})
+	if err != nil {
 		return nil, err
 	}
 	return b, nil
 }
 
-func (s *Service) DeleteBoard(ctx context.Context, id string) error {
-	return s.store.DeleteBoard(ctx, id)
+func (s *Service) ListDeletedBoards(ctx context.Context) ([]model.Board, error) {
+	return s.store.ListDeletedBoards(ctx)
+}
+
+// --- Workflow ---
+
+func (s *Service) GetWorkflow(ctx context.Context, boardID string) (*model.Workflow, error) {
+	b, err := s.store.GetBoard(ctx, boardID)
+	if err != nil {
+		return nil, err
+	}
+	if b.Workflow == nil {
+		return model.DefaultWorkflow(), nil
+	}
+	return b.Workflow, nil
+}
+
+// SetWorkflow replaces a board's workflow. It is rejected if any card on the
+// board currently holds a status the new workflow does not define.
+func (s *Service) SetWorkflow(ctx context.Context, boardID string, wf *model.Workflow, actor string) (*model.Workflow, error) {
+	if err := wf.Validate(); err != nil {
+		return nil, err
+	}
+	b, err := s.activeBoard(ctx, boardID)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := s.store.CountCardsByStatus(ctx, boardID)
+	if err != nil {
+		return nil, err
+	}
+	for status := range counts {
+		if !wf.HasStatus(status) {
+			return nil, fmt.Errorf("cards on this board still use status %s", status)
+		}
+	}
+	lists, err := s.store.ListListsByBoard(ctx, boardID, true)
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range lists {
+		if l.Status != "" && !wf.HasStatus(l.Status) {
+			return nil, fmt.Errorf("list %q is still bound to status %s", l.Name, l.Status)
+		}
+	}
+	prev := b.Workflow
+	if prev == nil {
+		prev = model.DefaultWorkflow()
+	}
+	b.Workflow = wf
+	err = s.inTx(ctx, func(tx *Service) error {
+		if err := tx.store.UpdateBoard(ctx, b); err != nil {
+			return err
+		}
+		tx.publish("board.updated", boardID, b)
+		return tx.audit(ctx, boardID, model.EntityBoard, boardID, model.ActionWorkflowChanged, actor, prev, wf)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return wf, nil
+}
+
+// workflowForList returns the workflow and board ID governing cards in the
+// given list.
+func (s *Service) workflowForList(ctx context.Context, listID string) (*model.Workflow, string, error) {
+	l, err := s.store.GetList(ctx, listID)
+	if err != nil {
+		return nil, "", err
+	}
+	wf, err := s.GetWorkflow(ctx, l.BoardID)
+	if err != nil {
+		return nil, "", err
+	}
+	return wf, l.BoardID, nil
+}
+
+// checkTransition verifies that card c may move to status to under wf,
+// including any guards attached to the target status.
+func (s *Service) checkTransition(ctx context.Context, wf *model.Workflow, c *model.Card, to string) error {
+	if !wf.HasStatus(to) {
+		return fmt.Errorf("invalid status: %s", to)
+	}
+	if c.Status == to {
+		return nil
+	}
+	if !wf.CanTransition(c.Status, to) {
+		return fmt.Errorf("transition from %s to %s is not allowed", c.Status, to)
+	}
+	for _, g := range wf.Guards[to] {
+		switch g {
+		case model.GuardHasAssignee:
+			if c.Assignee == "" {
+				return fmt.Errorf("cannot enter %s: card has no assignee", to)
+			}
+		case model.GuardDependenciesDone:
+			deps, err := s.store.GetDependencies(ctx, c.ID)
+			if err != nil {
+				return err
+			}
+			for _, d := range deps {
+				if d.Status != model.StatusDone {
+					return fmt.Errorf("cannot enter %s: dependency %q is not done", to, d.Title)
+				}
+			}
+		case model.GuardChecklistComplete:
+			items, err := s.store.ListChecklistItems(ctx, c.ID)
+			if err != nil {
+				return err
+			}
+			open := 0
+			for _, it := range items {
+				if !it.Done {
+					open++
+				}
+			}
+			if open > 0 {
+				return fmt.Errorf("cannot enter %s: %d of %d checklist items are open", to, open, len(items))
+			}
+		}
+	}
+	return nil
 }
 
 // --- Lists ---
 
-func (s *Service) CreateList(ctx context.Context, boardID, name string, position int, actor string) (*model.List, error) {
+func (s *Service) CreateList(ctx context.Context, boardID, name, status string, pos model.Placement, actor string) (*model.List, error) {
 	if name == "" {
 		return nil, fmt.Errorf("list name is required")
 	}
-	l := &model.List{ID: model.NewID(), BoardID: boardID, Name: name, Position: position}
-	if err := s.store.CreateList(ctx, l); err != nil {
+	if _, err := s.activeBoard(ctx, boardID); err != nil {
+		return nil, err
+	}
+	if err := s.checkListStatus(ctx, boardID, status); err != nil {
+		return nil, err
+	}
+	l := &model.List{ID: model.NewID(), BoardID: boardID, Name: name, Status: status}
+	err := s.inTx(ctx, func(tx *Service) error {
+		if err := tx.store.CreateList(ctx, l); err != nil {
+			return err
+		}
+		if !pos.IsZero() {
+			if err := tx.store.MoveList(ctx, l.ID, pos); err != nil {
+				return err
+			}
+			moved, err := tx.store.GetList(ctx, l.ID)
+			if err != nil {
+				return err
+			}
+			l = moved
+		}
+		tx.publish("list.created", boardID, l)
+		return tx.audit(ctx, boardID, model.EntityList, l.ID, model.ActionCreated, actor, nil, l)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (s *Service) GetList(ctx context.Context, id string) (*model.List, error) {
+	return s.store.GetList(ctx, id)
+}
+
+// checkListStatus verifies that a list's status binding, if any, names a
+// status in the board's workflow.
+func (s *Service) checkListStatus(ctx context.Context, boardID, status string) error {
+	if status == "" {
+		return nil
+	}
+	wf, err := s.GetWorkflow(ctx, boardID)
+	if err != nil {
+		return err
+	}
+	if !wf.HasStatus(status) {
+		return fmt.Errorf("invalid status: %s", status)
+	}
+	return nil
+}
+
+// listForStatus returns the first list on the board bound to status, or nil
+// if no list is bound to it.
+func (s *Service) listForStatus(ctx context.Context, boardID, status string) (*model.List, error) {
+	lists, err := s.store.ListListsByBoard(ctx, boardID, false)
+	if err != nil {
+		return nil, err
+	}
+	for i := range lists {
+		if lists[i].Status == status {
+			return &lists[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// ListListsByBoard returns the board's lists with their cards. Archived
+// lists and cards are only included when includeArchived is set. A positive
+// cardLimit caps the cards returned per list; lists with more cards carry a
+// cursor for ListCardsByList.
+func (s *Service) ListListsByBoard(ctx context.Context, boardID string, includeArchived bool, cardLimit int) ([]model.List, error) {
+	lists, err := s.store.ListListsByBoard(ctx, boardID, includeArchived)
+	if err != nil {
+		return nil, err
+	}
+	cards, next, err := s.store.ListCardsByBoard(ctx, boardID, includeArchived, cardLimit)
+	if err != nil {
+		return nil, err
+	}
+	byList := map[string][]model.Card{}
+	for _, c := range cards {
+		byList[c.ListID] = append(byList[c.ListID], c)
+	}
+	for i := range lists {
+		lists[i].Cards = byList[lists[i].ID]
+		if lists[i].Cards == nil {
+			lists[i].Cards = []model.Card{}
+		}
+		lists[i].CardsCursor = next[lists[i].ID]
+	}
+	return lists, nil
+}
+
+// ListCardsByList returns a page of the list's cards with their labels, and
+// the cursor of the next page if there is one.
+func (s *Service) ListCardsByList(ctx context.Context, listID string, includeArchived bool, page model.Page) ([]model.Card, string, error) {
+	cards, next, err := s.store.ListCardsByList(ctx, listID, includeArchived, page)
+	if err != nil {
+		return nil, "", err
+	}
+	if cards == nil {
+		cards = []model.Card{}
+	}
+	if err := s.attachSummaries(ctx, cards); err != nil {
+		return nil, "", err
+	}
+	return cards, next, nil
+}
+
+// attachSummaries sets the labels, dependency counts, checklist progress,
+// and custom field values of cards that are listed, loading them for all
+// the cards at once.
+func (s *Service) attachSummaries(ctx context.Context, cards []model.Card) error {
+	ids := make([]string, len(cards))
+	for i := range cards {
+		ids[i] = cards[i].ID
+	}
+	labels, err := s.store.GetLabelsForCards(ctx, ids)
+	if err != nil {
+		return err
+	}
+	deps, dependents, err := s.store.CountDependencies(ctx, ids)
+	if err != nil {
+		return err
+	}
+	done, total, err := s.store.CountChecklists(ctx, ids)
+	if err != nil {
+		return err
+	}
+	fields, err := s.store.GetFieldValuesForCards(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for i := range cards {
+		cards[i].Labels = labels[cards[i].ID]
+		if cards[i].Labels == nil {
+			cards[i].Labels = []model.Label{}
+		}
+		cards[i].DependencyCount = deps[cards[i].ID]
+		cards[i].DependentCount = dependents[cards[i].ID]
+		cards[i].ChecklistDone = done[cards[i].ID]
+		cards[i].ChecklistTotal = total[cards[i].ID]
+		cards[i].Fields = fields[cards[i].ID]
+	}
+	return nil
+}
+
+// UpdateList renames, rebinds, or reorders a list. A nil status or pos
+// leaves that attribute unchanged; an empty status removes the binding.
+func (s *Service) UpdateList(ctx context.Context, id, name string, status *string, pos *model.Placement, actor string) (*model.List, error) {
+	l, err := s.store.GetList(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.activeBoard(ctx, l.BoardID); err != nil {
+		return nil, err
+	}
+	before := *l
+	if name != "" {
+		l.Name = name
+	}
+	if status != nil {
+		if err := s.checkListStatus(ctx, l.BoardID, *status); err != nil {
+			return nil, err
+		}
+		l.Status = *status
+	}
+	err = s.inTx(ctx, func(tx *Service) error {
+		if err := tx.store.UpdateList(ctx, l); err != nil {
+			return err
+		}
+		if pos != nil {
+			if err := tx.store.MoveList(ctx, id, *pos); err != nil {
+				return err
+			}
+			moved, err := tx.store.GetList(ctx, id)
+			if err != nil {
+				return err
+			}
+			l = moved
+		}
+		tx.publish("list.updated", l.BoardID, l)
+		return tx.audit(ctx, l.BoardID, model.EntityList, id, model.ActionUpdated, actor, &before, l)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// checkListOpen rejects lists that are archived or in the trash as targets
+// for new or moved cards.
+func checkListOpen(l *model.List) error {
+	if l.DeletedAt != nil {
+		return fmt.Errorf("list is deleted: %s", l.ID)
+	}
+	if l.ArchivedAt != nil {
+		return fmt.Errorf("list is archived: %s", l.ID)
+	}
+	return nil
+}
+
+// setListLifecycle applies one of the store's list lifecycle operations,
+// audits it, and publishes the resulting list under the given event type.
+func (s *Service) setListLifecycle(ctx context.Context, id, actor, action, typ string, op func(store.Store, context.Context, string) error) (*model.List, error) {
+	before, err := s.store.GetList(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	var l *model.List
+	err = s.inTx(ctx, func(tx *Service) error {
+		if err := op(tx.store, ctx, id); err != nil {
+			return err
+		}
+		var err error
+		if l, err = tx.store.GetList(ctx, id); err != nil {
+			return err
+		}
+		tx.publish(typ, l.BoardID, l)
+		return tx.audit(ctx, l.BoardID, model.EntityList, id, action, actor, before, l)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (s *Service) ArchiveList(ctx context.Context, id, actor string) (*model.List, error) {
+	return s.setListLifecycle(ctx, id, actor, model.ActionArchived, "list.archived", store.Store.ArchiveList)
+}
+
+func (s *Service) UnarchiveList(ctx context.Context, id, actor string) (*model.List, error) {
+	return s.setListLifecycle(ctx, id, actor, model.ActionUnarchived, "list.unarchived", store.Store.UnarchiveList)
+}
+
+// DeleteList moves the list and its cards to the trash.
+func (s *Service) DeleteList(ctx context.Context, id, actor string) error {
+	l, err := s.store.GetList(ctx, id)
+	if err != nil {
+		return err
+	}
+	return s.inTx(ctx, func(tx *Service) error {
+		if err := tx.store.DeleteList(ctx, id); err != nil {
+			return err
+		}
+		tx.publish("list.deleted", l.BoardID, map[string]string{"id": id})
+		return tx.audit(ctx, l.BoardID, model.EntityList, id, model.ActionDeleted, actor, l, nil)
+	})
+}
+
+func (s *Service) RestoreList(ctx context.Context, id, actor string) (*model.List, error) {
+	l, err := s.store.GetList(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	b, err := s.store.GetBoard(ctx, l.BoardID)
+	if err != nil {
+		return nil, err
+	}
+	if b.DeletedAt != nil {
+		return nil, fmt.Errorf("board is deleted; restore it first: %s", b.ID)
+	}
+	return s.setListLifecycle(ctx, id, actor, model.ActionRestored, "list.restored", store.Store.RestoreList)
+}
+
+// --- Cards ---
+
+func (s *Service) CreateCard(ctx context.Context, listID, title, description, assignee, priority, parentID string, due *time.Time, actor string, pos model.Placement) (*model.Card, error) {
+	if title == "" {
+		return nil, fmt.Errorf("card title is required")
+	}
+	if priority == "" {
+		priority = model.PriorityMedium
+	}
+	if !model.ValidPriority(priority) {
+		return nil, fmt.Errorf("invalid priority: %s", priority)
+	}
+	l, err := s.store.GetList(ctx, listID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkListOpen(l); err != nil {
+		return nil, err
+	}
+	wf, boardID, err := s.workflowForList(ctx, listID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.activeBoard(ctx, boardID); err != nil {
+		return nil, err
+	}
+	if parentID != "" {
+		if err := s.checkParent(ctx, boardID, parentID); err != nil {
+			return nil, err
+		}
+	}
+	status := model.StatusUnassigned
+	if assignee != "" && wf.HasStatus(model.StatusAssigned) {
+		status = model.StatusAssigned
+	}
+	if !wf.HasStatus(status) {
+		status = wf.Statuses[0]
+	}
+	c := &model.Card{
+		ID:          model.NewID(),
+		ListID:      listID,
+		Title:       title,
+		Description: description,
+		Assignee:    assignee,
+		Status:      status,
+		Priority:    priority,
+		ParentID:    parentID,
+		DueDate:     due,
+	}
+	if l.Status != "" && l.Status != c.Status {
+		// A card created in a bound list enters the list's status, as if
+		// it had been moved there.
+		if err := s.checkTransition(ctx, wf, c, l.Status); err != nil {
+			return nil, err
+		}
+		c.Status = l.Status
+	}
+	err = s.inTx(ctx, func(tx *Service) error {
+		if err := tx.store.CreateCard(ctx, c); err != nil {
+			return err
+		}
+		if !pos.IsZero() {
+			if err := tx.store.MoveCard(ctx, c.ID, listID, pos); err != nil {
+				return err
+			}
+			moved, err := tx.store.GetCard(ctx, c.ID)
+			if err != nil {
+				return err
+			}
+			c = moved
+		}
+		c.Labels = []model.Label{}
+		tx.logActivity(ctx, c.ID, actor, model.ActionCreated, map[string]string{"title": title})
+		tx.publish("card.created", boardID, c)
+		return tx.audit(ctx, boardID, model.EntityCard, c.ID, model.ActionCreated, actor, nil, c)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.notifyAssigned(ctx, boardID, c, actor)
+	return c, nil
+}
+
+// GetCard returns the card with its labels, dependencies, dependents, and
+// latest activity.
+func (s *Service) GetCard(ctx context.Context, id string) (*model.Card, error) {
+	return s.store.GetCardDetail(ctx, id, 50)
+}
+
+func (s *Service) UpdateCard(ctx context.Context, id string, updates map[string]any, actor string) (*model.Card, error) {
+	c, err := s.store.GetCard(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if c.DeletedAt != nil {
+		return nil, fmt.Errorf("card is in the trash: %s", id)
+	}
+	wf, boardID, err := s.workflowForList(ctx, c.ListID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.activeBoard(ctx, boardID); err != nil {
+		return nil, err
+	}
+	before := *c
+	if v, ok := updates["title"].(string); ok && v != "" {
+		c.Title = v
+	}
+	if v, ok := updates["description"].(string); ok {
+		c.Description = v
+	}
+	if v, ok := updates["assignee"].(string); ok {
+		c.Assignee = v
+	}
+	fromListID := c.ListID
+	toListID := c.ListID
+	if v, ok := updates["status"].(string); ok && v != c.Status {
+		if err := s.checkTransition(ctx, wf, c, v); err != nil {
+			return nil, err
+		}
+		c.Status = v
+		// Follow the status into its bound list unless the card already
+		// sits in a list bound to it.
+		current, err := s.store.GetList(ctx, c.ListID)
+		if err != nil {
+			return nil, err
+		}
+		if current.Status != v {
+			target, err := s.listForStatus(ctx, boardID, v)
+			if err != nil {
+				return nil, err
+			}
+			if target != nil {
+				toListID = target.ID
+			}
+		}
+	}
+	if v, ok := updates["priority"].(string); ok {
+		if !model.ValidPriority(v) {
+			return nil, fmt.Errorf("invalid priority: %s", v)
+		}
+		c.Priority = v
+	}
+	// due_date is a string to set, or null / "" to clear.
+	rawDue, dueSet := updates["due_date"]
+	if dueSet {
+		v, ok := rawDue.(string)
+		if !ok && rawDue != nil {
+			return nil, fmt.Errorf("invalid due_date: must be an RFC 3339 string or null")
+		}
+		due, err := model.ParseDueDate(v)
+		if err != nil {
+			return nil, err
+		}
+		c.DueDate = due
+	}
+	// parent_id is a card ID to nest the card under, or null / "" to
+	// detach it.
+	rawParent, parentSet := updates["parent_id"]
+	if parentSet {
+		v, ok := rawParent.(string)
+		if !ok && rawParent != nil {
+			return nil, fmt.Errorf("invalid parent_id: must be a card ID or null")
+		}
+		if v != "" && v != c.ParentID {
+			if err := s.checkParent(ctx, boardID, v); err != nil {
+				return nil, err
+			}
+		}
+		c.ParentID = v
+	}
+	// fields maps custom field names to values to set, or to null to
+	// clear.
+	var fieldValues []fieldValue
+	if raw, ok := updates["fields"]; ok {
+		fieldValues, err = s.parseFieldValues(ctx, boardID, raw)
+		if err != nil {
+			return nil, err
+		}
+	}
+	// Everything is validated; the writes succeed or fail together.
+	var changedFields []string
+	err = s.inTx(ctx, func(tx *Service) error {
+		if c.ParentID != before.ParentID {
+			if err := tx.store.SetCardParent(ctx, c.ID, c.ParentID); err != nil {
+				return err
+			}
+		}
+		if err := tx.store.UpdateCard(ctx, c); err != nil {
+			return err
+		}
+		var err error
+		if changedFields, err = tx.setFieldValues(ctx, c.ID, fieldValues, actor); err != nil {
+			return err
+		}
+		if c.ParentID != before.ParentID {
+			tx.logActivity(ctx, c.ID, actor, model.ActionParentChanged, map[string]string{
+				"from": before.ParentID, "to": c.ParentID,
+			})
+		}
+		if dueSet && !equalDue(before.DueDate, c.DueDate) {
+			tx.logActivity(ctx, c.ID, actor, model.ActionDueDateChanged, map[string]any{
+				"from": before.DueDate, "to": c.DueDate,
+			})
+		}
+		detail := map[string]any{}
+		for k, v := range updates {
+			if k == "due_date" || k == "parent_id" || k == "fields" {
+				continue
+			}
+			detail[k] = v
+		}
+		if toListID != fromListID {
+			if err := tx.store.MoveCard(ctx, c.ID, toListID, model.Placement{}); err != nil {
+				return err
+			}
+			c.ListID = toListID
+			detail["from_list"] = fromListID
+			detail["to_list"] = toListID
+		}
+		if len(detail) > 0 {
+			tx.logActivity(ctx, c.ID, actor, model.ActionStatusChanged, detail)
+		}
+		tx.publish("card.updated", boardID, c)
+		return tx.audit(ctx, boardID, model.EntityCard, id, model.ActionUpdated, actor, &before, c)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if c.Assignee != before.Assignee {
+		s.notifyAssigned(ctx, boardID, c, actor)
+	}
+	if c.Status != before.Status {
+		s.notifyStatusChanged(ctx, boardID, c, before.Status, actor)
+		s.completeParent(ctx, c)
+	} else {
+		var skip []string
+		if c.Assignee != before.Assignee {
+			// The new assignee was told of the whole card already.
+			skip = append(skip, c.Assignee)
+		}
+		s.notifyUpdated(ctx, boardID, c, cardChanges(&before, c, changedFields), actor, skip...)
+	}
+	return s.GetCard(ctx, id)
+}
+
+// cardChanges names the attributes of a card that an update changed, other
+// than its status, for notifications. fields names the custom fields that
+// changed.
+func cardChanges(before, after *model.Card, fields []string) []string {
+	var changes []string
+	if after.Title != before.Title {
+		changes = append(changes, "title")
+	}
+	if after.Description != before.Description {
+		changes = append(changes, "description")
+	}
+	if after.Assignee != before.Assignee {
+		changes = append(changes, "assignee")
+	}
+	if after.Priority != before.Priority {
+		changes = append(changes, "priority")
+	}
+	if !equalDue(before.DueDate, after.DueDate) {
+		changes = append(changes, "due date")
+	}
+	if after.ParentID != before.ParentID {
+		changes = append(changes, "parent")
+	}
+	for _, f := range fields {
+		changes = append(changes, "field "+f)
+	}
+	return changes
+}
+
+// equalDue reports whether two optional due dates are the same instant.
+func equalDue(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// MoveCard moves the card to the target list, which may be on another
+// board. A card that moves boards takes the target workflow's first status
+// unless the target list is bound to one or the workflow has its own, keeps
+// the labels opts says it keeps and the custom field values the target
+// board has fields of the same name and type for, and is announced on both
+// boards.
+func (s *Service) MoveCard(ctx context.Context, cardID, targetListID string, opts model.CardMoveOptions, actor string) (*model.Card, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	c, err := s.store.GetCard(ctx, cardID)
+	if err != nil {
+		return nil, err
+	}
+	if c.ArchivedAt != nil || c.DeletedAt != nil {
+		return nil, fmt.Errorf("card is archived or deleted: %s", cardID)
+	}
+	l, err := s.store.GetList(ctx, targetListID)
+	if err != nil {
 		return nil, err
 	}
-	s.publish("list.created", boardID, l)
-	return l, nil
-}
-
-func (s *Service) GetList(ctx context.Context, id string) (*model.List, error) {
-	return s.store.GetList(ctx, id)
-}
-
-func (s *Service) ListListsByBoard(ctx context.Context, boardID string) ([]model.List, error) {
-	lists, err := s.store.ListListsByBoard(ctx, boardID)
+	if err := checkListOpen(l); err != nil {
+		return nil, err
+	}
+	if _, err := s.activeBoard(ctx, l.BoardID); err != nil {
+		return nil, err
+	}
+	wf, err := s.GetWorkflow(ctx, l.BoardID)
 	if err != nil {
 		return nil, err
 	}
-	for i := range lists {
-		cards, err := s.store.ListCardsByList(ctx, lists[i].ID)
-		if err != nil {
+	_, fromBoardID, err := s.workflowForList(ctx, c.ListID)
+	if err != nil {
+		return nil, err
+	}
+	crossBoard := fromBoardID != l.BoardID
+	if crossBoard {
+		if err := s.checkFamilyBoard(ctx, c); err != nil {
 			return nil, err
 		}
-		if cards == nil {
-			cards = []model.Card{}
+		if err := s.checkDependencyBoards(ctx, c, fromBoardID, l.BoardID); err != nil {
+			return nil, err
 		}
-		for j := range cards {
-			labels, err := s.store.GetLabelsForCard(ctx, cards[j].ID)
-			if err != nil {
-				return nil, err
+	}
+	before := *c
+	fromListID := c.ListID
+	fromStatus := c.Status
+	status := c.Status
+	switch {
+	case l.Status != "":
+		status = l.Status
+	case crossBoard && !wf.HasStatus(status):
+		status = wf.Statuses[0]
+	}
+	if l.Status != "" && l.Status != c.Status {
+		// The target list is bound to a status: the move is also a status
+		// change and must satisfy the workflow.
+		if err := s.checkTransition(ctx, wf, c, l.Status); err != nil {
+			return nil, err
+		}
+	}
+	err = s.inTx(ctx, func(tx *Service) error {
+		if err := tx.store.MoveCard(ctx, cardID, targetListID, opts.Placement); err != nil {
+			return err
+		}
+		if status != c.Status {
+			c.Status = status
+			if err := tx.store.UpdateCard(ctx, c); err != nil {
+				return err
 			}
-			if labels == nil {
-				labels = []model.Label{}
+		}
+		if crossBoard {
+			if err := tx.rehome(ctx, c, fromBoardID, l.BoardID, opts, actor); err != nil {
+				return err
 			}
-			cards[j].Labels = labels
 		}
-		lists[i].Cards = cards
-	}
-	return lists, nil
-}
-
-func (s *Service) UpdateList(ctx context.Context, id, name string, position int) (*model.List, error) {
-	l, err := s.store.GetList(ctx, id)
+		moved, err := tx.store.GetCard(ctx, cardID)
+		if err != nil {
+			return err
+		}
+		detail := map[string]string{"from_list": fromListID, "to_list": targetListID}
+		payload := map[string]any{
+			"card_id": cardID, "from_list": fromListID, "to_list": targetListID, "position": moved.Position,
+		}
+		if c.Status != fromStatus {
+			detail["from_status"] = fromStatus
+			detail["to_status"] = c.Status
+			payload["status"] = c.Status
+		}
+		if crossBoard {
+			detail["from_board"], detail["to_board"] = fromBoardID, l.BoardID
+			payload["from_board"], payload["to_board"] = fromBoardID, l.BoardID
+		}
+		tx.logActivity(ctx, cardID, actor, model.ActionMoved, detail)
+		if crossBoard {
+			if err := tx.audit(ctx, fromBoardID, model.EntityCard, cardID, model.ActionMoved, actor, &before, moved); err != nil {
+				return err
+			}
+			tx.publish("card.moved", fromBoardID, payload)
+		}
+		tx.publish("card.moved", l.BoardID, payload)
+		return tx.audit(ctx, l.BoardID, model.EntityCard, cardID, model.ActionMoved, actor, &before, moved)
+	})
 	if err != nil {
 		return nil, err
 	}
-	if name != "" {
-		l.Name = name
-	}
-	l.Position = position
-	if err := s.store.UpdateList(ctx, l); err != nil {
-		return nil, err
+	if c.Status != fromStatus {
+		s.notifyStatusChanged(ctx, l.BoardID, c, fromStatus, actor)
+		s.completeParent(ctx, c)
+	} else if fromListID != targetListID {
+		s.notifyMoved(ctx, l.BoardID, c, l, actor)
 	}
-	s.publish("list.updated", l.BoardID, l)
-	return l, nil
+	return s.GetCard(ctx, cardID)
 }
 
-func (s *Service) DeleteList(ctx context.Context, id string) error {
-	l, err := s.store.GetList(ctx, id)
+// rehome moves the card's labels and custom field values from the board it
+// left to the one it moved to: the labels as opts says, and the values to
+// fields of the same name that accept them. The rest are dropped.
+func (s *Service) rehome(ctx context.Context, c *model.Card, fromBoardID, toBoardID string, opts model.CardMoveOptions, actor string) error {
+	labels, err := s.store.GetLabelsForCard(ctx, c.ID)
 	if err != nil {
 		return err
 	}
-	if err := s.store.DeleteList(ctx, id); err != nil {
+	labelIDs, err := s.mapLabels(ctx, c.ID, toBoardID, false, opts.LabelMap, opts.Labels, actor)
+	if err != nil {
 		return err
 	}
-	s.publish("list.deleted", l.BoardID, map[string]string{"id": id})
-	return nil
-}
-
-// --- Cards ---
-
-func (s *Service) CreateCard(ctx context.Context, listID, title, description, assignee, priority, actor string, position int) (*model.Card, error) {
-	if title == "" {
-		return nil, fmt.Errorf("card title is required")
+	for _, l := range labels {
+		if err := s.store.RemoveLabelFromCard(ctx, c.ID, l.ID); err != nil {
+			return err
+		}
 	}
-	if priority == "" {
-		priority = model.PriorityMedium
+	for _, id := range labelIDs {
+		if err := s.store.AddLabelToCard(ctx, c.ID, id); err != nil {
+			return err
+		}
 	}
-	if !model.ValidPriority(priority) {
-		return nil, fmt.Errorf("invalid priority: %s", priority)
+
+	values, err := s.store.GetFieldValuesForCards(ctx, []string{c.ID})
+	if err != nil {
+		return err
 	}
-	status := model.StatusUnassigned
-	if assignee != "" {
-		status = model.StatusAssigned
+	if len(values[c.ID]) == 0 {
+		return nil
 	}
-	c := &model.Card{
-		ID:          model.NewID(),
-		ListID:      listID,
-		Title:       title,
-		Description: description,
-		Position:    position,
-		Assignee:    assignee,
-		Status:      status,
-		Priority:    priority,
+	old, err := s.store.ListCustomFields(ctx, fromBoardID)
+	if err != nil {
+		return err
 	}
-	if err := s.store.CreateCard(ctx, c); err != nil {
-		return nil, err
+	cleared := map[string]any{}
+	for _, f := range old {
+		cleared[f.ID] = nil
+	}
+	if err := s.store.SetCardFieldValues(ctx, c.ID, cleared); err != nil {
+		return err
 	}
-	c.Labels = []model.Label{}
-	l, _ := s.store.GetList(ctx, listID)
-	boardID := ""
-	if l != nil {
-		boardID = l.BoardID
+	fields, err := s.store.ListCustomFields(ctx, toBoardID)
+	if err != nil {
+		return err
 	}
-	s.logActivity(ctx, c.ID, actor, model.ActionCreated, map[string]string{"title": title})
-	s.publish("card.created", boardID, c)
-	return c, nil
+	return s.copyFieldValues(ctx, c.ID, values[c.ID], fields)
 }
 
-func (s *Service) GetCard(ctx context.Context, id string) (*model.Card, error) {
-	c, err := s.store.GetCard(ctx, id)
+// checkDependencyBoards refuses to move the card from one board to another
+// when cross-board dependencies are disabled and the move would leave it
+// depending on, or blocking, a card on another board.
+func (s *Service) checkDependencyBoards(ctx context.Context, c *model.Card, fromBoardID, toBoardID string) error {
+	if !s.noCrossBoardDeps {
+		return nil
+	}
+	deps, err := s.store.GetDependencies(ctx, c.ID)
 	if err != nil {
-		return nil, err
-	}
-	c.Labels, _ = s.store.GetLabelsForCard(ctx, id)
-	if c.Labels == nil {
-		c.Labels = []model.Label{}
-	}
-	c.Dependencies, _ = s.store.GetDependencies(ctx, id)
-	if c.Dependencies == nil {
-		c.Dependencies = []model.Card{}
+		return err
 	}
-	c.Dependents, _ = s.store.GetDependents(ctx, id)
-	if c.Dependents == nil {
-		c.Dependents = []model.Card{}
+	dependents, err := s.store.GetDependents(ctx, c.ID)
+	if err != nil {
+		return err
 	}
-	c.Activity, _ = s.store.ListActivityByCard(ctx, id, 50)
-	if c.Activity == nil {
-		c.Activity = []model.ActivityLog{}
+	for _, d := range append(deps, dependents...) {
+		boardID := fromBoardID
+		if d.Board != nil {
+			boardID = d.Board.ID
+		}
+		if boardID != toBoardID {
+			return fmt.Errorf("cross-board dependencies are disabled: card %s is linked to card %s on another board", c.ID, d.ID)
+		}
 	}
-	return c, nil
+	return nil
 }
 
-func (s *Service) UpdateCard(ctx context.Context, id string, updates map[string]any, actor string) (*model.Card, error) {
-	c, err := s.store.GetCard(ctx, id)
+// AssignCard sets the card's assignee. Assigning an unassigned card moves
+// it to assigned, and unassigning an assigned card moves it back, when the
+// workflow allows it; like any status change, that must pass the target
+// status's guards and takes the card to the list bound to it.
+func (s *Service) AssignCard(ctx context.Context, cardID, assignee, actor string) (*model.Card, error) {
+	c, err := s.store.GetCard(ctx, cardID)
 	if err != nil {
 		return nil, err
 	}
-	if v, ok := updates["title"].(string); ok && v != "" {
-		c.Title = v
+	if c.DeletedAt != nil {
+		return nil, fmt.Errorf("card is in the trash: %s", cardID)
 	}
-	if v, ok := updates["description"].(string); ok {
-		c.Description = v
+	wf, boardID, err := s.workflowForList(ctx, c.ListID)
+	if err != nil {
+		return nil, err
 	}
-	if v, ok := updates["assignee"].(string); ok {
-		c.Assignee = v
+	if _, err := s.activeBoard(ctx, boardID); err != nil {
+		return nil, err
 	}
-	if v, ok := updates["status"].(string); ok {
-		if !model.ValidStatus(v) {
-			return nil, fmt.Errorf("invalid status: %s", v)
-		}
-		c.Status = v
+	before := *c
+	oldAssignee := c.Assignee
+	c.Assignee = assignee
+	status := c.Status
+	if assignee != "" && c.Status == model.StatusUnassigned && wf.CanTransition(c.Status, model.StatusAssigned) {
+		status = model.StatusAssigned
 	}
-	if v, ok := updates["priority"].(string); ok {
-		if !model.ValidPriority(v) {
-			return nil, fmt.Errorf("invalid priority: %s", v)
+	if assignee == "" && c.Status == model.StatusAssigned && wf.CanTransition(c.Status, model.StatusUnassigned) {
+		status = model.StatusUnassigned
+	}
+	toListID := c.ListID
+	if status != c.Status {
+		if err := s.checkTransition(ctx, wf, c, status); err != nil {
+			return nil, err
 		}
-		c.Priority = v
+		c.Status = status
+		current, err := s.store.GetList(ctx, c.ListID)
+		if err != nil {
+			return nil, err
+		}
+		if current.Status != status {
+			target, err := s.listForStatus(ctx, boardID, status)
+			if err != nil {
+				return nil, err
+			}
+			if target != nil {
+				toListID = target.ID
+			}
+		}
+	}
+	action := model.ActionAssigned
+	if assignee == "" {
+		action = model.ActionUnassigned
 	}
-	if err := s.store.UpdateCard(ctx, c); err != nil {
+	err = s.inTx(ctx, func(tx *Service) error {
+		if err := tx.store.UpdateCard(ctx, c); err != nil {
+			return err
+		}
+		detail := map[string]string{"from": oldAssignee, "to": assignee}
+		if toListID != c.ListID {
+			if err := tx.store.MoveCard(ctx, c.ID, toListID, model.Placement{}); err != nil {
+				return err
+			}
+			detail["from_list"], detail["to_list"] = c.ListID, toListID
+			c.ListID = toListID
+		}
+		if c.Status != before.Status {
+			detail["from_status"], detail["to_status"] = before.Status, c.Status
+		}
+		tx.logActivity(ctx, cardID, actor, action, detail)
+		tx.publish("card.updated", boardID, c)
+		return tx.audit(ctx, boardID, model.EntityCard, cardID, action, actor, &before, c)
+	})
+	if err != nil {
 		return nil, err
 	}
-	l, _ := s.store.GetList(ctx, c.ListID)
-	boardID := ""
-	if l != nil {
-		boardID = l.BoardID
+	if assignee != oldAssignee {
+		s.notifyAssigned(ctx, boardID, c, actor)
 	}
-	s.logActivity(ctx, c.ID, actor, model.ActionStatusChanged, updates)
-	s.publish("card.updated", boardID, c)
-	return s.GetCard(ctx, id)
+	return s.GetCard(ctx, cardID)
 }
 
-func (s *Service) MoveCard(ctx context.Context, cardID, targetListID string, position int, actor string) (*model.Card, error) {
-	c, err := s.store.GetCard(ctx, cardID)
+// setCardLifecycle applies one of the store's card lifecycle operations,
+// logs and audits it, and publishes the event.
+func (s *Service) setCardLifecycle(ctx context.Context, id, actor, action, typ string, op func(store.Store, context.Context, string) error) (*model.Card, error) {
+	c, err := s.store.GetCard(ctx, id)
 	if err != nil {
 		return nil, err
 	}
-	fromListID := c.ListID
-	if err := s.store.MoveCard(ctx, cardID, targetListID, position); err != nil {
+	l, err := s.store.GetList(ctx, c.ListID)
+	if err != nil {
 		return nil, err
 	}
-	l, _ := s.store.GetList(ctx, targetListID)
-	boardID := ""
-	if l != nil {
-		boardID = l.BoardID
-	}
-	s.logActivity(ctx, cardID, actor, model.ActionMoved, map[string]string{
-		"from_list": fromListID, "to_list": targetListID,
-	})
-	s.publish("card.moved", boardID, map[string]any{
-		"card_id": cardID, "from_list": fromListID, "to_list": targetListID, "position": position,
+	err = s.inTx(ctx, func(tx *Service) error {
+		if err := op(tx.store, ctx, id); err != nil {
+			return err
+		}
+		after, err := tx.store.GetCard(ctx, id)
+		if err != nil {
+			return err
+		}
+		tx.logActivity(ctx, id, actor, action, map[string]string{"list_id": c.ListID})
+		tx.publish(typ, l.BoardID, map[string]string{"id": id})
+		return tx.audit(ctx, l.BoardID, model.EntityCard, id, action, actor, c, after)
 	})
-	return s.GetCard(ctx, cardID)
+	if err != nil {
+		return nil, err
+	}
+	return s.GetCard(ctx, id)
 }
 
-func (s *Service) AssignCard(ctx context.Context, cardID, assignee, actor string) (*model.Card, error) {
-	c, err := s.store.GetCard(ctx, cardID)
+func (s *Service) ArchiveCard(ctx context.Context, id, actor string) (*model.Card, error) {
+	return s.setCardLifecycle(ctx, id, actor, model.ActionArchived, "card.archived", store.Store.ArchiveCard)
+}
+
+func (s *Service) UnarchiveCard(ctx context.Context, id, actor string) (*model.Card, error) {
+	return s.setCardLifecycle(ctx, id, actor, model.ActionUnarchived, "card.unarchived", store.Store.UnarchiveCard)
+}
+
+// DeleteCard moves the card to the trash. Its activity log is kept until
+// the card is purged.
+func (s *Service) DeleteCard(ctx context.Context, id, actor string) error {
+	_, err := s.setCardLifecycle(ctx, id, actor, model.ActionDeleted, "card.deleted", store.Store.DeleteCard)
+	return err
+}
+
+func (s *Service) RestoreCard(ctx context.Context, id, actor string) (*model.Card, error) {
+	c, err := s.store.GetCard(ctx, id)
 	if err != nil {
 		return nil, err
 	}
-	oldAssignee := c.Assignee
-	c.Assignee = assignee
-	if assignee != "" && c.Status == model.StatusUnassigned {
-		c.Status = model.StatusAssigned
-	}
-	if assignee == "" && c.Status == model.StatusAssigned {
-		c.Status = model.StatusUnassigned
-	}
-	if err := s.store.UpdateCard(ctx, c); err != nil {
+	l, err := s.store.GetList(ctx, c.ListID)
+	if err != nil {
 		return nil, err
 	}
-	l, _ := s.store.GetList(ctx, c.ListID)
-	boardID := ""
-	if l != nil {
-		boardID = l.BoardID
+	if l.DeletedAt != nil {
+		return nil, fmt.Errorf("list is deleted; restore it first: %s", l.ID)
 	}
-	action := model.ActionAssigned
-	if assignee == "" {
-		action = model.ActionUnassigned
+	return s.setCardLifecycle(ctx, id, actor, model.ActionRestored, "card.restored", store.Store.RestoreCard)
+}
+
+// SearchCards finds cards on the board. expr is a filter expression in the
+// language of package filter; query, assignee, status, and label are
+// shorthands ANDed with it, and empty ones are ignored. Boards in the trash
+// cannot be searched. The cursor of the next page, if there is one, is
+// returned alongside the results.
+func (s *Service) SearchCards(ctx context.Context, boardID, query, assignee, status, label, expr string, includeArchived bool, page model.Page) ([]model.Card, string, error) {
+	if _, err := s.activeBoard(ctx, boardID); err != nil {
+		return nil, "", err
+	}
+	q, err := filter.Parse(expr)
+	if err != nil {
+		return nil, "", err
 	}
-	s.logActivity(ctx, cardID, actor, action, map[string]string{
-		"from": oldAssignee, "to": assignee,
-	})
-	s.publish("card.updated", boardID, c)
-	return s.GetCard(ctx, cardID)
+	q.AddText(query)
+	for _, f := range [][2]string{{"assignee", assignee}, {"status", status}, {"label", label}} {
+		if f[1] != "" {
+			q.Add(f[0], f[1])
+		}
+	}
+	return s.store.SearchCards(ctx, boardID, q, includeArchived, page)
 }
 
-func (s *Service) DeleteCard(ctx context.Context, id, actor string) error {
-	c, err := s.store.GetCard(ctx, id)
+// --- Trash ---
+
+// ListTrash returns the board's deleted lists and individually deleted
+// cards.
+func (s *Service) ListTrash(ctx context.Context, boardID string) ([]model.List, []model.Card, error) {
+	return s.store.ListTrash(ctx, boardID)
+}
+
+// PurgeTrash permanently removes everything that has been in the trash for
+// longer than retention, along with the contents of its attachments.
+func (s *Service) PurgeTrash(ctx context.Context, retention time.Duration) (int64, error) {
+	cutoff := time.Now().Add(-retention)
+	var n int64
+	var blobs []string
+	err := s.inTx(ctx, func(tx *Service) error {
+		var err error
+		if n, blobs, err = tx.store.PurgeDeleted(ctx, cutoff); err != nil || n == 0 {
+			return err
+		}
+		return tx.audit(ctx, "", model.EntityTrash, "", model.ActionPurged, "system", nil, map[string]any{
+			"deleted_before": cutoff.UTC(), "count": n,
+		})
+	})
 	if err != nil {
-		return err
+		return 0, err
+	}
+	s.deleteBlobs(ctx, blobs)
+	return n, nil
+}
+
+// RunTrashPurger purges the trash every interval until ctx is cancelled.
+func (s *Service) RunTrashPurger(ctx context.Context, retention, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if n, err := s.PurgeTrash(ctx, retention); err != nil {
+			log.Printf("trash purge failed: %v", err)
+		} else if n > 0 {
+			log.Printf("purged %d items from trash", n)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
 	}
-	l, _ := s.store.GetList(ctx, c.ListID)
-	boardID := ""
-	if l != nil {
-		boardID = l.BoardID
+}
+
+// NotifyOverdue publishes a card.overdue event for each card that has come
+// due by now and has not been announced yet. It returns the number of
+// events published.
+func (s *Service) NotifyOverdue(ctx context.Context, now time.Time) (int, error) {
+	claimed, err := s.store.ClaimOverdueCards(ctx, now)
+	if err != nil {
+		return 0, err
 	}
-	if err := s.store.DeleteCard(ctx, id); err != nil {
-		return err
+	n := 0
+	for boardID, cards := range claimed {
+		for i := range cards {
+			s.publish("card.overdue", boardID, &cards[i])
+			n++
+		}
 	}
-	s.publish("card.deleted", boardID, map[string]string{"id": id})
-	return nil
+	return n, nil
 }
 
-func (s *Service) SearchCards(ctx context.Context, boardID, query, assignee, status, label string) ([]model.Card, error) {
-	return s.store.SearchCards(ctx, boardID, query, assignee, status, label)
+// RunOverdueNotifier checks for overdue cards every interval until ctx is
+// cancelled.
+func (s *Service) RunOverdueNotifier(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.NotifyOverdue(ctx, time.Now()); err != nil {
+			log.Printf("overdue check failed: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
 }
 
 // --- Dependencies ---
@@ -333,42 +1330,74 @@ func (s *Service) AddDependency(ctx context.Context, cardID, dependsOnCardID, ac
 	if cardID == dependsOnCardID {
 		return fmt.Errorf("card cannot depend on itself")
 	}
-	dep := &model.CardDependency{
-		ID: model.NewID(), CardID: cardID, DependsOnCardID: dependsOnCardID,
+	c, boardID, err := s.activeCard(ctx, cardID)
+	if err != nil {
+		return err
 	}
-	if err := s.store.AddDependency(ctx, dep); err != nil {
+	if _, _, err := s.activeCard(ctx, dependsOnCardID); err != nil {
 		return err
 	}
-	c, _ := s.store.GetCard(ctx, cardID)
-	l, _ := s.store.GetList(ctx, c.ListID)
-	boardID := ""
-	if l != nil {
-		boardID = l.BoardID
+	if s.noCrossBoardDeps {
+		if err := s.checkSameBoard(ctx, cardID, dependsOnCardID); err != nil {
+			return err
+		}
+	}
+	dep := &model.CardDependency{
+		ID: model.NewID(), CardID: cardID, DependsOnCardID: dependsOnCardID,
 	}
-	s.logActivity(ctx, cardID, actor, model.ActionDependencyAdded, map[string]string{
-		"depends_on": dependsOnCardID,
+	return s.inTx(ctx, func(tx *Service) error {
+		if err := tx.store.AddDependency(ctx, dep); err != nil {
+			return err
+		}
+		tx.logActivity(ctx, cardID, actor, model.ActionDependencyAdded, map[string]string{
+			"depends_on": dependsOnCardID,
+		})
+		tx.publish("card.updated", boardID, c)
+		return tx.audit(ctx, boardID, model.EntityCard, cardID, model.ActionDependencyAdded, actor, nil, map[string]string{
+			"depends_on": dependsOnCardID,
+		})
 	})
-	s.publish("card.updated", boardID, c)
-	return nil
 }
 
-func (s *Service) RemoveDependency(ctx context.Context, cardID, dependsOnCardID, actor string) error {
-	if err := s.store.RemoveDependency(ctx, cardID, dependsOnCardID); err != nil {
-		return err
+// checkSameBoard refuses a dependency between cards on different boards.
+func (s *Service) checkSameBoard(ctx context.Context, cardID, dependsOnCardID string) error {
+	var boards [2]string
+	for i, id := range []string{cardID, dependsOnCardID} {
+		c, err := s.store.GetCard(ctx, id)
+		if err != nil {
+			return err
+		}
+		if _, boards[i], err = s.workflowForList(ctx, c.ListID); err != nil {
+			return err
+		}
 	}
-	c, _ := s.store.GetCard(ctx, cardID)
-	l, _ := s.store.GetList(ctx, c.ListID)
-	boardID := ""
-	if l != nil {
-		boardID = l.BoardID
+	if boards[0] != boards[1] {
+		return fmt.Errorf("cross-board dependencies are disabled: cards %s and %s are on different boards", cardID, dependsOnCardID)
 	}
-	s.logActivity(ctx, cardID, actor, model.ActionDependencyRemoved, map[string]string{
-		"depends_on": dependsOnCardID,
-	})
-	s.publish("card.updated", boardID, c)
 	return nil
 }
 
+func (s *Service) RemoveDependency(ctx context.Context, cardID, dependsOnCardID, actor string) error {
+	return s.inTx(ctx, func(tx *Service) error {
+		if err := tx.store.RemoveDependency(ctx, cardID, dependsOnCardID); err != nil {
+			return err
+		}
+		c, _ := tx.store.GetCard(ctx, cardID)
+		l, _ := tx.store.GetList(ctx, c.ListID)
+		boardID := ""
+		if l != nil {
+			boardID = l.BoardID
+		}
+		tx.logActivity(ctx, cardID, actor, model.ActionDependencyRemoved, map[string]string{
+			"depends_on": dependsOnCardID,
+		})
+		tx.publish("card.updated", boardID, c)
+		return tx.audit(ctx, boardID, model.EntityCard, cardID, model.ActionDependencyRemoved, actor, nil, map[string]string{
+			"depends_on": dependsOnCardID,
+		})
+	})
+}
+
 func (s *Service) GetDependencies(ctx context.Context, cardID string) ([]model.Card, error) {
 	return s.store.GetDependencies(ctx, cardID)
 }
@@ -379,18 +1408,27 @@ func (s *Service) GetDependents(ctx context.Context, cardID string) ([]model.Car
 
 // --- Labels ---
 
-func (s *Service) CreateLabel(ctx context.Context, boardID, name, color string) (*model.Label, error) {
+func (s *Service) CreateLabel(ctx context.Context, boardID, name, color, actor string) (*model.Label, error) {
 	if name == "" {
 		return nil, fmt.Errorf("label name is required")
 	}
 	if color == "" {
 		color = "#6b7280"
 	}
+	if _, err := s.activeBoard(ctx, boardID); err != nil {
+		return nil, err
+	}
 	l := &model.Label{ID: model.NewID(), BoardID: boardID, Name: name, Color: color}
-	if err := s.store.CreateLabel(ctx, l); err != nil {
+	err := s.inTx(ctx, func(tx *Service) error {
+		if err := tx.store.CreateLabel(ctx, l); err != nil {
+			return err
+		}
+		tx.publish("label.created", boardID, l)
+		return tx.audit(ctx, boardID, model.EntityLabel, l.ID, model.ActionCreated, actor, nil, l)
+	})
+	if err != nil {
 		return nil, err
 	}
-	s.publish("label.created", boardID, l)
 	return l, nil
 }
 
@@ -398,87 +1436,92 @@ func (s *Service) ListLabelsByBoard(ctx context.Context, boardID string) ([]mode
 	return s.store.ListLabelsByBoard(ctx, boardID)
 }
 
-func (s *Service) UpdateLabel(ctx context.Context, id, name, color string) (*model.Label, error) {
+func (s *Service) UpdateLabel(ctx context.Context, id, name, color, actor string) (*model.Label, error) {
 	l, err := s.store.GetLabel(ctx, id)
 	if err != nil {
 		return nil, err
 	}
+	before := *l
 	if name != "" {
 		l.Name = name
 	}
 	if color != "" {
 		l.Color = color
 	}
-	if err := s.store.UpdateLabel(ctx, l); err != nil {
+	err = s.inTx(ctx, func(tx *Service) error {
+		if err := tx.store.UpdateLabel(ctx, l); err != nil {
+			return err
+		}
+		tx.publish("label.updated", l.BoardID, l)
+		return tx.audit(ctx, l.BoardID, model.EntityLabel, id, model.ActionUpdated, actor, &before, l)
+	})
+	if err != nil {
 		return nil, err
 	}
-	s.publish("label.updated", l.BoardID, l)
 	return l, nil
 }
 
-func (s *Service) DeleteLabel(ctx context.Context, id string) error {
+func (s *Service) DeleteLabel(ctx context.Context, id, actor string) error {
 	l, err := s.store.GetLabel(ctx, id)
 	if err != nil {
 		return err
 	}
-	if err := s.store.DeleteLabel(ctx, id); err != nil {
-		return err
-	}
-	s.publish("label.deleted", l.BoardID, map[string]string{"id": id})
-	return nil
+	return s.inTx(ctx, func(tx *Service) error {
+		if err := tx.store.DeleteLabel(ctx, id); err != nil {
+			return err
+		}
+		tx.publish("label.deleted", l.BoardID, map[string]string{"id": id})
+		return tx.audit(ctx, l.BoardID, model.EntityLabel, id, model.ActionDeleted, actor, l, nil)
+	})
 }
 
+// AddLabelToCard labels the card with one of its own board's labels.
 func (s *Service) AddLabelToCard(ctx context.Context, cardID, labelID, actor string) error {
-	if err := s.store.AddLabelToCard(ctx, cardID, labelID); err != nil {
+	_, boardID, err := s.activeCard(ctx, cardID)
+	if err != nil {
+		return err
+	}
+	label, err := s.store.GetLabel(ctx, labelID)
+	if err != nil {
 		return err
 	}
-	c, _ := s.store.GetCard(ctx, cardID)
-	l, _ := s.store.GetList(ctx, c.ListID)
-	boardID := ""
-	if l != nil {
-		boardID = l.BoardID
+	if label.BoardID != boardID {
+		return fmt.Errorf("label %s is not on the card's board", labelID)
 	}
-	s.logActivity(ctx, cardID, actor, model.ActionLabelAdded, map[string]string{"label_id": labelID})
-	s.publish("card.updated", boardID, c)
-	return nil
+	return s.inTx(ctx, func(tx *Service) error {
+		if err := tx.store.AddLabelToCard(ctx, cardID, labelID); err != nil {
+			return err
+		}
+		c, _ := tx.store.GetCard(ctx, cardID)
+		tx.logActivity(ctx, cardID, actor, model.ActionLabelAdded, map[string]string{"label_id": labelID})
+		tx.publish("card.updated", boardID, c)
+		return tx.audit(ctx, boardID, model.EntityCard, cardID, model.ActionLabelAdded, actor, nil, map[string]string{"label_id": labelID})
+	})
 }
 
 func (s *Service) RemoveLabelFromCard(ctx context.Context, cardID, labelID, actor string) error {
-	if err := s.store.RemoveLabelFromCard(ctx, cardID, labelID); err != nil {
-		return err
-	}
-	c, _ := s.store.GetCard(ctx, cardID)
-	l, _ := s.store.GetList(ctx, c.ListID)
-	boardID := ""
-	if l != nil {
-		boardID = l.BoardID
-	}
-	s.logActivity(ctx, cardID, actor, model.ActionLabelRemoved, map[string]string{"label_id": labelID})
-	s.publish("card.updated", boardID, c)
-	return nil
+	return s.inTx(ctx, func(tx *Service) error {
+		if err := tx.store.RemoveLabelFromCard(ctx, cardID, labelID); err != nil {
+			return err
+		}
+		c, _ := tx.store.GetCard(ctx, cardID)
+		l, _ := tx.store.GetList(ctx, c.ListID)
+		boardID := ""
+		if l != nil {
+			boardID = l.BoardID
+		}
+		tx.logActivity(ctx, cardID, actor, model.ActionLabelRemoved, map[string]string{"label_id": labelID})
+		tx.publish("card.updated", boardID, c)
+		return tx.audit(ctx, boardID, model.EntityCard, cardID, model.ActionLabelRemoved, actor, nil, map[string]string{"label_id": labelID})
+	})
 }
 
 // --- Activity ---
 
-func (s *Service) AddComment(ctx context.Context, cardID, actor, text string) error {
-	if text == "" {
-		return fmt.Errorf("comment text is required")
-	}
-	s.logActivity(ctx, cardID, actor, model.ActionComment, map[string]string{"text": text})
-	c, _ := s.store.GetCard(ctx, cardID)
-	l, _ := s.store.GetList(ctx, c.ListID)
-	boardID := ""
-	if l != nil {
-		boardID = l.BoardID
-	}
-	s.publish("activity.new", boardID, map[string]string{"card_id": cardID, "actor": actor})
-	return nil
-}
-
-func (s *Service) ListActivityByCard(ctx context.Context, cardID string, limit int) ([]model.ActivityLog, error) {
-	return s.store.ListActivityByCard(ctx, cardID, limit)
+func (s *Service) ListActivityByCard(ctx context.Context, cardID string, page model.Page) ([]model.ActivityLog, string, error) {
+	return s.store.ListActivityByCard(ctx, cardID, page)
 }
 
-func (s *Service) ListActivityByBoard(ctx context.Context, boardID string, limit int) ([]model.ActivityLog, error) {
-	return s.store.ListActivityByBoard(ctx, boardID, limit)
+func (s *Service) ListActivityByBoard(ctx context.Context, boardID string, page model.Page) ([]model.ActivityLog, string, error) {
+	return s.store.ListActivityByBoard(ctx, boardID, page)
 }
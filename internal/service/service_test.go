@@ -0,0 +1,1897 @@
+package service_test
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	"github.com/aellingwood/cielo/internal/blob"
+	"github.com/aellingwood/cielo/internal/event"
+	"github.com/aellingwood/cielo/internal/model"
+	"github.com/aellingwood/cielo/internal/service"
+	"github.com/aellingwood/cielo/internal/store"
+)
+
+func setupService(t *testing.T) *service.Service {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	if err := store.RunMigrations(db); err != nil {
+		t.Fatal(err)
+	}
+	return service.New(store.NewSQLiteStore(db), event.NewBus())
+}
+
+func TestWorkflowTransitions(t *testing.T) {
+	svc := setupService(t)
+	ctx := context.Background()
+
+	b, _ := svc.CreateBoard(ctx, "Board", "", "user")
+	l, _ := svc.CreateList(ctx, b.ID, "Todo", "", model.Placement{}, "user")
+	_, err := svc.SetWorkflow(ctx, b.ID, &model.Workflow{
+		Statuses: []string{"unassigned", "assigned", "in_progress", "review", "done"},
+		Transitions: map[string][]string{
+			"unassigned":  {"assigned"},
+			"assigned":    {"unassigned", "in_progress"},
+			"in_progress": {"review"},
+			"review":      {"in_progress", "done"},
+			"done":        {},
+		},
+		Guards: map[string][]string{"in_progress": {model.GuardHasAssignee}},
+	}, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := svc.CreateCard(ctx, l.ID, "Task", "", "", "", "", nil, "user", model.Placement{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.UpdateCard(ctx, c.ID, map[string]any{"status": "done"}, "user"); err == nil {
+		t.Error("expected unassigned -> done to be rejected")
+	}
+	if _, err := svc.UpdateCard(ctx, c.ID, map[string]any{"status": "bogus"}, "user"); err == nil {
+		t.Error("expected unknown status to be rejected")
+	}
+
+	c, err = svc.AssignCard(ctx, c.ID, "alice", "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Status != "assigned" {
+		t.Fatalf("expected assigned, got %s", c.Status)
+	}
+	for _, st := range []string{"in_progress", "review", "done"} {
+		if _, err := svc.UpdateCard(ctx, c.ID, map[string]any{"status": st}, "user"); err != nil {
+			t.Fatalf("transition to %s failed: %v", st, err)
+		}
+	}
+	if _, err := svc.UpdateCard(ctx, c.ID, map[string]any{"status": "review"}, "user"); err == nil {
+		t.Error("expected done to be terminal")
+	}
+}
+
+func TestWorkflowGuards(t *testing.T) {
+	svc := setupService(t)
+	ctx := context.Background()
+
+	b, _ := svc.CreateBoard(ctx, "Board", "", "user")
+	l, _ := svc.CreateList(ctx, b.ID, "Todo", "", model.Placement{}, "user")
+	wf := model.DefaultWorkflow()
+	wf.Guards = map[string][]string{
+		model.StatusInProgress: {model.GuardHasAssignee},
+		model.StatusDone:       {model.GuardDependenciesDone},
+	}
+	if _, err := svc.SetWorkflow(ctx, b.ID, wf, "user"); err != nil {
+		t.Fatal(err)
+	}
+
+	blocker, _ := svc.CreateCard(ctx, l.ID, "Blocker", "", "", "", "", nil, "user", model.Placement{})
+	c, _ := svc.CreateCard(ctx, l.ID, "Task", "", "", "", "", nil, "user", model.Placement{})
+	svc.AddDependency(ctx, c.ID, blocker.ID, "user")
+
+	if _, err := svc.UpdateCard(ctx, c.ID, map[string]any{"status": model.StatusInProgress}, "user"); err == nil {
+		t.Error("expected in_progress without assignee to be rejected")
+	}
+	if _, err := svc.UpdateCard(ctx, c.ID, map[string]any{"status": model.StatusDone}, "user"); err == nil {
+		t.Error("expected done with open dependency to be rejected")
+	}
+	if _, err := svc.UpdateCard(ctx, blocker.ID, map[string]any{"status": model.StatusDone}, "user"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.UpdateCard(ctx, c.ID, map[string]any{"status": model.StatusDone}, "user"); err != nil {
+		t.Errorf("expected done once dependencies are done: %v", err)
+	}
+}
+
+func TestSetWorkflowRejectsOrphanedStatuses(t *testing.T) {
+	svc := setupService(t)
+	ctx := context.Background()
+
+	b, _ := svc.CreateBoard(ctx, "Board", "", "user")
+	l, _ := svc.CreateList(ctx, b.ID, "Todo", "", model.Placement{}, "user")
+	svc.CreateCard(ctx, l.ID, "Task", "", "", "", "", nil, "user", model.Placement{})
+
+	if _, err := svc.SetWorkflow(ctx, b.ID, &model.Workflow{Statuses: []string{"todo", "done"}}, "user"); err == nil {
+		t.Error("expected workflow without unassigned to be rejected while cards use it")
+	}
+	if _, err := svc.SetWorkflow(ctx, b.ID, &model.Workflow{Statuses: []string{"Bad Status"}}, "user"); err == nil {
+		t.Error("expected invalid status name to be rejected")
+	}
+}
+
+func TestListStatusBinding(t *testing.T) {
+	svc := setupService(t)
+	ctx := context.Background()
+
+	b, _ := svc.CreateBoard(ctx, "Board", "", "user")
+	todo, _ := svc.CreateList(ctx, b.ID, "Todo", "", model.Placement{}, "user")
+	doing, err := svc.CreateList(ctx, b.ID, "Doing", model.StatusInProgress, model.Placement{}, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	done, _ := svc.CreateList(ctx, b.ID, "Done", model.StatusDone, model.Placement{}, "user")
+	if _, err := svc.CreateList(ctx, b.ID, "Bad", "bogus", model.Placement{}, "user"); err == nil {
+		t.Error("expected list bound to unknown status to be rejected")
+	}
+
+	c, _ := svc.CreateCard(ctx, todo.ID, "Task", "", "alice", "", "", nil, "user", model.Placement{})
+
+	c, err = svc.MoveCard(ctx, c.ID, doing.ID, model.CardMoveOptions{}, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Status != model.StatusInProgress {
+		t.Errorf("expected move to set status in_progress, got %s", c.Status)
+	}
+	moves := 0
+	for _, a := range c.Activity {
+		if a.Action == model.ActionMoved {
+			moves++
+		}
+	}
+	if moves != 1 || len(c.Activity) != 2 {
+		t.Errorf("expected a single moved entry after create, got %+v", c.Activity)
+	}
+
+	c, err = svc.UpdateCard(ctx, c.ID, map[string]any{"status": model.StatusDone}, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.ListID != done.ID {
+		t.Errorf("expected status change to move card to Done list, got %s", c.ListID)
+	}
+
+	// A card already in a list bound to its new status stays there, even
+	// if that list is not the first bound to it.
+	shipped, _ := svc.CreateList(ctx, b.ID, "Shipped", "", model.Placement{}, "user")
+	c2, _ := svc.CreateCard(ctx, doing.ID, "Other", "", "alice", "", "", nil, "user", model.Placement{})
+	if _, err := svc.MoveCard(ctx, c2.ID, shipped.ID, model.CardMoveOptions{}, "user"); err != nil {
+		t.Fatal(err)
+	}
+	done2 := model.StatusDone
+	if _, err := svc.UpdateList(ctx, shipped.ID, "", &done2, nil, "user"); err != nil {
+		t.Fatal(err)
+	}
+	if c2, err = svc.UpdateCard(ctx, c2.ID, map[string]any{"status": model.StatusDone}, "user"); err != nil {
+		t.Fatal(err)
+	}
+	if c2.ListID != shipped.ID {
+		t.Errorf("expected the card to stay in Shipped, got %s", c2.ListID)
+	}
+
+	if _, err := svc.SetWorkflow(ctx, b.ID, &model.Workflow{Statuses: []string{"unassigned", "assigned", "done"}}, "user"); err == nil {
+		t.Error("expected workflow dropping a bound status to be rejected")
+	}
+
+	created, err := svc.CreateCard(ctx, done.ID, "Already done", "", "", "", "", nil, "user", model.Placement{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.Status != model.StatusDone {
+		t.Errorf("expected a card created in Done to be done, got %s", created.Status)
+	}
+
+	// Assigning a card changes its status, which takes it to the list
+	// bound to that status.
+	assigned, _ := svc.CreateList(ctx, b.ID, "Assigned", model.StatusAssigned, model.Placement{}, "user")
+	c3, _ := svc.CreateCard(ctx, todo.ID, "Unowned", "", "", "", "", nil, "user", model.Placement{})
+	if c3, err = svc.AssignCard(ctx, c3.ID, "bob", "user"); err != nil {
+		t.Fatal(err)
+	}
+	if c3.Status != model.StatusAssigned || c3.ListID != assigned.ID {
+		t.Errorf("expected assigning to move the card to Assigned, got %s in %s", c3.Status, c3.ListID)
+	}
+}
+
+func TestMoveIntoBoundListEnforcesWorkflow(t *testing.T) {
+	svc := setupService(t)
+	ctx := context.Background()
+
+	b, _ := svc.CreateBoard(ctx, "Board", "", "user")
+	todo, _ := svc.CreateList(ctx, b.ID, "Todo", "", model.Placement{}, "user")
+	doing, _ := svc.CreateList(ctx, b.ID, "Doing", model.StatusInProgress, model.Placement{}, "user")
+	wf := model.DefaultWorkflow()
+	wf.Guards = map[string][]string{model.StatusInProgress: {model.GuardHasAssignee}}
+	svc.SetWorkflow(ctx, b.ID, wf, "user")
+
+	c, _ := svc.CreateCard(ctx, todo.ID, "Task", "", "", "", "", nil, "user", model.Placement{})
+	if _, err := svc.MoveCard(ctx, c.ID, doing.ID, model.CardMoveOptions{}, "user"); err == nil {
+		t.Error("expected move into guarded list to be rejected")
+	}
+	got, _ := svc.GetCard(ctx, c.ID)
+	if got.ListID != todo.ID {
+		t.Errorf("card should not have moved")
+	}
+	if _, err := svc.CreateCard(ctx, doing.ID, "Unowned", "", "", "", "", nil, "user", model.Placement{}); err == nil {
+		t.Error("expected creating an unassigned card in a guarded list to be rejected")
+	}
+}
+
+func TestBadPlacementWritesNothing(t *testing.T) {
+	svc := setupService(t)
+	ctx := context.Background()
+
+	b, _ := svc.CreateBoard(ctx, "Board", "", "user")
+	l, _ := svc.CreateList(ctx, b.ID, "Todo", "", model.Placement{}, "user")
+	if _, err := svc.CreateList(ctx, b.ID, "Stray", "", model.Placement{Before: "nope"}, "user"); err == nil {
+		t.Error("expected a list placed before an unknown list to be rejected")
+	}
+	if _, err := svc.CreateCard(ctx, l.ID, "Stray", "", "", "", "", nil, "user", model.Placement{Before: "nope"}); err == nil {
+		t.Error("expected a card placed before an unknown card to be rejected")
+	}
+	if _, err := svc.UpdateList(ctx, l.ID, "Renamed", nil, &model.Placement{After: "nope"}, "user"); err == nil {
+		t.Error("expected a list moved after an unknown list to be rejected")
+	}
+
+	lists, _ := svc.ListListsByBoard(ctx, b.ID, false, 0)
+	if len(lists) != 1 || lists[0].Name != "Todo" || len(lists[0].Cards) != 0 {
+		t.Errorf("expected the board to be unchanged, got %+v", lists)
+	}
+	audit, _ := svc.ListAudit(ctx, model.AuditFilter{BoardID: b.ID}, "user")
+	if len(audit) != 2 {
+		t.Errorf("expected only the board and list creation audited, got %d entries", len(audit))
+	}
+}
+
+func TestTrashRestoreOrder(t *testing.T) {
+	svc := setupService(t)
+	ctx := context.Background()
+
+	b, _ := svc.CreateBoard(ctx, "Board", "", "user")
+	l, _ := svc.CreateList(ctx, b.ID, "Todo", "", model.Placement{}, "user")
+	other, _ := svc.CreateList(ctx, b.ID, "Other", "", model.Placement{}, "user")
+	c, _ := svc.CreateCard(ctx, l.ID, "Task", "", "", "", "", nil, "user", model.Placement{})
+	d, _ := svc.CreateCard(ctx, other.ID, "Other task", "", "", "", "", nil, "user", model.Placement{})
+	bug, _ := svc.CreateLabel(ctx, b.ID, "bug", "", "user")
+	points, _ := svc.CreateCustomField(ctx, b.ID, "points", model.FieldNumber, nil, model.Placement{}, "user")
+
+	if err := svc.DeleteCard(ctx, c.ID, "user"); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.AddLabelToCard(ctx, c.ID, bug.ID, "user"); err == nil {
+		t.Error("expected labelling a deleted card to be rejected")
+	}
+	if err := svc.AddDependency(ctx, d.ID, c.ID, "user"); err == nil {
+		t.Error("expected a dependency on a deleted card to be rejected")
+	}
+	if _, err := svc.MoveCard(ctx, c.ID, other.ID, model.CardMoveOptions{}, "user"); err == nil {
+		t.Error("expected moving a deleted card to be rejected")
+	}
+	if _, err := svc.UpdateCard(ctx, c.ID, map[string]any{"title": "Edited"}, "user"); err == nil {
+		t.Error("expected updating a deleted card to be rejected")
+	}
+	if err := svc.DeleteList(ctx, l.ID, "user"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.RestoreCard(ctx, c.ID, "user"); err == nil {
+		t.Error("expected restoring a card in a deleted list to be rejected")
+	}
+	if _, err := svc.RestoreList(ctx, l.ID, "user"); err != nil {
+		t.Fatal(err)
+	}
+	c, err := svc.RestoreCard(ctx, c.ID, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.DeletedAt != nil {
+		t.Errorf("expected restored card, got %+v", c)
+	}
+	restored := false
+	for _, a := range c.Activity {
+		restored = restored || a.Action == model.ActionRestored
+	}
+	if !restored {
+		t.Errorf("expected restore activity, got %+v", c.Activity)
+	}
+
+	svc.ArchiveList(ctx, other.ID, "user")
+	if _, err := svc.CreateCard(ctx, other.ID, "New", "", "", "", "", nil, "user", model.Placement{}); err == nil {
+		t.Error("expected creating a card in an archived list to be rejected")
+	}
+
+	if err := svc.DeleteBoard(ctx, b.ID, "user"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.GetBoard(ctx, b.ID, false); err == nil {
+		t.Error("expected a deleted board to be hidden")
+	}
+	if _, err := svc.GetBoard(ctx, b.ID, true); err != nil {
+		t.Errorf("expected a deleted board with includeDeleted: %v", err)
+	}
+	if _, err := svc.CreateList(ctx, b.ID, "New", "", model.Placement{}, "user"); err == nil {
+		t.Error("expected creating a list on a deleted board to be rejected")
+	}
+	if _, err := svc.CreateCard(ctx, l.ID, "New", "", "", "", "", nil, "user", model.Placement{}); err == nil {
+		t.Error("expected creating a card on a deleted board to be rejected")
+	}
+	if _, err := svc.UpdateCard(ctx, c.ID, map[string]any{"title": "Edited"}, "user"); err == nil {
+		t.Error("expected updating a card on a deleted board to be rejected")
+	}
+	if err := svc.AddLabelToCard(ctx, c.ID, bug.ID, "user"); err == nil {
+		t.Error("expected labelling a card on a deleted board to be rejected")
+	}
+	if err := svc.AddDependency(ctx, c.ID, d.ID, "user"); err == nil {
+		t.Error("expected a dependency on a deleted board to be rejected")
+	}
+	name := "View"
+	if _, err := svc.CreateView(ctx, b.ID, model.ViewSpec{Name: &name}, "user"); err == nil {
+		t.Error("expected creating a view on a deleted board to be rejected")
+	}
+	if _, err := svc.CreateCustomField(ctx, b.ID, "team", model.FieldText, nil, model.Placement{}, "user"); err == nil {
+		t.Error("expected creating a field on a deleted board to be rejected")
+	}
+	if _, err := svc.UpdateCustomField(ctx, points.ID, model.CustomFieldSpec{Name: &name}, "user"); err == nil {
+		t.Error("expected updating a field on a deleted board to be rejected")
+	}
+	if err := svc.DeleteCustomField(ctx, points.ID, "user"); err == nil {
+		t.Error("expected deleting a field on a deleted board to be rejected")
+	}
+	if _, _, err := svc.SearchCards(ctx, b.ID, "", "", "", "", "", false, model.Page{}); err == nil {
+		t.Error("expected searching a deleted board to be rejected")
+	}
+	if _, err := svc.RestoreBoard(ctx, b.ID, "user"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.CreateCard(ctx, l.ID, "New", "", "", "", "", nil, "user", model.Placement{}); err != nil {
+		t.Errorf("expected a restored board to accept cards: %v", err)
+	}
+}
+
+func TestAuditSurvivesPurge(t *testing.T) {
+	svc := setupService(t)
+	ctx := service.WithSource(context.Background(), "rest:req-1")
+
+	b, _ := svc.CreateBoard(ctx, "Board", "", "user")
+	l, _ := svc.CreateList(ctx, b.ID, "Todo", "", model.Placement{}, "user")
+	c, _ := svc.CreateCard(ctx, l.ID, "Task", "", "", "", "", nil, "agent", model.Placement{})
+	svc.CreateLabel(ctx, b.ID, "bug", "", "user")
+	if err := svc.DeleteCard(ctx, c.ID, "agent"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.PurgeTrash(ctx, -time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.GetCard(ctx, c.ID); err == nil {
+		t.Fatal("expected card to be purged")
+	}
+
+	entries, err := svc.ListAudit(ctx, model.AuditFilter{BoardID: b.ID, EntityID: c.ID}, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected create and delete entries for the card, got %+v", entries)
+	}
+	del := entries[0]
+	if del.Action != model.ActionDeleted || del.Actor != "agent" || del.Source != "rest:req-1" {
+		t.Errorf("unexpected delete entry: %+v", del)
+	}
+	if len(del.Before) == 0 || len(del.After) == 0 {
+		t.Errorf("expected before and after snapshots, got %+v", del)
+	}
+	if entries[1].Action != model.ActionCreated || entries[1].Before != nil {
+		t.Errorf("unexpected create entry: %+v", entries[1])
+	}
+
+	labels, _ := svc.ListAudit(ctx, model.AuditFilter{BoardID: b.ID, EntityType: model.EntityLabel}, "user")
+	if len(labels) != 1 {
+		t.Errorf("expected 1 label entry, got %d", len(labels))
+	}
+	byUser, _ := svc.ListAudit(ctx, model.AuditFilter{BoardID: b.ID, Actor: "user"}, "user")
+	if len(byUser) != 3 {
+		t.Errorf("expected 3 entries by user, got %d", len(byUser))
+	}
+	purges, _ := svc.ListAudit(ctx, model.AuditFilter{EntityType: model.EntityTrash}, "user")
+	if len(purges) != 1 || purges[0].Actor != "system" {
+		t.Errorf("expected one purge entry by system, got %+v", purges)
+	}
+}
+
+func TestUnauditedChangesAreUndone(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	defer db.Close()
+	if err := store.RunMigrations(db); err != nil {
+		t.Fatal(err)
+	}
+	svc := service.New(store.NewSQLiteStore(db), event.NewBus())
+	ctx := context.Background()
+
+	b, _ := svc.CreateBoard(ctx, "Board", "", "user")
+	l, _ := svc.CreateList(ctx, b.ID, "Todo", "", model.Placement{}, "user")
+	if _, err := db.Exec(`CREATE TRIGGER refuse_audit BEFORE INSERT ON audit_log BEGIN SELECT RAISE(ABORT, 'audit unavailable'); END`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.CreateCard(ctx, l.ID, "Task", "", "", "", "", nil, "user", model.Placement{}); err == nil {
+		t.Error("expected a card that cannot be audited to be rejected")
+	}
+	if _, err := svc.UpdateList(ctx, l.ID, "Renamed", nil, nil, "user"); err == nil {
+		t.Error("expected a list update that cannot be audited to be rejected")
+	}
+	lists, _ := svc.ListListsByBoard(ctx, b.ID, false, 0)
+	if len(lists) != 1 || lists[0].Name != "Todo" || len(lists[0].Cards) != 0 {
+		t.Errorf("expected the board to be unchanged, got %+v", lists)
+	}
+}
+
+func TestSavedViews(t *testing.T) {
+	svc := setupService(t)
+	ctx := context.Background()
+	str := func(s string) *string { return &s }
+	yes := true
+
+	b, _ := svc.CreateBoard(ctx, "Board", "", "user")
+	l, _ := svc.CreateList(ctx, b.ID, "Todo", "", model.Placement{}, "user")
+	svc.CreateCard(ctx, l.ID, "Low", "", "alice", "low", "", nil, "user", model.Placement{})
+	svc.CreateCard(ctx, l.ID, "Critical", "", "alice", "critical", "", nil, "user", model.Placement{})
+	svc.CreateCard(ctx, l.ID, "Unassigned", "", "", "critical", "", nil, "user", model.Placement{})
+
+	shared, err := svc.CreateView(ctx, b.ID, model.ViewSpec{
+		Name: str("Alice's work"), Filter: str("assignee:alice"), Sort: str("-priority"),
+		Columns: &[]string{"title", "priority"},
+	}, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	private, err := svc.CreateView(ctx, b.ID, model.ViewSpec{Name: str("Unassigned"), Filter: str("assignee:none"), Private: &yes}, "bot")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cards, _, err := svc.RunView(ctx, shared.ID, "bot", false, model.Page{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cards) != 2 || cards[0].Title != "Critical" || cards[1].Title != "Low" {
+		t.Errorf("shared view returned %+v", cards)
+	}
+
+	if views, _ := svc.ListViews(ctx, b.ID, "user"); len(views) != 1 {
+		t.Errorf("user sees %d views, want 1", len(views))
+	}
+	if views, _ := svc.ListViews(ctx, b.ID, "bot"); len(views) != 2 {
+		t.Errorf("bot sees %d views, want 2", len(views))
+	}
+	if _, _, err := svc.RunView(ctx, private.ID, "user", false, model.Page{}); err == nil {
+		t.Error("expected private view to be hidden from other actors")
+	}
+	if err := svc.DeleteView(ctx, private.ID, "user"); err == nil {
+		t.Error("expected other actors not to delete a private view")
+	}
+	if _, err := svc.UpdateView(ctx, shared.ID, model.ViewSpec{Private: &yes}, "bot"); err == nil {
+		t.Error("expected only the creator to make a shared view private")
+	}
+	if views, _ := svc.ListViews(ctx, b.ID, "user"); len(views) != 1 {
+		t.Errorf("expected the shared view to stay shared, user sees %d views", len(views))
+	}
+
+	for _, spec := range []model.ViewSpec{
+		{Filter: str("colour:red")},
+		{Sort: str("colour")},
+		{Sort: str("priority due")},
+		{Columns: &[]string{"colour"}},
+		{Name: str(" ")},
+	} {
+		if _, err := svc.UpdateView(ctx, shared.ID, spec, "user"); err == nil {
+			t.Errorf("expected %+v to be rejected", spec)
+		}
+	}
+	v, err := svc.UpdateView(ctx, shared.ID, model.ViewSpec{Filter: str("priority:low")}, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Name != "Alice's work" || v.Sort != "-priority" || len(v.Columns) != 2 {
+		t.Errorf("update changed omitted fields: %+v", v)
+	}
+
+	entries, _ := svc.ListAudit(ctx, model.AuditFilter{BoardID: b.ID, EntityType: model.EntityView}, "user")
+	for _, e := range entries {
+		if e.EntityID == private.ID {
+			t.Errorf("expected the private view to stay off the board's audit trail, got %+v", e)
+		}
+	}
+	if len(entries) != 2 {
+		t.Errorf("expected the shared view's create and update audited, got %d entries", len(entries))
+	}
+	if others, _ := svc.ListAudit(ctx, model.AuditFilter{EntityID: private.ID}, "user"); len(others) != 0 {
+		t.Errorf("expected the private view's audit hidden from other actors, got %+v", others)
+	}
+	if own, _ := svc.ListAudit(ctx, model.AuditFilter{EntityType: model.EntityView}, "bot"); len(own) != 3 {
+		t.Errorf("expected the owner to see the private view's audit, got %d entries", len(own))
+	}
+}
+
+func TestBoardSummary(t *testing.T) {
+	svc := setupService(t)
+	ctx := context.Background()
+
+	b, _ := svc.CreateBoard(ctx, "Board", "", "user")
+	todo, _ := svc.CreateList(ctx, b.ID, "Todo", "", model.Placement{}, "user")
+	doing, _ := svc.CreateList(ctx, b.ID, "Doing", "", model.Placement{}, "user")
+	for _, title := range []string{"One", "Two", "Three"} {
+		svc.CreateCard(ctx, todo.ID, title, "A long description", "", "high", "", nil, "user", model.Placement{})
+	}
+	c, _ := svc.CreateCard(ctx, doing.ID, "Four", "", "alice", "", "", nil, "user", model.Placement{})
+
+	sum, err := svc.SummarizeBoard(ctx, b.ID, false, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum.Total != 4 || sum.ByPriority["high"] != 3 || sum.ByStatus["assigned"] != 1 || len(sum.Lists) != 2 {
+		t.Errorf("unexpected summary: %+v", sum)
+	}
+	if sum.Lists[0].Total != 3 || sum.Lists[0].Cards != nil || sum.ETag == "" {
+		t.Errorf("unexpected todo summary: %+v", sum.Lists[0])
+	}
+
+	again, _ := svc.SummarizeBoard(ctx, b.ID, false, 0)
+	if again.ETag != sum.ETag {
+		t.Error("expected the ETag to be stable while the board is unchanged")
+	}
+
+	withCards, err := svc.SummarizeBoard(ctx, b.ID, true, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	todoSum := withCards.Lists[0]
+	if len(todoSum.Cards) != 2 || todoSum.Cards[0].Title != "One" || todoSum.CardsCursor == "" {
+		t.Errorf("unexpected headlines: %+v", todoSum)
+	}
+	if withCards.ETag == sum.ETag {
+		t.Error("expected headlines to change the ETag")
+	}
+
+	svc.UpdateCard(ctx, c.ID, map[string]any{"status": "blocked"}, "user")
+	changed, _ := svc.SummarizeBoard(ctx, b.ID, false, 0)
+	if changed.ETag == sum.ETag || changed.Blocked != 1 {
+		t.Errorf("expected a blocked card and a new ETag, got %+v", changed)
+	}
+}
+
+func TestDueDates(t *testing.T) {
+	svc := setupService(t)
+	ctx := context.Background()
+
+	b, _ := svc.CreateBoard(ctx, "Board", "", "user")
+	l, _ := svc.CreateList(ctx, b.ID, "Todo", "", model.Placement{}, "user")
+	past := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	late, err := svc.CreateCard(ctx, l.ID, "Late", "", "", "", "", &past, "user", model.Placement{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	soon, _ := svc.CreateCard(ctx, l.ID, "Soon", "", "", "", "", nil, "user", model.Placement{})
+	svc.CreateCard(ctx, l.ID, "Whenever", "", "", "", "", nil, "user", model.Placement{})
+
+	due := time.Now().Add(36 * time.Hour).UTC().Format(time.RFC3339)
+	c, err := svc.UpdateCard(ctx, soon.ID, map[string]any{"due_date": due}, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.DueDate == nil || c.DueDate.Format(time.RFC3339) != due {
+		t.Fatalf("expected due date %s, got %v", due, c.DueDate)
+	}
+	if len(c.Activity) == 0 || c.Activity[0].Action != model.ActionDueDateChanged {
+		t.Errorf("expected a due_date_changed entry, got %+v", c.Activity)
+	}
+	for _, a := range c.Activity {
+		if a.Action == model.ActionStatusChanged {
+			t.Error("a due-date-only update should not log a status change")
+		}
+	}
+	if _, err := svc.UpdateCard(ctx, soon.ID, map[string]any{"due_date": "next tuesday"}, "user"); err == nil {
+		t.Error("expected an invalid due date to be rejected")
+	}
+
+	for expr, want := range map[string]string{"overdue:true": "Late", "due_within:2d": "Soon"} {
+		cards, _, err := svc.SearchCards(ctx, b.ID, "", "", "", "", expr, false, model.Page{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(cards) != 1 || cards[0].Title != want {
+			t.Errorf("%s: expected [%s], got %+v", expr, want, cards)
+		}
+	}
+
+	if n, err := svc.NotifyOverdue(ctx, time.Now()); err != nil || n != 1 {
+		t.Fatalf("expected one overdue card, got %d (%v)", n, err)
+	}
+	if n, _ := svc.NotifyOverdue(ctx, time.Now()); n != 0 {
+		t.Errorf("expected an overdue card to be announced once, got %d", n)
+	}
+	if n, _ := svc.NotifyOverdue(ctx, time.Now().Add(48*time.Hour)); n != 1 {
+		t.Errorf("expected the card coming due to be announced, got %d", n)
+	}
+
+	c, err = svc.UpdateCard(ctx, late.ID, map[string]any{"due_date": nil}, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.DueDate != nil {
+		t.Errorf("expected the due date to be cleared, got %v", c.DueDate)
+	}
+}
+
+func TestChecklists(t *testing.T) {
+	svc := setupService(t)
+	ctx := context.Background()
+
+	b, _ := svc.CreateBoard(ctx, "Board", "", "user")
+	l, _ := svc.CreateList(ctx, b.ID, "Todo", "", model.Placement{}, "user")
+	c, _ := svc.CreateCard(ctx, l.ID, "Task", "", "", "", "", nil, "user", model.Placement{})
+	if _, err := svc.SetWorkflow(ctx, b.ID, &model.Workflow{
+		Statuses: []string{"unassigned", "assigned", "in_progress", "blocked", "done"},
+		Guards:   map[string][]string{"done": {model.GuardChecklistComplete}},
+	}, "user"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := svc.AddChecklistItem(ctx, c.ID, "  ", "", model.Placement{}, "agent"); err == nil {
+		t.Error("expected an empty item to be rejected")
+	}
+	write, err := svc.AddChecklistItem(ctx, c.ID, "Write it", "alice", model.Placement{}, "agent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	test, _ := svc.AddChecklistItem(ctx, c.ID, "Test it", "", model.Placement{}, "agent")
+
+	if _, err := svc.UpdateCard(ctx, c.ID, map[string]any{"status": "done"}, "user"); err == nil {
+		t.Error("expected open checklist items to block done")
+	}
+
+	done := true
+	for _, id := range []string{write.ID, test.ID} {
+		it, err := svc.UpdateChecklistItem(ctx, id, model.ChecklistItemSpec{Done: &done}, "agent")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !it.Done || it.CompletedAt == nil {
+			t.Errorf("expected %s to be completed, got %+v", it.Text, it)
+		}
+	}
+	card, err := svc.UpdateCard(ctx, c.ID, map[string]any{"status": "done"}, "user")
+	if err != nil {
+		t.Fatalf("expected a complete checklist to allow done: %v", err)
+	}
+	if card.ChecklistDone != 2 || card.ChecklistTotal != 2 || len(card.Checklist) != 2 {
+		t.Errorf("unexpected card checklist: %d/%d %+v", card.ChecklistDone, card.ChecklistTotal, card.Checklist)
+	}
+
+	open := false
+	it, err := svc.UpdateChecklistItem(ctx, test.ID, model.ChecklistItemSpec{Done: &open}, "agent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if it.Done || it.CompletedAt != nil {
+		t.Errorf("expected a reopened item, got %+v", it)
+	}
+	if _, err := svc.UpdateChecklistItem(ctx, test.ID, model.ChecklistItemSpec{Done: &done, Placement: model.Placement{Before: "nope"}}, "agent"); err == nil {
+		t.Error("expected a bad placement to be rejected")
+	}
+	if items, _ := svc.ListChecklist(ctx, c.ID); items[1].Done {
+		t.Error("expected a rejected update to leave the item open")
+	}
+
+	acts, _, _ := svc.ListActivityByCard(ctx, c.ID, model.Page{})
+	counts := map[string]int{}
+	for _, a := range acts {
+		counts[a.Action]++
+	}
+	if counts[model.ActionChecklistAdded] != 2 || counts[model.ActionChecklistDone] != 2 || counts[model.ActionChecklistReopened] != 1 {
+		t.Errorf("unexpected checklist activity: %v", counts)
+	}
+
+	if err := svc.DeleteChecklistItem(ctx, write.ID, "agent"); err != nil {
+		t.Fatal(err)
+	}
+	items, _ := svc.ListChecklist(ctx, c.ID)
+	if len(items) != 1 || items[0].ID != test.ID || items[0].Position != 0 {
+		t.Errorf("unexpected checklist after delete: %+v", items)
+	}
+}
+
+func TestCardHierarchy(t *testing.T) {
+	svc := setupService(t)
+	ctx := context.Background()
+
+	b, _ := svc.CreateBoard(ctx, "Board", "", "user")
+	todo, _ := svc.CreateList(ctx, b.ID, "Todo", "", model.Placement{}, "user")
+	doing, _ := svc.CreateList(ctx, b.ID, "Doing", "", model.Placement{}, "user")
+	epic, _ := svc.CreateCard(ctx, todo.ID, "Epic", "", "", "", "", nil, "user", model.Placement{})
+	a, err := svc.CreateCard(ctx, todo.ID, "A", "", "", "", epic.ID, nil, "user", model.Placement{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bc, _ := svc.CreateCard(ctx, doing.ID, "B", "", "", "", "", nil, "user", model.Placement{})
+	if _, err := svc.UpdateCard(ctx, bc.ID, map[string]any{"parent_id": epic.ID}, "user"); err != nil {
+		t.Fatal(err)
+	}
+	sub, _ := svc.CreateCard(ctx, doing.ID, "B.1", "", "", "", bc.ID, nil, "user", model.Placement{})
+	if _, err := svc.CreateCard(ctx, todo.ID, "Orphan", "", "", "", "missing", nil, "user", model.Placement{}); err == nil {
+		t.Error("expected an unknown parent to be rejected")
+	}
+	if _, err := svc.UpdateCard(ctx, epic.ID, map[string]any{"parent_id": sub.ID}, "user"); err == nil {
+		t.Error("expected a cycle to be rejected")
+	}
+	if _, err := svc.UpdateCard(ctx, epic.ID, map[string]any{"parent_id": epic.ID}, "user"); err == nil {
+		t.Error("expected a card to be rejected as its own parent")
+	}
+
+	children, err := svc.ListChildren(ctx, epic.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(children) != 2 || children[0].ID != a.ID || children[1].ID != bc.ID {
+		t.Errorf("unexpected children: %+v", children)
+	}
+	card, _ := svc.GetCard(ctx, bc.ID)
+	if card.Parent == nil || card.Parent.ID != epic.ID || len(card.Children) != 1 || card.Children[0].ID != sub.ID {
+		t.Errorf("unexpected family: parent %+v children %+v", card.Parent, card.Children)
+	}
+
+	wf := model.DefaultWorkflow()
+	wf.CompleteParents = true
+	if _, err := svc.SetWorkflow(ctx, b.ID, wf, "user"); err != nil {
+		t.Fatal(err)
+	}
+	svc.UpdateCard(ctx, a.ID, map[string]any{"status": "done"}, "agent")
+	r, err := svc.GetRollup(ctx, epic.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Children != 2 || r.Total != 3 || r.Done != 1 || r.Percent != 33 {
+		t.Errorf("unexpected rollup: %+v", r)
+	}
+	if e, _ := svc.GetCard(ctx, epic.ID); e.Status == "done" {
+		t.Error("expected the epic to stay open while children are open")
+	}
+
+	// Finishing the last grandchild completes B, which completes the epic.
+	svc.UpdateCard(ctx, sub.ID, map[string]any{"status": "done"}, "agent")
+	for _, id := range []string{bc.ID, epic.ID} {
+		if c, _ := svc.GetCard(ctx, id); c.Status != "done" {
+			t.Errorf("expected %s to be completed, got %s", c.Title, c.Status)
+		}
+	}
+
+	if _, err := svc.UpdateCard(ctx, a.ID, map[string]any{"parent_id": nil, "fields": map[string]any{"nope": 1}}, "user"); err == nil {
+		t.Error("expected an unknown field to be rejected")
+	}
+	if c, _ := svc.GetCard(ctx, a.ID); c.ParentID != epic.ID {
+		t.Errorf("expected a rejected update to leave the parent alone, got %q", c.ParentID)
+	}
+	if _, err := svc.UpdateCard(ctx, a.ID, map[string]any{"parent_id": nil}, "user"); err != nil {
+		t.Fatal(err)
+	}
+	if r, _ := svc.GetRollup(ctx, epic.ID); r.Children != 1 || r.Total != 2 {
+		t.Errorf("expected detached card to leave the rollup: %+v", r)
+	}
+
+	ob, _ := svc.CreateBoard(ctx, "Other", "", "user")
+	ol, _ := svc.CreateList(ctx, ob.ID, "Todo", "", model.Placement{}, "user")
+	if _, err := svc.CreateCard(ctx, ol.ID, "Stray", "", "", "", epic.ID, nil, "user", model.Placement{}); err == nil {
+		t.Error("expected a parent on another board to be rejected")
+	}
+	if _, err := svc.UpdateCard(ctx, a.ID, map[string]any{"parent_id": epic.ID}, "user"); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{a.ID, epic.ID} {
+		if _, err := svc.MoveCard(ctx, id, ol.ID, model.CardMoveOptions{}, "user"); err == nil {
+			t.Error("expected moving a card away from its family to be rejected")
+		}
+	}
+}
+
+func TestCustomFields(t *testing.T) {
+	svc := setupService(t)
+	ctx := context.Background()
+
+	b, _ := svc.CreateBoard(ctx, "Board", "", "user")
+	l, _ := svc.CreateList(ctx, b.ID, "Todo", "", model.Placement{}, "user")
+	c, _ := svc.CreateCard(ctx, l.ID, "Task", "", "", "", "", nil, "user", model.Placement{})
+
+	for _, f := range []struct{ name, typ string }{{"Points", model.FieldNumber}, {"points", "money"}, {"model", model.FieldEnum}} {
+		if _, err := svc.CreateCustomField(ctx, b.ID, f.name, f.typ, nil, model.Placement{}, "user"); err == nil {
+			t.Errorf("expected field %s (%s) to be rejected", f.name, f.typ)
+		}
+	}
+	if _, err := svc.CreateCustomField(ctx, b.ID, "points", model.FieldNumber, []string{"a"}, model.Placement{}, "user"); err == nil {
+		t.Error("expected options on a number field to be rejected")
+	}
+	points, err := svc.CreateCustomField(ctx, b.ID, "points", model.FieldNumber, nil, model.Placement{}, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.CreateCustomField(ctx, b.ID, "points", model.FieldText, nil, model.Placement{}, "user"); err == nil {
+		t.Error("expected a duplicate name to be rejected")
+	}
+	tier, _ := svc.CreateCustomField(ctx, b.ID, "tier", model.FieldEnum, []string{"small", "large"}, model.Placement{}, "user")
+	svc.CreateCustomField(ctx, b.ID, "pr_url", model.FieldURL, nil, model.Placement{}, "user")
+	svc.CreateCustomField(ctx, b.ID, "shipped", model.FieldDate, nil, model.Placement{}, "user")
+
+	for _, fields := range []map[string]any{
+		{"points": "lots"},
+		{"points": true},
+		{"tier": "medium"},
+		{"pr_url": "ftp://example.com/x"},
+		{"pr_url": "example.com"},
+		{"shipped": "next week"},
+		{"colour": "red"},
+	} {
+		if _, err := svc.UpdateCard(ctx, c.ID, map[string]any{"fields": fields}, "agent"); err == nil {
+			t.Errorf("expected %v to be rejected", fields)
+		}
+	}
+	if _, err := svc.UpdateCard(ctx, c.ID, map[string]any{"fields": "points=3"}, "agent"); err == nil {
+		t.Error("expected fields that are not an object to be rejected")
+	}
+
+	card, err := svc.UpdateCard(ctx, c.ID, map[string]any{"fields": map[string]any{
+		"points": 3.0, "tier": "large", "pr_url": "https://example.com/pull/7", "shipped": "2026-02-01",
+	}}, "agent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	shipped, _ := card.Fields["shipped"].(time.Time)
+	if card.Fields["points"] != 3.0 || card.Fields["tier"] != "large" || !shipped.Equal(time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected fields: %v", card.Fields)
+	}
+	card, err = svc.UpdateCard(ctx, c.ID, map[string]any{"fields": map[string]any{"points": "5", "pr_url": nil, "tier": "large"}}, "agent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := card.Fields["pr_url"]; ok || card.Fields["points"] != 5.0 {
+		t.Errorf("unexpected fields after update: %v", card.Fields)
+	}
+
+	acts, _, _ := svc.ListActivityByCard(ctx, c.ID, model.Page{})
+	changes := 0
+	for _, a := range acts {
+		if a.Action == model.ActionFieldChanged {
+			changes++
+		}
+		if a.Action == model.ActionStatusChanged {
+			t.Errorf("expected field updates to be logged only as field changes: %s", a.Detail)
+		}
+	}
+	// Four values set, then points and pr_url changed; tier was unchanged.
+	if changes != 6 {
+		t.Errorf("expected 6 field changes, got %d", changes)
+	}
+
+	cards, _, err := svc.SearchCards(ctx, b.ID, "", "", "", "", "field.points>=4 field.tier:large", false, model.Page{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cards) != 1 {
+		t.Errorf("expected the card to match, got %d cards", len(cards))
+	}
+
+	name := "estimate_points"
+	options := []string{"small"}
+	if _, err := svc.UpdateCustomField(ctx, points.ID, model.CustomFieldSpec{Options: &options}, "user"); err == nil {
+		t.Error("expected options on a number field to be rejected")
+	}
+	stray := "stray"
+	if _, err := svc.UpdateCustomField(ctx, points.ID, model.CustomFieldSpec{Name: &stray, Placement: model.Placement{After: "nope"}}, "user"); err == nil {
+		t.Error("expected a bad placement to be rejected")
+	}
+	if fields, _ := svc.ListCustomFields(ctx, b.ID); slices.ContainsFunc(fields, func(f model.CustomField) bool { return f.Name == stray }) {
+		t.Error("expected a rejected update to leave the field's name")
+	}
+	if _, err := svc.UpdateCustomField(ctx, points.ID, model.CustomFieldSpec{Name: &name}, "user"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.UpdateCustomField(ctx, tier.ID, model.CustomFieldSpec{Options: &options}, "user"); err != nil {
+		t.Fatal(err)
+	}
+	card, _ = svc.GetCard(ctx, c.ID)
+	if card.Fields["estimate_points"] != 5.0 || card.Fields["tier"] != "large" {
+		t.Errorf("expected values to follow the rename and survive removed options: %v", card.Fields)
+	}
+
+	if err := svc.DeleteCustomField(ctx, tier.ID, "user"); err != nil {
+		t.Fatal(err)
+	}
+	fields, _ := svc.ListCustomFields(ctx, b.ID)
+	if len(fields) != 3 || fields[0].Name != "estimate_points" {
+		t.Errorf("unexpected fields after delete: %+v", fields)
+	}
+}
+
+func TestAttachments(t *testing.T) {
+	svc := setupService(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+	blobs, err := blob.NewFSStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, _ := svc.CreateBoard(ctx, "Board", "", "user")
+	l, _ := svc.CreateList(ctx, b.ID, "Todo", "", model.Placement{}, "user")
+	c, _ := svc.CreateCard(ctx, l.ID, "Task", "", "", "", "", nil, "user", model.Placement{})
+
+	if _, err := svc.AddAttachment(ctx, c.ID, "build.log", "", strings.NewReader("ok"), "agent"); err == nil {
+		t.Error("expected attachments to be refused without a blob store")
+	}
+	svc.SetBlobStore(blobs, 16)
+
+	log, err := svc.AddAttachment(ctx, c.ID, "../../logs/build.log", "", strings.NewReader("all tests pass\n"), "agent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if log.Name != "build.log" || log.Size != 15 || log.UploadedBy != "agent" ||
+		log.SHA256 != "901b372e59bae8f79265c298e74a23fb626c6f699f8ee38239cf8b9354102c84" {
+		t.Errorf("unexpected attachment: %+v", log)
+	}
+	if !strings.HasPrefix(log.MimeType, "text/") {
+		t.Errorf("expected a text MIME type, got %q", log.MimeType)
+	}
+	png, err := svc.AddAttachment(ctx, c.ID, "shot", "", strings.NewReader("\x89PNG\r\n\x1a\n"), "agent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if png.MimeType != "image/png" {
+		t.Errorf("expected the type to be sniffed, got %q", png.MimeType)
+	}
+	if _, err := svc.AddAttachment(ctx, c.ID, "big.bin", "", strings.NewReader(strings.Repeat("x", 17)), "agent"); err == nil {
+		t.Error("expected an oversized upload to be refused")
+	}
+	if _, err := svc.AddAttachment(ctx, c.ID, "exact.bin", "", strings.NewReader(strings.Repeat("x", 16)), "agent"); err != nil {
+		t.Errorf("expected an upload at the limit to be accepted: %v", err)
+	}
+	if _, err := svc.AddAttachmentURL(ctx, c.ID, "", "file:///etc/passwd", "agent"); err == nil {
+		t.Error("expected a non-http URL to be refused")
+	}
+	ref, err := svc.AddAttachmentURL(ctx, c.ID, "", "https://ci.example.com/runs/42/report.html", "agent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref.Name != "report.html" || ref.MimeType != "text/html; charset=utf-8" {
+		t.Errorf("unexpected reference: %+v", ref)
+	}
+
+	a, rc, err := svc.OpenAttachment(ctx, log.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(rc)
+	rc.Close()
+	if string(data) != "all tests pass\n" || a.ID != log.ID {
+		t.Errorf("unexpected contents %q", data)
+	}
+	if _, rc, err := svc.OpenAttachment(ctx, ref.ID); err != nil || rc != nil {
+		t.Errorf("expected a reference to have no contents, got %v, %v", rc, err)
+	}
+
+	card, _ := svc.GetCard(ctx, c.ID)
+	if len(card.Attachments) != 4 {
+		t.Errorf("expected 4 attachments on the card, got %d", len(card.Attachments))
+	}
+	if err := svc.DeleteAttachment(ctx, png.ID, "agent"); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 2 {
+		t.Errorf("expected the deleted and oversized contents to be gone, got %d files", len(entries))
+	}
+
+	svc.DeleteCard(ctx, c.ID, "user")
+	if _, err := svc.PurgeTrash(ctx, -time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("expected purging the card to remove its attachments, got %d files", len(entries))
+	}
+}
+
+func TestComments(t *testing.T) {
+	svc := setupService(t)
+	ctx := context.Background()
+
+	b, _ := svc.CreateBoard(ctx, "Board", "", "user")
+	l, _ := svc.CreateList(ctx, b.ID, "Todo", "", model.Placement{}, "user")
+	c, _ := svc.CreateCard(ctx, l.ID, "Task", "", "", "", "", nil, "user", model.Placement{})
+	other, _ := svc.CreateCard(ctx, l.ID, "Other", "", "", "", "", nil, "user", model.Placement{})
+
+	if _, err := svc.AddComment(ctx, c.ID, "", "   ", "agent"); err == nil {
+		t.Error("expected an empty comment to be refused")
+	}
+	root, err := svc.AddComment(ctx, c.ID, "", "Ready for review, @bob and @carol-ops. Ping @bob again; mail bob@example.com, not `@nobody`", "agent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(root.Mentions, ",") != "bob,carol-ops" || root.Author != "agent" {
+		t.Errorf("unexpected comment: %+v", root)
+	}
+	reply, err := svc.AddComment(ctx, c.ID, root.ID, "Looks good", "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply.ParentID != root.ID {
+		t.Errorf("expected a reply, got %+v", reply)
+	}
+	if _, err := svc.AddComment(ctx, other.ID, root.ID, "Wrong card", "user"); err == nil {
+		t.Error("expected a reply across cards to be refused")
+	}
+
+	edited, err := svc.EditComment(ctx, root.ID, "Ready for review, @dave", "agent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if edited.EditedAt == nil || len(edited.Mentions) != 1 || edited.Mentions[0] != "dave" {
+		t.Errorf("unexpected edited comment: %+v", edited)
+	}
+
+	if err := svc.DeleteComment(ctx, root.ID, "agent"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.EditComment(ctx, root.ID, "Back", "agent"); err == nil {
+		t.Error("expected editing a deleted comment to be refused")
+	}
+	if _, err := svc.AddComment(ctx, c.ID, root.ID, "Too late", "user"); err == nil {
+		t.Error("expected a reply to a deleted comment to be refused")
+	}
+	comments, _, err := svc.ListComments(ctx, c.ID, model.Page{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(comments) != 2 || comments[0].DeletedAt == nil || comments[0].Body != "" || comments[1].ParentID != root.ID {
+		t.Errorf("expected the deleted comment to keep its place above its reply, got %+v", comments)
+	}
+
+	acts, _, _ := svc.ListActivityByCard(ctx, c.ID, model.Page{})
+	var actions []string
+	for _, a := range acts {
+		if strings.HasPrefix(a.Action, "comment") {
+			actions = append(actions, a.Action)
+		}
+	}
+	if strings.Join(actions, ",") != "comment_deleted,comment_edited,comment,comment" {
+		t.Errorf("unexpected comment activity: %v", actions)
+	}
+
+	cards, _, _ := svc.SearchCards(ctx, b.ID, "good", "", "", "", "", false, model.Page{})
+	if len(cards) != 1 || cards[0].ID != c.ID {
+		t.Errorf("expected comments to be searchable, got %+v", cards)
+	}
+	if cards, _, _ := svc.SearchCards(ctx, b.ID, "review", "", "", "", "", false, model.Page{}); len(cards) != 0 {
+		t.Errorf("expected deleted comments to leave the index, got %+v", cards)
+	}
+}
+
+func TestNotifications(t *testing.T) {
+	svc := setupService(t)
+	ctx := context.Background()
+
+	b, _ := svc.CreateBoard(ctx, "Board", "", "user")
+	l, _ := svc.CreateList(ctx, b.ID, "Todo", "", model.Placement{}, "user")
+	blocker, _ := svc.CreateCard(ctx, l.ID, "Design", "", "architect", "", "", nil, "user", model.Placement{})
+	task, _ := svc.CreateCard(ctx, l.ID, "Build", "", "", "", "", nil, "user", model.Placement{})
+	if err := svc.AddDependency(ctx, task.ID, blocker.ID, "user"); err != nil {
+		t.Fatal(err)
+	}
+
+	inbox := func(recipient string) []model.Notification {
+		t.Helper()
+		items, _, err := svc.ListNotifications(ctx, recipient, true, model.Page{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return items
+	}
+	types := func(items []model.Notification) string {
+		var out []string
+		for _, n := range items {
+			out = append(out, n.Type)
+		}
+		return strings.Join(out, ",")
+	}
+
+	if got := types(inbox("architect")); got != "assigned" {
+		t.Errorf("expected creating an assigned card to notify the assignee, got %q", got)
+	}
+	svc.AssignCard(ctx, task.ID, "coder", "user")
+	svc.AssignCard(ctx, task.ID, "coder", "user")
+	if got := types(inbox("coder")); got != "assigned" {
+		t.Errorf("expected one assignment notification, got %q", got)
+	}
+
+	comment, err := svc.AddComment(ctx, task.ID, "", "@researcher please look, and @user too", "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mentions := inbox("researcher")
+	if len(mentions) != 1 || mentions[0].Type != model.NotifyMention || mentions[0].CommentID != comment.ID ||
+		mentions[0].CardID != task.ID || mentions[0].BoardID != b.ID || mentions[0].Actor != "user" {
+		t.Errorf("unexpected mention notifications: %+v", mentions)
+	}
+	if len(inbox("user")) != 0 {
+		t.Error("expected actors not to be notified about their own mentions")
+	}
+	svc.EditComment(ctx, comment.ID, "@researcher please look, and @reviewer", "user")
+	if len(inbox("researcher")) != 1 || len(inbox("reviewer")) != 1 {
+		t.Error("expected an edit to notify only the names it adds")
+	}
+
+	svc.AckNotifications(ctx, "architect", nil)
+	svc.AckNotifications(ctx, "coder", nil)
+	if _, err := svc.UpdateCard(ctx, blocker.ID, map[string]any{"status": model.StatusInProgress}, "user"); err != nil {
+		t.Fatal(err)
+	}
+	if got := types(inbox("architect")); got != "status_changed" {
+		t.Errorf("expected the assignee to hear about the status change, got %q", got)
+	}
+	if _, err := svc.UpdateCard(ctx, blocker.ID, map[string]any{"status": model.StatusDone}, "architect"); err != nil {
+		t.Fatal(err)
+	}
+	if got := types(inbox("architect")); got != "status_changed" {
+		t.Errorf("expected no notification for the assignee's own change, got %q", got)
+	}
+	unblocked := inbox("coder")
+	if len(unblocked) != 1 || unblocked[0].Type != model.NotifyUnblocked || unblocked[0].CardID != task.ID {
+		t.Errorf("expected the dependent's assignee to be told it is unblocked, got %+v", unblocked)
+	}
+}
+
+func TestWatchers(t *testing.T) {
+	svc := setupService(t)
+	ctx := context.Background()
+
+	b, _ := svc.CreateBoard(ctx, "Board", "", "user")
+	todo, _ := svc.CreateList(ctx, b.ID, "Todo", "", model.Placement{}, "user")
+	doing, _ := svc.CreateList(ctx, b.ID, "Doing", "", model.Placement{}, "user")
+	c, _ := svc.CreateCard(ctx, todo.ID, "Task", "", "coder", "", "", nil, "user", model.Placement{})
+
+	if _, err := svc.WatchCard(ctx, c.ID, "", "user"); err == nil {
+		t.Error("expected a watcher to be required")
+	}
+	svc.WatchCard(ctx, c.ID, "supervisor", "supervisor")
+	watchers, err := svc.WatchCard(ctx, c.ID, "supervisor", "supervisor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc.WatchCard(ctx, c.ID, "pm", "pm")
+	if card, _ := svc.GetCard(ctx, c.ID); len(watchers) != 1 || strings.Join(card.Watchers, ",") != "supervisor,pm" {
+		t.Errorf("unexpected watchers: %v, %v", watchers, card.Watchers)
+	}
+	svc.AckNotifications(ctx, "coder", nil)
+
+	inbox := func(recipient string) string {
+		t.Helper()
+		items, _, err := svc.ListNotifications(ctx, recipient, true, model.Page{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var types []string
+		for _, n := range items {
+			types = append(types, n.Type)
+		}
+		svc.AckNotifications(ctx, recipient, nil)
+		return strings.Join(types, ",")
+	}
+
+	svc.UpdateCard(ctx, c.ID, map[string]any{"priority": model.PriorityHigh}, "pm")
+	if got := inbox("supervisor"); got != "card_updated" {
+		t.Errorf("expected watchers to hear about updates, got %q", got)
+	}
+	if got := inbox("pm"); got != "" {
+		t.Errorf("expected no notification for the watcher's own change, got %q", got)
+	}
+	if got := inbox("coder"); got != "card_updated" {
+		t.Errorf("expected the assignee to hear about updates, got %q", got)
+	}
+	svc.UpdateCard(ctx, c.ID, map[string]any{"priority": model.PriorityHigh}, "pm")
+	if got := inbox("supervisor"); got != "" {
+		t.Errorf("expected no notification for an update that changes nothing, got %q", got)
+	}
+
+	svc.MoveCard(ctx, c.ID, doing.ID, model.CardMoveOptions{}, "user")
+	if got := inbox("supervisor"); got != "card_moved" {
+		t.Errorf("expected watchers to hear about moves, got %q", got)
+	}
+	inbox("pm")
+	svc.AddComment(ctx, c.ID, "", "Any news, @pm?", "user")
+	if got := inbox("supervisor"); got != "commented" {
+		t.Errorf("expected watchers to hear about comments, got %q", got)
+	}
+	if got := inbox("pm"); got != "mention" {
+		t.Errorf("expected a mentioned watcher to be notified once, got %q", got)
+	}
+
+	svc.AssignCard(ctx, c.ID, "reviewer", "user")
+	inbox("supervisor")
+	if got := inbox("reviewer"); got != "assigned" {
+		t.Errorf("expected the new assignee to be told once, got %q", got)
+	}
+
+	if _, err := svc.UnwatchCard(ctx, c.ID, "supervisor", "user"); err != nil {
+		t.Fatal(err)
+	}
+	svc.UpdateCard(ctx, c.ID, map[string]any{"title": "Renamed"}, "user")
+	if got := inbox("supervisor"); got != "" {
+		t.Errorf("expected no notifications after unwatching, got %q", got)
+	}
+	if got := inbox("pm"); got != "card_updated" {
+		t.Errorf("expected the remaining watcher to be notified, got %q", got)
+	}
+
+	entries, _ := svc.ListAudit(ctx, model.AuditFilter{EntityID: c.ID}, "user")
+	watches := map[string]int{}
+	for _, e := range entries {
+		watches[e.Action]++
+	}
+	if watches[model.ActionWatched] != 3 || watches[model.ActionUnwatched] != 1 {
+		t.Errorf("expected watching and unwatching to be audited, got %v", watches)
+	}
+}
+
+func TestTemplates(t *testing.T) {
+	svc := setupService(t)
+	ctx := context.Background()
+	str := func(s string) *string { return &s }
+
+	if _, err := svc.CreateTemplate(ctx, model.TemplateSpec{Name: str("Empty")}, "user"); err == nil {
+		t.Error("expected a template without a board or card to be rejected")
+	}
+	bad := &model.BoardTemplate{
+		Lists: []model.ListTemplate{{Name: "Todo"}},
+		Cards: []model.BoardCardTemplate{{List: "Doing", CardTemplate: model.CardTemplate{Title: "Kickoff"}}},
+	}
+	if _, err := svc.CreateTemplate(ctx, model.TemplateSpec{Name: str("Bad"), Board: bad}, "user"); err == nil {
+		t.Error("expected a card in an unknown list to be rejected")
+	}
+
+	bt, err := svc.CreateTemplate(ctx, model.TemplateSpec{Name: str("{{project}} launch"), Board: &model.BoardTemplate{
+		Description: "Launch plan for {{project}}",
+		Workflow:    &model.Workflow{Statuses: []string{"todo", "doing", "done"}},
+		Lists:       []model.ListTemplate{{Name: "Backlog", Status: "todo"}, {Name: "Doing", Status: "doing"}, {Name: "Shipped", Status: "done"}},
+		Labels:      []model.LabelTemplate{{Name: "launch", Color: "#ef4444"}},
+		Cards: []model.BoardCardTemplate{{List: "Backlog", CardTemplate: model.CardTemplate{
+			Title: "Announce {{project}}", Priority: model.PriorityHigh, Labels: []string{"launch"},
+			Checklist: []string{"Draft post for {{ project }}", "Review with {{owner}}"},
+		}}},
+	}}, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(bt.Variables, ",") != "project,owner" {
+		t.Errorf("expected the template's variables in order of appearance, got %v", bt.Variables)
+	}
+	if _, err := svc.CreateBoardFromTemplate(ctx, bt.ID, "", map[string]string{"project": "Apollo"}, "user"); err == nil || !strings.Contains(err.Error(), "owner") {
+		t.Errorf("expected the missing variable to be named, got %v", err)
+	}
+	boards, _, _ := svc.ListBoards(ctx, model.Page{})
+	if len(boards) != 0 {
+		t.Fatalf("expected no board from a failed instantiation, got %d", len(boards))
+	}
+
+	b, err := svc.CreateBoardFromTemplate(ctx, bt.ID, "", map[string]string{"project": "Apollo", "owner": "pm"}, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Name != "Apollo launch" || b.Description != "Launch plan for Apollo" || len(b.Workflow.Statuses) != 3 {
+		t.Errorf("unexpected board: %+v", b)
+	}
+	lists, _ := svc.ListListsByBoard(ctx, b.ID, false, 10)
+	if len(lists) != 3 || lists[2].Status != "done" || len(lists[0].Cards) != 1 {
+		t.Fatalf("unexpected lists: %+v", lists)
+	}
+	card, _ := svc.GetCard(ctx, lists[0].Cards[0].ID)
+	if card.Title != "Announce Apollo" || card.Status != "todo" || card.Priority != model.PriorityHigh ||
+		len(card.Labels) != 1 || len(card.Checklist) != 2 || card.Checklist[1].Text != "Review with pm" {
+		t.Errorf("unexpected card: %+v", card)
+	}
+
+	ct, err := svc.CreateTemplate(ctx, model.TemplateSpec{Name: str("Bug in {{area}}"), Card: &model.CardTemplate{
+		Title: "Bug: {{summary}}", Labels: []string{"launch", "bug"}, Checklist: []string{"Reproduce", "Fix"},
+	}}, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(ct.Variables, ",") != "summary" {
+		t.Errorf("expected only the card's own variables, got %v", ct.Variables)
+	}
+	if _, err := svc.CreateBoardFromTemplate(ctx, ct.ID, "", nil, "user"); err == nil {
+		t.Error("expected a card template to be refused for a board")
+	}
+	vars := map[string]string{"summary": "login fails"}
+	if _, err := svc.CreateCardFromTemplate(ctx, ct.ID, lists[1].ID, vars, "", model.Placement{Before: "missing"}, "user"); err == nil {
+		t.Fatal("expected a bad placement to fail")
+	}
+	if labels, _ := svc.ListLabelsByBoard(ctx, b.ID); len(labels) != 1 {
+		t.Errorf("expected the failed card's new label to be rolled back, got %v", labels)
+	}
+	c, err := svc.CreateCardFromTemplate(ctx, ct.ID, lists[1].ID, vars, "coder", model.Placement{}, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Title != "Bug: login fails" || c.Assignee != "coder" || len(c.Labels) != 2 || len(c.Checklist) != 2 {
+		t.Errorf("unexpected card: %+v", c)
+	}
+	if labels, _ := svc.ListLabelsByBoard(ctx, b.ID); len(labels) != 2 {
+		t.Errorf("expected the missing label to be created, got %v", labels)
+	}
+
+	renamed, err := svc.UpdateTemplate(ctx, ct.ID, model.TemplateSpec{Name: str("Bug report")}, "user")
+	if err != nil || renamed.Name != "Bug report" || renamed.Card.Title != "Bug: {{summary}}" {
+		t.Errorf("expected a rename to keep the card, got %+v, %v", renamed, err)
+	}
+	if _, err := svc.UpdateTemplate(ctx, ct.ID, model.TemplateSpec{Board: bt.Board}, "user"); err == nil {
+		t.Error("expected changing a template's kind to fail")
+	}
+	if err := svc.DeleteTemplate(ctx, ct.ID, "user"); err != nil {
+		t.Fatal(err)
+	}
+	if templates, _ := svc.ListTemplates(ctx, ""); len(templates) != 1 {
+		t.Errorf("expected one template left, got %d", len(templates))
+	}
+}
+
+func TestCloneBoard(t *testing.T) {
+	svc := setupService(t)
+	ctx := context.Background()
+
+	b, _ := svc.CreateBoard(ctx, "Plan", "The plan", "user")
+	svc.SetWorkflow(ctx, b.ID, &model.Workflow{Statuses: []string{"todo", "doing", "done"}}, "user")
+	todo, _ := svc.CreateList(ctx, b.ID, "Todo", "todo", model.Placement{}, "user")
+	old, _ := svc.CreateList(ctx, b.ID, "Old", "", model.Placement{}, "user")
+	svc.ArchiveList(ctx, old.ID, "user")
+	done, _ := svc.CreateList(ctx, b.ID, "Done", "done", model.Placement{}, "user")
+	bug, _ := svc.CreateLabel(ctx, b.ID, "bug", "#ef4444", "user")
+	svc.CreateCustomField(ctx, b.ID, "points", model.FieldNumber, nil, model.Placement{}, "user")
+
+	epic, _ := svc.CreateCard(ctx, todo.ID, "Epic", "", "", "", "", nil, "user", model.Placement{})
+	task, _ := svc.CreateCard(ctx, todo.ID, "Task", "details", "coder", model.PriorityHigh, epic.ID, nil, "user", model.Placement{})
+	blocker, _ := svc.CreateCard(ctx, done.ID, "Blocker", "", "", "", "", nil, "user", model.Placement{})
+	svc.AddDependency(ctx, task.ID, blocker.ID, "user")
+	svc.AddLabelToCard(ctx, task.ID, bug.ID, "user")
+	svc.UpdateCard(ctx, task.ID, map[string]any{"fields": map[string]any{"points": 3.0}}, "user")
+	svc.AddChecklistItem(ctx, task.ID, "Write it", "", model.Placement{}, "user")
+	svc.AddComment(ctx, task.ID, "", "First thoughts", "pm")
+	shelved, _ := svc.CreateCard(ctx, todo.ID, "Shelved", "", "", "", "", nil, "user", model.Placement{})
+	svc.ArchiveCard(ctx, shelved.ID, "user")
+
+	if _, err := svc.CloneBoard(ctx, b.ID, model.BoardCloneOptions{Dependencies: true}, "user"); err == nil {
+		t.Error("expected dependencies without cards to be rejected")
+	}
+	bare, err := svc.CloneBoard(ctx, b.ID, model.BoardCloneOptions{}, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bare.Name != "Plan (copy)" || len(bare.Workflow.Statuses) != 3 {
+		t.Errorf("unexpected copy: %+v", bare)
+	}
+	lists, _ := svc.ListListsByBoard(ctx, bare.ID, true, 10)
+	if len(lists) != 3 || lists[0].Name != "Todo" || lists[1].Name != "Done" || lists[2].ArchivedAt == nil || len(lists[0].Cards) != 0 {
+		t.Fatalf("expected the lists without cards, archived last, got %+v", lists)
+	}
+
+	full, err := svc.CloneBoard(ctx, b.ID, model.BoardCloneOptions{Name: "Variant", Cards: true, Labels: true, Dependencies: true, Activity: true}, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lists, _ = svc.ListListsByBoard(ctx, full.ID, false, 10)
+	if len(lists) != 2 || len(lists[0].Cards) != 2 || len(lists[1].Cards) != 1 {
+		t.Fatalf("unexpected cards: %+v", lists)
+	}
+	copied, _ := svc.GetCard(ctx, lists[0].Cards[1].ID)
+	if copied.ID == task.ID || copied.Title != "Task" || copied.Status != task.Status || copied.Assignee != "coder" {
+		t.Errorf("unexpected copy of the task: %+v", copied)
+	}
+	if copied.Parent == nil || copied.Parent.ID != lists[0].Cards[0].ID {
+		t.Errorf("expected the parent link to point at the copied epic, got %+v", copied.Parent)
+	}
+	if len(copied.Dependencies) != 1 || copied.Dependencies[0].ID != lists[1].Cards[0].ID {
+		t.Errorf("expected the dependency to point at the copied blocker, got %+v", copied.Dependencies)
+	}
+	if len(copied.Labels) != 1 || copied.Labels[0].ID == bug.ID || copied.Labels[0].BoardID != full.ID {
+		t.Errorf("expected the copy to carry the copied label, got %+v", copied.Labels)
+	}
+	if copied.Fields["points"] != 3.0 || len(copied.Checklist) != 1 {
+		t.Errorf("expected field values and checklist to be copied, got %v, %v", copied.Fields, copied.Checklist)
+	}
+	comments, _, _ := svc.ListComments(ctx, copied.ID, model.Page{})
+	original, _, _ := svc.ListComments(ctx, task.ID, model.Page{})
+	if len(comments) != 1 || comments[0].Author != "pm" || !comments[0].CreatedAt.Equal(original[0].CreatedAt) {
+		t.Errorf("expected the comment to keep its author and time, got %+v", comments)
+	}
+	if all, _ := svc.ListListsByBoard(ctx, full.ID, true, 10); len(all[0].Cards) != 3 || all[0].Cards[2].ArchivedAt == nil {
+		t.Errorf("expected the archived card to be copied archived, got %+v", all[0].Cards)
+	}
+	if orig, _ := svc.GetCard(ctx, task.ID); len(orig.Dependents) != 0 || len(orig.Dependencies) != 1 {
+		t.Errorf("expected the original to be untouched, got %+v", orig)
+	}
+}
+
+func TestCloneCard(t *testing.T) {
+	svc := setupService(t)
+	ctx := context.Background()
+
+	b, _ := svc.CreateBoard(ctx, "Board", "", "user")
+	todo, _ := svc.CreateList(ctx, b.ID, "Todo", "", model.Placement{}, "user")
+	bug, _ := svc.CreateLabel(ctx, b.ID, "bug", "#ef4444", "user")
+	ui, _ := svc.CreateLabel(ctx, b.ID, "ui", "#3b82f6", "user")
+	blocker, _ := svc.CreateCard(ctx, todo.ID, "Blocker", "", "", "", "", nil, "user", model.Placement{})
+	c, _ := svc.CreateCard(ctx, todo.ID, "Fix login", "steps", "", model.PriorityCritical, "", nil, "user", model.Placement{})
+	svc.AddLabelToCard(ctx, c.ID, bug.ID, "user")
+	svc.AddLabelToCard(ctx, c.ID, ui.ID, "user")
+	svc.AddDependency(ctx, c.ID, blocker.ID, "user")
+	svc.AddChecklistItem(ctx, c.ID, "Reproduce", "", model.Placement{}, "user")
+
+	same, err := svc.CloneCard(ctx, c.ID, todo.ID, model.CardCloneOptions{Dependencies: true, Placement: model.Placement{Before: c.ID}}, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if same.ID == c.ID || same.Title != "Fix login" || same.Position != 1 || len(same.Labels) != 2 ||
+		len(same.Checklist) != 1 || len(same.Dependencies) != 1 || same.Dependencies[0].ID != blocker.ID {
+		t.Errorf("unexpected copy on the same board: %+v", same)
+	}
+
+	other, _ := svc.CreateBoard(ctx, "Other", "", "user")
+	inbox, _ := svc.CreateList(ctx, other.ID, "Inbox", "", model.Placement{}, "user")
+	otherBug, _ := svc.CreateLabel(ctx, other.ID, "defect", "", "user")
+	if _, err := svc.CloneCard(ctx, c.ID, inbox.ID, model.CardCloneOptions{LabelMap: map[string]string{bug.ID: ui.ID}}, "user"); err == nil {
+		t.Error("expected mapping to a label of another board to fail")
+	}
+	moved, err := svc.CloneCard(ctx, c.ID, inbox.ID, model.CardCloneOptions{
+		Title: "Fix login (other)", LabelMap: map[string]string{bug.ID: otherBug.ID}, Dependencies: true,
+	}, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, l := range moved.Labels {
+		if l.BoardID != other.ID {
+			t.Errorf("expected only labels of the target board, got %+v", l)
+		}
+		names = append(names, l.Name)
+	}
+	slices.Sort(names)
+	if moved.Title != "Fix login (other)" || strings.Join(names, ",") != "defect,ui" || len(moved.Dependencies) != 0 {
+		t.Errorf("unexpected copy on another board: %+v", moved)
+	}
+
+	guarded, _ := svc.CreateBoard(ctx, "Guarded", "", "user")
+	svc.SetWorkflow(ctx, guarded.ID, &model.Workflow{
+		Statuses: []string{"open", "done"},
+		Guards:   map[string][]string{"done": {model.GuardHasAssignee}},
+	}, "user")
+	done, _ := svc.CreateList(ctx, guarded.ID, "Done", "done", model.Placement{}, "user")
+	if _, err := svc.CloneCard(ctx, c.ID, done.ID, model.CardCloneOptions{}, "user"); err == nil {
+		t.Error("expected a copy into a guarded status to be rejected")
+	}
+	svc.DeleteBoard(ctx, other.ID, "user")
+	if _, err := svc.CloneCard(ctx, c.ID, inbox.ID, model.CardCloneOptions{}, "user"); err == nil {
+		t.Error("expected a copy onto a deleted board to be rejected")
+	}
+}
+
+func TestMoveCardAcrossBoards(t *testing.T) {
+	svc := setupService(t)
+	ctx := context.Background()
+
+	b, _ := svc.CreateBoard(ctx, "Board", "", "user")
+	todo, _ := svc.CreateList(ctx, b.ID, "Todo", "", model.Placement{}, "user")
+	bug, _ := svc.CreateLabel(ctx, b.ID, "bug", "#ef4444", "user")
+	ui, _ := svc.CreateLabel(ctx, b.ID, "ui", "#3b82f6", "user")
+	svc.CreateCustomField(ctx, b.ID, "points", model.FieldNumber, nil, model.Placement{}, "user")
+	svc.CreateCustomField(ctx, b.ID, "team", model.FieldText, nil, model.Placement{}, "user")
+	c, _ := svc.CreateCard(ctx, todo.ID, "Fix login", "", "", "", "", nil, "user", model.Placement{})
+	svc.AddLabelToCard(ctx, c.ID, bug.ID, "user")
+	svc.AddLabelToCard(ctx, c.ID, ui.ID, "user")
+	if _, err := svc.UpdateCard(ctx, c.ID, map[string]any{"fields": map[string]any{"points": 3.0, "team": "web"}}, "user"); err != nil {
+		t.Fatal(err)
+	}
+
+	other, _ := svc.CreateBoard(ctx, "Other", "", "user")
+	svc.SetWorkflow(ctx, other.ID, &model.Workflow{Statuses: []string{"open", "closed"}}, "user")
+	inbox, _ := svc.CreateList(ctx, other.ID, "Inbox", "", model.Placement{}, "user")
+	otherBug, _ := svc.CreateLabel(ctx, other.ID, "bug", "", "user")
+	svc.CreateCustomField(ctx, other.ID, "points", model.FieldNumber, nil, model.Placement{}, "user")
+
+	if err := svc.AddLabelToCard(ctx, c.ID, otherBug.ID, "user"); err == nil {
+		t.Error("expected a label from another board to be rejected")
+	}
+
+	if _, err := svc.MoveCard(ctx, c.ID, inbox.ID, model.CardMoveOptions{Labels: "keep"}, "user"); err == nil {
+		t.Error("expected an unknown label policy to be rejected")
+	}
+	moved, err := svc.MoveCard(ctx, c.ID, inbox.ID, model.CardMoveOptions{Labels: model.LabelsMatch}, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if moved.ListID != inbox.ID || moved.Status != "open" || len(moved.Labels) != 1 || moved.Labels[0].ID != otherBug.ID {
+		t.Errorf("unexpected card after move: %+v", moved)
+	}
+	if len(moved.Fields) != 1 || moved.Fields["points"] != 3.0 {
+		t.Errorf("expected only the points value to follow the card, got %v", moved.Fields)
+	}
+	for _, boardID := range []string{b.ID, other.ID} {
+		entries, _ := svc.ListAudit(ctx, model.AuditFilter{BoardID: boardID, EntityID: c.ID}, "user")
+		if len(entries) == 0 || entries[0].Action != model.ActionMoved {
+			t.Errorf("expected the move in the audit log of board %s, got %+v", boardID, entries)
+		}
+	}
+
+	back, err := svc.MoveCard(ctx, c.ID, todo.ID, model.CardMoveOptions{}, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if back.Status != model.StatusUnassigned || len(back.Labels) != 1 || back.Labels[0].ID != bug.ID {
+		t.Errorf("unexpected card after moving back: %+v", back)
+	}
+	if _, err := svc.MoveCard(ctx, c.ID, inbox.ID, model.CardMoveOptions{Labels: model.LabelsDrop}, "user"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := svc.GetCard(ctx, c.ID); len(got.Labels) != 0 {
+		t.Errorf("expected labels to be dropped, got %+v", got.Labels)
+	}
+}
+
+func TestCrossBoardDependencies(t *testing.T) {
+	svc := setupService(t)
+	ctx := context.Background()
+
+	b, _ := svc.CreateBoard(ctx, "Board", "", "user")
+	todo, _ := svc.CreateList(ctx, b.ID, "Todo", "", model.Placement{}, "user")
+	other, _ := svc.CreateBoard(ctx, "Other", "", "user")
+	inbox, _ := svc.CreateList(ctx, other.ID, "Inbox", "", model.Placement{}, "user")
+	c, _ := svc.CreateCard(ctx, todo.ID, "Ship", "", "", "", "", nil, "user", model.Placement{})
+	local, _ := svc.CreateCard(ctx, todo.ID, "Build", "", "", "", "", nil, "user", model.Placement{})
+	remote, _ := svc.CreateCard(ctx, inbox.ID, "Approve", "", "", "", "", nil, "user", model.Placement{})
+
+	if err := svc.AddDependency(ctx, c.ID, local.ID, "user"); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.AddDependency(ctx, c.ID, remote.ID, "user"); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := svc.GetCard(ctx, c.ID)
+	for _, d := range got.Dependencies {
+		switch d.ID {
+		case local.ID:
+			if d.Board != nil {
+				t.Errorf("expected no board on a dependency on the same board, got %+v", d.Board)
+			}
+		case remote.ID:
+			if d.Board == nil || d.Board.ID != other.ID || d.Board.Name != "Other" {
+				t.Errorf("expected the other board on a cross-board dependency, got %+v", d.Board)
+			}
+		}
+	}
+	dependents, _ := svc.GetDependents(ctx, remote.ID)
+	if len(dependents) != 1 || dependents[0].Board == nil || dependents[0].Board.ID != b.ID {
+		t.Errorf("expected the dependent's board, got %+v", dependents)
+	}
+
+	svc.SetCrossBoardDependencies(false)
+	spare, _ := svc.CreateCard(ctx, inbox.ID, "Review", "", "", "", "", nil, "user", model.Placement{})
+	if err := svc.AddDependency(ctx, local.ID, spare.ID, "user"); err == nil {
+		t.Error("expected a cross-board dependency to be rejected")
+	}
+	if err := svc.AddDependency(ctx, remote.ID, spare.ID, "user"); err != nil {
+		t.Errorf("expected a dependency on the same board to be allowed: %v", err)
+	}
+	if _, err := svc.MoveCard(ctx, local.ID, inbox.ID, model.CardMoveOptions{}, "user"); err == nil {
+		t.Error("expected moving a card away from its dependents to be rejected")
+	}
+	svc.RemoveDependency(ctx, c.ID, local.ID, "user")
+	if _, err := svc.MoveCard(ctx, local.ID, inbox.ID, model.CardMoveOptions{}, "user"); err != nil {
+		t.Errorf("expected a card without dependencies to move: %v", err)
+	}
+}
+
+func TestBatch(t *testing.T) {
+	svc := setupService(t)
+	ctx := context.Background()
+
+	results, err := svc.Batch(ctx, []model.BatchOp{
+		{Op: "create_board", Ref: "board", Args: map[string]any{"name": "Launch"}},
+		{Op: "create_list", Ref: "todo", Args: map[string]any{"board_id": "$board", "name": "Todo"}},
+		{Op: "create_label", Ref: "bug", Args: map[string]any{"board_id": "$board", "name": "bug"}},
+		{Op: "create_card", Ref: "build", Args: map[string]any{"list_id": "$todo", "title": "Build"}},
+		{Op: "create_card", Ref: "ship", Args: map[string]any{"list_id": "$todo", "title": "$5 budget", "assignee": "ops"}},
+		{Op: "add_dependency", Args: map[string]any{"card_id": "$ship", "depends_on_card_id": "$build"}},
+		{Op: "add_label_to_card", Args: map[string]any{"card_id": "$build", "label_id": "$bug"}},
+		{Op: "add_checklist_item", Args: map[string]any{"card_id": "$build", "text": "Compile"}},
+	}, "planner")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 8 || results[0].ID == "" || results[5].ID != "" {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+	ship, err := svc.GetCard(ctx, results[4].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ship.Title != "$5 budget" || ship.ListID != results[1].ID || len(ship.Dependencies) != 1 || ship.Dependencies[0].ID != results[3].ID {
+		t.Errorf("unexpected card from batch: %+v", ship)
+	}
+	if build, _ := svc.GetCard(ctx, results[3].ID); len(build.Labels) != 1 || build.ChecklistTotal != 1 {
+		t.Errorf("expected the label and checklist item on the card, got %+v", build)
+	}
+
+	for _, ops := range [][]model.BatchOp{
+		{
+			{Op: "create_board", Ref: "b", Args: map[string]any{"name": "Doomed"}},
+			{Op: "create_list", Ref: "l", Args: map[string]any{"board_id": "$b", "name": "Todo"}},
+			{Op: "create_card", Args: map[string]any{"list_id": "$l", "title": ""}},
+		},
+		{
+			{Op: "create_board", Args: map[string]any{"name": "Doomed", "list_id": "$l"}},
+			{Op: "create_list", Ref: "l", Args: map[string]any{"board_id": results[0].ID, "name": "Todo"}},
+		},
+		{
+			{Op: "create_board", Args: map[string]any{"name": "Doomed"}},
+			{Op: "drop_board", Args: map[string]any{}},
+		},
+		{
+			{Op: "create_board", Ref: "b", Args: map[string]any{"name": "Doomed"}},
+			{Op: "create_board", Ref: "b", Args: map[string]any{"name": "Doomed"}},
+		},
+	} {
+		if _, err := svc.Batch(ctx, ops, "planner"); err == nil {
+			t.Errorf("expected batch %+v to fail", ops)
+		}
+	}
+	boards, _, _ := svc.ListBoards(ctx, model.Page{})
+	if len(boards) != 1 {
+		t.Errorf("expected failed batches to leave nothing behind, got %d boards", len(boards))
+	}
+}
+
+func TestImportPlan(t *testing.T) {
+	svc := setupService(t)
+	ctx := context.Background()
+
+	plan, err := service.ParsePlan([]byte(`
+name: Launch
+labels:
+  - name: backend
+lists:
+  - name: Todo
+  - name: Doing
+epics:
+  - key: auth
+    title: Authentication
+    tasks:
+      - key: schema
+        title: User schema
+        labels: [backend]
+      - key: login
+        title: Login form
+        list: Doing
+        assignee: dev
+        depends_on: [schema]
+        checklist: [Design, Build]
+tasks:
+  - key: notes
+    title: Release notes
+    depends_on: [login]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dry, err := svc.ImportPlan(ctx, plan, true, "planner")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !dry.DryRun || dry.Board != nil || len(dry.Diff) != 10 ||
+		dry.Diff[6] != `+ card "Login form" (login) in "Doing" under "Authentication"` || dry.Diff[9] != "+ dependency notes -> login" {
+		t.Errorf("unexpected dry run: %+v", dry)
+	}
+	if boards, _, _ := svc.ListBoards(ctx, model.Page{}); len(boards) != 0 {
+		t.Fatalf("expected a dry run to create nothing, got %d boards", len(boards))
+	}
+
+	res, err := svc.ImportPlan(ctx, plan, false, "planner")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Board == nil || res.Board.Name != "Launch" || len(res.Cards) != 4 || !slices.Equal(res.Diff, dry.Diff) {
+		t.Fatalf("unexpected import: %+v", res)
+	}
+	login, _ := svc.GetCard(ctx, res.Cards["login"])
+	if login.ParentID != res.Cards["auth"] || login.Assignee != "dev" || login.ChecklistTotal != 2 ||
+		len(login.Dependencies) != 1 || login.Dependencies[0].ID != res.Cards["schema"] ||
+		len(login.Dependents) != 1 || login.Dependents[0].ID != res.Cards["notes"] {
+		t.Errorf("unexpected imported card: %+v", login)
+	}
+	if schema, _ := svc.GetCard(ctx, res.Cards["schema"]); len(schema.Labels) != 1 || schema.Labels[0].Name != "backend" {
+		t.Errorf("expected the label on the imported card, got %+v", schema.Labels)
+	}
+
+	for _, bad := range []string{
+		"name: X\ntasks:\n  - {key: a, title: A, depends_on: [b]}\n  - {key: b, title: B, depends_on: [a]}",
+		"name: X\ntasks:\n  - {key: a, title: A, depends_on: [missing]}",
+		"name: X\ntasks:\n  - {key: a, title: A}\n  - {key: a, title: B}",
+		"name: X\ntasks:\n  - {title: A, list: Nowhere}",
+		"name: X\ntasks:\n  - {title: A, labels: [nope]}",
+		"name: X\ntasks:\n  - {title: A, dependson: [b]}",
+	} {
+		p, err := service.ParsePlan([]byte(bad))
+		if err == nil {
+			_, err = svc.ImportPlan(ctx, p, false, "planner")
+		}
+		if err == nil {
+			t.Errorf("expected plan %q to be rejected", bad)
+		}
+	}
+	if boards, _, _ := svc.ListBoards(ctx, model.Page{}); len(boards) != 1 {
+		t.Errorf("expected rejected plans to create nothing, got %d boards", len(boards))
+	}
+}
+
+const trelloExport = `{
+  "id": "b1", "name": "Roadmap", "desc": "From Trello",
+  "labels": [{"id": "l1", "name": "bug", "color": "red"}, {"id": "l2", "name": "", "color": "sky_dark"}],
+  "lists": [
+    {"id": "L2", "name": "Doing", "pos": 2},
+    {"id": "L1", "name": "To Do", "pos": 1},
+    {"id": "L3", "name": "Old", "pos": 3, "closed": true}
+  ],
+  "members": [{"id": "m1", "username": "alice", "fullName": "Alice A"}],
+  "cards": [
+    {"id": "c1", "name": "Fix login", "desc": "It breaks", "idList": "L1", "pos": 1,
+     "due": "2026-03-01T12:00:00.000Z", "idLabels": ["l1", "l2"], "idMembers": ["m1"]},
+    {"id": "c2", "name": "Shipped", "idList": "L2", "pos": 1, "closed": true},
+    {"id": "c3", "name": "Ancient", "idList": "L3", "pos": 1}
+  ],
+  "checklists": [{"id": "k1", "idCard": "c1", "name": "Steps", "pos": 1, "checkItems": [
+    {"id": "i2", "name": "Patch", "state": "incomplete", "pos": 2},
+    {"id": "i1", "name": "Reproduce", "state": "complete", "pos": 1}
+  ]}],
+  "actions": [
+    {"id": "a2", "type": "commentCard", "date": "2026-02-02T10:00:00.000Z",
+     "data": {"text": "Fixed on staging", "card": {"id": "c1"}}, "memberCreator": {"id": "m1", "username": "alice"}},
+    {"id": "a1", "type": "commentCard", "date": "2026-02-01T09:30:00.000Z",
+     "data": {"text": "Seen again", "card": {"id": "c1"}}, "memberCreator": {"id": "m2", "username": "bob"}},
+    {"id": "a0", "type": "updateCard", "date": "2026-01-01T00:00:00.000Z", "data": {"card": {"id": "c1"}}}
+  ]
+}`
+
+func TestImportTrello(t *testing.T) {
+	svc := setupService(t)
+	ctx := context.Background()
+
+	res, err := svc.ImportTrello(ctx, []byte(trelloExport), false, "importer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int{"board": 1, "label": 2, "list": 3, "card": 3, "checklist_item": 2, "comment": 2}
+	if res.Board == nil || res.Board.Name != "Roadmap" || res.Board.Description != "From Trello" || !maps.Equal(res.Created, want) {
+		t.Fatalf("unexpected import: %+v", res)
+	}
+	boardID := res.Board.ID
+
+	lists, err := svc.ListListsByBoard(ctx, boardID, true, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lists) != 3 || lists[0].Name != "To Do" || lists[1].Name != "Doing" || lists[2].Name != "Old" || lists[2].ArchivedAt == nil {
+		t.Fatalf("unexpected lists: %+v", lists)
+	}
+	labels, _ := svc.ListLabelsByBoard(ctx, boardID)
+	colors := map[string]string{}
+	for _, l := range labels {
+		colors[l.Name] = l.Color
+	}
+	if colors["bug"] != "#eb5a46" || colors["sky_dark"] != "#00c2e0" {
+		t.Errorf("unexpected labels: %+v", labels)
+	}
+
+	cards, _, _ := svc.ListCardsByList(ctx, lists[0].ID, false, model.Page{})
+	if len(cards) != 1 {
+		t.Fatalf("expected 1 card in To Do, got %d", len(cards))
+	}
+	fix, _ := svc.GetCard(ctx, cards[0].ID)
+	due := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	if fix.Title != "Fix login" || fix.Assignee != "alice" || fix.DueDate == nil || !fix.DueDate.Equal(due) || len(fix.Labels) != 2 {
+		t.Errorf("unexpected card: %+v", fix)
+	}
+	if len(fix.Checklist) != 2 || fix.Checklist[0].Text != "Reproduce" || !fix.Checklist[0].Done || fix.Checklist[1].Done {
+		t.Errorf("unexpected checklist: %+v", fix.Checklist)
+	}
+	comments, _, _ := svc.ListComments(ctx, fix.ID, model.Page{})
+	if len(comments) != 2 {
+		t.Fatalf("expected 2 comments, got %+v", comments)
+	}
+	for _, c := range comments {
+		if c.Author == "bob" && !c.CreatedAt.Equal(time.Date(2026, 2, 1, 9, 30, 0, 0, time.UTC)) || c.Author != "bob" && c.Author != "alice" {
+			t.Errorf("expected the original author and time, got %+v", c)
+		}
+	}
+	activity, _, _ := svc.ListActivityByCard(ctx, fix.ID, model.Page{})
+	var commented []string
+	for _, a := range activity {
+		if a.Action == model.ActionComment && a.CreatedAt.Year() == 2026 && a.CreatedAt.Month() == time.February {
+			commented = append(commented, a.Actor)
+		}
+	}
+	if slices.Sort(commented); !slices.Equal(commented, []string{"alice", "bob"}) {
+		t.Errorf("expected the comments in the activity log, got %v", commented)
+	}
+	archived, _, _ := svc.ListCardsByList(ctx, lists[1].ID, true, model.Page{})
+	if len(archived) != 1 || archived[0].ArchivedAt == nil {
+		t.Errorf("expected the closed card to be archived, got %+v", archived)
+	}
+	if old, _, _ := svc.ListCardsByList(ctx, lists[2].ID, true, model.Page{}); len(old) != 1 || old[0].Title != "Ancient" {
+		t.Errorf("expected the card in the closed list, got %+v", old)
+	}
+
+	if _, err := svc.ImportTrello(ctx, []byte(trelloExport), false, "importer"); err == nil {
+		t.Error("expected importing the board again to be refused")
+	}
+	again, err := svc.ImportTrello(ctx, []byte(trelloExport), true, "importer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Board.ID != boardID || len(again.Created) != 0 || len(again.Updated) != 0 {
+		t.Errorf("expected re-importing an unchanged export to change nothing, got %+v", again)
+	}
+
+	changed := strings.NewReplacer(
+		`"name": "Fix login"`, `"name": "Fix login for good"`,
+		`"idList": "L1"`, `"idList": "L2"`,
+		`"state": "incomplete"`, `"state": "complete"`,
+		`"id": "c2", "name": "Shipped", "idList": "L2", "pos": 1, "closed": true`, `"id": "c2", "name": "Shipped", "idList": "L2", "pos": 1`,
+		`"cards": [`, `"cards": [{"id": "c4", "name": "New", "idList": "L3", "pos": 2},`,
+	).Replace(trelloExport)
+	upd, err := svc.ImportTrello(ctx, []byte(changed), true, "importer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !maps.Equal(upd.Created, map[string]int{"card": 1}) || !maps.Equal(upd.Updated, map[string]int{"card": 2, "checklist_item": 1}) {
+		t.Errorf("unexpected re-import: %+v", upd)
+	}
+	fix, _ = svc.GetCard(ctx, fix.ID)
+	if fix.Title != "Fix login for good" || fix.ListID != lists[1].ID || fix.ChecklistDone != 2 {
+		t.Errorf("expected the card to be updated, got %+v", fix)
+	}
+	if shipped, _ := svc.GetCard(ctx, archived[0].ID); shipped.ArchivedAt != nil {
+		t.Error("expected the reopened card to be unarchived")
+	}
+	if old, _, _ := svc.ListCardsByList(ctx, lists[2].ID, true, model.Page{}); len(old) != 2 {
+		t.Errorf("expected the new card in the closed list, got %+v", old)
+	}
+	if l, _ := svc.ListListsByBoard(ctx, boardID, true, 0); l[2].ArchivedAt == nil {
+		t.Error("expected the closed list to stay archived")
+	}
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/aellingwood/cielo/internal/model"
+)
+
+// SummarizeBoard returns the board's active lists with card counts by
+// status and priority, overdue and blocked counts, and board-wide totals.
+// With includeCards, each list also carries the headlines of its cards, up
+// to cardsPerList of them when that is positive. The summary's ETag changes
+// whenever any of its content does.
+func (s *Service) SummarizeBoard(ctx context.Context, boardID string, includeCards bool, cardsPerList int) (*model.BoardSummary, error) {
+	b, err := s.activeBoard(ctx, boardID)
+	if err != nil {
+		return nil, err
+	}
+	lists, err := s.store.ListListsByBoard(ctx, boardID, false)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := s.store.CountCardsByList(ctx, boardID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	sum := &model.BoardSummary{
+		ID:          b.ID,
+		Name:        b.Name,
+		Description: b.Description,
+		UpdatedAt:   b.UpdatedAt,
+		CardCounts:  model.NewCardCounts(),
+		Lists:       make([]model.ListSummary, len(lists)),
+	}
+	index := map[string]int{}
+	for i, l := range lists {
+		c, ok := counts[l.ID]
+		if !ok {
+			c = model.NewCardCounts()
+		}
+		sum.Add(c)
+		sum.Lists[i] = model.ListSummary{ID: l.ID, Name: l.Name, Position: l.Position, Status: l.Status, CardCounts: c}
+		index[l.ID] = i
+	}
+
+	if includeCards {
+		cards, next, err := s.store.ListCardsByBoard(ctx, boardID, false, cardsPerList)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range cards {
+			i, ok := index[c.ListID]
+			if !ok {
+				continue
+			}
+			sum.Lists[i].Cards = append(sum.Lists[i].Cards, c.Headline())
+		}
+		for id, cursor := range next {
+			if i, ok := index[id]; ok {
+				sum.Lists[i].CardsCursor = cursor
+			}
+		}
+	}
+
+	data, err := json.Marshal(sum)
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256.Sum256(data)
+	sum.ETag = fmt.Sprintf(`"%x"`, hash[:16])
+	return sum, nil
+}
@@ -0,0 +1,341 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/aellingwood/cielo/internal/model"
+)
+
+// ListTemplates returns the templates of the given kind, or of every kind
+// when kind is empty, ordered by name.
+func (s *Service) ListTemplates(ctx context.Context, kind string) ([]model.Template, error) {
+	if kind != "" && kind != model.TemplateBoard && kind != model.TemplateCard {
+		return nil, fmt.Errorf("invalid template kind %q: use board or card", kind)
+	}
+	templates, err := s.store.ListTemplates(ctx, kind)
+	if err != nil {
+		return nil, err
+	}
+	for i := range templates {
+		templates[i].Variables = templateVariables(&templates[i])
+	}
+	return templates, nil
+}
+
+func (s *Service) GetTemplate(ctx context.Context, id string) (*model.Template, error) {
+	t, err := s.store.GetTemplate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	t.Variables = templateVariables(t)
+	return t, nil
+}
+
+// CreateTemplate saves a board template or a card template, according to
+// which of the two spec holds.
+func (s *Service) CreateTemplate(ctx context.Context, spec model.TemplateSpec, actor string) (*model.Template, error) {
+	t := &model.Template{ID: model.NewID(), CreatedBy: actor}
+	switch {
+	case spec.Board != nil:
+		t.Kind = model.TemplateBoard
+	case spec.Card != nil:
+		t.Kind = model.TemplateCard
+	}
+	applyTemplateSpec(t, spec)
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	t.Variables = templateVariables(t)
+	err := s.inTx(ctx, func(tx *Service) error {
+		if err := tx.store.CreateTemplate(ctx, t); err != nil {
+			return err
+		}
+		return tx.audit(ctx, "", model.EntityTemplate, t.ID, model.ActionCreated, actor, nil, t)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// UpdateTemplate renames a template or replaces its description or
+// blueprint. Boards and cards made from it are not affected.
+func (s *Service) UpdateTemplate(ctx context.Context, id string, spec model.TemplateSpec, actor string) (*model.Template, error) {
+	t, err := s.GetTemplate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	before := *t
+	applyTemplateSpec(t, spec)
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	t.Variables = templateVariables(t)
+	err = s.inTx(ctx, func(tx *Service) error {
+		if err := tx.store.UpdateTemplate(ctx, t); err != nil {
+			return err
+		}
+		return tx.audit(ctx, "", model.EntityTemplate, id, model.ActionUpdated, actor, &before, t)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (s *Service) DeleteTemplate(ctx context.Context, id, actor string) error {
+	t, err := s.GetTemplate(ctx, id)
+	if err != nil {
+		return err
+	}
+	return s.inTx(ctx, func(tx *Service) error {
+		if err := tx.store.DeleteTemplate(ctx, id); err != nil {
+			return err
+		}
+		return tx.audit(ctx, "", model.EntityTemplate, id, model.ActionDeleted, actor, t, nil)
+	})
+}
+
+func applyTemplateSpec(t *model.Template, spec model.TemplateSpec) {
+	if spec.Name != nil {
+		t.Name = strings.TrimSpace(*spec.Name)
+	}
+	if spec.Description != nil {
+		t.Description = *spec.Description
+	}
+	if spec.Board != nil {
+		t.Board = spec.Board
+	}
+	if spec.Card != nil {
+		t.Card = spec.Card
+	}
+}
+
+// CreateBoardFromTemplate makes a board from a board template, filling in
+// its placeholders from vars. An empty name takes the template's name. The
+// board, its workflow, lists, labels, and cards are created together or not
+// at all.
+func (s *Service) CreateBoardFromTemplate(ctx context.Context, id, name string, vars map[string]string, actor string) (*model.Board, error) {
+	t, err := s.GetTemplate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if t.Kind != model.TemplateBoard {
+		return nil, fmt.Errorf("template %s is not a board template", id)
+	}
+	e := &expander{vars: vars}
+	if name == "" {
+		name = t.Name
+	}
+	name = e.expand(name)
+	bt := e.expandBoard(t.Board)
+	if err := e.err(); err != nil {
+		return nil, err
+	}
+	// Filled-in names may collide even where the placeholders did not.
+	if err := bt.Validate(); err != nil {
+		return nil, err
+	}
+	var b *model.Board
+	err = s.inTx(ctx, func(tx *Service) error {
+		var err error
+		if b, err = tx.CreateBoard(ctx, name, bt.Description, actor); err != nil {
+			return err
+		}
+		if bt.Workflow != nil {
+			if _, err := tx.SetWorkflow(ctx, b.ID, bt.Workflow, actor); err != nil {
+				return err
+			}
+		}
+		lists := map[string]string{}
+		for _, lt := range bt.Lists {
+			l, err := tx.CreateList(ctx, b.ID, lt.Name, lt.Status, model.Placement{}, actor)
+			if err != nil {
+				return err
+			}
+			lists[lt.Name] = l.ID
+		}
+		labels := map[string]string{}
+		for _, lt := range bt.Labels {
+			l, err := tx.CreateLabel(ctx, b.ID, lt.Name, lt.Color, actor)
+			if err != nil {
+				return err
+			}
+			labels[lt.Name] = l.ID
+		}
+		for _, ct := range bt.Cards {
+			if _, err := tx.createCardFromTemplate(ctx, lists[ct.List], &ct.CardTemplate, labels, "", model.Placement{}, actor); err != nil {
+				return err
+			}
+		}
+		b, err = tx.store.GetBoard(ctx, b.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// CreateCardFromTemplate makes a card in the list from a card template,
+// filling in its placeholders from vars. Labels the board lacks are created
+// with the default color. The card, its labels, and its checklist are
+// created together or not at all.
+func (s *Service) CreateCardFromTemplate(ctx context.Context, id, listID string, vars map[string]string, assignee string, pos model.Placement, actor string) (*model.Card, error) {
+	t, err := s.GetTemplate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if t.Kind != model.TemplateCard {
+		return nil, fmt.Errorf("template %s is not a card template", id)
+	}
+	e := &expander{vars: vars}
+	ct := e.expandCard(*t.Card)
+	if err := e.err(); err != nil {
+		return nil, err
+	}
+	if err := ct.Validate(); err != nil {
+		return nil, err
+	}
+	l, err := s.store.GetList(ctx, listID)
+	if err != nil {
+		return nil, err
+	}
+	var c *model.Card
+	err = s.inTx(ctx, func(tx *Service) error {
+		existing, err := tx.store.ListLabelsByBoard(ctx, l.BoardID)
+		if err != nil {
+			return err
+		}
+		labels := map[string]string{}
+		for _, lb := range existing {
+			labels[lb.Name] = lb.ID
+		}
+		for _, name := range ct.Labels {
+			if _, ok := labels[name]; ok {
+				continue
+			}
+			lb, err := tx.CreateLabel(ctx, l.BoardID, name, "", actor)
+			if err != nil {
+				return err
+			}
+			labels[name] = lb.ID
+		}
+		c, err = tx.createCardFromTemplate(ctx, listID, &ct, labels, assignee, pos, actor)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// createCardFromTemplate creates the card a filled-in template describes,
+// looking its labels up by name in labels, and returns its details.
+func (s *Service) createCardFromTemplate(ctx context.Context, listID string, ct *model.CardTemplate, labels map[string]string, assignee string, pos model.Placement, actor string) (*model.Card, error) {
+	c, err := s.CreateCard(ctx, listID, ct.Title, ct.Description, assignee, ct.Priority, "", nil, actor, pos)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range ct.Labels {
+		if err := s.AddLabelToCard(ctx, c.ID, labels[name], actor); err != nil {
+			return nil, err
+		}
+	}
+	for _, text := range ct.Checklist {
+		if _, err := s.AddChecklistItem(ctx, c.ID, text, "", model.Placement{}, actor); err != nil {
+			return nil, err
+		}
+	}
+	return s.GetCard(ctx, c.ID)
+}
+
+// placeholderPattern matches a {{name}} placeholder, allowing spaces inside
+// the braces.
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// expander fills in placeholders, remembering the names it has no value
+// for.
+type expander struct {
+	vars    map[string]string
+	missing []string
+}
+
+func (e *expander) expand(text string) string {
+	return placeholderPattern.ReplaceAllStringFunc(text, func(m string) string {
+		name := placeholderPattern.FindStringSubmatch(m)[1]
+		v, ok := e.vars[name]
+		if !ok {
+			if !slices.Contains(e.missing, name) {
+				e.missing = append(e.missing, name)
+			}
+			return m
+		}
+		return v
+	})
+}
+
+func (e *expander) expandAll(texts []string) []string {
+	if texts == nil {
+		return nil
+	}
+	out := make([]string, len(texts))
+	for i, t := range texts {
+		out[i] = e.expand(t)
+	}
+	return out
+}
+
+func (e *expander) expandCard(ct model.CardTemplate) model.CardTemplate {
+	ct.Title = e.expand(ct.Title)
+	ct.Description = e.expand(ct.Description)
+	ct.Labels = e.expandAll(ct.Labels)
+	ct.Checklist = e.expandAll(ct.Checklist)
+	return ct
+}
+
+// expandBoard fills in the text of a board template. Statuses and colors
+// are taken as they are.
+func (e *expander) expandBoard(bt *model.BoardTemplate) *model.BoardTemplate {
+	out := &model.BoardTemplate{Description: e.expand(bt.Description), Workflow: bt.Workflow}
+	for _, l := range bt.Lists {
+		out.Lists = append(out.Lists, model.ListTemplate{Name: e.expand(l.Name), Status: l.Status})
+	}
+	for _, l := range bt.Labels {
+		out.Labels = append(out.Labels, model.LabelTemplate{Name: e.expand(l.Name), Color: l.Color})
+	}
+	for _, c := range bt.Cards {
+		out.Cards = append(out.Cards, model.BoardCardTemplate{List: e.expand(c.List), CardTemplate: e.expandCard(c.CardTemplate)})
+	}
+	return out
+}
+
+// err reports the placeholders that had no value.
+func (e *expander) err() error {
+	if len(e.missing) == 0 {
+		return nil
+	}
+	return fmt.Errorf("missing template variables: %s", strings.Join(e.missing, ", "))
+}
+
+// templateVariables returns the names of the placeholders in the template,
+// in the order they first appear. A board template's name is scanned too,
+// as it names the board made from it; a card template's name is not used.
+func templateVariables(t *model.Template) []string {
+	e := &expander{}
+	switch {
+	case t.Board != nil:
+		e.expand(t.Name)
+		e.expandBoard(t.Board)
+	case t.Card != nil:
+		e.expandCard(*t.Card)
+	}
+	if e.missing == nil {
+		return []string{}
+	}
+	return e.missing
+}
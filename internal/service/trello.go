@@ -0,0 +1,589 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aellingwood/cielo/internal/model"
+)
+
+// trelloSource names Trello in import refs.
+const trelloSource = "trello"
+
+// trelloColors maps Trello's label colors to hex colors. Their _light and
+// _dark variants get the same.
+var trelloColors = map[string]string{
+	"green":  "#61bd4f",
+	"yellow": "#f2d600",
+	"orange": "#ff9f1a",
+	"red":    "#eb5a46",
+	"purple": "#c377e0",
+	"blue":   "#0079bf",
+	"sky":    "#00c2e0",
+	"lime":   "#51e898",
+	"pink":   "#ff78cb",
+	"black":  "#344563",
+}
+
+// trelloBoard is the part of Trello's board export that is imported.
+type trelloBoard struct {
+	ID         string            `json:"id"`
+	Name       string            `json:"name"`
+	Desc       string            `json:"desc"`
+	Labels     []trelloLabel     `json:"labels"`
+	Lists      []trelloList      `json:"lists"`
+	Cards      []trelloCard      `json:"cards"`
+	Checklists []trelloChecklist `json:"checklists"`
+	Actions    []trelloAction    `json:"actions"`
+	Members    []trelloMember    `json:"members"`
+}
+
+type trelloLabel struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+type trelloList struct {
+	ID     string  `json:"id"`
+	Name   string  `json:"name"`
+	Closed bool    `json:"closed"`
+	Pos    float64 `json:"pos"`
+}
+
+type trelloCard struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Desc      string   `json:"desc"`
+	IDList    string   `json:"idList"`
+	Closed    bool     `json:"closed"`
+	Pos       float64  `json:"pos"`
+	Due       string   `json:"due"`
+	IDLabels  []string `json:"idLabels"`
+	IDMembers []string `json:"idMembers"`
+}
+
+type trelloChecklist struct {
+	ID         string            `json:"id"`
+	IDCard     string            `json:"idCard"`
+	Name       string            `json:"name"`
+	Pos        float64           `json:"pos"`
+	CheckItems []trelloCheckItem `json:"checkItems"`
+}
+
+type trelloCheckItem struct {
+	ID    string  `json:"id"`
+	Name  string  `json:"name"`
+	State string  `json:"state"`
+	Pos   float64 `json:"pos"`
+}
+
+// trelloAction is an entry of the board's history; only comments, of type
+// commentCard, are imported.
+type trelloAction struct {
+	ID   string    `json:"id"`
+	Type string    `json:"type"`
+	Date time.Time `json:"date"`
+	Data struct {
+		Text string `json:"text"`
+		Card struct {
+			ID string `json:"id"`
+		} `json:"card"`
+	} `json:"data"`
+	MemberCreator trelloMember `json:"memberCreator"`
+}
+
+type trelloMember struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	FullName string `json:"fullName"`
+}
+
+// name is how the member is known in Cielo: their username, or else their
+// full name.
+func (m *trelloMember) name() string {
+	return cmp.Or(m.Username, m.FullName)
+}
+
+// trelloItem is a checklist item as imported: the items of a card's
+// checklists run together, prefixed with their checklist's name when the
+// card has more than one.
+type trelloItem struct {
+	id   string
+	text string
+	done bool
+}
+
+func trelloItems(checklists []trelloChecklist) []trelloItem {
+	slices.SortStableFunc(checklists, func(a, b trelloChecklist) int { return cmp.Compare(a.Pos, b.Pos) })
+	var items []trelloItem
+	for _, cl := range checklists {
+		slices.SortStableFunc(cl.CheckItems, func(a, b trelloCheckItem) int { return cmp.Compare(a.Pos, b.Pos) })
+		for _, it := range cl.CheckItems {
+			text := it.Name
+			if len(checklists) > 1 {
+				text = cl.Name + ": " + text
+			}
+			items = append(items, trelloItem{id: it.ID, text: text, done: it.State == "complete"})
+		}
+	}
+	return items
+}
+
+func trelloColor(color string) string {
+	base, _, _ := strings.Cut(color, "_")
+	return trelloColors[base]
+}
+
+// ImportTrello imports a board from Trello's JSON export: its lists and
+// labels, its cards with their due dates, first member as assignee, and
+// checklists, its comments with their original authors and times, and
+// which lists and cards are archived. Everything is imported in one
+// transaction. A board imported before is refused unless reimport is set;
+// then what earlier imports created is brought in line with the export and
+// what they lack is added. Nothing is removed, and lists and cards deleted
+// in Cielo since are left alone.
+func (s *Service) ImportTrello(ctx context.Context, data []byte, reimport bool, actor string) (*model.ImportResult, error) {
+	var tb trelloBoard
+	if err := json.Unmarshal(data, &tb); err != nil {
+		return nil, fmt.Errorf("invalid Trello export: %w", err)
+	}
+	if tb.ID == "" || tb.Name == "" {
+		return nil, fmt.Errorf("invalid Trello export: the board id and name are required")
+	}
+	res := &model.ImportResult{Created: map[string]int{}, Updated: map[string]int{}}
+	err := s.inTx(ctx, func(tx *Service) error {
+		im := &trelloImporter{
+			svc: tx, actor: actor, reimport: reimport, res: res,
+			members: map[string]string{}, lists: map[string]string{}, labels: map[string]string{},
+		}
+		return im.run(ctx, &tb)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// trelloImporter carries an import from Trello through its steps.
+type trelloImporter struct {
+	svc      *Service
+	actor    string
+	reimport bool
+	res      *model.ImportResult
+	// members, lists, and labels map Trello IDs to usernames and to the
+	// IDs of the lists and labels imported from them.
+	members map[string]string
+	lists   map[string]string
+	labels  map[string]string
+}
+
+func (im *trelloImporter) ref(ctx context.Context, trelloID string) (string, error) {
+	return im.svc.store.GetImportRef(ctx, trelloSource, trelloID)
+}
+
+func (im *trelloImporter) setRef(ctx context.Context, trelloID, entityType, id string) error {
+	im.res.Created[entityType]++
+	return im.svc.store.SetImportRef(ctx, trelloSource, trelloID, entityType, id)
+}
+
+func (im *trelloImporter) run(ctx context.Context, tb *trelloBoard) error {
+	for _, m := range tb.Members {
+		im.members[m.ID] = m.name()
+	}
+	b, err := im.board(ctx, tb)
+	if err != nil {
+		return err
+	}
+	for _, tl := range tb.Labels {
+		if err := im.label(ctx, b.ID, &tl); err != nil {
+			return fmt.Errorf("label %q: %w", tl.Name, err)
+		}
+	}
+	slices.SortStableFunc(tb.Lists, func(a, b trelloList) int { return cmp.Compare(a.Pos, b.Pos) })
+	for _, tl := range tb.Lists {
+		if err := im.list(ctx, b.ID, &tl); err != nil {
+			return fmt.Errorf("list %q: %w", tl.Name, err)
+		}
+	}
+
+	checklists := map[string][]trelloChecklist{}
+	for _, cl := range tb.Checklists {
+		checklists[cl.IDCard] = append(checklists[cl.IDCard], cl)
+	}
+	comments := map[string][]trelloAction{}
+	for _, a := range tb.Actions {
+		if a.Type == "commentCard" {
+			comments[a.Data.Card.ID] = append(comments[a.Data.Card.ID], a)
+		}
+	}
+	slices.SortStableFunc(tb.Cards, func(a, b trelloCard) int { return cmp.Compare(a.Pos, b.Pos) })
+	for _, tc := range tb.Cards {
+		// The export lists actions newest first.
+		cardComments := comments[tc.ID]
+		slices.SortStableFunc(cardComments, func(a, b trelloAction) int { return a.Date.Compare(b.Date) })
+		if err := im.card(ctx, &tc, trelloItems(checklists[tc.ID]), cardComments); err != nil {
+			return fmt.Errorf("card %q: %w", tc.Name, err)
+		}
+	}
+
+	// Lists are archived last, since cards cannot be added to or moved
+	// into archived lists.
+	for _, tl := range tb.Lists {
+		id, ok := im.lists[tl.ID]
+		if !ok || !tl.Closed {
+			continue
+		}
+		l, err := im.svc.store.GetList(ctx, id)
+		if err != nil {
+			return err
+		}
+		if l.ArchivedAt == nil {
+			if _, err := im.svc.ArchiveList(ctx, id, im.actor); err != nil {
+				return err
+			}
+		}
+	}
+	im.res.Board, err = im.svc.store.GetBoard(ctx, b.ID)
+	return err
+}
+
+func (im *trelloImporter) board(ctx context.Context, tb *trelloBoard) (*model.Board, error) {
+	s := im.svc
+	id, err := im.ref(ctx, tb.ID)
+	if err != nil {
+		return nil, err
+	}
+	if id != "" {
+		if b, err := s.store.GetBoard(ctx, id); err == nil {
+			if !im.reimport {
+				return nil, fmt.Errorf("this Trello board was already imported as board %s; re-import to update it", id)
+			}
+			if b.DeletedAt != nil {
+				return nil, fmt.Errorf("board %s is in the trash; restore it to re-import", id)
+			}
+			if b.Name != tb.Name || b.Description != tb.Desc {
+				im.res.Updated[model.EntityBoard]++
+				return s.UpdateBoard(ctx, id, tb.Name, tb.Desc, im.actor)
+			}
+			return b, nil
+		}
+	}
+	b, err := s.CreateBoard(ctx, tb.Name, tb.Desc, im.actor)
+	if err != nil {
+		return nil, err
+	}
+	return b, im.setRef(ctx, tb.ID, model.EntityBoard, b.ID)
+}
+
+func (im *trelloImporter) label(ctx context.Context, boardID string, tl *trelloLabel) error {
+	s := im.svc
+	// Trello labels may have only a color; Cielo's need a name.
+	name := cmp.Or(tl.Name, tl.Color, "label")
+	color := trelloColor(tl.Color)
+	id, err := im.ref(ctx, tl.ID)
+	if err != nil {
+		return err
+	}
+	if id != "" {
+		if l, err := s.store.GetLabel(ctx, id); err == nil {
+			im.labels[tl.ID] = id
+			if l.Name != name || (color != "" && l.Color != color) {
+				im.res.Updated[model.EntityLabel]++
+				_, err = s.UpdateLabel(ctx, id, name, color, im.actor)
+			}
+			return err
+		}
+	}
+	l, err := s.CreateLabel(ctx, boardID, name, color, im.actor)
+	if err != nil {
+		return err
+	}
+	im.labels[tl.ID] = l.ID
+	return im.setRef(ctx, tl.ID, model.EntityLabel, l.ID)
+}
+
+// list imports the list open, or reopens it if Trello has it open; closed
+// lists are archived once their cards are in.
+func (im *trelloImporter) list(ctx context.Context, boardID string, tl *trelloList) error {
+	s := im.svc
+	id, err := im.ref(ctx, tl.ID)
+	if err != nil {
+		return err
+	}
+	if id != "" {
+		if l, err := s.store.GetList(ctx, id); err == nil {
+			if l.DeletedAt != nil {
+				return nil
+			}
+			im.lists[tl.ID] = id
+			if l.Name != tl.Name || (l.ArchivedAt != nil) != tl.Closed {
+				im.res.Updated[model.EntityList]++
+			}
+			if l.Name != tl.Name {
+				if _, err := s.UpdateList(ctx, id, tl.Name, nil, nil, im.actor); err != nil {
+					return err
+				}
+			}
+			if l.ArchivedAt != nil && !tl.Closed {
+				_, err = s.UnarchiveList(ctx, id, im.actor)
+			}
+			return err
+		}
+	}
+	l, err := s.CreateList(ctx, boardID, tl.Name, "", model.Placement{}, im.actor)
+	if err != nil {
+		return err
+	}
+	im.lists[tl.ID] = l.ID
+	return im.setRef(ctx, tl.ID, model.EntityList, l.ID)
+}
+
+func (im *trelloImporter) card(ctx context.Context, tc *trelloCard, items []trelloItem, comments []trelloAction) error {
+	s := im.svc
+	listID, ok := im.lists[tc.IDList]
+	if !ok {
+		// The list is not in the export, or was deleted in Cielo.
+		return nil
+	}
+	due, err := model.ParseDueDate(tc.Due)
+	if err != nil {
+		return err
+	}
+	assignee := ""
+	if len(tc.IDMembers) > 0 {
+		assignee = im.members[tc.IDMembers[0]]
+	}
+	var labelIDs []string
+	for _, id := range tc.IDLabels {
+		if l, ok := im.labels[id]; ok {
+			labelIDs = append(labelIDs, l)
+		}
+	}
+
+	id, err := im.ref(ctx, tc.ID)
+	if err != nil {
+		return err
+	}
+	var c *model.Card
+	if id != "" {
+		c, _ = s.store.GetCard(ctx, id)
+	}
+	if c == nil {
+		if err := im.openList(ctx, listID); err != nil {
+			return err
+		}
+		c, err = s.CreateCard(ctx, listID, tc.Name, tc.Desc, assignee, "", "", due, im.actor, model.Placement{})
+		if err != nil {
+			return err
+		}
+		if err := im.setRef(ctx, tc.ID, model.EntityCard, c.ID); err != nil {
+			return err
+		}
+		for _, l := range labelIDs {
+			if err := s.AddLabelToCard(ctx, c.ID, l, im.actor); err != nil {
+				return err
+			}
+		}
+		for _, it := range items {
+			if err := im.checklistItem(ctx, c.ID, "", it); err != nil {
+				return err
+			}
+		}
+		if err := im.comments(ctx, c.ID, comments); err != nil {
+			return err
+		}
+		if tc.Closed {
+			_, err = s.ArchiveCard(ctx, c.ID, im.actor)
+		}
+		return err
+	}
+	if c.DeletedAt != nil {
+		return nil
+	}
+
+	// Work out what differs first: the card has to be open to change, and
+	// is only reopened if it does.
+	updates := map[string]any{}
+	if c.Title != tc.Name {
+		updates["title"] = tc.Name
+	}
+	if c.Description != tc.Desc {
+		updates["description"] = tc.Desc
+	}
+	if c.Assignee != assignee {
+		updates["assignee"] = assignee
+	}
+	if !equalDue(c.DueDate, due) {
+		updates["due_date"] = tc.Due
+	}
+	current, err := s.store.GetLabelsForCard(ctx, c.ID)
+	if err != nil {
+		return err
+	}
+	var addLabels, removeLabels []string
+	for _, l := range labelIDs {
+		if !slices.ContainsFunc(current, func(cl model.Label) bool { return cl.ID == l }) {
+			addLabels = append(addLabels, l)
+		}
+	}
+	imported := slices.Collect(maps.Values(im.labels))
+	for _, l := range current {
+		// Labels added in Cielo stay.
+		if slices.Contains(imported, l.ID) && !slices.Contains(labelIDs, l.ID) {
+			removeLabels = append(removeLabels, l.ID)
+		}
+	}
+	type itemChange struct {
+		item trelloItem
+		id   string // "" for a new item
+	}
+	var itemChanges []itemChange
+	for _, it := range items {
+		itemID, err := im.ref(ctx, it.id)
+		if err != nil {
+			return err
+		}
+		if itemID != "" {
+			if existing, err := s.store.GetChecklistItem(ctx, itemID); err == nil {
+				if existing.Text != it.text || existing.Done != it.done {
+					itemChanges = append(itemChanges, itemChange{it, itemID})
+				}
+				continue
+			}
+		}
+		itemChanges = append(itemChanges, itemChange{item: it})
+	}
+
+	changed := len(updates) > 0 || c.ListID != listID || len(addLabels) > 0 || len(removeLabels) > 0 || len(itemChanges) > 0
+	archived := c.ArchivedAt != nil
+	if changed || archived != tc.Closed {
+		im.res.Updated[model.EntityCard]++
+	}
+	if changed {
+		if archived {
+			if _, err := s.UnarchiveCard(ctx, c.ID, im.actor); err != nil {
+				return err
+			}
+		}
+		if len(updates) > 0 {
+			if _, err := s.UpdateCard(ctx, c.ID, updates, im.actor); err != nil {
+				return err
+			}
+		}
+		if c.ListID != listID {
+			if err := im.openList(ctx, listID); err != nil {
+				return err
+			}
+			if _, err := s.MoveCard(ctx, c.ID, listID, model.CardMoveOptions{}, im.actor); err != nil {
+				return err
+			}
+		}
+		for _, l := range addLabels {
+			if err := s.AddLabelToCard(ctx, c.ID, l, im.actor); err != nil {
+				return err
+			}
+		}
+		for _, l := range removeLabels {
+			if err := s.RemoveLabelFromCard(ctx, c.ID, l, im.actor); err != nil {
+				return err
+			}
+		}
+		for _, ch := range itemChanges {
+			if err := im.checklistItem(ctx, c.ID, ch.id, ch.item); err != nil {
+				return err
+			}
+		}
+	}
+	if err := im.comments(ctx, c.ID, comments); err != nil {
+		return err
+	}
+	switch {
+	case tc.Closed && (changed || !archived):
+		_, err = s.ArchiveCard(ctx, c.ID, im.actor)
+	case !tc.Closed && archived && !changed:
+		_, err = s.UnarchiveCard(ctx, c.ID, im.actor)
+	}
+	return err
+}
+
+// openList unarchives the list, which the import archives again at the
+// end, so that a card can move into it.
+func (im *trelloImporter) openList(ctx context.Context, id string) error {
+	l, err := im.svc.store.GetList(ctx, id)
+	if err != nil {
+		return err
+	}
+	if l.ArchivedAt != nil {
+		_, err = im.svc.UnarchiveList(ctx, id, im.actor)
+	}
+	return err
+}
+
+// checklistItem adds the item to the card's checklist, or updates the item
+// imported from it before, itemID.
+func (im *trelloImporter) checklistItem(ctx context.Context, cardID, itemID string, it trelloItem) error {
+	s := im.svc
+	if itemID != "" {
+		im.res.Updated[model.EntityChecklistItem]++
+		_, err := s.UpdateChecklistItem(ctx, itemID, model.ChecklistItemSpec{Text: &it.text, Done: &it.done}, im.actor)
+		return err
+	}
+	created, err := s.AddChecklistItem(ctx, cardID, it.text, "", model.Placement{}, im.actor)
+	if err != nil {
+		return err
+	}
+	if it.done {
+		if _, err := s.UpdateChecklistItem(ctx, created.ID, model.ChecklistItemSpec{Done: &it.done}, im.actor); err != nil {
+			return err
+		}
+	}
+	return im.setRef(ctx, it.id, model.EntityChecklistItem, created.ID)
+}
+
+// comments adds the comments not imported before to the card and its
+// activity log, with their original authors and times. Nobody is notified
+// of them: they are history.
+func (im *trelloImporter) comments(ctx context.Context, cardID string, actions []trelloAction) error {
+	s := im.svc
+	for _, a := range actions {
+		body := strings.TrimSpace(a.Data.Text)
+		if body == "" {
+			continue
+		}
+		id, err := im.ref(ctx, a.ID)
+		if err != nil {
+			return err
+		}
+		if id != "" {
+			if _, err := s.store.GetComment(ctx, id); err == nil {
+				continue
+			}
+		}
+		author := cmp.Or(a.MemberCreator.name(), "trello")
+		c := &model.Comment{
+			ID: model.NewID(), CardID: cardID, Author: author, Body: body,
+			Mentions: parseMentions(body), CreatedAt: a.Date,
+		}
+		if err := s.store.CreateComment(ctx, c); err != nil {
+			return err
+		}
+		detail, _ := json.Marshal(map[string]string{"comment_id": c.ID, "text": body})
+		if err := s.store.CreateActivity(ctx, &model.ActivityLog{
+			ID: model.NewID(), CardID: cardID, Actor: author, Action: model.ActionComment,
+			Detail: string(detail), CreatedAt: a.Date,
+		}); err != nil {
+			return err
+		}
+		if err := im.setRef(ctx, a.ID, model.EntityComment, c.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
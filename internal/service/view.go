@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/aellingwood/cielo/internal/filter"
+	"github.com/aellingwood/cielo/internal/model"
+)
+
+// CreateView saves a view on the board. A private view is owned by actor
+// and hidden from everyone else.
+func (s *Service) CreateView(ctx context.Context, boardID string, spec model.ViewSpec, actor string) (*model.View, error) {
+	if _, err := s.activeBoard(ctx, boardID); err != nil {
+		return nil, err
+	}
+	v := &model.View{ID: model.NewID(), BoardID: boardID, Columns: []string{}, CreatedBy: actor}
+	if err := applyViewSpec(v, spec, actor); err != nil {
+		return nil, err
+	}
+	if v.Name == "" {
+		return nil, fmt.Errorf("view name is required")
+	}
+	err := s.inTx(ctx, func(tx *Service) error {
+		if err := tx.store.CreateView(ctx, v); err != nil {
+			return err
+		}
+		tx.publishView("view.created", v, v)
+		return tx.auditView(ctx, v.ID, model.ActionCreated, actor, nil, v)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// ListViews returns the board's shared views and those private to actor.
+func (s *Service) ListViews(ctx context.Context, boardID, actor string) ([]model.View, error) {
+	return s.store.ListViewsByBoard(ctx, boardID, actor)
+}
+
+// GetView returns a view if actor may see it. Other actors' private views
+// are reported as not found.
+func (s *Service) GetView(ctx context.Context, id, actor string) (*model.View, error) {
+	v, err := s.store.GetView(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if v.Owner != "" && v.Owner != actor {
+		return nil, fmt.Errorf("view not found: %s", id)
+	}
+	return v, nil
+}
+
+func (s *Service) UpdateView(ctx context.Context, id string, spec model.ViewSpec, actor string) (*model.View, error) {
+	v, err := s.GetView(ctx, id, actor)
+	if err != nil {
+		return nil, err
+	}
+	before := *v
+	if err := applyViewSpec(v, spec, actor); err != nil {
+		return nil, err
+	}
+	if v.Name == "" {
+		return nil, fmt.Errorf("view name is required")
+	}
+	err = s.inTx(ctx, func(tx *Service) error {
+		if err := tx.store.UpdateView(ctx, v); err != nil {
+			return err
+		}
+		tx.publishView("view.updated", v, v)
+		return tx.auditView(ctx, id, model.ActionUpdated, actor, &before, v)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func (s *Service) DeleteView(ctx context.Context, id, actor string) error {
+	v, err := s.GetView(ctx, id, actor)
+	if err != nil {
+		return err
+	}
+	return s.inTx(ctx, func(tx *Service) error {
+		if err := tx.store.DeleteView(ctx, id); err != nil {
+			return err
+		}
+		tx.publishView("view.deleted", v, map[string]string{"id": id})
+		return tx.auditView(ctx, id, model.ActionDeleted, actor, v, nil)
+	})
+}
+
+// RunView searches the view's board with its filter. The view's sort comes
+// first, ahead of any sort in the filter itself.
+func (s *Service) RunView(ctx context.Context, id, actor string, includeArchived bool, page model.Page) ([]model.Card, string, error) {
+	v, err := s.GetView(ctx, id, actor)
+	if err != nil {
+		return nil, "", err
+	}
+	return s.SearchCards(ctx, v.BoardID, "", "", "", "", viewExpr(v), includeArchived, page)
+}
+
+func viewExpr(v *model.View) string {
+	if v.Sort == "" {
+		return v.Filter
+	}
+	return "sort:" + v.Sort + " " + v.Filter
+}
+
+// publishView announces changes to shared views only, so that private views
+// stay out of the board's event stream.
+func (s *Service) publishView(typ string, v *model.View, payload any) {
+	if v.Owner == "" {
+		s.publish(typ, v.BoardID, payload)
+	}
+}
+
+// auditView records a change to a view. Changes that involve a private
+// view, before or after, are kept off the board's audit trail, which every
+// reader of the board can see.
+func (s *Service) auditView(ctx context.Context, id, action, actor string, before, after *model.View) error {
+	var boardID string
+	private := false
+	for _, v := range []*model.View{before, after} {
+		if v != nil {
+			boardID = v.BoardID
+			private = private || v.Owner != ""
+		}
+	}
+	if private {
+		boardID = ""
+	}
+	return s.audit(ctx, boardID, model.EntityView, id, action, actor, before, after)
+}
+
+// applyViewSpec sets the fields present in spec on v, validating the filter,
+// sort, and columns. Only a private view's owner may change its privacy, and
+// a shared view can only be made private by whoever created it, so no one
+// can take a view away from the rest of the board.
+func applyViewSpec(v *model.View, spec model.ViewSpec, actor string) error {
+	if spec.Name != nil {
+		v.Name = strings.TrimSpace(*spec.Name)
+	}
+	if spec.Filter != nil {
+		if _, err := filter.Parse(*spec.Filter); err != nil {
+			return err
+		}
+		v.Filter = strings.TrimSpace(*spec.Filter)
+	}
+	if spec.Sort != nil {
+		sort := strings.TrimSpace(*spec.Sort)
+		if sort != "" {
+			q, err := filter.Parse("sort:" + sort)
+			if err != nil {
+				return err
+			}
+			if q.Text != "" {
+				return fmt.Errorf("invalid sort %q; use field,-field", sort)
+			}
+		}
+		v.Sort = sort
+	}
+	if spec.Columns != nil {
+		for _, c := range *spec.Columns {
+			if !slices.Contains(model.ViewColumns, c) {
+				return fmt.Errorf("unknown column %q; columns are %s", c, strings.Join(model.ViewColumns, ", "))
+			}
+		}
+		v.Columns = append([]string{}, *spec.Columns...)
+	}
+	if spec.Private != nil {
+		if v.Owner != "" && v.Owner != actor {
+			return fmt.Errorf("only the view's owner can change its privacy")
+		}
+		if *spec.Private && v.Owner == "" && v.CreatedBy != actor {
+			return fmt.Errorf("only the view's creator can make it private")
+		}
+		v.Owner = ""
+		if *spec.Private {
+			v.Owner = actor
+		}
+	}
+	return nil
+}
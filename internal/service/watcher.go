@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/aellingwood/cielo/internal/model"
+)
+
+// ListWatchers returns the actors watching the card besides its assignee.
+func (s *Service) ListWatchers(ctx context.Context, cardID string) ([]string, error) {
+	if _, err := s.store.GetCard(ctx, cardID); err != nil {
+		return nil, err
+	}
+	return s.store.ListWatchers(ctx, cardID)
+}
+
+// WatchCard makes watcher follow the card: it is notified when the card is
+// updated, moved, or commented on.
+func (s *Service) WatchCard(ctx context.Context, cardID, watcher, actor string) ([]string, error) {
+	if watcher == "" {
+		return nil, fmt.Errorf("watcher is required")
+	}
+	c, err := s.store.GetCard(ctx, cardID)
+	if err != nil {
+		return nil, err
+	}
+	if c.DeletedAt != nil {
+		return nil, fmt.Errorf("card is in the trash: %s", cardID)
+	}
+	_, boardID, err := s.workflowForList(ctx, c.ListID)
+	if err != nil {
+		return nil, err
+	}
+	err = s.inTx(ctx, func(tx *Service) error {
+		if err := tx.store.AddWatcher(ctx, cardID, watcher); err != nil {
+			return err
+		}
+		return tx.audit(ctx, boardID, model.EntityCard, cardID, model.ActionWatched, actor, nil, map[string]string{"watcher": watcher})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.store.ListWatchers(ctx, cardID)
+}
+
+// UnwatchCard stops watcher following the card. The card's assignee keeps
+// hearing about it regardless.
+func (s *Service) UnwatchCard(ctx context.Context, cardID, watcher, actor string) ([]string, error) {
+	c, err := s.store.GetCard(ctx, cardID)
+	if err != nil {
+		return nil, err
+	}
+	_, boardID, err := s.workflowForList(ctx, c.ListID)
+	if err != nil {
+		return nil, err
+	}
+	err = s.inTx(ctx, func(tx *Service) error {
+		if err := tx.store.RemoveWatcher(ctx, cardID, watcher); err != nil {
+			return err
+		}
+		return tx.audit(ctx, boardID, model.EntityCard, cardID, model.ActionUnwatched, actor, map[string]string{"watcher": watcher}, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.store.ListWatchers(ctx, cardID)
+}
+
+// watchers returns the actors who follow changes to the card: its assignee
+// and its watchers.
+func (s *Service) watchers(ctx context.Context, c *model.Card) []string {
+	watchers, err := s.store.ListWatchers(ctx, c.ID)
+	if err != nil {
+		log.Printf("list watchers of card %s: %v", c.ID, err)
+	}
+	return append([]string{c.Assignee}, watchers...)
+}
@@ -0,0 +1,72 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/aellingwood/cielo/internal/model"
+)
+
+const attachmentColumns = "id, card_id, name, size, mime_type, sha256, url, uploaded_by, created_at"
+
+func scanAttachment(row interface{ Scan(...any) error }) (*model.Attachment, error) {
+	var a model.Attachment
+	var createdAt string
+	if err := row.Scan(&a.ID, &a.CardID, &a.Name, &a.Size, &a.MimeType, &a.SHA256, &a.URL, &a.UploadedBy, &createdAt); err != nil {
+		return nil, err
+	}
+	a.CreatedAt = parseTime(createdAt)
+	return &a, nil
+}
+
+func (s *SQLiteStore) CreateAttachment(ctx context.Context, a *model.Attachment) error {
+	ts := now()
+	if _, err := s.db.ExecContext(ctx,
+		"INSERT INTO attachments ("+attachmentColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		a.ID, a.CardID, a.Name, a.Size, a.MimeType, a.SHA256, a.URL, a.UploadedBy, ts); err != nil {
+		return err
+	}
+	a.CreatedAt = parseTime(ts)
+	return nil
+}
+
+func (s *SQLiteStore) GetAttachment(ctx context.Context, id string) (*model.Attachment, error) {
+	a, err := scanAttachment(s.db.QueryRowContext(ctx,
+		"SELECT "+attachmentColumns+" FROM attachments WHERE id = ?", id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("attachment not found: %s", id)
+	}
+	return a, err
+}
+
+// ListAttachments returns the card's attachments, oldest first.
+func (s *SQLiteStore) ListAttachments(ctx context.Context, cardID string) ([]model.Attachment, error) {
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT "+attachmentColumns+" FROM attachments WHERE card_id = ? ORDER BY created_at, id", cardID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	attachments := []model.Attachment{}
+	for rows.Next() {
+		a, err := scanAttachment(rows)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, *a)
+	}
+	return attachments, rows.Err()
+}
+
+func (s *SQLiteStore) DeleteAttachment(ctx context.Context, id string) error {
+	res, err := s.db.ExecContext(ctx, "DELETE FROM attachments WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return fmt.Errorf("attachment not found: %s", id)
+	}
+	return nil
+}
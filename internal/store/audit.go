@@ -0,0 +1,95 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/aellingwood/cielo/internal/model"
+)
+
+func nullJSON(raw []byte) any {
+	if len(raw) == 0 {
+		return nil
+	}
+	return string(raw)
+}
+
+func (s *SQLiteStore) CreateAuditEntry(ctx context.Context, entry *model.AuditEntry) error {
+	ts := now()
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO audit_log (id, board_id, entity_type, entity_id, action, actor, before, after, source, created_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		entry.ID, entry.BoardID, entry.EntityType, entry.EntityID, entry.Action, entry.Actor,
+		nullJSON(entry.Before), nullJSON(entry.After), entry.Source, ts)
+	if err != nil {
+		return err
+	}
+	entry.CreatedAt = parseTime(ts)
+	return nil
+}
+
+// ListAudit returns audit entries matching the filter, newest first.
+func (s *SQLiteStore) ListAudit(ctx context.Context, f model.AuditFilter) ([]model.AuditEntry, error) {
+	conditions := []string{`NOT (entity_type = ? AND (
+		COALESCE(json_extract(before, '$.owner'), '') NOT IN ('', ?) OR
+		COALESCE(json_extract(after, '$.owner'), '') NOT IN ('', ?)))`}
+	args := []any{model.EntityView, f.Viewer, f.Viewer}
+	if f.BoardID != "" {
+		conditions = append(conditions, "board_id = ?")
+		args = append(args, f.BoardID)
+	}
+	if f.EntityType != "" {
+		conditions = append(conditions, "entity_type = ?")
+		args = append(args, f.EntityType)
+	}
+	if f.EntityID != "" {
+		conditions = append(conditions, "entity_id = ?")
+		args = append(args, f.EntityID)
+	}
+	if f.Actor != "" {
+		conditions = append(conditions, "actor = ?")
+		args = append(args, f.Actor)
+	}
+	if f.Since != nil {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, f.Since.UTC().Format(timeLayout))
+	}
+	if f.Until != nil {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, f.Until.UTC().Format(timeLayout))
+	}
+
+	q := "SELECT id, board_id, entity_type, entity_id, action, actor, before, after, source, created_at FROM audit_log" +
+		" WHERE " + strings.Join(conditions, " AND ")
+	q += " ORDER BY created_at DESC, id DESC"
+	if f.Limit > 0 {
+		q += " LIMIT ?"
+		args = append(args, f.Limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var entries []model.AuditEntry
+	for rows.Next() {
+		var e model.AuditEntry
+		var before, after sql.NullString
+		var createdAt string
+		if err := rows.Scan(&e.ID, &e.BoardID, &e.EntityType, &e.EntityID, &e.Action, &e.Actor,
+			&before, &after, &e.Source, &createdAt); err != nil {
+			return nil, err
+		}
+		if before.Valid {
+			e.Before = []byte(before.String)
+		}
+		if after.Valid {
+			e.After = []byte(after.String)
+		}
+		e.CreatedAt = parseTime(createdAt)
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
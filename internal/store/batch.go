@@ -0,0 +1,330 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/aellingwood/cielo/internal/model"
+)
+
+// ListCardsByBoard returns the cards of all the board's lists, ordered by
+// list and then as ListCardsByList orders them, with their labels,
+// dependency counts, and checklist progress. It takes four queries however
+// large the board is.
+// Cards of lists in the trash are skipped, as are archived cards and lists
+// unless includeArchived is set. A positive perList keeps only the first
+// cards of each list; the returned map holds the ListCardsByList cursor of
+// every list that was cut short.
+func (s *SQLiteStore) ListCardsByBoard(ctx context.Context, boardID string, includeArchived bool, perList int) ([]model.Card, map[string]string, error) {
+	where := "l.board_id = ? AND l.deleted_at IS NULL AND c.deleted_at IS NULL"
+	if !includeArchived {
+		where += " AND l.archived_at IS NULL AND c.archived_at IS NULL"
+	}
+	const order = "c.archived_at IS NOT NULL, c.position, c.id"
+	next := map[string]string{}
+	if perList <= 0 {
+		cards, err := s.queryCards(ctx,
+			"SELECT "+cardColumns+" FROM cards c JOIN lists l ON c.list_id = l.id WHERE "+where+
+				" ORDER BY c.list_id, "+order, boardID)
+		if err != nil {
+			return nil, nil, err
+		}
+		return cards, next, s.attachBoardSummaries(ctx, boardID, cards)
+	}
+
+	// Number the cards within each list and fetch one beyond perList, which
+	// tells whether the list continues.
+	all, err := s.queryCards(ctx,
+		"SELECT "+cardColumns+" FROM (SELECT c.*, ROW_NUMBER() OVER (PARTITION BY c.list_id ORDER BY "+order+") AS rn"+
+			" FROM cards c JOIN lists l ON c.list_id = l.id WHERE "+where+") c"+
+			" WHERE c.rn <= ? ORDER BY c.list_id, c.rn", boardID, perList+1)
+	if err != nil {
+		return nil, nil, err
+	}
+	cards := []model.Card{}
+	n := 0
+	for i, c := range all {
+		if i == 0 || c.ListID != all[i-1].ListID {
+			n = 0
+		}
+		n++
+		if n > perList {
+			last := cards[len(cards)-1]
+			next[c.ListID] = cursor{Archived: last.ArchivedAt != nil, Position: &last.Position, ID: last.ID}.encode()
+			continue
+		}
+		cards = append(cards, c)
+	}
+	return cards, next, s.attachBoardSummaries(ctx, boardID, cards)
+}
+
+// attachBoardSummaries sets the labels, dependency counts, checklist
+// progress, and custom field values of cards, all on the board, from every
+// label assignment, dependency, checklist item, and field value on the
+// board.
+// Reading them by board rather than by card ID lets SQLite walk each table
+// once.
+func (s *SQLiteStore) attachBoardSummaries(ctx context.Context, boardID string, cards []model.Card) error {
+	labels := map[string][]model.Label{}
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT cl.card_id, l.id, l.board_id, l.name, l.color FROM labels l
+		 JOIN card_labels cl ON l.id = cl.label_id WHERE l.board_id = ?`, boardID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var cardID string
+		var l model.Label
+		if err := rows.Scan(&cardID, &l.ID, &l.BoardID, &l.Name, &l.Color); err != nil {
+			return err
+		}
+		labels[cardID] = append(labels[cardID], l)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	deps, dependents := map[string]int{}, map[string]int{}
+	rows, err = s.db.QueryContext(ctx,
+		`SELECT d.card_id, d.depends_on_card_id FROM card_dependencies d
+		 JOIN cards a ON a.id = d.card_id JOIN lists la ON la.id = a.list_id
+		 JOIN cards b ON b.id = d.depends_on_card_id JOIN lists lb ON lb.id = b.list_id
+		 WHERE (la.board_id = ?1 OR lb.board_id = ?1) AND a.deleted_at IS NULL AND b.deleted_at IS NULL`, boardID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var cardID, dependsOn string
+		if err := rows.Scan(&cardID, &dependsOn); err != nil {
+			return err
+		}
+		deps[cardID]++
+		dependents[dependsOn]++
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	done, total, err := s.countChecklists(ctx,
+		`SELECT ci.card_id, SUM(ci.done), COUNT(*) FROM checklist_items ci
+		 JOIN cards c ON c.id = ci.card_id JOIN lists l ON l.id = c.list_id
+		 WHERE l.board_id = ? GROUP BY ci.card_id`, boardID)
+	if err != nil {
+		return err
+	}
+
+	fields, err := s.queryFieldValues(ctx,
+		`SELECT v.card_id, f.name, f.type, v.value FROM card_field_values v
+		 JOIN custom_fields f ON f.id = v.field_id WHERE f.board_id = ?`, boardID)
+	if err != nil {
+		return err
+	}
+
+	for i := range cards {
+		cards[i].Labels = labels[cards[i].ID]
+		if cards[i].Labels == nil {
+			cards[i].Labels = []model.Label{}
+		}
+		cards[i].DependencyCount = deps[cards[i].ID]
+		cards[i].DependentCount = dependents[cards[i].ID]
+		cards[i].ChecklistDone = done[cards[i].ID]
+		cards[i].ChecklistTotal = total[cards[i].ID]
+		cards[i].Fields = fields[cards[i].ID]
+	}
+	return nil
+}
+
+// idList encodes IDs for binding to a json_each() table-valued function,
+// which takes any number of them as a single parameter.
+func idList(ids []string) string {
+	if ids == nil {
+		ids = []string{}
+	}
+	data, _ := json.Marshal(ids)
+	return string(data)
+}
+
+// GetLabelsForCards returns the labels of each of the cards, keyed by card
+// ID. Cards without labels are absent from the map.
+func (s *SQLiteStore) GetLabelsForCards(ctx context.Context, cardIDs []string) (map[string][]model.Label, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT cl.card_id, l.id, l.board_id, l.name, l.color FROM labels l
+		 JOIN card_labels cl ON l.id = cl.label_id WHERE cl.card_id IN (SELECT value FROM json_each(?))`, idList(cardIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	labels := map[string][]model.Label{}
+	for rows.Next() {
+		var cardID string
+		var l model.Label
+		if err := rows.Scan(&cardID, &l.ID, &l.BoardID, &l.Name, &l.Color); err != nil {
+			return nil, err
+		}
+		labels[cardID] = append(labels[cardID], l)
+	}
+	return labels, rows.Err()
+}
+
+// CountDependencies returns, for each of the cards, how many cards it
+// depends on and how many depend on it. Cards in the trash are not counted,
+// matching GetDependencies and GetDependents.
+func (s *SQLiteStore) CountDependencies(ctx context.Context, cardIDs []string) (deps, dependents map[string]int, err error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT d.card_id, 1 FROM card_dependencies d JOIN cards c ON c.id = d.depends_on_card_id
+		 WHERE d.card_id IN (SELECT value FROM json_each(?1)) AND c.deleted_at IS NULL
+		 UNION ALL
+		 SELECT d.depends_on_card_id, 0 FROM card_dependencies d JOIN cards c ON c.id = d.card_id
+		 WHERE d.depends_on_card_id IN (SELECT value FROM json_each(?1)) AND c.deleted_at IS NULL`, idList(cardIDs))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+	deps, dependents = map[string]int{}, map[string]int{}
+	for rows.Next() {
+		var cardID string
+		var blocked bool
+		if err := rows.Scan(&cardID, &blocked); err != nil {
+			return nil, nil, err
+		}
+		if blocked {
+			deps[cardID]++
+		} else {
+			dependents[cardID]++
+		}
+	}
+	return deps, dependents, rows.Err()
+}
+
+// GetCardDetail returns the card with its labels, the cards it depends on
+// and the cards that depend on it (with their board if it is another), its
+// parent and children, its checklist, its attachments, its custom field
+// values, its watchers, and its most recent activity, in at most nine
+// queries. Like GetCard, it finds archived cards
+// and cards in the trash.
+func (s *SQLiteStore) GetCardDetail(ctx context.Context, id string, activity int) (*model.Card, error) {
+	var labels string
+	c, err := s.scanCard(withExtra(s.db.QueryRowContext(ctx,
+		"SELECT "+cardColumns+`, (SELECT json_group_array(json_object('id', l.id, 'board_id', l.board_id, 'name', l.name, 'color', l.color))
+		 FROM labels l JOIN card_labels cl ON l.id = cl.label_id WHERE cl.card_id = c.id)
+		 FROM cards c WHERE c.id = ?`, id), &labels))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("card not found: %s", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(labels), &c.Labels); err != nil {
+		return nil, fmt.Errorf("card %s: invalid labels: %w", id, err)
+	}
+
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT "+cardColumns+linkedColumns+`, 1 FROM cards c JOIN card_dependencies d ON c.id = d.depends_on_card_id`+linkedJoins+`
+		 WHERE d.card_id = ?1 AND c.deleted_at IS NULL
+		 UNION ALL
+		 SELECT `+cardColumns+linkedColumns+`, 0 FROM cards c JOIN card_dependencies d ON c.id = d.card_id`+linkedJoins+`
+		 WHERE d.depends_on_card_id = ?1 AND c.deleted_at IS NULL`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	c.Dependencies, c.Dependents = []model.Card{}, []model.Card{}
+	for rows.Next() {
+		var blocker bool
+		dep, err := s.scanLinked(rows, &blocker)
+		if err != nil {
+			return nil, err
+		}
+		if blocker {
+			c.Dependencies = append(c.Dependencies, *dep)
+		} else {
+			c.Dependents = append(c.Dependents, *dep)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := s.family(ctx, c); err != nil {
+		return nil, err
+	}
+
+	c.Checklist, err = s.ListChecklistItems(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	for _, it := range c.Checklist {
+		if it.Done {
+			c.ChecklistDone++
+		}
+	}
+	c.ChecklistTotal = len(c.Checklist)
+
+	c.Attachments, err = s.ListAttachments(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := s.GetFieldValuesForCards(ctx, []string{id})
+	if err != nil {
+		return nil, err
+	}
+	c.Fields = fields[id]
+
+	c.Watchers, err = s.ListWatchers(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	c.Activity, _, err = s.ListActivityByCard(ctx, id, model.Page{Limit: activity})
+	if err != nil {
+		return nil, err
+	}
+	if c.Activity == nil {
+		c.Activity = []model.ActivityLog{}
+	}
+	return c, nil
+}
+
+// CountCardsByList tallies the active cards of each of the board's active
+// lists, keyed by list ID. Cards due before now count as overdue.
+func (s *SQLiteStore) CountCardsByList(ctx context.Context, boardID string, now time.Time) (map[string]model.CardCounts, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT c.list_id, c.status, c.priority, COUNT(*),
+		        SUM(c.due_date IS NOT NULL AND c.due_date < ?2 AND c.status != 'done'),
+		        SUM(c.status = 'blocked' OR EXISTS (
+		            SELECT 1 FROM card_dependencies d JOIN cards dc ON dc.id = d.depends_on_card_id
+		            WHERE d.card_id = c.id AND dc.status != 'done' AND dc.deleted_at IS NULL))
+		 FROM cards c JOIN lists l ON c.list_id = l.id
+		 WHERE l.board_id = ?1 AND l.archived_at IS NULL AND l.deleted_at IS NULL AND `+activeCard+`
+		 GROUP BY c.list_id, c.status, c.priority`,
+		boardID, now.UTC().Format(timeLayout))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	counts := map[string]model.CardCounts{}
+	for rows.Next() {
+		var listID, status, priority string
+		var n, overdue, blocked int
+		if err := rows.Scan(&listID, &status, &priority, &n, &overdue, &blocked); err != nil {
+			return nil, err
+		}
+		c, ok := counts[listID]
+		if !ok {
+			c = model.NewCardCounts()
+		}
+		c.Total += n
+		c.ByStatus[status] += n
+		c.ByPriority[priority] += n
+		c.Overdue += overdue
+		c.Blocked += blocked
+		counts[listID] = c
+	}
+	return counts, rows.Err()
+}
@@ -0,0 +1,117 @@
+package store_test
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/aellingwood/cielo/internal/model"
+	"github.com/aellingwood/cielo/internal/store"
+)
+
+// seedBoard creates a board with lists lists of perList cards each. Every
+// card carries two of the board's labels and depends on the card before it.
+func seedBoard(tb testing.TB, s *store.SQLiteStore, lists, perList int) (*model.Board, []model.Card) {
+	tb.Helper()
+	ctx := context.Background()
+	b := &model.Board{ID: model.NewID(), Name: "Large"}
+	if err := s.CreateBoard(ctx, b); err != nil {
+		tb.Fatal(err)
+	}
+	labels := make([]*model.Label, 8)
+	for i := range labels {
+		labels[i] = &model.Label{ID: model.NewID(), BoardID: b.ID, Name: fmt.Sprintf("label-%d", i), Color: "#6b7280"}
+		if err := s.CreateLabel(ctx, labels[i]); err != nil {
+			tb.Fatal(err)
+		}
+	}
+	var cards []model.Card
+	for i := 0; i < lists; i++ {
+		l := &model.List{ID: model.NewID(), BoardID: b.ID, Name: fmt.Sprintf("List %d", i), Position: i}
+		if err := s.CreateList(ctx, l); err != nil {
+			tb.Fatal(err)
+		}
+		for j := 0; j < perList; j++ {
+			c := &model.Card{ID: model.NewID(), ListID: l.ID, Title: fmt.Sprintf("Card %d.%d", i, j), Position: j, Status: "unassigned", Priority: "medium"}
+			if err := s.CreateCard(ctx, c); err != nil {
+				tb.Fatal(err)
+			}
+			for _, label := range []*model.Label{labels[j%8], labels[(j+3)%8]} {
+				if err := s.AddLabelToCard(ctx, c.ID, label.ID); err != nil {
+					tb.Fatal(err)
+				}
+			}
+			if n := len(cards); n > 0 {
+				if err := s.AddDependency(ctx, &model.CardDependency{ID: model.NewID(), CardID: c.ID, DependsOnCardID: cards[n-1].ID}); err != nil {
+					tb.Fatal(err)
+				}
+			}
+			cards = append(cards, *c)
+		}
+	}
+	return b, cards
+}
+
+// setupBenchDB opens a file-backed database the way the server does, so
+// that per-query overhead is representative.
+func setupBenchDB(b *testing.B) *store.SQLiteStore {
+	b.Helper()
+	db, err := store.Open(filepath.Join(b.TempDir(), "bench.db"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { db.Close() })
+	if err := store.RunMigrations(db); err != nil {
+		b.Fatal(err)
+	}
+	return store.NewSQLiteStore(db)
+}
+
+// BenchmarkLoadBoard compares loading a 50-list, 2,000-card board with its
+// labels one list and one card at a time, as ListListsByBoard used to,
+// against ListCardsByBoard, which also counts dependencies and checklist
+// items and loads custom field values.
+func BenchmarkLoadBoard(b *testing.B) {
+	s := setupBenchDB(b)
+	ctx := context.Background()
+	board, _ := seedBoard(b, s, 50, 40)
+
+	b.Run("PerCard", func(b *testing.B) {
+		queries := 0
+		for b.Loop() {
+			queries = 1
+			lists, err := s.ListListsByBoard(ctx, board.ID, false)
+			if err != nil {
+				b.Fatal(err)
+			}
+			for _, l := range lists {
+				queries++
+				cards, _, err := s.ListCardsByList(ctx, l.ID, false, model.Page{})
+				if err != nil {
+					b.Fatal(err)
+				}
+				for _, c := range cards {
+					queries++
+					if _, err := s.GetLabelsForCard(ctx, c.ID); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		}
+		b.ReportMetric(float64(queries), "queries/op")
+	})
+	b.Run("Batched", func(b *testing.B) {
+		for b.Loop() {
+			if _, err := s.ListListsByBoard(ctx, board.ID, false); err != nil {
+				b.Fatal(err)
+			}
+			if _, _, err := s.ListCardsByBoard(ctx, board.ID, false, 0); err != nil {
+				b.Fatal(err)
+			}
+		}
+		// Lists, then cards, labels, dependencies, checklists, and custom
+		// field values.
+		b.ReportMetric(6, "queries/op")
+	})
+}
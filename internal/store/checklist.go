@@ -0,0 +1,175 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/aellingwood/cielo/internal/model"
+)
+
+const checklistColumns = "id, card_id, text, done, assignee, position, completed_at, created_at, updated_at"
+
+func scanChecklistItem(row interface{ Scan(...any) error }) (*model.ChecklistItem, error) {
+	var it model.ChecklistItem
+	var completedAt sql.NullString
+	var createdAt, updatedAt string
+	if err := row.Scan(&it.ID, &it.CardID, &it.Text, &it.Done, &it.Assignee, &it.Position,
+		&completedAt, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+	it.CompletedAt = parseNullTime(completedAt)
+	it.CreatedAt = parseTime(createdAt)
+	it.UpdatedAt = parseTime(updatedAt)
+	return &it, nil
+}
+
+func nullTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	v := t.UTC().Format(timeLayout)
+	return &v
+}
+
+// CreateChecklistItem adds the item to its card's checklist at the spot pos
+// asks for, or at the end.
+func (s *SQLiteStore) CreateChecklistItem(ctx context.Context, item *model.ChecklistItem, pos model.Placement) error {
+	return s.inTx(ctx, func(tx *SQLiteStore) error {
+		ids, err := tx.checklistIDs(ctx, item.CardID, "")
+		if err != nil {
+			return err
+		}
+		ids, err = placeAt(ids, item.ID, pos, "checklist item is not on the card: %s")
+		if err != nil {
+			return err
+		}
+		ts := now()
+		if _, err := tx.db.ExecContext(ctx,
+			"INSERT INTO checklist_items ("+checklistColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+			item.ID, item.CardID, item.Text, item.Done, item.Assignee, len(ids)-1,
+			nullTime(item.CompletedAt), ts, ts); err != nil {
+			return err
+		}
+		if err := tx.renumber(ctx, "checklist_items", ids); err != nil {
+			return err
+		}
+		item.Position = indexOf(ids, item.ID)
+		item.CreatedAt = parseTime(ts)
+		item.UpdatedAt = item.CreatedAt
+		return nil
+	})
+}
+
+func (s *SQLiteStore) GetChecklistItem(ctx context.Context, id string) (*model.ChecklistItem, error) {
+	it, err := scanChecklistItem(s.db.QueryRowContext(ctx,
+		"SELECT "+checklistColumns+" FROM checklist_items WHERE id = ?", id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("checklist item not found: %s", id)
+	}
+	return it, err
+}
+
+// ListChecklistItems returns the card's checklist in order.
+func (s *SQLiteStore) ListChecklistItems(ctx context.Context, cardID string) ([]model.ChecklistItem, error) {
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT "+checklistColumns+" FROM checklist_items WHERE card_id = ? ORDER BY position, id", cardID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []model.ChecklistItem{}
+	for rows.Next() {
+		it, err := scanChecklistItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, *it)
+	}
+	return items, rows.Err()
+}
+
+func (s *SQLiteStore) UpdateChecklistItem(ctx context.Context, item *model.ChecklistItem) error {
+	ts := now()
+	res, err := s.db.ExecContext(ctx,
+		"UPDATE checklist_items SET text = ?, done = ?, assignee = ?, completed_at = ?, updated_at = ? WHERE id = ?",
+		item.Text, item.Done, item.Assignee, nullTime(item.CompletedAt), ts, item.ID)
+	if err != nil {
+		return err
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return fmt.Errorf("checklist item not found: %s", item.ID)
+	}
+	item.UpdatedAt = parseTime(ts)
+	return nil
+}
+
+// MoveChecklistItem reorders the item within its card's checklist.
+func (s *SQLiteStore) MoveChecklistItem(ctx context.Context, id string, pos model.Placement) error {
+	return s.inTx(ctx, func(tx *SQLiteStore) error {
+		it, err := tx.GetChecklistItem(ctx, id)
+		if err != nil {
+			return err
+		}
+		ids, err := tx.checklistIDs(ctx, it.CardID, id)
+		if err != nil {
+			return err
+		}
+		ids, err = placeAt(ids, id, pos, "checklist item is not on the card: %s")
+		if err != nil {
+			return err
+		}
+		return tx.renumber(ctx, "checklist_items", ids)
+	})
+}
+
+// DeleteChecklistItem removes the item and closes the gap it leaves.
+func (s *SQLiteStore) DeleteChecklistItem(ctx context.Context, id string) error {
+	return s.inTx(ctx, func(tx *SQLiteStore) error {
+		it, err := tx.GetChecklistItem(ctx, id)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.db.ExecContext(ctx, "DELETE FROM checklist_items WHERE id = ?", id); err != nil {
+			return err
+		}
+		ids, err := tx.checklistIDs(ctx, it.CardID, id)
+		if err != nil {
+			return err
+		}
+		return tx.renumber(ctx, "checklist_items", ids)
+	})
+}
+
+func (s *SQLiteStore) checklistIDs(ctx context.Context, cardID, excludeID string) ([]string, error) {
+	return s.queryIDs(ctx,
+		"SELECT id FROM checklist_items WHERE card_id = ? AND id != ? ORDER BY position, id", cardID, excludeID)
+}
+
+// CountChecklists returns, for each of the cards that has a checklist, how
+// many of its items are done and how many it has.
+func (s *SQLiteStore) CountChecklists(ctx context.Context, cardIDs []string) (done, total map[string]int, err error) {
+	return s.countChecklists(ctx,
+		"SELECT card_id, SUM(done), COUNT(*) FROM checklist_items WHERE card_id IN (SELECT value FROM json_each(?)) GROUP BY card_id",
+		idList(cardIDs))
+}
+
+func (s *SQLiteStore) countChecklists(ctx context.Context, query string, args ...any) (done, total map[string]int, err error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+	done, total = map[string]int{}, map[string]int{}
+	for rows.Next() {
+		var cardID string
+		var d, n int
+		if err := rows.Scan(&cardID, &d, &n); err != nil {
+			return nil, nil, err
+		}
+		done[cardID], total[cardID] = d, n
+	}
+	return done, total, rows.Err()
+}
@@ -0,0 +1,112 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aellingwood/cielo/internal/model"
+)
+
+const commentColumns = "id, card_id, parent_id, author, body, mentions, edited_at, deleted_at, created_at, updated_at"
+
+func scanComment(row interface{ Scan(...any) error }) (*model.Comment, error) {
+	var c model.Comment
+	var parentID, editedAt, deletedAt sql.NullString
+	var mentions, createdAt, updatedAt string
+	if err := row.Scan(&c.ID, &c.CardID, &parentID, &c.Author, &c.Body, &mentions,
+		&editedAt, &deletedAt, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(mentions), &c.Mentions); err != nil {
+		return nil, fmt.Errorf("comment %s: invalid mentions: %w", c.ID, err)
+	}
+	c.ParentID = parentID.String
+	c.EditedAt = parseNullTime(editedAt)
+	c.DeletedAt = parseNullTime(deletedAt)
+	c.CreatedAt = parseTime(createdAt)
+	c.UpdatedAt = parseTime(updatedAt)
+	return &c, nil
+}
+
+// CreateComment stores the comment, created at its CreatedAt if that is set
+// and otherwise now.
+func (s *SQLiteStore) CreateComment(ctx context.Context, c *model.Comment) error {
+	ts := stamp(c.CreatedAt)
+	if _, err := s.db.ExecContext(ctx,
+		"INSERT INTO comments ("+commentColumns+") VALUES (?, ?, NULLIF(?, ''), ?, ?, ?, ?, ?, ?, ?)",
+		c.ID, c.CardID, c.ParentID, c.Author, c.Body, encodeStrings(c.Mentions),
+		nullTime(c.EditedAt), nullTime(c.DeletedAt), ts, ts); err != nil {
+		return err
+	}
+	c.CreatedAt = parseTime(ts)
+	c.UpdatedAt = c.CreatedAt
+	return nil
+}
+
+func (s *SQLiteStore) GetComment(ctx context.Context, id string) (*model.Comment, error) {
+	c, err := scanComment(s.db.QueryRowContext(ctx,
+		"SELECT "+commentColumns+" FROM comments WHERE id = ?", id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("comment not found: %s", id)
+	}
+	return c, err
+}
+
+// ListComments returns a page of the card's comments, oldest first, and the
+// cursor of the next page if there is one. Deleted comments are included so
+// that replies to them can be placed.
+func (s *SQLiteStore) ListComments(ctx context.Context, cardID string, page model.Page) ([]model.Comment, string, error) {
+	cur, err := decodeCursor(page.Cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	q := "SELECT " + commentColumns + " FROM comments WHERE card_id = ?"
+	args := []any{cardID}
+	if cur.ID != "" {
+		q += " AND (created_at, id) > (?, ?)"
+		args = append(args, cur.CreatedAt, cur.ID)
+	}
+	limit, args := limitClause(page.Limit, args)
+	rows, err := s.db.QueryContext(ctx, q+" ORDER BY created_at, id"+limit, args...)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+	comments := []model.Comment{}
+	for rows.Next() {
+		c, err := scanComment(rows)
+		if err != nil {
+			return nil, "", err
+		}
+		comments = append(comments, *c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+	comments, more := trimPage(comments, page.Limit)
+	if !more {
+		return comments, "", nil
+	}
+	last := comments[len(comments)-1]
+	return comments, cursor{CreatedAt: last.CreatedAt.UTC().Format(timeLayout), ID: last.ID}.encode(), nil
+}
+
+// UpdateComment saves the comment's body, mentions, and edit and deletion
+// times.
+func (s *SQLiteStore) UpdateComment(ctx context.Context, c *model.Comment) error {
+	ts := now()
+	res, err := s.db.ExecContext(ctx,
+		"UPDATE comments SET body = ?, mentions = ?, edited_at = ?, deleted_at = ?, updated_at = ? WHERE id = ?",
+		c.Body, encodeStrings(c.Mentions), nullTime(c.EditedAt), nullTime(c.DeletedAt), ts, c.ID)
+	if err != nil {
+		return err
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return fmt.Errorf("comment not found: %s", c.ID)
+	}
+	c.UpdatedAt = parseTime(ts)
+	return nil
+}
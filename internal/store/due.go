@@ -0,0 +1,55 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/aellingwood/cielo/internal/model"
+)
+
+// ClaimOverdueCards returns the active cards that have come due by now and
+// have not yet been announced as overdue, keyed by board ID, and marks them
+// announced. Done cards and cards on archived or trashed lists and boards are
+// skipped. A card is claimed again if its due date is later moved past the
+// last announcement.
+func (s *SQLiteStore) ClaimOverdueCards(ctx context.Context, now time.Time) (map[string][]model.Card, error) {
+	ts := now.UTC().Format(timeLayout)
+	claimed := map[string][]model.Card{}
+	err := s.inTx(ctx, func(tx *SQLiteStore) error {
+		rows, err := tx.db.QueryContext(ctx,
+			`SELECT `+cardColumns+`, l.board_id FROM cards c
+			 JOIN lists l ON c.list_id = l.id JOIN boards b ON l.board_id = b.id
+			 WHERE c.due_date IS NOT NULL AND c.due_date <= ? AND c.status != 'done'
+			   AND (c.overdue_notified_at IS NULL OR c.overdue_notified_at < c.due_date)
+			   AND `+activeCard+` AND l.archived_at IS NULL AND l.deleted_at IS NULL AND b.deleted_at IS NULL
+			 ORDER BY c.due_date, c.id`, ts)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		var ids []string
+		for rows.Next() {
+			var boardID string
+			c, err := tx.scanCard(withExtra(rows, &boardID))
+			if err != nil {
+				return err
+			}
+			claimed[boardID] = append(claimed[boardID], *c)
+			ids = append(ids, c.ID)
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		_, err = tx.db.ExecContext(ctx,
+			"UPDATE cards SET overdue_notified_at = ? WHERE id IN (SELECT value FROM json_each(?))",
+			ts, idList(ids))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return claimed, nil
+}
@@ -0,0 +1,235 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aellingwood/cielo/internal/model"
+)
+
+const fieldColumns = "id, board_id, name, type, options, position, created_at, updated_at"
+
+func scanCustomField(row interface{ Scan(...any) error }) (*model.CustomField, error) {
+	var f model.CustomField
+	var options, createdAt, updatedAt string
+	if err := row.Scan(&f.ID, &f.BoardID, &f.Name, &f.Type, &options, &f.Position, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(options), &f.Options); err != nil {
+		return nil, fmt.Errorf("custom field %s: invalid options: %w", f.ID, err)
+	}
+	f.CreatedAt = parseTime(createdAt)
+	f.UpdatedAt = parseTime(updatedAt)
+	return &f, nil
+}
+
+// encodeStrings encodes values as a JSON array, never null.
+func encodeStrings(values []string) string {
+	if values == nil {
+		values = []string{}
+	}
+	data, _ := json.Marshal(values)
+	return string(data)
+}
+
+// CreateCustomField adds the field to its board at the spot pos asks for,
+// or at the end.
+func (s *SQLiteStore) CreateCustomField(ctx context.Context, f *model.CustomField, pos model.Placement) error {
+	return s.inTx(ctx, func(tx *SQLiteStore) error {
+		ids, err := tx.fieldIDs(ctx, f.BoardID, "")
+		if err != nil {
+			return err
+		}
+		ids, err = placeAt(ids, f.ID, pos, "custom field is not on the board: %s")
+		if err != nil {
+			return err
+		}
+		ts := now()
+		if _, err := tx.db.ExecContext(ctx,
+			"INSERT INTO custom_fields ("+fieldColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+			f.ID, f.BoardID, f.Name, f.Type, encodeStrings(f.Options), len(ids)-1, ts, ts); err != nil {
+			return err
+		}
+		if err := tx.renumber(ctx, "custom_fields", ids); err != nil {
+			return err
+		}
+		f.Position = indexOf(ids, f.ID)
+		f.CreatedAt = parseTime(ts)
+		f.UpdatedAt = f.CreatedAt
+		return nil
+	})
+}
+
+func (s *SQLiteStore) GetCustomField(ctx context.Context, id string) (*model.CustomField, error) {
+	f, err := scanCustomField(s.db.QueryRowContext(ctx,
+		"SELECT "+fieldColumns+" FROM custom_fields WHERE id = ?", id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("custom field not found: %s", id)
+	}
+	return f, err
+}
+
+// ListCustomFields returns the board's custom fields in order.
+func (s *SQLiteStore) ListCustomFields(ctx context.Context, boardID string) ([]model.CustomField, error) {
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT "+fieldColumns+" FROM custom_fields WHERE board_id = ? ORDER BY position, id", boardID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	fields := []model.CustomField{}
+	for rows.Next() {
+		f, err := scanCustomField(rows)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, *f)
+	}
+	return fields, rows.Err()
+}
+
+// UpdateCustomField saves the field's name and options.
+func (s *SQLiteStore) UpdateCustomField(ctx context.Context, f *model.CustomField) error {
+	ts := now()
+	res, err := s.db.ExecContext(ctx,
+		"UPDATE custom_fields SET name = ?, options = ?, updated_at = ? WHERE id = ?",
+		f.Name, encodeStrings(f.Options), ts, f.ID)
+	if err != nil {
+		return err
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return fmt.Errorf("custom field not found: %s", f.ID)
+	}
+	f.UpdatedAt = parseTime(ts)
+	return nil
+}
+
+// MoveCustomField reorders the field among its board's fields.
+func (s *SQLiteStore) MoveCustomField(ctx context.Context, id string, pos model.Placement) error {
+	return s.inTx(ctx, func(tx *SQLiteStore) error {
+		f, err := tx.GetCustomField(ctx, id)
+		if err != nil {
+			return err
+		}
+		ids, err := tx.fieldIDs(ctx, f.BoardID, id)
+		if err != nil {
+			return err
+		}
+		ids, err = placeAt(ids, id, pos, "custom field is not on the board: %s")
+		if err != nil {
+			return err
+		}
+		return tx.renumber(ctx, "custom_fields", ids)
+	})
+}
+
+// DeleteCustomField removes the field along with the cards' values for it.
+func (s *SQLiteStore) DeleteCustomField(ctx context.Context, id string) error {
+	return s.inTx(ctx, func(tx *SQLiteStore) error {
+		f, err := tx.GetCustomField(ctx, id)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.db.ExecContext(ctx, "DELETE FROM custom_fields WHERE id = ?", id); err != nil {
+			return err
+		}
+		ids, err := tx.fieldIDs(ctx, f.BoardID, id)
+		if err != nil {
+			return err
+		}
+		return tx.renumber(ctx, "custom_fields", ids)
+	})
+}
+
+func (s *SQLiteStore) fieldIDs(ctx context.Context, boardID, excludeID string) ([]string, error) {
+	return s.queryIDs(ctx,
+		"SELECT id FROM custom_fields WHERE board_id = ? AND id != ? ORDER BY position, id", boardID, excludeID)
+}
+
+// SetCardFieldValues sets the card's values for the given fields, keyed by
+// field ID. Values are strings, float64s, or times; nil removes the value.
+func (s *SQLiteStore) SetCardFieldValues(ctx context.Context, cardID string, values map[string]any) error {
+	return s.inTx(ctx, func(tx *SQLiteStore) error {
+		for fieldID, v := range values {
+			var err error
+			if v == nil {
+				_, err = tx.db.ExecContext(ctx,
+					"DELETE FROM card_field_values WHERE card_id = ? AND field_id = ?", cardID, fieldID)
+			} else {
+				var text string
+				text, err = encodeFieldValue(v)
+				if err != nil {
+					return err
+				}
+				_, err = tx.db.ExecContext(ctx,
+					`INSERT INTO card_field_values (card_id, field_id, value) VALUES (?, ?, ?)
+					 ON CONFLICT (card_id, field_id) DO UPDATE SET value = excluded.value`, cardID, fieldID, text)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetFieldValuesForCards returns the custom field values of each of the
+// cards, keyed by card ID and then by field name. Cards without values are
+// absent from the map.
+func (s *SQLiteStore) GetFieldValuesForCards(ctx context.Context, cardIDs []string) (map[string]map[string]any, error) {
+	return s.queryFieldValues(ctx,
+		`SELECT v.card_id, f.name, f.type, v.value FROM card_field_values v
+		 JOIN custom_fields f ON f.id = v.field_id WHERE v.card_id IN (SELECT value FROM json_each(?))`, idList(cardIDs))
+}
+
+func (s *SQLiteStore) queryFieldValues(ctx context.Context, query string, args ...any) (map[string]map[string]any, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	values := map[string]map[string]any{}
+	for rows.Next() {
+		var cardID, name, typ, text string
+		if err := rows.Scan(&cardID, &name, &typ, &text); err != nil {
+			return nil, err
+		}
+		if values[cardID] == nil {
+			values[cardID] = map[string]any{}
+		}
+		values[cardID][name] = decodeFieldValue(typ, text)
+	}
+	return values, rows.Err()
+}
+
+// encodeFieldValue turns a value into the text stored for it. Numbers use
+// their shortest decimal form and dates the timestamp layout, so that both
+// can be compared in SQL.
+func encodeFieldValue(v any) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case time.Time:
+		return v.UTC().Format(timeLayout), nil
+	}
+	return "", fmt.Errorf("unsupported custom field value %v (%T)", v, v)
+}
+
+func decodeFieldValue(typ, text string) any {
+	switch typ {
+	case model.FieldNumber:
+		if n, err := strconv.ParseFloat(text, 64); err == nil {
+			return n
+		}
+	case model.FieldDate:
+		return parseTime(text)
+	}
+	return text
+}
@@ -0,0 +1,112 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aellingwood/cielo/internal/model"
+)
+
+// SetCardParent makes parentID the parent of the card, or detaches it when
+// parentID is empty. A card cannot become its own ancestor.
+func (s *SQLiteStore) SetCardParent(ctx context.Context, cardID, parentID string) error {
+	return s.inTx(ctx, func(tx *SQLiteStore) error {
+		if parentID != "" {
+			// Walk up from the new parent; meeting the card means a cycle.
+			var cycle bool
+			err := tx.db.QueryRowContext(ctx,
+				`WITH RECURSIVE ancestors(id) AS (
+				     SELECT ?1
+				     UNION
+				     SELECT c.parent_id FROM cards c JOIN ancestors a ON c.id = a.id WHERE c.parent_id IS NOT NULL
+				 )
+				 SELECT EXISTS (SELECT 1 FROM ancestors WHERE id = ?2)`, parentID, cardID).Scan(&cycle)
+			if err != nil {
+				return err
+			}
+			if cycle {
+				return fmt.Errorf("card %s cannot be a child of its own descendant %s", cardID, parentID)
+			}
+		}
+		res, err := tx.db.ExecContext(ctx,
+			"UPDATE cards SET parent_id = NULLIF(?, ''), updated_at = ? WHERE id = ?", parentID, now(), cardID)
+		if err != nil {
+			return err
+		}
+		n, _ := res.RowsAffected()
+		if n == 0 {
+			return fmt.Errorf("card not found: %s", cardID)
+		}
+		return nil
+	})
+}
+
+// ListChildren returns the card's children that are not in the trash,
+// ordered by list and then by position.
+func (s *SQLiteStore) ListChildren(ctx context.Context, parentID string) ([]model.Card, error) {
+	return s.queryCards(ctx,
+		"SELECT "+cardColumns+` FROM cards c JOIN lists l ON c.list_id = l.id
+		 WHERE c.parent_id = ? AND c.deleted_at IS NULL
+		 ORDER BY l.position, c.archived_at IS NOT NULL, c.position, c.id`, parentID)
+}
+
+// GetRollup counts the card's descendants by status.
+func (s *SQLiteStore) GetRollup(ctx context.Context, cardID string) (*model.Rollup, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`WITH RECURSIVE tree(id, depth) AS (
+		     SELECT id, 1 FROM cards WHERE parent_id = ?1 AND deleted_at IS NULL
+		     UNION
+		     SELECT c.id, t.depth + 1 FROM cards c JOIN tree t ON c.parent_id = t.id
+		     WHERE c.deleted_at IS NULL AND c.id != ?1
+		 )
+		 SELECT c.status, COUNT(*), SUM(t.depth = 1) FROM tree t JOIN cards c ON c.id = t.id
+		 GROUP BY c.status`, cardID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	r := &model.Rollup{CardID: cardID, ByStatus: map[string]int{}}
+	for rows.Next() {
+		var status string
+		var n, children int
+		if err := rows.Scan(&status, &n, &children); err != nil {
+			return nil, err
+		}
+		r.ByStatus[status] = n
+		r.Total += n
+		r.Children += children
+		if status == model.StatusDone {
+			r.Done += n
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if r.Total > 0 {
+		r.Percent = r.Done * 100 / r.Total
+	}
+	return r, nil
+}
+
+// family sets the headlines of the card's parent and children for
+// GetCardDetail. Cards in the trash are left out.
+func (s *SQLiteStore) family(ctx context.Context, c *model.Card) error {
+	if c.ParentID != "" {
+		p, err := s.GetCard(ctx, c.ParentID)
+		if err != nil {
+			return err
+		}
+		if p.DeletedAt == nil {
+			h := p.Headline()
+			c.Parent = &h
+		}
+	}
+	children, err := s.ListChildren(ctx, c.ID)
+	if err != nil {
+		return err
+	}
+	for i := range children {
+		c.Children = append(c.Children, children[i].Headline())
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+)
+
+// GetImportRef returns the ID of the entity imported from the source's
+// entity externalID, or "" if none was.
+func (s *SQLiteStore) GetImportRef(ctx context.Context, source, externalID string) (string, error) {
+	var id string
+	err := s.db.QueryRowContext(ctx,
+		"SELECT entity_id FROM import_refs WHERE source = ? AND external_id = ?", source, externalID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return id, err
+}
+
+// SetImportRef records that the entity entityID was imported from the
+// source's entity externalID, replacing any entity recorded before.
+func (s *SQLiteStore) SetImportRef(ctx context.Context, source, externalID, entityType, entityID string) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO import_refs (source, external_id, entity_type, entity_id) VALUES (?, ?, ?, ?)
+		 ON CONFLICT (source, external_id) DO UPDATE SET entity_type = excluded.entity_type, entity_id = excluded.entity_id`,
+		source, externalID, entityType, entityID)
+	return err
+}
@@ -1,14 +1,37 @@
 package store
 
 import (
+	"context"
 	"database/sql"
 	"io/fs"
 	"sort"
+	"strings"
 
 	"github.com/aellingwood/cielo/migrations"
 )
 
+// RunMigrations applies every embedded migration that has not been recorded
+// in schema_migrations yet. Each migration is recorded in the same
+// transaction that applies it, so an interrupted run leaves it either fully
+// applied or not at all. All files run on a single connection so that
+// migrations which rebuild tables can toggle connection-scoped pragmas such
+// as foreign_keys; those pragmas run outside the transaction.
 func RunMigrations(db *sql.DB) error {
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx,
+		`CREATE TABLE IF NOT EXISTS schema_migrations (
+			version    TEXT PRIMARY KEY,
+			applied_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now'))
+		)`); err != nil {
+		return err
+	}
+
 	entries, err := fs.ReadDir(migrations.FS, ".")
 	if err != nil {
 		return err
@@ -23,13 +46,70 @@ func RunMigrations(db *sql.DB) error {
 	sort.Strings(files)
 
 	for _, f := range files {
+		var applied int
+		if err := conn.QueryRowContext(ctx,
+			"SELECT COUNT(*) FROM schema_migrations WHERE version = ?", f).Scan(&applied); err != nil {
+			return err
+		}
+		if applied > 0 {
+			continue
+		}
 		data, err := fs.ReadFile(migrations.FS, f)
 		if err != nil {
 			return err
 		}
-		if _, err := db.Exec(string(data)); err != nil {
+		before, body, after := splitMigration(string(data))
+		if _, err := conn.ExecContext(ctx, before); err != nil {
 			return err
 		}
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, body); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if _, err := tx.ExecContext(ctx,
+			"INSERT INTO schema_migrations (version) VALUES (?)", f); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+		if _, err := conn.ExecContext(ctx, after); err != nil {
+			return err
+		}
+	}
+	_, err = conn.ExecContext(ctx, "PRAGMA foreign_keys = ON")
+	return err
+}
+
+// splitMigration separates the pragmas at the start and end of a script,
+// which cannot change inside a transaction, from the body that runs in one.
+// A file may wrap its body in BEGIN; and COMMIT; lines of its own; those
+// are dropped in favour of the runner's transaction.
+func splitMigration(script string) (before, body, after string) {
+	lines := strings.Split(script, "\n")
+	var pre, post []string
+	i := 0
+	for ; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(line, "PRAGMA") {
+			pre = append(pre, line)
+		} else if line != "" && line != "BEGIN;" && !strings.HasPrefix(line, "--") {
+			break
+		}
+	}
+	j := len(lines)
+	for ; j > i; j-- {
+		line := strings.TrimSpace(lines[j-1])
+		if strings.HasPrefix(line, "PRAGMA") {
+			post = append([]string{line}, post...)
+		} else if line != "" && line != "COMMIT;" && !strings.HasPrefix(line, "--") {
+			break
+		}
 	}
-	return nil
+	return strings.Join(pre, "\n"), strings.Join(lines[i:j], "\n"), strings.Join(post, "\n")
 }
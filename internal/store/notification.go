@@ -0,0 +1,97 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/aellingwood/cielo/internal/model"
+)
+
+const notificationColumns = "id, recipient, type, board_id, card_id, comment_id, actor, message, read_at, created_at"
+
+func scanNotification(row interface{ Scan(...any) error }) (*model.Notification, error) {
+	var n model.Notification
+	var readAt sql.NullString
+	var createdAt string
+	if err := row.Scan(&n.ID, &n.Recipient, &n.Type, &n.BoardID, &n.CardID, &n.CommentID, &n.Actor, &n.Message,
+		&readAt, &createdAt); err != nil {
+		return nil, err
+	}
+	n.ReadAt = parseNullTime(readAt)
+	n.CreatedAt = parseTime(createdAt)
+	return &n, nil
+}
+
+// CreateNotifications stores the notifications together.
+func (s *SQLiteStore) CreateNotifications(ctx context.Context, notifications []*model.Notification) error {
+	return s.inTx(ctx, func(tx *SQLiteStore) error {
+		ts := now()
+		for _, n := range notifications {
+			if _, err := tx.db.ExecContext(ctx,
+				"INSERT INTO notifications ("+notificationColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, NULL, ?)",
+				n.ID, n.Recipient, n.Type, n.BoardID, n.CardID, n.CommentID, n.Actor, n.Message, ts); err != nil {
+				return err
+			}
+			n.CreatedAt = parseTime(ts)
+		}
+		return nil
+	})
+}
+
+// ListNotifications returns a page of the recipient's notifications, newest
+// first, and the cursor of the next page if there is one.
+func (s *SQLiteStore) ListNotifications(ctx context.Context, recipient string, unreadOnly bool, page model.Page) ([]model.Notification, string, error) {
+	cur, err := decodeCursor(page.Cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	q := "SELECT " + notificationColumns + " FROM notifications WHERE recipient = ?"
+	args := []any{recipient}
+	if unreadOnly {
+		q += " AND read_at IS NULL"
+	}
+	if cur.ID != "" {
+		q += " AND (created_at, id) < (?, ?)"
+		args = append(args, cur.CreatedAt, cur.ID)
+	}
+	limit, args := limitClause(page.Limit, args)
+	rows, err := s.db.QueryContext(ctx, q+" ORDER BY created_at DESC, id DESC"+limit, args...)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+	notifications := []model.Notification{}
+	for rows.Next() {
+		n, err := scanNotification(rows)
+		if err != nil {
+			return nil, "", err
+		}
+		notifications = append(notifications, *n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+	notifications, more := trimPage(notifications, page.Limit)
+	if !more {
+		return notifications, "", nil
+	}
+	last := notifications[len(notifications)-1]
+	return notifications, cursor{CreatedAt: last.CreatedAt.UTC().Format(timeLayout), ID: last.ID}.encode(), nil
+}
+
+// AckNotifications marks the recipient's notifications with the given IDs
+// as read, or all of them when ids is empty, and returns how many were
+// unread.
+func (s *SQLiteStore) AckNotifications(ctx context.Context, recipient string, ids []string) (int64, error) {
+	q := "UPDATE notifications SET read_at = ? WHERE recipient = ? AND read_at IS NULL"
+	args := []any{now(), recipient}
+	if len(ids) > 0 {
+		q += " AND id IN (SELECT value FROM json_each(?))"
+		args = append(args, idList(ids))
+	}
+	res, err := s.db.ExecContext(ctx, q, args...)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
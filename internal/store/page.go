@@ -0,0 +1,59 @@
+package store
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aellingwood/cielo/internal/model"
+)
+
+// cursor is the decoded form of an opaque page cursor. Keyset listings
+// resume after the row identified by its sort key and UUIDv7 ID; searches
+// carry the values of all their sort keys in Keys. Ranked search results,
+// whose order has no stable key, resume at an offset.
+type cursor struct {
+	CreatedAt string `json:"t,omitempty"`
+	Position  *int   `json:"p,omitempty"`
+	Archived  bool   `json:"a,omitempty"`
+	Keys      []any  `json:"k,omitempty"`
+	Offset    int    `json:"o,omitempty"`
+	ID        string `json:"id,omitempty"`
+}
+
+func (c cursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor decodes s, which must be empty or produced by encode. An
+// empty cursor decodes to the zero value.
+func decodeCursor(s string) (cursor, error) {
+	var c cursor
+	if s == "" {
+		return c, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || json.Unmarshal(data, &c) != nil {
+		return c, fmt.Errorf("%w: %s", model.ErrInvalidCursor, s)
+	}
+	return c, nil
+}
+
+// limitClause returns a LIMIT, with its argument appended to args, that
+// fetches one row beyond the page so that callers can tell whether another
+// page follows.
+func limitClause(limit int, args []any) (string, []any) {
+	if limit <= 0 {
+		return "", args
+	}
+	return " LIMIT ?", append(args, limit+1)
+}
+
+// trimPage cuts items down to limit and reports whether it did.
+func trimPage[T any](items []T, limit int) ([]T, bool) {
+	if limit <= 0 || len(items) <= limit {
+		return items, false
+	}
+	return items[:limit], true
+}
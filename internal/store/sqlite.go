@@ -3,23 +3,126 @@ package store
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
 
+	"github.com/aellingwood/cielo/internal/filter"
 	"github.com/aellingwood/cielo/internal/model"
 )
 
 const timeLayout = "2006-01-02T15:04:05.000Z"
 
+// dbtx is the subset of *sql.DB and *sql.Tx the store queries through, so
+// the same methods run inside or outside a transaction.
+type dbtx interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
 type SQLiteStore struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewSQLiteStore(db *sql.DB) *SQLiteStore {
 	return &SQLiteStore{db: db}
 }
 
+// Open opens the database at path with the settings the store relies on.
+// Pragmas are applied per connection so every pooled connection enforces
+// foreign keys, and transactions take the write lock up front so concurrent
+// reorders queue behind each other instead of failing.
+func Open(path string) (*sql.DB, error) {
+	dsn := path + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_txlock=immediate"
+	return sql.Open("sqlite", dsn)
+}
+
+// inTx runs fn against a store bound to a transaction. If s is already
+// bound to one, fn joins it.
+func (s *SQLiteStore) inTx(ctx context.Context, fn func(tx *SQLiteStore) error) error {
+	db, ok := s.db.(*sql.DB)
+	if !ok {
+		return fn(s)
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(&SQLiteStore{db: tx}); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) InTx(ctx context.Context, fn func(tx Store) error) error {
+	return s.inTx(ctx, func(tx *SQLiteStore) error { return fn(tx) })
+}
+
+func indexOf(ids []string, id string) int {
+	for i, v := range ids {
+		if v == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// placeAt inserts id into the ordered sibling IDs at the spot pos asks for.
+// notFound formats the error when a Before/After reference is not a sibling.
+func placeAt(ids []string, id string, pos model.Placement, notFound string) ([]string, error) {
+	idx := len(ids)
+	switch {
+	case pos.Before != "":
+		idx = indexOf(ids, pos.Before)
+		if idx < 0 {
+			return nil, fmt.Errorf(notFound, pos.Before)
+		}
+	case pos.After != "":
+		idx = indexOf(ids, pos.After)
+		if idx < 0 {
+			return nil, fmt.Errorf(notFound, pos.After)
+		}
+		idx++
+	case pos.Position != nil:
+		idx = min(max(*pos.Position, 0), len(ids))
+	}
+	out := make([]string, 0, len(ids)+1)
+	out = append(out, ids[:idx]...)
+	out = append(out, id)
+	return append(out, ids[idx:]...), nil
+}
+
+func (s *SQLiteStore) queryIDs(ctx context.Context, query string, args ...any) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// renumber rewrites positions in table so the given IDs are ordered 0..n-1.
+func (s *SQLiteStore) renumber(ctx context.Context, table string, ids []string) error {
+	q := "UPDATE " + table + " SET position = ? WHERE id = ? AND position != ?"
+	for i, id := range ids {
+		if _, err := s.db.ExecContext(ctx, q, i, id, i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func parseTime(s string) time.Time {
 	t, _ := time.Parse(timeLayout, s)
 	return t
@@ -29,64 +132,131 @@ func now() string {
 	return time.Now().UTC().Format(timeLayout)
 }
 
-// --- Boards ---
+// stamp formats t for storage, or the current time if t is zero, so that
+// copied and imported records can keep their original times.
+func stamp(t time.Time) string {
+	if t.IsZero() {
+		return now()
+	}
+	return t.UTC().Format(timeLayout)
+}
 
-func (s *SQLiteStore) CreateBoard(ctx context.Context, board *model.Board) error {
-	ts := now()
-	_, err := s.db.ExecContext(ctx,
-		"INSERT INTO boards (id, name, description, created_at, updated_at) VALUES (?, ?, ?, ?, ?)",
-		board.ID, board.Name, board.Description, ts, ts)
-	if err != nil {
-		return err
+func parseNullTime(s sql.NullString) *time.Time {
+	if !s.Valid {
+		return nil
 	}
-	board.CreatedAt = parseTime(ts)
-	board.UpdatedAt = parseTime(ts)
-	return nil
+	t := parseTime(s.String)
+	return &t
 }
 
-func (s *SQLiteStore) GetBoard(ctx context.Context, id string) (*model.Board, error) {
-	var b model.Board
-	var createdAt, updatedAt string
-	err := s.db.QueryRowContext(ctx,
-		"SELECT id, name, description, created_at, updated_at FROM boards WHERE id = ?", id).
-		Scan(&b.ID, &b.Name, &b.Description, &createdAt, &updatedAt)
-	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("board not found: %s", id)
+func encodeWorkflow(w *model.Workflow) string {
+	if w == nil {
+		return ""
 	}
-	if err != nil {
+	data, _ := json.Marshal(w)
+	return string(data)
+}
+
+func decodeWorkflow(s string) *model.Workflow {
+	if s == "" {
+		return nil
+	}
+	var w model.Workflow
+	if err := json.Unmarshal([]byte(s), &w); err != nil {
+		return nil
+	}
+	return &w
+}
+
+// --- Boards ---
+
+const boardColumns = "id, name, description, workflow, deleted_at, created_at, updated_at"
+
+func scanBoard(row interface{ Scan(...any) error }) (*model.Board, error) {
+	var b model.Board
+	var workflow, createdAt, updatedAt string
+	var deletedAt sql.NullString
+	if err := row.Scan(&b.ID, &b.Name, &b.Description, &workflow, &deletedAt, &createdAt, &updatedAt); err != nil {
 		return nil, err
 	}
+	b.Workflow = decodeWorkflow(workflow)
+	b.DeletedAt = parseNullTime(deletedAt)
 	b.CreatedAt = parseTime(createdAt)
 	b.UpdatedAt = parseTime(updatedAt)
 	return &b, nil
 }
 
-func (s *SQLiteStore) ListBoards(ctx context.Context) ([]model.Board, error) {
-	rows, err := s.db.QueryContext(ctx,
-		"SELECT id, name, description, created_at, updated_at FROM boards ORDER BY created_at ASC")
+func (s *SQLiteStore) queryBoards(ctx context.Context, query string, args ...any) ([]model.Board, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 	var boards []model.Board
 	for rows.Next() {
-		var b model.Board
-		var createdAt, updatedAt string
-		if err := rows.Scan(&b.ID, &b.Name, &b.Description, &createdAt, &updatedAt); err != nil {
+		b, err := scanBoard(rows)
+		if err != nil {
 			return nil, err
 		}
-		b.CreatedAt = parseTime(createdAt)
-		b.UpdatedAt = parseTime(updatedAt)
-		boards = append(boards, b)
+		boards = append(boards, *b)
 	}
 	return boards, rows.Err()
 }
 
+func (s *SQLiteStore) CreateBoard(ctx context.Context, board *model.Board) error {
+	ts := now()
+	_, err := s.db.ExecContext(ctx,
+		"INSERT INTO boards (id, name, description, workflow, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)",
+		board.ID, board.Name, board.Description, encodeWorkflow(board.Workflow), ts, ts)
+	if err != nil {
+		return err
+	}
+	board.CreatedAt = parseTime(ts)
+	board.UpdatedAt = parseTime(ts)
+	return nil
+}
+
+// GetBoard returns the board even if it is in the trash; check DeletedAt.
+func (s *SQLiteStore) GetBoard(ctx context.Context, id string) (*model.Board, error) {
+	b, err := scanBoard(s.db.QueryRowContext(ctx,
+		"SELECT "+boardColumns+" FROM boards WHERE id = ?", id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("board not found: %s", id)
+	}
+	return b, err
+}
+
+// ListBoards returns a page of boards outside the trash, oldest first, and
+// the cursor of the next page if there is one.
+func (s *SQLiteStore) ListBoards(ctx context.Context, page model.Page) ([]model.Board, string, error) {
+	cur, err := decodeCursor(page.Cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	q := "SELECT " + boardColumns + " FROM boards WHERE deleted_at IS NULL"
+	var args []any
+	if cur.ID != "" {
+		q += " AND (created_at, id) > (?, ?)"
+		args = append(args, cur.CreatedAt, cur.ID)
+	}
+	limit, args := limitClause(page.Limit, args)
+	boards, err := s.queryBoards(ctx, q+" ORDER BY created_at ASC, id ASC"+limit, args...)
+	if err != nil {
+		return nil, "", err
+	}
+	boards, more := trimPage(boards, page.Limit)
+	if !more {
+		return boards, "", nil
+	}
+	last := boards[len(boards)-1]
+	return boards, cursor{CreatedAt: last.CreatedAt.UTC().Format(timeLayout), ID: last.ID}.encode(), nil
+}
+
 func (s *SQLiteStore) UpdateBoard(ctx context.Context, board *model.Board) error {
 	ts := now()
 	res, err := s.db.ExecContext(ctx,
-		"UPDATE boards SET name = ?, description = ?, updated_at = ? WHERE id = ?",
-		board.Name, board.Description, ts, board.ID)
+		"UPDATE boards SET name = ?, description = ?, workflow = ?, updated_at = ? WHERE id = ?",
+		board.Name, board.Description, encodeWorkflow(board.Workflow), ts, board.ID)
 	if err != nil {
 		return err
 	}
@@ -98,70 +268,120 @@ func (s *SQLiteStore) UpdateBoard(ctx context.Context, board *model.Board) error
 	return nil
 }
 
+// DeleteBoard moves the board to the trash. Its lists and cards stay
+// untouched and come back with it on restore.
 func (s *SQLiteStore) DeleteBoard(ctx context.Context, id string) error {
-	_, err := s.db.ExecContext(ctx, "DELETE FROM boards WHERE id = ?", id)
-	return err
+	return s.setLifecycle(ctx, "boards", "deleted_at", id, true)
 }
 
-// --- Lists ---
+func (s *SQLiteStore) RestoreBoard(ctx context.Context, id string) error {
+	return s.setLifecycle(ctx, "boards", "deleted_at", id, false)
+}
 
-func (s *SQLiteStore) CreateList(ctx context.Context, list *model.List) error {
-	ts := now()
-	_, err := s.db.ExecContext(ctx,
-		"INSERT INTO lists (id, board_id, name, position, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)",
-		list.ID, list.BoardID, list.Name, list.Position, ts, ts)
+func (s *SQLiteStore) ListDeletedBoards(ctx context.Context) ([]model.Board, error) {
+	return s.queryBoards(ctx,
+		"SELECT "+boardColumns+" FROM boards WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC")
+}
+
+// setLifecycle sets or clears a lifecycle timestamp column (archived_at or
+// deleted_at) on a row that has no ordering of its own.
+func (s *SQLiteStore) setLifecycle(ctx context.Context, table, column, id string, on bool) error {
+	var ts any
+	if on {
+		ts = now()
+	}
+	res, err := s.db.ExecContext(ctx,
+		"UPDATE "+table+" SET "+column+" = ?, updated_at = ? WHERE id = ?", ts, now(), id)
 	if err != nil {
 		return err
 	}
-	list.CreatedAt = parseTime(ts)
-	list.UpdatedAt = parseTime(ts)
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return fmt.Errorf("%s not found: %s", strings.TrimSuffix(table, "s"), id)
+	}
 	return nil
 }
 
-func (s *SQLiteStore) GetList(ctx context.Context, id string) (*model.List, error) {
+// --- Lists ---
+
+const listColumns = "l.id, l.board_id, l.name, l.position, l.status, l.archived_at, l.deleted_at, l.created_at, l.updated_at"
+
+// activeList matches lists that are neither archived nor in the trash.
+const activeList = "l.archived_at IS NULL AND l.deleted_at IS NULL"
+
+func scanList(row interface{ Scan(...any) error }) (*model.List, error) {
 	var l model.List
 	var createdAt, updatedAt string
-	err := s.db.QueryRowContext(ctx,
-		"SELECT id, board_id, name, position, created_at, updated_at FROM lists WHERE id = ?", id).
-		Scan(&l.ID, &l.BoardID, &l.Name, &l.Position, &createdAt, &updatedAt)
-	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("list not found: %s", id)
-	}
-	if err != nil {
+	var archivedAt, deletedAt sql.NullString
+	if err := row.Scan(&l.ID, &l.BoardID, &l.Name, &l.Position, &l.Status,
+		&archivedAt, &deletedAt, &createdAt, &updatedAt); err != nil {
 		return nil, err
 	}
+	l.ArchivedAt = parseNullTime(archivedAt)
+	l.DeletedAt = parseNullTime(deletedAt)
 	l.CreatedAt = parseTime(createdAt)
 	l.UpdatedAt = parseTime(updatedAt)
 	return &l, nil
 }
 
-func (s *SQLiteStore) ListListsByBoard(ctx context.Context, boardID string) ([]model.List, error) {
-	rows, err := s.db.QueryContext(ctx,
-		"SELECT id, board_id, name, position, created_at, updated_at FROM lists WHERE board_id = ? ORDER BY position ASC",
-		boardID)
+func (s *SQLiteStore) queryLists(ctx context.Context, query string, args ...any) ([]model.List, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 	var lists []model.List
 	for rows.Next() {
-		var l model.List
-		var createdAt, updatedAt string
-		if err := rows.Scan(&l.ID, &l.BoardID, &l.Name, &l.Position, &createdAt, &updatedAt); err != nil {
+		l, err := scanList(rows)
+		if err != nil {
 			return nil, err
 		}
-		l.CreatedAt = parseTime(createdAt)
-		l.UpdatedAt = parseTime(updatedAt)
-		lists = append(lists, l)
+		lists = append(lists, *l)
 	}
 	return lists, rows.Err()
 }
 
+// CreateList appends the list after the board's existing lists.
+func (s *SQLiteStore) CreateList(ctx context.Context, list *model.List) error {
+	ts := now()
+	err := s.db.QueryRowContext(ctx,
+		`INSERT INTO lists (id, board_id, name, position, status, created_at, updated_at)
+		 VALUES (?, ?, ?, (SELECT COALESCE(MAX(position) + 1, 0) FROM lists l WHERE board_id = ? AND `+activeList+`), ?, ?, ?)
+		 RETURNING position`,
+		list.ID, list.BoardID, list.Name, list.BoardID, list.Status, ts, ts).Scan(&list.Position)
+	if err != nil {
+		return err
+	}
+	list.CreatedAt = parseTime(ts)
+	list.UpdatedAt = parseTime(ts)
+	return nil
+}
+
+// GetList returns the list even if it is archived or in the trash.
+func (s *SQLiteStore) GetList(ctx context.Context, id string) (*model.List, error) {
+	l, err := scanList(s.db.QueryRowContext(ctx,
+		"SELECT "+listColumns+" FROM lists l WHERE l.id = ?", id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("list not found: %s", id)
+	}
+	return l, err
+}
+
+// ListListsByBoard returns the board's lists in order. Lists in the trash
+// are never included; archived lists only when includeArchived is set.
+func (s *SQLiteStore) ListListsByBoard(ctx context.Context, boardID string, includeArchived bool) ([]model.List, error) {
+	q := "SELECT " + listColumns + " FROM lists l WHERE l.board_id = ? AND l.deleted_at IS NULL"
+	if !includeArchived {
+		q += " AND l.archived_at IS NULL"
+	}
+	return s.queryLists(ctx, q+" ORDER BY l.archived_at IS NOT NULL, l.position ASC", boardID)
+}
+
 func (s *SQLiteStore) UpdateList(ctx context.Context, list *model.List) error {
 	ts := now()
 	res, err := s.db.ExecContext(ctx,
-		"UPDATE lists SET name = ?, position = ?, updated_at = ? WHERE id = ?",
-		list.Name, list.Position, ts, list.ID)
+		"UPDATE lists SET name = ?, status = ?, updated_at = ? WHERE id = ?",
+		list.Name, list.Status, ts, list.ID)
 	if err != nil {
 		return err
 	}
@@ -173,13 +393,84 @@ func (s *SQLiteStore) UpdateList(ctx context.Context, list *model.List) error {
 	return nil
 }
 
+func (s *SQLiteStore) activeListIDs(ctx context.Context, boardID, excludeID string) ([]string, error) {
+	return s.queryIDs(ctx,
+		"SELECT l.id FROM lists l WHERE l.board_id = ? AND l.id != ? AND "+activeList+" ORDER BY l.position ASC, l.id ASC",
+		boardID, excludeID)
+}
+
+// MoveList reorders a list within its board and renumbers its siblings.
+func (s *SQLiteStore) MoveList(ctx context.Context, listID string, pos model.Placement) error {
+	return s.inTx(ctx, func(tx *SQLiteStore) error {
+		l, err := tx.GetList(ctx, listID)
+		if err != nil {
+			return err
+		}
+		ids, err := tx.activeListIDs(ctx, l.BoardID, listID)
+		if err != nil {
+			return err
+		}
+		ids, err = placeAt(ids, listID, pos, "list is not on this board: %s")
+		if err != nil {
+			return err
+		}
+		if _, err := tx.db.ExecContext(ctx, "UPDATE lists SET updated_at = ? WHERE id = ?", now(), listID); err != nil {
+			return err
+		}
+		return tx.renumber(ctx, "lists", ids)
+	})
+}
+
+// setListLifecycle archives, trashes, or brings back a list. Hidden lists
+// drop out of the board's ordering; returning lists are appended to it.
+func (s *SQLiteStore) setListLifecycle(ctx context.Context, id, column string, on bool) error {
+	return s.inTx(ctx, func(tx *SQLiteStore) error {
+		l, err := tx.GetList(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := tx.setLifecycle(ctx, "lists", column, id, on); err != nil {
+			return err
+		}
+		ids, err := tx.activeListIDs(ctx, l.BoardID, id)
+		if err != nil {
+			return err
+		}
+		if l, err = tx.GetList(ctx, id); err != nil {
+			return err
+		}
+		if l.ArchivedAt == nil && l.DeletedAt == nil {
+			ids = append(ids, id)
+		}
+		return tx.renumber(ctx, "lists", ids)
+	})
+}
+
+func (s *SQLiteStore) ArchiveList(ctx context.Context, id string) error {
+	return s.setListLifecycle(ctx, id, "archived_at", true)
+}
+
+func (s *SQLiteStore) UnarchiveList(ctx context.Context, id string) error {
+	return s.setListLifecycle(ctx, id, "archived_at", false)
+}
+
+// DeleteList moves the list to the trash; its cards are hidden with it.
 func (s *SQLiteStore) DeleteList(ctx context.Context, id string) error {
-	_, err := s.db.ExecContext(ctx, "DELETE FROM lists WHERE id = ?", id)
-	return err
+	return s.setListLifecycle(ctx, id, "deleted_at", true)
+}
+
+func (s *SQLiteStore) RestoreList(ctx context.Context, id string) error {
+	return s.setListLifecycle(ctx, id, "deleted_at", false)
 }
 
 // --- Cards ---
 
+const cardColumns = "c.id, c.list_id, c.title, c.description, c.position, c.assignee, c.status, c.priority, COALESCE(c.parent_id, ''), c.due_date, c.archived_at, c.deleted_at, c.created_at, c.updated_at"
+
+// activeCard matches cards that are neither archived nor in the trash.
+const activeCard = "c.archived_at IS NULL AND c.deleted_at IS NULL"
+
+// CreateCard appends the card after the list's existing cards.
 func (s *SQLiteStore) CreateCard(ctx context.Context, card *model.Card) error {
 	ts := now()
 	var dueDate *string
@@ -193,11 +484,12 @@ func (s *SQLiteStore) CreateCard(ctx context.Context, card *model.Card) error {
 	if card.Priority == "" {
 		card.Priority = model.PriorityMedium
 	}
-	_, err := s.db.ExecContext(ctx,
-		`INSERT INTO cards (id, list_id, title, description, position, assignee, status, priority, due_date, created_at, updated_at)
-		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
-		card.ID, card.ListID, card.Title, card.Description, card.Position,
-		card.Assignee, card.Status, card.Priority, dueDate, ts, ts)
+	err := s.db.QueryRowContext(ctx,
+		`INSERT INTO cards (id, list_id, title, description, position, assignee, status, priority, parent_id, due_date, created_at, updated_at)
+		 VALUES (?, ?, ?, ?, (SELECT COALESCE(MAX(position) + 1, 0) FROM cards c WHERE list_id = ? AND `+activeCard+`), ?, ?, ?, NULLIF(?, ''), ?, ?, ?)
+		 RETURNING position`,
+		card.ID, card.ListID, card.Title, card.Description, card.ListID,
+		card.Assignee, card.Status, card.Priority, card.ParentID, dueDate, ts, ts).Scan(&card.Position)
 	if err != nil {
 		return err
 	}
@@ -209,35 +501,22 @@ func (s *SQLiteStore) CreateCard(ctx context.Context, card *model.Card) error {
 func (s *SQLiteStore) scanCard(row interface{ Scan(...any) error }) (*model.Card, error) {
 	var c model.Card
 	var createdAt, updatedAt string
-	var dueDate sql.NullString
+	var dueDate, archivedAt, deletedAt sql.NullString
 	err := row.Scan(&c.ID, &c.ListID, &c.Title, &c.Description, &c.Position,
-		&c.Assignee, &c.Status, &c.Priority, &dueDate, &createdAt, &updatedAt)
+		&c.Assignee, &c.Status, &c.Priority, &c.ParentID, &dueDate, &archivedAt, &deletedAt, &createdAt, &updatedAt)
 	if err != nil {
 		return nil, err
 	}
 	c.CreatedAt = parseTime(createdAt)
 	c.UpdatedAt = parseTime(updatedAt)
-	if dueDate.Valid {
-		t := parseTime(dueDate.String)
-		c.DueDate = &t
-	}
+	c.DueDate = parseNullTime(dueDate)
+	c.ArchivedAt = parseNullTime(archivedAt)
+	c.DeletedAt = parseNullTime(deletedAt)
 	return &c, nil
 }
 
-func (s *SQLiteStore) GetCard(ctx context.Context, id string) (*model.Card, error) {
-	c, err := s.scanCard(s.db.QueryRowContext(ctx,
-		`SELECT id, list_id, title, description, position, assignee, status, priority, due_date, created_at, updated_at
-		 FROM cards WHERE id = ?`, id))
-	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("card not found: %s", id)
-	}
-	return c, err
-}
-
-func (s *SQLiteStore) ListCardsByList(ctx context.Context, listID string) ([]model.Card, error) {
-	rows, err := s.db.QueryContext(ctx,
-		`SELECT id, list_id, title, description, position, assignee, status, priority, due_date, created_at, updated_at
-		 FROM cards WHERE list_id = ? ORDER BY position ASC`, listID)
+func (s *SQLiteStore) queryCards(ctx context.Context, query string, args ...any) ([]model.Card, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -253,6 +532,50 @@ func (s *SQLiteStore) ListCardsByList(ctx context.Context, listID string) ([]mod
 	return cards, rows.Err()
 }
 
+// GetCard returns the card even if it is archived or in the trash.
+func (s *SQLiteStore) GetCard(ctx context.Context, id string) (*model.Card, error) {
+	c, err := s.scanCard(s.db.QueryRowContext(ctx,
+		"SELECT "+cardColumns+" FROM cards c WHERE c.id = ?", id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("card not found: %s", id)
+	}
+	return c, err
+}
+
+// ListCardsByList returns a page of the list's cards in order, and the
+// cursor of the next page if there is one. Cards in the trash are never
+// included; archived cards only when includeArchived is set, after the
+// active ones.
+func (s *SQLiteStore) ListCardsByList(ctx context.Context, listID string, includeArchived bool, page model.Page) ([]model.Card, string, error) {
+	cur, err := decodeCursor(page.Cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	q := "SELECT " + cardColumns + " FROM cards c WHERE c.list_id = ? AND c.deleted_at IS NULL"
+	args := []any{listID}
+	if !includeArchived {
+		q += " AND c.archived_at IS NULL"
+	}
+	if cur.ID != "" {
+		if cur.Position == nil {
+			return nil, "", fmt.Errorf("%w: %s", model.ErrInvalidCursor, page.Cursor)
+		}
+		q += " AND (c.archived_at IS NOT NULL, c.position, c.id) > (?, ?, ?)"
+		args = append(args, cur.Archived, *cur.Position, cur.ID)
+	}
+	limit, args := limitClause(page.Limit, args)
+	cards, err := s.queryCards(ctx, q+" ORDER BY c.archived_at IS NOT NULL, c.position ASC, c.id ASC"+limit, args...)
+	if err != nil {
+		return nil, "", err
+	}
+	cards, more := trimPage(cards, page.Limit)
+	if !more {
+		return cards, "", nil
+	}
+	last := cards[len(cards)-1]
+	return cards, cursor{Archived: last.ArchivedAt != nil, Position: &last.Position, ID: last.ID}.encode(), nil
+}
+
 func (s *SQLiteStore) UpdateCard(ctx context.Context, card *model.Card) error {
 	ts := now()
 	var dueDate *string
@@ -261,9 +584,9 @@ func (s *SQLiteStore) UpdateCard(ctx context.Context, card *model.Card) error {
 		dueDate = &d
 	}
 	res, err := s.db.ExecContext(ctx,
-		`UPDATE cards SET list_id=?, title=?, description=?, position=?, assignee=?, status=?, priority=?, due_date=?, updated_at=?
+		`UPDATE cards SET title=?, description=?, assignee=?, status=?, priority=?, due_date=?, updated_at=?
 		 WHERE id=?`,
-		card.ListID, card.Title, card.Description, card.Position,
+		card.Title, card.Description,
 		card.Assignee, card.Status, card.Priority, dueDate, ts, card.ID)
 	if err != nil {
 		return err
@@ -276,74 +599,337 @@ func (s *SQLiteStore) UpdateCard(ctx context.Context, card *model.Card) error {
 	return nil
 }
 
-func (s *SQLiteStore) MoveCard(ctx context.Context, cardID, targetListID string, position int) error {
-	ts := now()
-	res, err := s.db.ExecContext(ctx,
-		"UPDATE cards SET list_id = ?, position = ?, updated_at = ? WHERE id = ?",
-		targetListID, position, ts, cardID)
-	if err != nil {
-		return err
-	}
-	n, _ := res.RowsAffected()
-	if n == 0 {
-		return fmt.Errorf("card not found: %s", cardID)
-	}
-	return nil
+func (s *SQLiteStore) activeCardIDs(ctx context.Context, listID, excludeID string) ([]string, error) {
+	return s.queryIDs(ctx,
+		"SELECT c.id FROM cards c WHERE c.list_id = ? AND c.id != ? AND "+activeCard+" ORDER BY c.position ASC, c.id ASC",
+		listID, excludeID)
 }
 
-func (s *SQLiteStore) DeleteCard(ctx context.Context, id string) error {
-	_, err := s.db.ExecContext(ctx, "DELETE FROM cards WHERE id = ?", id)
-	return err
+// MoveCard places a card in the target list and renumbers both the source
+// and target lists in one transaction.
+func (s *SQLiteStore) MoveCard(ctx context.Context, cardID, targetListID string, pos model.Placement) error {
+	return s.inTx(ctx, func(tx *SQLiteStore) error {
+		c, err := tx.GetCard(ctx, cardID)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.GetList(ctx, targetListID); err != nil {
+			return err
+		}
+		ids, err := tx.activeCardIDs(ctx, targetListID, cardID)
+		if err != nil {
+			return err
+		}
+		ids, err = placeAt(ids, cardID, pos, "card is not in the target list: %s")
+		if err != nil {
+			return err
+		}
+		if _, err := tx.db.ExecContext(ctx,
+			"UPDATE cards SET list_id = ?, updated_at = ? WHERE id = ?", targetListID, now(), cardID); err != nil {
+			return err
+		}
+		if err := tx.renumber(ctx, "cards", ids); err != nil {
+			return err
+		}
+		if c.ListID == targetListID {
+			return nil
+		}
+		ids, err = tx.activeCardIDs(ctx, c.ListID, cardID)
+		if err != nil {
+			return err
+		}
+		return tx.renumber(ctx, "cards", ids)
+	})
 }
 
-func (s *SQLiteStore) SearchCards(ctx context.Context, boardID, query, assignee, status, label string) ([]model.Card, error) {
-	var conditions []string
-	var args []any
+// setCardLifecycle archives, trashes, or brings back a card. Hidden cards
+// drop out of their list's ordering; returning cards are appended to it.
+func (s *SQLiteStore) setCardLifecycle(ctx context.Context, id, column string, on bool) error {
+	return s.inTx(ctx, func(tx *SQLiteStore) error {
+		if err := tx.setLifecycle(ctx, "cards", column, id, on); err != nil {
+			return err
+		}
+		c, err := tx.GetCard(ctx, id)
+		if err != nil {
+			return err
+		}
+		ids, err := tx.activeCardIDs(ctx, c.ListID, id)
+		if err != nil {
+			return err
+		}
+		if c.ArchivedAt == nil && c.DeletedAt == nil {
+			ids = append(ids, id)
+		}
+		return tx.renumber(ctx, "cards", ids)
+	})
+}
 
-	conditions = append(conditions, "l.board_id = ?")
-	args = append(args, boardID)
+func (s *SQLiteStore) ArchiveCard(ctx context.Context, id string) error {
+	return s.setCardLifecycle(ctx, id, "archived_at", true)
+}
+
+func (s *SQLiteStore) UnarchiveCard(ctx context.Context, id string) error {
+	return s.setCardLifecycle(ctx, id, "archived_at", false)
+}
 
-	if query != "" {
-		conditions = append(conditions, "(c.title LIKE ? OR c.description LIKE ?)")
-		q := "%" + query + "%"
-		args = append(args, q, q)
+// DeleteCard moves the card to the trash. Its activity, labels, and
+// dependencies are kept until the card is purged.
+func (s *SQLiteStore) DeleteCard(ctx context.Context, id string) error {
+	return s.setCardLifecycle(ctx, id, "deleted_at", true)
+}
+
+func (s *SQLiteStore) RestoreCard(ctx context.Context, id string) error {
+	return s.setCardLifecycle(ctx, id, "deleted_at", false)
+}
+
+// SearchCards returns the board's cards matching q. Text in q is an FTS5
+// match expression over titles, descriptions, and comments, supporting
+// "phrases", prefix* terms, and AND/OR/NOT; matches carry a bm25 score,
+// with title hits weighted highest, and a highlighted snippet. Results are
+// ordered by q's sort keys, then by relevance when there is text, then in
+// board order. A limit in q takes precedence over the page's. Terms on
+// custom fields are resolved against the board's fields. The cursor of
+// the next page, if there is one, is returned alongside the results:
+// without text it resumes after the last card, so cards changing between
+// pages are neither skipped nor repeated; ranked results page by offset.
+func (s *SQLiteStore) SearchCards(ctx context.Context, boardID string, q *filter.Query, includeArchived bool, page model.Page) ([]model.Card, string, error) {
+	fields, err := s.ListCustomFields(ctx, boardID)
+	if err != nil {
+		return nil, "", err
 	}
-	if assignee != "" {
-		conditions = append(conditions, "c.assignee = ?")
-		args = append(args, assignee)
+	withFields := *q
+	withFields.Fields = map[string]string{}
+	for _, f := range fields {
+		withFields.Fields[f.Name] = f.Type
 	}
-	if status != "" {
-		conditions = append(conditions, "c.status = ?")
-		args = append(args, status)
+	compiled, err := filter.Compile(&withFields, time.Now())
+	if err != nil {
+		return nil, "", err
+	}
+	cur, err := decodeCursor(page.Cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	if q.Limit > 0 {
+		page.Limit = q.Limit
 	}
 
-	baseQuery := `SELECT DISTINCT c.id, c.list_id, c.title, c.description, c.position, c.assignee, c.status, c.priority, c.due_date, c.created_at, c.updated_at
-		FROM cards c JOIN lists l ON c.list_id = l.id`
+	conditions := []string{"l.board_id = ?", "c.deleted_at IS NULL", "l.deleted_at IS NULL"}
+	args := []any{boardID}
+	if !includeArchived {
+		conditions = append(conditions, "c.archived_at IS NULL", "l.archived_at IS NULL")
+	}
+	conditions = append(conditions, compiled.Where...)
+	args = append(args, compiled.Args...)
 
-	if label != "" {
-		baseQuery += " JOIN card_labels cl ON c.id = cl.card_id JOIN labels lb ON cl.label_id = lb.id"
-		conditions = append(conditions, "lb.name = ?")
-		args = append(args, label)
+	if q.Text != "" {
+		return s.searchRanked(ctx, q.Text, compiled, conditions, args, cur, page)
 	}
 
-	baseQuery += " WHERE " + strings.Join(conditions, " AND ") + " ORDER BY c.position ASC"
+	// Without text the order is a key on every row, ending in the card's
+	// unique ID, so pages resume after the last card of the previous one.
+	keys := append(compiled.Keys,
+		filter.SortKey{Expr: "l.position"}, filter.SortKey{Expr: "c.position"}, filter.SortKey{Expr: "c.id"})
+	if cur.ID != "" {
+		if len(cur.Keys) != len(keys) {
+			return nil, "", fmt.Errorf("%w: %s", model.ErrInvalidCursor, page.Cursor)
+		}
+		cond, after := filter.After(keys, cur.Keys)
+		conditions = append(conditions, cond)
+		args = append(args, after...)
+	}
+	limit, args := limitClause(page.Limit, args)
+	exprs := make([]string, len(keys))
+	for i, k := range keys {
+		exprs[i] = k.Expr
+	}
+	rows, err := s.db.QueryContext(ctx, "SELECT "+cardColumns+", "+strings.Join(exprs, ", ")+
+		" FROM cards c JOIN lists l ON c.list_id = l.id WHERE "+strings.Join(conditions, " AND ")+
+		" ORDER BY "+filter.OrderBy(keys)+limit, args...)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+	var cards []model.Card
+	var last []any
+	for rows.Next() {
+		values := make([]any, len(keys))
+		dest := make([]any, len(keys))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		c, err := s.scanCard(withExtra(rows, dest...))
+		if err != nil {
+			return nil, "", err
+		}
+		cards = append(cards, *c)
+		if len(cards) == page.Limit {
+			last = values
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+	cards, more := trimPage(cards, page.Limit)
+	if !more {
+		return cards, "", nil
+	}
+	return cards, cursor{Keys: last, ID: cards[len(cards)-1].ID}.encode(), nil
+}
+
+// searchRanked runs a full-text search. Its results are ordered by score,
+// which no key identifies a row by, so its pages resume at an offset.
+func (s *SQLiteStore) searchRanked(ctx context.Context, text string, compiled *filter.SQL, conditions []string, args []any, cur cursor, page model.Page) ([]model.Card, string, error) {
+	var order []string
+	if compiled.OrderBy != "" {
+		order = append(order, compiled.OrderBy)
+	}
+	order = append(order, "score ASC", "l.position ASC", "c.position ASC")
+	conditions = append([]string{"cards_fts MATCH ?"}, conditions...)
+	args = append([]any{text}, args...)
+	limit, args := limitClause(page.Limit, args)
+	if cur.Offset > 0 {
+		if limit == "" {
+			limit = " LIMIT -1"
+		}
+		limit += " OFFSET ?"
+		args = append(args, cur.Offset)
+	}
+	cards, err := s.queryRankedCards(ctx,
+		"SELECT "+cardColumns+", "+searchRank+" AS score, "+searchSnippet+
+			" FROM cards_fts JOIN card_search cs ON cs.id = cards_fts.rowid"+
+			" JOIN cards c ON c.id = cs.card_id JOIN lists l ON c.list_id = l.id"+
+			" WHERE "+strings.Join(conditions, " AND ")+" ORDER BY "+strings.Join(order, ", ")+limit, args...)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid search query %q: %w", text, err)
+	}
+	cards, more := trimPage(cards, page.Limit)
+	if !more {
+		return cards, "", nil
+	}
+	return cards, cursor{Offset: cur.Offset + len(cards)}.encode(), nil
+}
 
-	rows, err := s.db.QueryContext(ctx, baseQuery, args...)
+// queryRankedCards runs a full-text query selecting the card columns
+// followed by a score and a snippet.
+func (s *SQLiteStore) queryRankedCards(ctx context.Context, query string, args ...any) ([]model.Card, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 	var cards []model.Card
 	for rows.Next() {
-		c, err := s.scanCard(rows)
+		var score float64
+		var snippet string
+		c, err := s.scanCard(withExtra(rows, &score, &snippet))
 		if err != nil {
 			return nil, err
 		}
+		c.Score = score
+		c.Snippet = snippet
 		cards = append(cards, *c)
 	}
 	return cards, rows.Err()
 }
 
+// searchRank weights title matches above description matches above
+// comment matches.
+const searchRank = "bm25(cards_fts, 10.0, 5.0, 1.0)"
+
+// searchSnippet highlights matched terms in the best-matching column.
+const searchSnippet = "snippet(cards_fts, -1, '<mark>', '</mark>', '…', 16)"
+
+// extraScanner appends destinations to a Scan call so that queries can
+// select columns beyond those a scan helper knows about.
+type extraScanner struct {
+	row   interface{ Scan(...any) error }
+	extra []any
+}
+
+func withExtra(row interface{ Scan(...any) error }, extra ...any) extraScanner {
+	return extraScanner{row: row, extra: extra}
+}
+
+func (e extraScanner) Scan(dest ...any) error {
+	return e.row.Scan(append(dest, e.extra...)...)
+}
+
+func (s *SQLiteStore) CountCardsByStatus(ctx context.Context, boardID string) (map[string]int, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT c.status, COUNT(*) FROM cards c JOIN lists l ON c.list_id = l.id
+		 WHERE l.board_id = ? GROUP BY c.status`, boardID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	counts := map[string]int{}
+	for rows.Next() {
+		var status string
+		var n int
+		if err := rows.Scan(&status, &n); err != nil {
+			return nil, err
+		}
+		counts[status] = n
+	}
+	return counts, rows.Err()
+}
+
+// --- Trash ---
+
+// ListTrash returns the board's lists and cards that are in the trash. Cards
+// are only listed individually if they were deleted on their own.
+func (s *SQLiteStore) ListTrash(ctx context.Context, boardID string) ([]model.List, []model.Card, error) {
+	lists, err := s.queryLists(ctx,
+		"SELECT "+listColumns+" FROM lists l WHERE l.board_id = ? AND l.deleted_at IS NOT NULL ORDER BY l.deleted_at DESC",
+		boardID)
+	if err != nil {
+		return nil, nil, err
+	}
+	cards, err := s.queryCards(ctx,
+		"SELECT "+cardColumns+` FROM cards c JOIN lists l ON c.list_id = l.id
+		 WHERE l.board_id = ? AND c.deleted_at IS NOT NULL ORDER BY c.deleted_at DESC`,
+		boardID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return lists, cards, nil
+}
+
+// PurgeDeleted permanently removes boards, lists, and cards that were moved
+// to the trash before the given time, returning how many rows went and the
+// blob keys of the attachments that went with them, whose contents the
+// caller should now delete.
+func (s *SQLiteStore) PurgeDeleted(ctx context.Context, before time.Time) (int64, []string, error) {
+	cutoff := before.UTC().Format(timeLayout)
+	var total int64
+	var blobs []string
+	err := s.inTx(ctx, func(tx *SQLiteStore) error {
+		var err error
+		blobs, err = tx.queryIDs(ctx,
+			`SELECT a.id FROM attachments a JOIN cards c ON c.id = a.card_id
+			 JOIN lists l ON l.id = c.list_id JOIN boards b ON b.id = l.board_id
+			 WHERE a.url = '' AND (c.deleted_at < ?1 OR l.deleted_at < ?1 OR b.deleted_at < ?1)`, cutoff)
+		if err != nil {
+			return err
+		}
+		for _, table := range []string{"boards", "lists", "cards"} {
+			res, err := tx.db.ExecContext(ctx,
+				"DELETE FROM "+table+" WHERE deleted_at IS NOT NULL AND deleted_at < ?", cutoff)
+			if err != nil {
+				return err
+			}
+			n, _ := res.RowsAffected()
+			total += n
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+	return total, blobs, nil
+}
+
 // --- Dependencies ---
 
 func (s *SQLiteStore) AddDependency(ctx context.Context, dep *model.CardDependency) error {
@@ -365,36 +951,38 @@ func (s *SQLiteStore) RemoveDependency(ctx context.Context, cardID, dependsOnCar
 	return err
 }
 
-func (s *SQLiteStore) GetDependencies(ctx context.Context, cardID string) ([]model.Card, error) {
-	rows, err := s.db.QueryContext(ctx,
-		`SELECT c.id, c.list_id, c.title, c.description, c.position, c.assignee, c.status, c.priority, c.due_date, c.created_at, c.updated_at
-		 FROM cards c JOIN card_dependencies d ON c.id = d.depends_on_card_id WHERE d.card_id = ?`, cardID)
+// linkedColumns follow cardColumns when selecting the cards linked to card
+// ?1 by dependencies, joined with linkedJoins: the board of each, and
+// whether it is another than ?1's.
+const (
+	linkedColumns = `, b.id, b.name, b.id != (SELECT ol.board_id FROM cards oc JOIN lists ol ON ol.id = oc.list_id WHERE oc.id = ?1)`
+	linkedJoins   = ` JOIN lists l ON l.id = c.list_id JOIN boards b ON b.id = l.board_id`
+)
+
+// scanLinked scans a card selected with linkedColumns, then extra, setting
+// its Board if it is on another board than the card it is linked to.
+func (s *SQLiteStore) scanLinked(row interface{ Scan(...any) error }, extra ...any) (*model.Card, error) {
+	var ref model.BoardRef
+	var other bool
+	c, err := s.scanCard(withExtra(row, append([]any{&ref.ID, &ref.Name, &other}, extra...)...))
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-	var cards []model.Card
-	for rows.Next() {
-		c, err := s.scanCard(rows)
-		if err != nil {
-			return nil, err
-		}
-		cards = append(cards, *c)
+	if other {
+		c.Board = &ref
 	}
-	return cards, rows.Err()
+	return c, nil
 }
 
-func (s *SQLiteStore) GetDependents(ctx context.Context, cardID string) ([]model.Card, error) {
-	rows, err := s.db.QueryContext(ctx,
-		`SELECT c.id, c.list_id, c.title, c.description, c.position, c.assignee, c.status, c.priority, c.due_date, c.created_at, c.updated_at
-		 FROM cards c JOIN card_dependencies d ON c.id = d.card_id WHERE d.depends_on_card_id = ?`, cardID)
+func (s *SQLiteStore) queryLinkedCards(ctx context.Context, query, cardID string) ([]model.Card, error) {
+	rows, err := s.db.QueryContext(ctx, query, cardID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 	var cards []model.Card
 	for rows.Next() {
-		c, err := s.scanCard(rows)
+		c, err := s.scanLinked(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -403,6 +991,22 @@ func (s *SQLiteStore) GetDependents(ctx context.Context, cardID string) ([]model
 	return cards, rows.Err()
 }
 
+// GetDependencies returns the cards the card depends on. Those on another
+// board carry it in Board.
+func (s *SQLiteStore) GetDependencies(ctx context.Context, cardID string) ([]model.Card, error) {
+	return s.queryLinkedCards(ctx,
+		"SELECT "+cardColumns+linkedColumns+` FROM cards c JOIN card_dependencies d ON c.id = d.depends_on_card_id`+linkedJoins+`
+		 WHERE d.card_id = ?1 AND c.deleted_at IS NULL`, cardID)
+}
+
+// GetDependents returns the cards that depend on the card. Those on
+// another board carry it in Board.
+func (s *SQLiteStore) GetDependents(ctx context.Context, cardID string) ([]model.Card, error) {
+	return s.queryLinkedCards(ctx,
+		"SELECT "+cardColumns+linkedColumns+` FROM cards c JOIN card_dependencies d ON c.id = d.card_id`+linkedJoins+`
+		 WHERE d.depends_on_card_id = ?1 AND c.deleted_at IS NULL`, cardID)
+}
+
 // --- Labels ---
 
 func (s *SQLiteStore) CreateLabel(ctx context.Context, label *model.Label) error {
@@ -498,8 +1102,10 @@ func (s *SQLiteStore) GetLabelsForCard(ctx context.Context, cardID string) ([]mo
 
 // --- Activity ---
 
+// CreateActivity records the entry, at its CreatedAt if that is set and
+// otherwise now.
 func (s *SQLiteStore) CreateActivity(ctx context.Context, entry *model.ActivityLog) error {
-	ts := now()
+	ts := stamp(entry.CreatedAt)
 	_, err := s.db.ExecContext(ctx,
 		"INSERT INTO activity_log (id, card_id, actor, action, detail, created_at) VALUES (?, ?, ?, ?, ?, ?)",
 		entry.ID, entry.CardID, entry.Actor, entry.Action, entry.Detail, ts)
@@ -510,35 +1116,52 @@ func (s *SQLiteStore) CreateActivity(ctx context.Context, entry *model.ActivityL
 	return nil
 }
 
-func (s *SQLiteStore) ListActivityByCard(ctx context.Context, cardID string, limit int) ([]model.ActivityLog, error) {
-	q := "SELECT id, card_id, actor, action, detail, created_at FROM activity_log WHERE card_id = ? ORDER BY created_at DESC"
-	if limit > 0 {
-		q += fmt.Sprintf(" LIMIT %d", limit)
-	}
-	rows, err := s.db.QueryContext(ctx, q, cardID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	return scanActivities(rows)
+// ListActivityByCard returns a page of the card's activity, newest first,
+// and the cursor of the next page if there is one.
+func (s *SQLiteStore) ListActivityByCard(ctx context.Context, cardID string, page model.Page) ([]model.ActivityLog, string, error) {
+	return s.pageActivities(ctx,
+		"SELECT a.id, a.card_id, a.actor, a.action, a.detail, a.created_at FROM activity_log a WHERE a.card_id = ?",
+		cardID, page)
 }
 
-func (s *SQLiteStore) ListActivityByBoard(ctx context.Context, boardID string, limit int) ([]model.ActivityLog, error) {
-	q := `SELECT a.id, a.card_id, a.actor, a.action, a.detail, a.created_at
+// ListActivityByBoard returns a page of activity across the board's cards,
+// newest first, and the cursor of the next page if there is one.
+func (s *SQLiteStore) ListActivityByBoard(ctx context.Context, boardID string, page model.Page) ([]model.ActivityLog, string, error) {
+	return s.pageActivities(ctx,
+		`SELECT a.id, a.card_id, a.actor, a.action, a.detail, a.created_at
 		  FROM activity_log a
 		  JOIN cards c ON a.card_id = c.id
 		  JOIN lists l ON c.list_id = l.id
-		  WHERE l.board_id = ?
-		  ORDER BY a.created_at DESC`
-	if limit > 0 {
-		q += fmt.Sprintf(" LIMIT %d", limit)
+		  WHERE l.board_id = ?`,
+		boardID, page)
+}
+
+func (s *SQLiteStore) pageActivities(ctx context.Context, q string, id string, page model.Page) ([]model.ActivityLog, string, error) {
+	cur, err := decodeCursor(page.Cursor)
+	if err != nil {
+		return nil, "", err
 	}
-	rows, err := s.db.QueryContext(ctx, q, boardID)
+	args := []any{id}
+	if cur.ID != "" {
+		q += " AND (a.created_at, a.id) < (?, ?)"
+		args = append(args, cur.CreatedAt, cur.ID)
+	}
+	limit, args := limitClause(page.Limit, args)
+	rows, err := s.db.QueryContext(ctx, q+" ORDER BY a.created_at DESC, a.id DESC"+limit, args...)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	defer rows.Close()
-	return scanActivities(rows)
+	entries, err := scanActivities(rows)
+	if err != nil {
+		return nil, "", err
+	}
+	entries, more := trimPage(entries, page.Limit)
+	if !more {
+		return entries, "", nil
+	}
+	last := entries[len(entries)-1]
+	return entries, cursor{CreatedAt: last.CreatedAt.UTC().Format(timeLayout), ID: last.ID}.encode(), nil
 }
 
 func scanActivities(rows *sql.Rows) ([]model.ActivityLog, error) {
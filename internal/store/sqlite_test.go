@@ -3,15 +3,24 @@ package store_test
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	_ "modernc.org/sqlite"
 
+	"github.com/aellingwood/cielo/internal/filter"
 	"github.com/aellingwood/cielo/internal/model"
 	"github.com/aellingwood/cielo/internal/store"
+	"github.com/aellingwood/cielo/migrations"
 )
 
-func setupTestDB(t *testing.T) (*store.SQLiteStore, *sql.DB) {
+func setupTestDB(t testing.TB) (*store.SQLiteStore, *sql.DB) {
 	t.Helper()
 	db, err := sql.Open("sqlite", ":memory:")
 	if err != nil {
@@ -33,7 +42,7 @@ func TestRunMigrations(t *testing.T) {
 	if err := store.RunMigrations(db); err != nil {
 		t.Fatalf("RunMigrations failed: %v", err)
 	}
-	tables := []string{"boards", "lists", "cards", "card_dependencies", "labels", "card_labels", "activity_log"}
+	tables := []string{"schema_migrations", "boards", "lists", "cards", "card_dependencies", "labels", "card_labels", "activity_log", "audit_log", "views", "checklist_items", "custom_fields", "card_field_values", "attachments", "comments", "notifications", "card_watchers", "templates", "import_refs"}
 	for _, table := range tables {
 		var name string
 		err := db.QueryRow("SELECT name FROM sqlite_master WHERE type='table' AND name=?", table).Scan(&name)
@@ -41,6 +50,73 @@ func TestRunMigrations(t *testing.T) {
 			t.Errorf("table %q not found: %v", table, err)
 		}
 	}
+	entries, _ := fs.ReadDir(migrations.FS, ".")
+	var files int
+	for _, e := range entries {
+		if strings.HasSuffix(e.Name(), ".sql") {
+			files++
+		}
+	}
+	var recorded int
+	db.QueryRow("SELECT COUNT(*) FROM schema_migrations").Scan(&recorded)
+	if recorded != files {
+		t.Errorf("expected %d recorded migrations, got %d", files, recorded)
+	}
+}
+
+func TestRunMigrationsTwice(t *testing.T) {
+	s, db := setupTestDB(t)
+	defer db.Close()
+	ctx := context.Background()
+
+	b := &model.Board{ID: model.NewID(), Name: "Board"}
+	s.CreateBoard(ctx, b)
+	if err := store.RunMigrations(db); err != nil {
+		t.Fatalf("second RunMigrations failed: %v", err)
+	}
+	if _, err := s.GetBoard(ctx, b.ID); err != nil {
+		t.Errorf("board lost after re-running migrations: %v", err)
+	}
+}
+
+func TestBoardWorkflow(t *testing.T) {
+	s, db := setupTestDB(t)
+	defer db.Close()
+	ctx := context.Background()
+
+	b := &model.Board{ID: model.NewID(), Name: "Board"}
+	s.CreateBoard(ctx, b)
+	got, _ := s.GetBoard(ctx, b.ID)
+	if got.Workflow != nil {
+		t.Errorf("expected no workflow on new board, got %+v", got.Workflow)
+	}
+
+	b.Workflow = &model.Workflow{
+		Statuses:    []string{"todo", "review", "done"},
+		Transitions: map[string][]string{"todo": {"review"}},
+		Guards:      map[string][]string{"done": {model.GuardHasAssignee}},
+	}
+	if err := s.UpdateBoard(ctx, b); err != nil {
+		t.Fatal(err)
+	}
+	got, _ = s.GetBoard(ctx, b.ID)
+	if got.Workflow == nil || len(got.Workflow.Statuses) != 3 || got.Workflow.Guards["done"][0] != model.GuardHasAssignee {
+		t.Errorf("workflow not persisted: %+v", got.Workflow)
+	}
+
+	l := &model.List{ID: model.NewID(), BoardID: b.ID, Name: "Todo"}
+	s.CreateList(ctx, l)
+	c := &model.Card{ID: model.NewID(), ListID: l.ID, Title: "Task", Status: "review"}
+	if err := s.CreateCard(ctx, c); err != nil {
+		t.Fatalf("custom status rejected: %v", err)
+	}
+	counts, err := s.CountCardsByStatus(ctx, b.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counts["review"] != 1 {
+		t.Errorf("expected 1 review card, got %v", counts)
+	}
 }
 
 func TestBoardCRUD(t *testing.T) {
@@ -61,7 +137,7 @@ func TestBoardCRUD(t *testing.T) {
 		t.Errorf("expected name 'Test Board', got %q", got.Name)
 	}
 
-	boards, err := s.ListBoards(ctx)
+	boards, _, err := s.ListBoards(ctx, model.Page{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -81,9 +157,13 @@ func TestBoardCRUD(t *testing.T) {
 	if err := s.DeleteBoard(ctx, b.ID); err != nil {
 		t.Fatal(err)
 	}
-	_, err = s.GetBoard(ctx, b.ID)
-	if err == nil {
-		t.Error("expected error after delete")
+	got, err = s.GetBoard(ctx, b.ID)
+	if err != nil || got.DeletedAt == nil {
+		t.Errorf("expected deleted board to remain in trash, got %+v, %v", got, err)
+	}
+	boards, _, _ = s.ListBoards(ctx, model.Page{})
+	if len(boards) != 0 {
+		t.Errorf("expected deleted board to be hidden, got %d boards", len(boards))
 	}
 }
 
@@ -100,7 +180,7 @@ func TestListCRUD(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	lists, err := s.ListListsByBoard(ctx, b.ID)
+	lists, err := s.ListListsByBoard(ctx, b.ID, false)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -132,7 +212,7 @@ func TestCardCRUD(t *testing.T) {
 		t.Errorf("expected 'Task 1', got %q", got.Title)
 	}
 
-	cards, err := s.ListCardsByList(ctx, l.ID)
+	cards, _, err := s.ListCardsByList(ctx, l.ID, false, model.Page{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -156,7 +236,7 @@ func TestMoveCard(t *testing.T) {
 	c := &model.Card{ID: model.NewID(), ListID: l1.ID, Title: "Task", Position: 0, Status: "unassigned", Priority: "medium"}
 	s.CreateCard(ctx, c)
 
-	if err := s.MoveCard(ctx, c.ID, l2.ID, 0); err != nil {
+	if err := s.MoveCard(ctx, c.ID, l2.ID, model.Placement{}); err != nil {
 		t.Fatal(err)
 	}
 	got, _ := s.GetCard(ctx, c.ID)
@@ -265,7 +345,7 @@ func TestActivity(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	entries, err := s.ListActivityByCard(ctx, c.ID, 10)
+	entries, _, err := s.ListActivityByCard(ctx, c.ID, model.Page{Limit: 10})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -273,7 +353,7 @@ func TestActivity(t *testing.T) {
 		t.Errorf("unexpected activity: %+v", entries)
 	}
 
-	entries, err = s.ListActivityByBoard(ctx, b.ID, 10)
+	entries, _, err = s.ListActivityByBoard(ctx, b.ID, model.Page{Limit: 10})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -282,6 +362,15 @@ func TestActivity(t *testing.T) {
 	}
 }
 
+func mustParse(t *testing.T, expr string) *filter.Query {
+	t.Helper()
+	q, err := filter.Parse(expr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return q
+}
+
 func TestSearchCards(t *testing.T) {
 	s, db := setupTestDB(t)
 	defer db.Close()
@@ -297,7 +386,7 @@ func TestSearchCards(t *testing.T) {
 	s.CreateCard(ctx, c1)
 	s.CreateCard(ctx, c2)
 
-	cards, err := s.SearchCards(ctx, b.ID, "bug", "", "", "")
+	cards, _, err := s.SearchCards(ctx, b.ID, &filter.Query{Text: "bug"}, false, model.Page{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -305,13 +394,1303 @@ func TestSearchCards(t *testing.T) {
 		t.Errorf("search by text failed")
 	}
 
-	cards, _ = s.SearchCards(ctx, b.ID, "", "alice", "", "")
+	cards, _, _ = s.SearchCards(ctx, b.ID, mustParse(t, "assignee:alice"), false, model.Page{})
 	if len(cards) != 1 {
 		t.Errorf("search by assignee failed")
 	}
 
-	cards, _ = s.SearchCards(ctx, b.ID, "", "", "in_progress", "")
+	cards, _, _ = s.SearchCards(ctx, b.ID, mustParse(t, "status:in_progress"), false, model.Page{})
 	if len(cards) != 1 {
 		t.Errorf("search by status failed")
 	}
 }
+
+func TestFullTextSearch(t *testing.T) {
+	s, db := setupTestDB(t)
+	defer db.Close()
+	ctx := context.Background()
+
+	b := &model.Board{ID: model.NewID(), Name: "Board"}
+	s.CreateBoard(ctx, b)
+	l := &model.List{ID: model.NewID(), BoardID: b.ID, Name: "Todo"}
+	s.CreateList(ctx, l)
+
+	inTitle := &model.Card{ID: model.NewID(), ListID: l.ID, Title: "Database migration", Description: "Schema work"}
+	inDesc := &model.Card{ID: model.NewID(), ListID: l.ID, Title: "Cleanup", Description: "Remove the old database dump"}
+	other := &model.Card{ID: model.NewID(), ListID: l.ID, Title: "Write release notes", Description: "For the next version"}
+	for _, c := range []*model.Card{inTitle, inDesc, other} {
+		if err := s.CreateCard(ctx, c); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	titles := func(query string) []string {
+		t.Helper()
+		cards, _, err := s.SearchCards(ctx, b.ID, &filter.Query{Text: query}, false, model.Page{})
+		if err != nil {
+			t.Fatalf("search %q: %v", query, err)
+		}
+		var out []string
+		for _, c := range cards {
+			out = append(out, c.Title)
+		}
+		return out
+	}
+
+	if got := titles("database"); strings.Join(got, ",") != "Database migration,Cleanup" {
+		t.Errorf("expected title match ranked first, got %v", got)
+	}
+	if got := titles(`"old database"`); len(got) != 1 || got[0] != "Cleanup" {
+		t.Errorf("phrase search: got %v", got)
+	}
+	if got := titles("migrat*"); len(got) != 1 || got[0] != "Database migration" {
+		t.Errorf("prefix search: got %v", got)
+	}
+	if got := titles("database NOT dump"); len(got) != 1 || got[0] != "Database migration" {
+		t.Errorf("NOT search: got %v", got)
+	}
+	if got := titles("release OR schema"); len(got) != 2 {
+		t.Errorf("OR search: got %v", got)
+	}
+
+	cards, _, _ := s.SearchCards(ctx, b.ID, &filter.Query{Text: "dump"}, false, model.Page{})
+	if len(cards) != 1 || !strings.Contains(cards[0].Snippet, "<mark>dump</mark>") {
+		t.Errorf("expected highlighted snippet, got %+v", cards)
+	}
+
+	comment := &model.Comment{ID: model.NewID(), CardID: other.ID, Author: "alice", Body: "Blocked on the changelog generator"}
+	s.CreateComment(ctx, comment)
+	if got := titles("changelog"); len(got) != 1 || got[0] != "Write release notes" {
+		t.Errorf("comment search: got %v", got)
+	}
+	comment.Body = "Blocked on the version bump"
+	s.UpdateComment(ctx, comment)
+	if got := titles("changelog"); len(got) != 0 {
+		t.Errorf("expected the edited comment's old text to be unindexed, got %v", got)
+	}
+	deletedAt := time.Now()
+	comment.Body, comment.DeletedAt = "", &deletedAt
+	s.UpdateComment(ctx, comment)
+	if got := titles("bump"); len(got) != 0 {
+		t.Errorf("expected deleted comments to be unindexed, got %v", got)
+	}
+
+	other.Title = "Publish announcement"
+	s.UpdateCard(ctx, other)
+	if got := titles("release"); len(got) != 0 {
+		t.Errorf("expected stale title to be unindexed, got %v", got)
+	}
+	if got := titles("announcement"); len(got) != 1 {
+		t.Errorf("expected new title to be indexed, got %v", got)
+	}
+
+	s.DeleteCard(ctx, inDesc.ID)
+	if got := titles("dump"); len(got) != 0 {
+		t.Errorf("expected deleted card to be hidden, got %v", got)
+	}
+	s.PurgeDeleted(ctx, time.Now().Add(time.Hour))
+	var n int
+	db.QueryRow("SELECT COUNT(*) FROM card_search WHERE card_id = ?", inDesc.ID).Scan(&n)
+	if n != 0 {
+		t.Errorf("expected purged card to leave the index")
+	}
+
+	if _, _, err := s.SearchCards(ctx, b.ID, &filter.Query{Text: `"unterminated`}, false, model.Page{}); err == nil {
+		t.Error("expected invalid query to fail")
+	}
+
+	s.CreateCard(ctx, &model.Card{ID: model.NewID(), ListID: l.ID, Title: "Ship v1.2 of foo-bar", Description: "Set pr_url: when done"})
+	for _, text := range []string{"v1.2", "foo-bar", "pr_url:", `"unterminated v1.2`} {
+		var q filter.Query
+		q.AddText(text)
+		cards, _, err := s.SearchCards(ctx, b.ID, &q, false, model.Page{})
+		if err != nil {
+			t.Errorf("search %q: %v", text, err)
+		} else if len(cards) != 1 && text != `"unterminated v1.2` {
+			t.Errorf("search %q: expected one card, got %d", text, len(cards))
+		}
+	}
+	for _, expr := range []string{"v1.2", "foo-bar status:unassigned"} {
+		cards, _, err := s.SearchCards(ctx, b.ID, mustParse(t, expr), false, model.Page{})
+		if err != nil {
+			t.Errorf("filter %q: %v", expr, err)
+		} else if len(cards) != 1 {
+			t.Errorf("filter %q: expected one card, got %d", expr, len(cards))
+		}
+	}
+}
+
+func TestSearchFilters(t *testing.T) {
+	s, db := setupTestDB(t)
+	defer db.Close()
+	ctx := context.Background()
+
+	b := &model.Board{ID: model.NewID(), Name: "Board"}
+	s.CreateBoard(ctx, b)
+	todo := &model.List{ID: model.NewID(), BoardID: b.ID, Name: "Todo"}
+	doing := &model.List{ID: model.NewID(), BoardID: b.ID, Name: "In Progress"}
+	s.CreateList(ctx, todo)
+	s.CreateList(ctx, doing)
+
+	soon := time.Now().Add(48 * time.Hour)
+	later := time.Now().Add(30 * 24 * time.Hour)
+	cards := []*model.Card{
+		{ID: model.NewID(), ListID: todo.ID, Title: "Login bug", Assignee: "alice", Status: "in_progress", Priority: "critical", DueDate: &soon},
+		{ID: model.NewID(), ListID: todo.ID, Title: "Signup bug", Assignee: "bob", Status: "blocked", Priority: "high", DueDate: &later},
+		{ID: model.NewID(), ListID: doing.ID, Title: "Docs", Status: "unassigned", Priority: "low"},
+		{ID: model.NewID(), ListID: doing.ID, Title: "Refactor login", Assignee: "alice", Status: "done", Priority: "medium"},
+	}
+	for _, c := range cards {
+		if err := s.CreateCard(ctx, c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	coding := &model.Label{ID: model.NewID(), BoardID: b.ID, Name: "coding", Color: "#000"}
+	s.CreateLabel(ctx, coding)
+	s.AddLabelToCard(ctx, cards[0].ID, coding.ID)
+	s.AddLabelToCard(ctx, cards[3].ID, coding.ID)
+
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"status:in_progress,blocked", "Login bug,Signup bug"},
+		{"priority>=high", "Login bug,Signup bug"},
+		{"priority<high sort:-priority", "Refactor login,Docs"},
+		{"label:coding -assignee:bob", "Login bug,Refactor login"},
+		{"-label:coding", "Signup bug,Docs"},
+		{"assignee:none", "Docs"},
+		{"due<7d", "Login bug"},
+		{"-due<7d", "Signup bug,Docs,Refactor login"},
+		{"has:due sort:-due", "Signup bug,Login bug"},
+		{"list:\"In Progress\"", "Docs,Refactor login"},
+		{"updated>-1h created<=now", "Login bug,Signup bug,Docs,Refactor login"},
+		{"updated<2000-01-01", ""},
+		{"sort:title limit:2", "Docs,Login bug"},
+		{"login label:coding", "Login bug,Refactor login"},
+		{"bug priority:high", "Signup bug"},
+	}
+	for _, tt := range tests {
+		found, _, err := s.SearchCards(ctx, b.ID, mustParse(t, tt.expr), false, model.Page{})
+		if err != nil {
+			t.Errorf("%s: %v", tt.expr, err)
+			continue
+		}
+		var titles []string
+		for _, c := range found {
+			titles = append(titles, c.Title)
+		}
+		if got := strings.Join(titles, ","); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func cardOrder(t *testing.T, s *store.SQLiteStore, listID string) []string {
+	t.Helper()
+	cards, _, err := s.ListCardsByList(context.Background(), listID, false, model.Page{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var titles []string
+	for i, c := range cards {
+		if c.Position != i {
+			t.Errorf("card %q at index %d has position %d", c.Title, i, c.Position)
+		}
+		titles = append(titles, c.Title)
+	}
+	return titles
+}
+
+func TestCardOrdering(t *testing.T) {
+	s, db := setupTestDB(t)
+	defer db.Close()
+	ctx := context.Background()
+
+	b := &model.Board{ID: model.NewID(), Name: "Board"}
+	s.CreateBoard(ctx, b)
+	l1 := &model.List{ID: model.NewID(), BoardID: b.ID, Name: "Todo"}
+	l2 := &model.List{ID: model.NewID(), BoardID: b.ID, Name: "Done"}
+	s.CreateList(ctx, l1)
+	s.CreateList(ctx, l2)
+
+	cards := map[string]*model.Card{}
+	for _, title := range []string{"a", "b", "c", "d"} {
+		c := &model.Card{ID: model.NewID(), ListID: l1.ID, Title: title}
+		if err := s.CreateCard(ctx, c); err != nil {
+			t.Fatal(err)
+		}
+		cards[title] = c
+	}
+	if got := strings.Join(cardOrder(t, s, l1.ID), ""); got != "abcd" {
+		t.Fatalf("expected appended order abcd, got %s", got)
+	}
+
+	zero := 0
+	steps := []struct {
+		card   string
+		list   *model.List
+		pos    model.Placement
+		l1, l2 string
+	}{
+		{"d", l1, model.Placement{Position: &zero}, "dabc", ""},
+		{"a", l1, model.Placement{After: cards["c"].ID}, "dbca", ""},
+		{"b", l2, model.Placement{}, "dca", "b"},
+		{"c", l2, model.Placement{Before: cards["b"].ID}, "da", "cb"},
+		{"d", l2, model.Placement{After: cards["c"].ID}, "a", "cdb"},
+	}
+	for _, st := range steps {
+		if err := s.MoveCard(ctx, cards[st.card].ID, st.list.ID, st.pos); err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Join(cardOrder(t, s, l1.ID), ""); got != st.l1 {
+			t.Errorf("after moving %s: expected source %q, got %q", st.card, st.l1, got)
+		}
+		if got := strings.Join(cardOrder(t, s, l2.ID), ""); got != st.l2 {
+			t.Errorf("after moving %s: expected target %q, got %q", st.card, st.l2, got)
+		}
+	}
+
+	if err := s.MoveCard(ctx, cards["a"].ID, l2.ID, model.Placement{Before: cards["a"].ID}); err == nil {
+		t.Error("expected error placing a card relative to a card outside the target list")
+	}
+
+	if err := s.DeleteCard(ctx, cards["d"].ID); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(cardOrder(t, s, l2.ID), ""); got != "cb" {
+		t.Errorf("expected cb after delete, got %s", got)
+	}
+}
+
+func TestListOrdering(t *testing.T) {
+	s, db := setupTestDB(t)
+	defer db.Close()
+	ctx := context.Background()
+
+	b := &model.Board{ID: model.NewID(), Name: "Board"}
+	s.CreateBoard(ctx, b)
+	lists := map[string]*model.List{}
+	for _, name := range []string{"a", "b", "c"} {
+		l := &model.List{ID: model.NewID(), BoardID: b.ID, Name: name}
+		s.CreateList(ctx, l)
+		lists[name] = l
+	}
+	order := func() string {
+		ls, _ := s.ListListsByBoard(ctx, b.ID, false)
+		var out string
+		for i, l := range ls {
+			if l.Position != i {
+				t.Errorf("list %q at index %d has position %d", l.Name, i, l.Position)
+			}
+			out += l.Name
+		}
+		return out
+	}
+
+	if err := s.MoveList(ctx, lists["c"].ID, model.Placement{Before: lists["a"].ID}); err != nil {
+		t.Fatal(err)
+	}
+	if got := order(); got != "cab" {
+		t.Errorf("expected cab, got %s", got)
+	}
+	big := 99
+	s.MoveList(ctx, lists["c"].ID, model.Placement{Position: &big})
+	if got := order(); got != "abc" {
+		t.Errorf("expected out-of-range position to clamp to end, got %s", got)
+	}
+	s.DeleteList(ctx, lists["a"].ID)
+	if got := order(); got != "bc" {
+		t.Errorf("expected bc after delete, got %s", got)
+	}
+}
+
+func TestConcurrentMoves(t *testing.T) {
+	db, err := store.Open(filepath.Join(t.TempDir(), "cielo.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := store.RunMigrations(db); err != nil {
+		t.Fatal(err)
+	}
+	s := store.NewSQLiteStore(db)
+	ctx := context.Background()
+
+	b := &model.Board{ID: model.NewID(), Name: "Board"}
+	s.CreateBoard(ctx, b)
+	var lists []*model.List
+	for i := 0; i < 3; i++ {
+		l := &model.List{ID: model.NewID(), BoardID: b.ID, Name: fmt.Sprintf("list-%d", i)}
+		s.CreateList(ctx, l)
+		lists = append(lists, l)
+	}
+	var cardIDs []string
+	for i := 0; i < 20; i++ {
+		c := &model.Card{ID: model.NewID(), ListID: lists[0].ID, Title: fmt.Sprintf("card-%d", i)}
+		s.CreateCard(ctx, c)
+		cardIDs = append(cardIDs, c.ID)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8*25)
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 25; i++ {
+				pos := (w + i) % 5
+				err := s.MoveCard(ctx, cardIDs[(w*7+i)%len(cardIDs)], lists[(w+i)%len(lists)].ID, model.Placement{Position: &pos})
+				if err != nil {
+					errs <- err
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("concurrent move failed: %v", err)
+	}
+
+	total := 0
+	for _, l := range lists {
+		total += len(cardOrder(t, s, l.ID))
+	}
+	if total != len(cardIDs) {
+		t.Errorf("expected %d cards across lists, got %d", len(cardIDs), total)
+	}
+}
+
+func TestArchiveAndTrash(t *testing.T) {
+	s, db := setupTestDB(t)
+	defer db.Close()
+	ctx := context.Background()
+
+	b := &model.Board{ID: model.NewID(), Name: "Board"}
+	s.CreateBoard(ctx, b)
+	l1 := &model.List{ID: model.NewID(), BoardID: b.ID, Name: "Todo"}
+	l2 := &model.List{ID: model.NewID(), BoardID: b.ID, Name: "Done"}
+	s.CreateList(ctx, l1)
+	s.CreateList(ctx, l2)
+	cards := map[string]*model.Card{}
+	for _, title := range []string{"a", "b", "c"} {
+		c := &model.Card{ID: model.NewID(), ListID: l1.ID, Title: title}
+		s.CreateCard(ctx, c)
+		cards[title] = c
+	}
+	entry := &model.ActivityLog{ID: model.NewID(), CardID: cards["b"].ID, Actor: "agent", Action: model.ActionCreated, Detail: "{}"}
+	s.CreateActivity(ctx, entry)
+
+	if err := s.ArchiveCard(ctx, cards["a"].ID); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(cardOrder(t, s, l1.ID), ""); got != "bc" {
+		t.Errorf("expected archived card hidden, got %s", got)
+	}
+	all, _, _ := s.ListCardsByList(ctx, l1.ID, true, model.Page{})
+	if len(all) != 3 {
+		t.Errorf("expected archived card with flag, got %d cards", len(all))
+	}
+	found, _, _ := s.SearchCards(ctx, b.ID, &filter.Query{}, false, model.Page{})
+	if len(found) != 2 {
+		t.Errorf("expected search to skip archived card, got %d", len(found))
+	}
+	found, _, _ = s.SearchCards(ctx, b.ID, &filter.Query{}, true, model.Page{})
+	if len(found) != 3 {
+		t.Errorf("expected search to include archived card with flag, got %d", len(found))
+	}
+	s.UnarchiveCard(ctx, cards["a"].ID)
+	if got := strings.Join(cardOrder(t, s, l1.ID), ""); got != "bca" {
+		t.Errorf("expected unarchived card appended, got %s", got)
+	}
+
+	if err := s.DeleteCard(ctx, cards["b"].ID); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(cardOrder(t, s, l1.ID), ""); got != "ca" {
+		t.Errorf("expected deleted card hidden, got %s", got)
+	}
+	found, _, _ = s.SearchCards(ctx, b.ID, &filter.Query{}, true, model.Page{})
+	if len(found) != 2 {
+		t.Errorf("expected search to skip deleted card even with flag, got %d", len(found))
+	}
+	entries, _, _ := s.ListActivityByCard(ctx, cards["b"].ID, model.Page{Limit: 10})
+	if len(entries) != 1 {
+		t.Errorf("expected activity to survive soft delete")
+	}
+
+	s.ArchiveList(ctx, l1.ID)
+	lists, _ := s.ListListsByBoard(ctx, b.ID, false)
+	if len(lists) != 1 || lists[0].ID != l2.ID || lists[0].Position != 0 {
+		t.Errorf("expected only Done at position 0, got %+v", lists)
+	}
+	lists, _ = s.ListListsByBoard(ctx, b.ID, true)
+	if len(lists) != 2 {
+		t.Errorf("expected archived list with flag, got %d", len(lists))
+	}
+	s.UnarchiveList(ctx, l1.ID)
+	s.DeleteList(ctx, l2.ID)
+
+	trashLists, trashCards, err := s.ListTrash(ctx, b.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trashLists) != 1 || len(trashCards) != 1 || trashCards[0].ID != cards["b"].ID {
+		t.Errorf("unexpected trash: %+v %+v", trashLists, trashCards)
+	}
+
+	if err := s.RestoreCard(ctx, cards["b"].ID); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(cardOrder(t, s, l1.ID), ""); got != "cab" {
+		t.Errorf("expected restored card appended, got %s", got)
+	}
+
+	n, _, err := s.PurgeDeleted(ctx, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("expected 1 purged row, got %d", n)
+	}
+	if _, err := s.GetList(ctx, l2.ID); err == nil {
+		t.Error("expected purged list to be gone")
+	}
+	if _, err := s.GetCard(ctx, cards["b"].ID); err != nil {
+		t.Error("restored card should survive purge")
+	}
+}
+
+func TestAuditLog(t *testing.T) {
+	s, _ := setupTestDB(t)
+	ctx := context.Background()
+
+	for i, actor := range []string{"user", "agent", "user"} {
+		err := s.CreateAuditEntry(ctx, &model.AuditEntry{
+			ID: model.NewID(), BoardID: "b1", EntityType: model.EntityCard, EntityID: fmt.Sprintf("c%d", i),
+			Action: model.ActionCreated, Actor: actor, After: []byte(`{"title":"x"}`),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	s.CreateAuditEntry(ctx, &model.AuditEntry{
+		ID: model.NewID(), BoardID: "b2", EntityType: model.EntityBoard, EntityID: "b2",
+		Action: model.ActionDeleted, Actor: "user", Before: []byte(`{"name":"old"}`),
+	})
+
+	entries, err := s.ListAudit(ctx, model.AuditFilter{BoardID: "b1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(entries))
+	}
+	if string(entries[0].After) != `{"title":"x"}` || entries[0].Before != nil {
+		t.Errorf("unexpected snapshots: %+v", entries[0])
+	}
+
+	byActor, _ := s.ListAudit(ctx, model.AuditFilter{BoardID: "b1", Actor: "user"})
+	if len(byActor) != 2 {
+		t.Errorf("expected 2 entries by user, got %d", len(byActor))
+	}
+	limited, _ := s.ListAudit(ctx, model.AuditFilter{Limit: 1})
+	if len(limited) != 1 || limited[0].BoardID != "b2" {
+		t.Errorf("expected newest entry only, got %+v", limited)
+	}
+
+	future := time.Now().Add(time.Hour)
+	none, _ := s.ListAudit(ctx, model.AuditFilter{Since: &future})
+	if len(none) != 0 {
+		t.Errorf("expected no entries after %v, got %d", future, len(none))
+	}
+	all, _ := s.ListAudit(ctx, model.AuditFilter{Until: &future})
+	if len(all) != 4 {
+		t.Errorf("expected 4 entries before %v, got %d", future, len(all))
+	}
+}
+
+func TestPagination(t *testing.T) {
+	s, db := setupTestDB(t)
+	defer db.Close()
+	ctx := context.Background()
+
+	for i := range 5 {
+		s.CreateBoard(ctx, &model.Board{ID: model.NewID(), Name: fmt.Sprintf("Board %d", i)})
+	}
+	var names []string
+	page := model.Page{Limit: 2}
+	for pages := 0; ; pages++ {
+		boards, next, err := s.ListBoards(ctx, page)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, b := range boards {
+			names = append(names, b.Name)
+		}
+		if next == "" {
+			if pages != 2 {
+				t.Errorf("expected 3 pages, got %d", pages+1)
+			}
+			break
+		}
+		page.Cursor = next
+	}
+	if strings.Join(names, ",") != "Board 0,Board 1,Board 2,Board 3,Board 4" {
+		t.Errorf("unexpected boards: %v", names)
+	}
+
+	boards, _, _ := s.ListBoards(ctx, model.Page{})
+	b := boards[0]
+	l := &model.List{ID: model.NewID(), BoardID: b.ID, Name: "Todo"}
+	s.CreateList(ctx, l)
+	var ids []string
+	for i := range 7 {
+		c := &model.Card{ID: model.NewID(), ListID: l.ID, Title: fmt.Sprintf("Card %d", i)}
+		s.CreateCard(ctx, c)
+		ids = append(ids, c.ID)
+		for j := range 3 {
+			s.CreateActivity(ctx, &model.ActivityLog{
+				ID: model.NewID(), CardID: c.ID, Actor: "user", Action: model.ActionComment,
+				Detail: fmt.Sprintf(`{"text":"note %d"}`, j),
+			})
+		}
+	}
+	s.ArchiveCard(ctx, ids[1])
+
+	collectCards := func(includeArchived bool) []string {
+		var titles []string
+		page := model.Page{Limit: 3}
+		for {
+			cards, next, err := s.ListCardsByList(ctx, l.ID, includeArchived, page)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, c := range cards {
+				titles = append(titles, c.Title)
+			}
+			if next == "" {
+				return titles
+			}
+			page.Cursor = next
+		}
+	}
+	if got := strings.Join(collectCards(false), ","); got != "Card 0,Card 2,Card 3,Card 4,Card 5,Card 6" {
+		t.Errorf("unexpected active cards: %s", got)
+	}
+	if got := strings.Join(collectCards(true), ","); got != "Card 0,Card 2,Card 3,Card 4,Card 5,Card 6,Card 1" {
+		t.Errorf("unexpected cards with archived: %s", got)
+	}
+
+	seen := map[string]bool{}
+	page = model.Page{Limit: 4}
+	var last time.Time
+	for {
+		entries, next, err := s.ListActivityByBoard(ctx, b.ID, page)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, e := range entries {
+			if seen[e.ID] {
+				t.Errorf("activity %s returned twice", e.ID)
+			}
+			if !last.IsZero() && e.CreatedAt.After(last) {
+				t.Errorf("activity out of order")
+			}
+			seen[e.ID] = true
+			last = e.CreatedAt
+		}
+		if next == "" {
+			break
+		}
+		page.Cursor = next
+	}
+	if len(seen) != 21 {
+		t.Errorf("expected 21 activity entries, got %d", len(seen))
+	}
+
+	first, next, _ := s.SearchCards(ctx, b.ID, &filter.Query{Text: "card"}, false, model.Page{Limit: 4})
+	rest, after, _ := s.SearchCards(ctx, b.ID, &filter.Query{Text: "card"}, false, model.Page{Cursor: next, Limit: 4})
+	if len(first) != 4 || len(rest) != 2 || after != "" {
+		t.Errorf("expected search pages of 4 and 2, got %d and %d (next %q)", len(first), len(rest), after)
+	}
+	limited, next, _ := s.SearchCards(ctx, b.ID, mustParse(t, "limit:5"), false, model.Page{Limit: 2})
+	if len(limited) != 5 || next == "" {
+		t.Errorf("expected filter limit to set the page size, got %d (next %q)", len(limited), next)
+	}
+	sorted, next, _ := s.SearchCards(ctx, b.ID, mustParse(t, "sort:-title"), false, model.Page{Limit: 3})
+	s.ArchiveCard(ctx, ids[6])
+	tail, after, err := s.SearchCards(ctx, b.ID, mustParse(t, "sort:-title"), false, model.Page{Cursor: next, Limit: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var titles []string
+	for _, c := range append(sorted, tail...) {
+		titles = append(titles, c.Title)
+	}
+	if got := strings.Join(titles, ","); got != "Card 6,Card 5,Card 4,Card 3,Card 2,Card 0" || after != "" {
+		t.Errorf("expected filter search to resume after the last card, got %s (next %q)", got, after)
+	}
+
+	if _, _, err := s.ListCardsByList(ctx, l.ID, false, model.Page{Cursor: "not-a-cursor!", Limit: 2}); !errors.Is(err, model.ErrInvalidCursor) {
+		t.Errorf("expected ErrInvalidCursor, got %v", err)
+	}
+}
+
+func TestBatchedCardLoading(t *testing.T) {
+	s, db := setupTestDB(t)
+	defer db.Close()
+	ctx := context.Background()
+	b, seeded := seedBoard(t, s, 3, 5)
+
+	cards, next, err := s.ListCardsByBoard(ctx, b.ID, false, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cards) != 15 || len(next) != 0 {
+		t.Fatalf("got %d cards and %d cursors, want 15 and 0", len(cards), len(next))
+	}
+	for i, c := range cards {
+		if c.ID != seeded[i].ID || len(c.Labels) != 2 {
+			t.Errorf("card %d is %s with %d labels, want %s with 2", i, c.Title, len(c.Labels), seeded[i].Title)
+		}
+	}
+	if cards[0].DependencyCount != 0 || cards[0].DependentCount != 1 || cards[14].DependencyCount != 1 || cards[14].DependentCount != 0 {
+		t.Errorf("unexpected dependency counts on first and last cards: %+v, %+v", cards[0], cards[14])
+	}
+	ids := make([]string, len(seeded))
+	for i, c := range seeded {
+		ids[i] = c.ID
+	}
+	labels, err := s.GetLabelsForCards(ctx, ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range seeded {
+		if len(labels[c.ID]) != 2 {
+			t.Errorf("card %s has %d labels, want 2", c.Title, len(labels[c.ID]))
+		}
+	}
+	deps, dependents, err := s.CountDependencies(ctx, ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deps[ids[0]] != 0 || dependents[ids[0]] != 1 || deps[ids[14]] != 1 || dependents[ids[14]] != 0 {
+		t.Errorf("unexpected dependency counts: %v %v", deps, dependents)
+	}
+
+	// Archived cards are left out, and a per-list limit yields cursors that
+	// continue in ListCardsByList.
+	s.ArchiveCard(ctx, seeded[0].ID)
+	cards, next, err = s.ListCardsByBoard(ctx, b.ID, false, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cards) != 6 || len(next) != 3 {
+		t.Fatalf("got %d cards and %d cursors, want 6 and 3", len(cards), len(next))
+	}
+	if cards[0].ID != seeded[1].ID {
+		t.Errorf("expected archived card to be skipped, got %s first", cards[0].Title)
+	}
+	rest, _, err := s.ListCardsByList(ctx, seeded[0].ListID, false, model.Page{Cursor: next[seeded[0].ListID]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rest) != 2 || rest[0].ID != seeded[3].ID {
+		t.Errorf("cursor resumed at %+v", rest)
+	}
+
+	c, err := s.GetCardDetail(ctx, seeded[7].ID, 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Labels) != 2 || len(c.Dependencies) != 1 || c.Dependencies[0].ID != seeded[6].ID ||
+		len(c.Dependents) != 1 || c.Dependents[0].ID != seeded[8].ID || c.Activity == nil {
+		t.Errorf("unexpected card detail: %+v", c)
+	}
+	s.DeleteCard(ctx, seeded[8].ID)
+	if c, _ := s.GetCardDetail(ctx, seeded[7].ID, 50); len(c.Dependents) != 0 {
+		t.Errorf("expected trashed dependent to be ignored: %+v", c.Dependents)
+	}
+	if _, dependents, _ := s.CountDependencies(ctx, ids); dependents[ids[7]] != 0 {
+		t.Error("expected trashed dependent not to be counted")
+	}
+	if _, err := s.GetCardDetail(ctx, "missing", 50); err == nil {
+		t.Error("expected missing card to be an error")
+	}
+}
+
+func TestCountCardsByList(t *testing.T) {
+	s, db := setupTestDB(t)
+	defer db.Close()
+	ctx := context.Background()
+	now := time.Now()
+
+	b := &model.Board{ID: model.NewID(), Name: "Board"}
+	s.CreateBoard(ctx, b)
+	todo := &model.List{ID: model.NewID(), BoardID: b.ID, Name: "Todo", Position: 0}
+	done := &model.List{ID: model.NewID(), BoardID: b.ID, Name: "Done", Position: 1}
+	s.CreateList(ctx, todo)
+	s.CreateList(ctx, done)
+
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+	cards := []*model.Card{
+		{ID: model.NewID(), ListID: todo.ID, Title: "Late", Status: "in_progress", Priority: "high", DueDate: &past},
+		{ID: model.NewID(), ListID: todo.ID, Title: "Waiting", Status: "assigned", Priority: "high", DueDate: &future},
+		{ID: model.NewID(), ListID: todo.ID, Title: "Stuck", Status: "blocked", Priority: "low"},
+		{ID: model.NewID(), ListID: done.ID, Title: "Finished late", Status: "done", Priority: "medium", DueDate: &past},
+		{ID: model.NewID(), ListID: todo.ID, Title: "Archived", Status: "assigned", Priority: "high", DueDate: &past},
+	}
+	for _, c := range cards {
+		if err := s.CreateCard(ctx, c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s.ArchiveCard(ctx, cards[4].ID)
+	// Waiting depends on Late, which is not done; Stuck depends on a done card.
+	s.AddDependency(ctx, &model.CardDependency{ID: model.NewID(), CardID: cards[1].ID, DependsOnCardID: cards[0].ID})
+	s.AddDependency(ctx, &model.CardDependency{ID: model.NewID(), CardID: cards[2].ID, DependsOnCardID: cards[3].ID})
+
+	counts, err := s.CountCardsByList(ctx, b.ID, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := counts[todo.ID]
+	if got.Total != 3 || got.ByStatus["in_progress"] != 1 || got.ByStatus["assigned"] != 1 || got.ByStatus["blocked"] != 1 ||
+		got.ByPriority["high"] != 2 || got.ByPriority["low"] != 1 {
+		t.Errorf("unexpected todo counts: %+v", got)
+	}
+	if got.Overdue != 1 || got.Blocked != 2 {
+		t.Errorf("todo overdue = %d, blocked = %d; want 1 and 2", got.Overdue, got.Blocked)
+	}
+	if got := counts[done.ID]; got.Total != 1 || got.Overdue != 0 || got.Blocked != 0 {
+		t.Errorf("unexpected done counts: %+v", got)
+	}
+}
+
+func TestChecklistItems(t *testing.T) {
+	s, db := setupTestDB(t)
+	defer db.Close()
+	ctx := context.Background()
+	b, seeded := seedBoard(t, s, 1, 2)
+	card := seeded[0]
+
+	add := func(text string, pos model.Placement) *model.ChecklistItem {
+		t.Helper()
+		it := &model.ChecklistItem{ID: model.NewID(), CardID: card.ID, Text: text}
+		if err := s.CreateChecklistItem(ctx, it, pos); err != nil {
+			t.Fatal(err)
+		}
+		return it
+	}
+	first := add("first", model.Placement{})
+	third := add("third", model.Placement{})
+	second := add("second", model.Placement{Before: third.ID})
+	if second.Position != 1 {
+		t.Errorf("expected second at position 1, got %d", second.Position)
+	}
+	if err := s.CreateChecklistItem(ctx, &model.ChecklistItem{ID: model.NewID(), CardID: card.ID, Text: "x"},
+		model.Placement{After: "missing"}); err == nil {
+		t.Error("expected placement after an unknown item to fail")
+	}
+
+	order := func() string {
+		items, err := s.ListChecklistItems(ctx, card.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var texts []string
+		for i, it := range items {
+			if it.Position != i {
+				t.Errorf("%s at position %d, want %d", it.Text, it.Position, i)
+			}
+			texts = append(texts, it.Text)
+		}
+		return strings.Join(texts, ",")
+	}
+	if got := order(); got != "first,second,third" {
+		t.Errorf("order = %s", got)
+	}
+	zero := 0
+	if err := s.MoveChecklistItem(ctx, third.ID, model.Placement{Position: &zero}); err != nil {
+		t.Fatal(err)
+	}
+	if got := order(); got != "third,first,second" {
+		t.Errorf("order after move = %s", got)
+	}
+
+	done := time.Now()
+	first.Done, first.CompletedAt = true, &done
+	if err := s.UpdateChecklistItem(ctx, first); err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.GetChecklistItem(ctx, first.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Done || got.CompletedAt == nil {
+		t.Errorf("expected a completed item, got %+v", got)
+	}
+
+	cards, _, err := s.ListCardsByBoard(ctx, b.ID, false, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cards[0].ChecklistDone != 1 || cards[0].ChecklistTotal != 3 || cards[1].ChecklistTotal != 0 {
+		t.Errorf("unexpected board progress: %d/%d, %d", cards[0].ChecklistDone, cards[0].ChecklistTotal, cards[1].ChecklistTotal)
+	}
+	doneByCard, total, err := s.CountChecklists(ctx, []string{card.ID, seeded[1].ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doneByCard[card.ID] != 1 || total[card.ID] != 3 || total[seeded[1].ID] != 0 {
+		t.Errorf("unexpected counts: %v %v", doneByCard, total)
+	}
+
+	if err := s.DeleteChecklistItem(ctx, third.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got := order(); got != "first,second" {
+		t.Errorf("order after delete = %s", got)
+	}
+	c, err := s.GetCardDetail(ctx, card.ID, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Checklist) != 2 || c.ChecklistDone != 1 || c.ChecklistTotal != 2 {
+		t.Errorf("unexpected card checklist: %+v", c.Checklist)
+	}
+	if _, err := s.GetChecklistItem(ctx, third.ID); err == nil {
+		t.Error("expected deleted item to be gone")
+	}
+}
+
+func TestCardParents(t *testing.T) {
+	s, db := setupTestDB(t)
+	defer db.Close()
+	ctx := context.Background()
+	_, seeded := seedBoard(t, s, 2, 2)
+	root, mid, leaf, other := seeded[0].ID, seeded[1].ID, seeded[2].ID, seeded[3].ID
+
+	for _, pair := range [][2]string{{mid, root}, {leaf, mid}, {other, root}} {
+		if err := s.SetCardParent(ctx, pair[0], pair[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.SetCardParent(ctx, root, leaf); err == nil {
+		t.Error("expected a cycle to be rejected")
+	}
+	if err := s.SetCardParent(ctx, "missing", root); err == nil {
+		t.Error("expected a missing card to be an error")
+	}
+
+	children, err := s.ListChildren(ctx, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(children) != 2 || children[0].ID != mid || children[1].ID != other || children[0].ParentID != root {
+		t.Errorf("unexpected children: %+v", children)
+	}
+	c, _ := s.GetCard(ctx, leaf)
+	c.Status = model.StatusDone
+	s.UpdateCard(ctx, c)
+	r, err := s.GetRollup(ctx, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Children != 2 || r.Total != 3 || r.Done != 1 || r.ByStatus[model.StatusDone] != 1 {
+		t.Errorf("unexpected rollup: %+v", r)
+	}
+
+	s.DeleteCard(ctx, mid)
+	if r, _ := s.GetRollup(ctx, root); r.Children != 1 || r.Total != 1 {
+		t.Errorf("expected trashed subtree to be left out: %+v", r)
+	}
+	if err := s.SetCardParent(ctx, other, ""); err != nil {
+		t.Fatal(err)
+	}
+	if c, _ := s.GetCard(ctx, other); c.ParentID != "" {
+		t.Errorf("expected card to be detached, got parent %q", c.ParentID)
+	}
+}
+
+func TestCustomFields(t *testing.T) {
+	s, db := setupTestDB(t)
+	defer db.Close()
+	ctx := context.Background()
+	b, seeded := seedBoard(t, s, 1, 3)
+
+	create := func(name, typ string, pos model.Placement, options ...string) *model.CustomField {
+		t.Helper()
+		f := &model.CustomField{ID: model.NewID(), BoardID: b.ID, Name: name, Type: typ, Options: options}
+		if err := s.CreateCustomField(ctx, f, pos); err != nil {
+			t.Fatal(err)
+		}
+		return f
+	}
+	points := create("points", model.FieldNumber, model.Placement{})
+	shipped := create("shipped", model.FieldDate, model.Placement{})
+	tier := create("tier", model.FieldEnum, model.Placement{Before: points.ID}, "small", "large")
+	if err := s.CreateCustomField(ctx, &model.CustomField{ID: model.NewID(), BoardID: b.ID, Name: "points", Type: model.FieldText}, model.Placement{}); err == nil {
+		t.Error("expected a duplicate field name to be rejected")
+	}
+	fields, err := s.ListCustomFields(ctx, b.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fields) != 3 || fields[0].ID != tier.ID || fields[1].ID != points.ID || fields[2].Position != 2 {
+		t.Errorf("unexpected field order: %+v", fields)
+	}
+	if len(fields[0].Options) != 2 || fields[0].Options[1] != "large" {
+		t.Errorf("unexpected options: %v", fields[0].Options)
+	}
+
+	day := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	set := func(cardID string, values map[string]any) {
+		t.Helper()
+		if err := s.SetCardFieldValues(ctx, cardID, values); err != nil {
+			t.Fatal(err)
+		}
+	}
+	set(seeded[0].ID, map[string]any{points.ID: 2.5, shipped.ID: day, tier.ID: "small"})
+	set(seeded[1].ID, map[string]any{points.ID: 8.0})
+	set(seeded[1].ID, map[string]any{points.ID: 13.0, tier.ID: "large"})
+
+	values, err := s.GetFieldValuesForCards(ctx, []string{seeded[0].ID, seeded[1].ID, seeded[2].ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := values[seeded[0].ID]
+	if first["points"] != 2.5 || first["tier"] != "small" || !first["shipped"].(time.Time).Equal(day) {
+		t.Errorf("unexpected values: %v", first)
+	}
+	if values[seeded[1].ID]["points"] != 13.0 || values[seeded[2].ID] != nil {
+		t.Errorf("unexpected values: %v", values)
+	}
+
+	cards, _, err := s.ListCardsByBoard(ctx, b.ID, false, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cards[1].Fields["tier"] != "large" || cards[2].Fields != nil {
+		t.Errorf("unexpected listed fields: %v, %v", cards[1].Fields, cards[2].Fields)
+	}
+	detail, err := s.GetCardDetail(ctx, seeded[0].ID, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(detail.Fields) != 3 {
+		t.Errorf("unexpected detail fields: %v", detail.Fields)
+	}
+
+	search := func(expr string) []string {
+		t.Helper()
+		cards, _, err := s.SearchCards(ctx, b.ID, mustParse(t, expr), false, model.Page{})
+		if err != nil {
+			t.Fatalf("%s: %v", expr, err)
+		}
+		var titles []string
+		for _, c := range cards {
+			titles = append(titles, c.Title)
+		}
+		return titles
+	}
+	for expr, want := range map[string]string{
+		// Numbers compare numerically, not as text.
+		"field.points>3":           "Card 0.1",
+		"field.points<=2.5":        "Card 0.0",
+		"field.tier:small,large":   "Card 0.0,Card 0.1",
+		"-field.tier:large":        "Card 0.0,Card 0.2",
+		"field.shipped:2026-02-01": "Card 0.0",
+		"has:field.shipped":        "Card 0.0",
+	} {
+		if got := strings.Join(search(expr), ","); got != want {
+			t.Errorf("%s: got %q, want %q", expr, got, want)
+		}
+	}
+	if _, _, err := s.SearchCards(ctx, b.ID, mustParse(t, "field.missing:x"), false, model.Page{}); err == nil {
+		t.Error("expected an unknown custom field to be rejected")
+	}
+
+	set(seeded[0].ID, map[string]any{tier.ID: nil})
+	if err := s.DeleteCustomField(ctx, points.ID); err != nil {
+		t.Fatal(err)
+	}
+	values, _ = s.GetFieldValuesForCards(ctx, []string{seeded[0].ID, seeded[1].ID})
+	if len(values[seeded[0].ID]) != 1 || len(values[seeded[1].ID]) != 1 {
+		t.Errorf("expected cleared and deleted field values to be gone: %v", values)
+	}
+	if f, _ := s.GetCustomField(ctx, shipped.ID); f.Position != 1 {
+		t.Errorf("expected positions to close up, got %d", f.Position)
+	}
+}
+
+func TestAttachments(t *testing.T) {
+	s, db := setupTestDB(t)
+	defer db.Close()
+	ctx := context.Background()
+	_, seeded := seedBoard(t, s, 1, 2)
+	c := seeded[0]
+
+	file := &model.Attachment{ID: model.NewID(), CardID: c.ID, Name: "log.txt", Size: 3, MimeType: "text/plain", SHA256: "abc", UploadedBy: "agent"}
+	link := &model.Attachment{ID: model.NewID(), CardID: c.ID, Name: "report", URL: "https://example.com/report", UploadedBy: "user"}
+	other := &model.Attachment{ID: model.NewID(), CardID: seeded[1].ID, Name: "keep.txt", UploadedBy: "agent"}
+	for _, a := range []*model.Attachment{file, link, other} {
+		if err := s.CreateAttachment(ctx, a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := s.ListAttachments(ctx, c.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].ID != file.ID || got[0].Size != 3 || got[1].URL != link.URL {
+		t.Errorf("unexpected attachments: %+v", got)
+	}
+	detail, err := s.GetCardDetail(ctx, c.ID, 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(detail.Attachments) != 2 {
+		t.Errorf("expected the card detail to include 2 attachments, got %d", len(detail.Attachments))
+	}
+
+	if err := s.DeleteCard(ctx, c.ID); err != nil {
+		t.Fatal(err)
+	}
+	_, keys, err := s.PurgeDeleted(ctx, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || keys[0] != file.ID {
+		t.Errorf("expected only the purged card's stored contents to be returned, got %v", keys)
+	}
+	if _, err := s.GetAttachment(ctx, file.ID); err == nil {
+		t.Error("expected the purged card's attachments to be gone")
+	}
+	if _, err := s.GetAttachment(ctx, other.ID); err != nil {
+		t.Errorf("expected other cards' attachments to be kept: %v", err)
+	}
+}
+
+func TestComments(t *testing.T) {
+	s, db := setupTestDB(t)
+	defer db.Close()
+	ctx := context.Background()
+	_, seeded := seedBoard(t, s, 1, 2)
+	c := seeded[0]
+
+	root := &model.Comment{ID: model.NewID(), CardID: c.ID, Author: "alice", Body: "Ready for @bob", Mentions: []string{"bob"}}
+	reply := &model.Comment{ID: model.NewID(), CardID: c.ID, ParentID: root.ID, Author: "bob", Body: "On it"}
+	other := &model.Comment{ID: model.NewID(), CardID: seeded[1].ID, Author: "alice", Body: "Elsewhere"}
+	for _, cm := range []*model.Comment{root, reply, other} {
+		if err := s.CreateComment(ctx, cm); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.CreateComment(ctx, &model.Comment{ID: model.NewID(), CardID: c.ID, ParentID: "missing", Author: "bob", Body: "x"}); err == nil {
+		t.Error("expected a reply to a missing comment to be rejected")
+	}
+
+	got, err := s.GetComment(ctx, reply.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ParentID != root.ID || got.Author != "bob" || got.EditedAt != nil || len(got.Mentions) != 0 {
+		t.Errorf("unexpected reply: %+v", got)
+	}
+
+	var ids []string
+	page := model.Page{Limit: 1}
+	for {
+		comments, next, err := s.ListComments(ctx, c.ID, page)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, cm := range comments {
+			ids = append(ids, cm.ID)
+		}
+		if next == "" {
+			break
+		}
+		page.Cursor = next
+	}
+	if len(ids) != 2 || ids[0] != root.ID || ids[1] != reply.ID {
+		t.Errorf("expected comments oldest first across pages, got %v", ids)
+	}
+
+	edited := time.Now()
+	root.Body, root.Mentions, root.EditedAt = "Ready for @carol", []string{"carol"}, &edited
+	if err := s.UpdateComment(ctx, root); err != nil {
+		t.Fatal(err)
+	}
+	got, _ = s.GetComment(ctx, root.ID)
+	if got.Body != "Ready for @carol" || len(got.Mentions) != 1 || got.Mentions[0] != "carol" || got.EditedAt == nil {
+		t.Errorf("unexpected edited comment: %+v", got)
+	}
+
+	if err := s.DeleteCard(ctx, c.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := s.PurgeDeleted(ctx, time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.GetComment(ctx, reply.ID); err == nil {
+		t.Error("expected the purged card's comments to be gone")
+	}
+	if _, err := s.GetComment(ctx, other.ID); err != nil {
+		t.Errorf("expected other cards' comments to be kept: %v", err)
+	}
+}
+
+func TestNotifications(t *testing.T) {
+	s, db := setupTestDB(t)
+	defer db.Close()
+	ctx := context.Background()
+	b, seeded := seedBoard(t, s, 1, 2)
+
+	var ns []*model.Notification
+	for i, r := range []string{"researcher", "researcher", "researcher", "coder"} {
+		ns = append(ns, &model.Notification{
+			ID: model.NewID(), Recipient: r, Type: model.NotifyMention, BoardID: b.ID,
+			CardID: seeded[i%2].ID, Actor: "user", Message: fmt.Sprintf("note %d", i),
+		})
+	}
+	if err := s.CreateNotifications(ctx, ns); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	page := model.Page{Limit: 2}
+	for {
+		items, next, err := s.ListNotifications(ctx, "researcher", true, page)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, n := range items {
+			got = append(got, n.Message)
+		}
+		if next == "" {
+			break
+		}
+		page.Cursor = next
+	}
+	if strings.Join(got, ",") != "note 2,note 1,note 0" {
+		t.Errorf("expected the researcher's notifications newest first, got %v", got)
+	}
+
+	n, err := s.AckNotifications(ctx, "researcher", []string{ns[0].ID, ns[3].ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("expected only the recipient's own notification to be acknowledged, got %d", n)
+	}
+	unread, _, _ := s.ListNotifications(ctx, "researcher", true, model.Page{})
+	all, _, _ := s.ListNotifications(ctx, "researcher", false, model.Page{})
+	if len(unread) != 2 || len(all) != 3 || all[2].ReadAt == nil {
+		t.Errorf("unexpected inbox after ack: %d unread, %d total", len(unread), len(all))
+	}
+	if n, _ := s.AckNotifications(ctx, "researcher", nil); n != 2 {
+		t.Errorf("expected acknowledging all to mark 2 read, got %d", n)
+	}
+	if coder, _, _ := s.ListNotifications(ctx, "coder", true, model.Page{}); len(coder) != 1 {
+		t.Errorf("expected other inboxes to be untouched, got %d", len(coder))
+	}
+}
+
+func TestWatchers(t *testing.T) {
+	s, db := setupTestDB(t)
+	defer db.Close()
+	ctx := context.Background()
+	_, seeded := seedBoard(t, s, 1, 1)
+	c := seeded[0]
+
+	for _, w := range []string{"supervisor", "pm", "supervisor"} {
+		if err := s.AddWatcher(ctx, c.ID, w); err != nil {
+			t.Fatal(err)
+		}
+	}
+	watchers, err := s.ListWatchers(ctx, c.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(watchers, ",") != "supervisor,pm" {
+		t.Errorf("expected watchers once each in the order they started, got %v", watchers)
+	}
+	if err := s.RemoveWatcher(ctx, c.ID, "supervisor"); err != nil {
+		t.Fatal(err)
+	}
+	detail, err := s.GetCardDetail(ctx, c.ID, 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(detail.Watchers) != 1 || detail.Watchers[0] != "pm" {
+		t.Errorf("expected the card detail to list its watchers, got %v", detail.Watchers)
+	}
+
+	s.DeleteCard(ctx, c.ID)
+	s.PurgeDeleted(ctx, time.Now().Add(time.Hour))
+	var n int
+	db.QueryRow("SELECT COUNT(*) FROM card_watchers").Scan(&n)
+	if n != 0 {
+		t.Errorf("expected purging the card to drop its watchers, got %d", n)
+	}
+}
+
+func TestTemplates(t *testing.T) {
+	s, db := setupTestDB(t)
+	defer db.Close()
+	ctx := context.Background()
+
+	board := &model.Template{ID: model.NewID(), Name: "Sprint", Kind: model.TemplateBoard, Board: &model.BoardTemplate{
+		Lists: []model.ListTemplate{{Name: "Todo"}, {Name: "Done", Status: model.StatusDone}},
+	}}
+	card := &model.Template{ID: model.NewID(), Name: "Bug", Kind: model.TemplateCard, Card: &model.CardTemplate{
+		Title: "Fix {{summary}}", Checklist: []string{"Reproduce", "Fix"},
+	}}
+	for _, tmpl := range []*model.Template{board, card} {
+		if err := s.CreateTemplate(ctx, tmpl); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := s.GetTemplate(ctx, board.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Card != nil || len(got.Board.Lists) != 2 || got.Board.Lists[1].Status != model.StatusDone {
+		t.Errorf("expected the board template to round-trip, got %+v", got.Board)
+	}
+	all, err := s.ListTemplates(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 2 || all[0].Name != "Bug" {
+		t.Errorf("expected both templates ordered by name, got %v", all)
+	}
+	cards, _ := s.ListTemplates(ctx, model.TemplateCard)
+	if len(cards) != 1 || cards[0].Card.Title != "Fix {{summary}}" {
+		t.Errorf("expected only the card template, got %v", cards)
+	}
+
+	card.Card.Checklist = append(card.Card.Checklist, "Test")
+	if err := s.UpdateTemplate(ctx, card); err != nil {
+		t.Fatal(err)
+	}
+	got, _ = s.GetTemplate(ctx, card.ID)
+	if len(got.Card.Checklist) != 3 {
+		t.Errorf("expected the updated checklist, got %v", got.Card.Checklist)
+	}
+
+	if err := s.DeleteTemplate(ctx, card.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.GetTemplate(ctx, card.ID); err == nil {
+		t.Error("expected the deleted template to be gone")
+	}
+	if err := s.DeleteTemplate(ctx, card.ID); err == nil {
+		t.Error("expected deleting a missing template to fail")
+	}
+}
@@ -2,35 +2,63 @@ package store
 
 import (
 	"context"
+	"time"
 
+	"github.com/aellingwood/cielo/internal/filter"
 	"github.com/aellingwood/cielo/internal/model"
 )
 
 type Store interface {
+	// InTx runs fn against a store bound to a transaction, committing if fn
+	// succeeds and rolling back if it fails. Calls on a store that is
+	// already bound to a transaction join it.
+	InTx(ctx context.Context, fn func(tx Store) error) error
+
 	CreateBoard(ctx context.Context, board *model.Board) error
 	GetBoard(ctx context.Context, id string) (*model.Board, error)
-	ListBoards(ctx context.Context) ([]model.Board, error)
+	ListBoards(ctx context.Context, page model.Page) ([]model.Board, string, error)
 	UpdateBoard(ctx context.Context, board *model.Board) error
 	DeleteBoard(ctx context.Context, id string) error
+	RestoreBoard(ctx context.Context, id string) error
+	ListDeletedBoards(ctx context.Context) ([]model.Board, error)
 
 	CreateList(ctx context.Context, list *model.List) error
 	GetList(ctx context.Context, id string) (*model.List, error)
-	ListListsByBoard(ctx context.Context, boardID string) ([]model.List, error)
+	ListListsByBoard(ctx context.Context, boardID string, includeArchived bool) ([]model.List, error)
 	UpdateList(ctx context.Context, list *model.List) error
+	MoveList(ctx context.Context, listID string, pos model.Placement) error
+	ArchiveList(ctx context.Context, id string) error
+	UnarchiveList(ctx context.Context, id string) error
 	DeleteList(ctx context.Context, id string) error
+	RestoreList(ctx context.Context, id string) error
 
 	CreateCard(ctx context.Context, card *model.Card) error
 	GetCard(ctx context.Context, id string) (*model.Card, error)
-	ListCardsByList(ctx context.Context, listID string) ([]model.Card, error)
+	ListCardsByList(ctx context.Context, listID string, includeArchived bool, page model.Page) ([]model.Card, string, error)
+	ListCardsByBoard(ctx context.Context, boardID string, includeArchived bool, perList int) ([]model.Card, map[string]string, error)
+	GetCardDetail(ctx context.Context, id string, activity int) (*model.Card, error)
 	UpdateCard(ctx context.Context, card *model.Card) error
-	MoveCard(ctx context.Context, cardID, targetListID string, position int) error
+	MoveCard(ctx context.Context, cardID, targetListID string, pos model.Placement) error
+	ArchiveCard(ctx context.Context, id string) error
+	UnarchiveCard(ctx context.Context, id string) error
 	DeleteCard(ctx context.Context, id string) error
-	SearchCards(ctx context.Context, boardID, query, assignee, status, label string) ([]model.Card, error)
+	RestoreCard(ctx context.Context, id string) error
+	SearchCards(ctx context.Context, boardID string, q *filter.Query, includeArchived bool, page model.Page) ([]model.Card, string, error)
+	CountCardsByStatus(ctx context.Context, boardID string) (map[string]int, error)
+	CountCardsByList(ctx context.Context, boardID string, now time.Time) (map[string]model.CardCounts, error)
+	ClaimOverdueCards(ctx context.Context, now time.Time) (map[string][]model.Card, error)
+	SetCardParent(ctx context.Context, cardID, parentID string) error
+	ListChildren(ctx context.Context, parentID string) ([]model.Card, error)
+	GetRollup(ctx context.Context, cardID string) (*model.Rollup, error)
 
 	AddDependency(ctx context.Context, dep *model.CardDependency) error
 	RemoveDependency(ctx context.Context, cardID, dependsOnCardID string) error
 	GetDependencies(ctx context.Context, cardID string) ([]model.Card, error)
 	GetDependents(ctx context.Context, cardID string) ([]model.Card, error)
+	CountDependencies(ctx context.Context, cardIDs []string) (deps, dependents map[string]int, err error)
+
+	ListTrash(ctx context.Context, boardID string) ([]model.List, []model.Card, error)
+	PurgeDeleted(ctx context.Context, before time.Time) (n int64, blobs []string, err error)
 
 	CreateLabel(ctx context.Context, label *model.Label) error
 	GetLabel(ctx context.Context, id string) (*model.Label, error)
@@ -40,8 +68,62 @@ type Store interface {
 	AddLabelToCard(ctx context.Context, cardID, labelID string) error
 	RemoveLabelFromCard(ctx context.Context, cardID, labelID string) error
 	GetLabelsForCard(ctx context.Context, cardID string) ([]model.Label, error)
+	GetLabelsForCards(ctx context.Context, cardIDs []string) (map[string][]model.Label, error)
+
+	CreateChecklistItem(ctx context.Context, item *model.ChecklistItem, pos model.Placement) error
+	GetChecklistItem(ctx context.Context, id string) (*model.ChecklistItem, error)
+	ListChecklistItems(ctx context.Context, cardID string) ([]model.ChecklistItem, error)
+	UpdateChecklistItem(ctx context.Context, item *model.ChecklistItem) error
+	MoveChecklistItem(ctx context.Context, id string, pos model.Placement) error
+	DeleteChecklistItem(ctx context.Context, id string) error
+	CountChecklists(ctx context.Context, cardIDs []string) (done, total map[string]int, err error)
+
+	CreateAttachment(ctx context.Context, a *model.Attachment) error
+	GetAttachment(ctx context.Context, id string) (*model.Attachment, error)
+	ListAttachments(ctx context.Context, cardID string) ([]model.Attachment, error)
+	DeleteAttachment(ctx context.Context, id string) error
+
+	CreateComment(ctx context.Context, c *model.Comment) error
+	GetComment(ctx context.Context, id string) (*model.Comment, error)
+	ListComments(ctx context.Context, cardID string, page model.Page) ([]model.Comment, string, error)
+	UpdateComment(ctx context.Context, c *model.Comment) error
+
+	AddWatcher(ctx context.Context, cardID, watcher string) error
+	RemoveWatcher(ctx context.Context, cardID, watcher string) error
+	ListWatchers(ctx context.Context, cardID string) ([]string, error)
+
+	CreateNotifications(ctx context.Context, notifications []*model.Notification) error
+	ListNotifications(ctx context.Context, recipient string, unreadOnly bool, page model.Page) ([]model.Notification, string, error)
+	AckNotifications(ctx context.Context, recipient string, ids []string) (int64, error)
+
+	CreateCustomField(ctx context.Context, field *model.CustomField, pos model.Placement) error
+	GetCustomField(ctx context.Context, id string) (*model.CustomField, error)
+	ListCustomFields(ctx context.Context, boardID string) ([]model.CustomField, error)
+	UpdateCustomField(ctx context.Context, field *model.CustomField) error
+	MoveCustomField(ctx context.Context, id string, pos model.Placement) error
+	DeleteCustomField(ctx context.Context, id string) error
+	SetCardFieldValues(ctx context.Context, cardID string, values map[string]any) error
+	GetFieldValuesForCards(ctx context.Context, cardIDs []string) (map[string]map[string]any, error)
+
+	CreateView(ctx context.Context, view *model.View) error
+	GetView(ctx context.Context, id string) (*model.View, error)
+	ListViewsByBoard(ctx context.Context, boardID, owner string) ([]model.View, error)
+	UpdateView(ctx context.Context, view *model.View) error
+	DeleteView(ctx context.Context, id string) error
+
+	CreateTemplate(ctx context.Context, t *model.Template) error
+	GetTemplate(ctx context.Context, id string) (*model.Template, error)
+	ListTemplates(ctx context.Context, kind string) ([]model.Template, error)
+	UpdateTemplate(ctx context.Context, t *model.Template) error
+	DeleteTemplate(ctx context.Context, id string) error
+
+	GetImportRef(ctx context.Context, source, externalID string) (string, error)
+	SetImportRef(ctx context.Context, source, externalID, entityType, entityID string) error
+
+	CreateAuditEntry(ctx context.Context, entry *model.AuditEntry) error
+	ListAudit(ctx context.Context, f model.AuditFilter) ([]model.AuditEntry, error)
 
 	CreateActivity(ctx context.Context, entry *model.ActivityLog) error
-	ListActivityByCard(ctx context.Context, cardID string, limit int) ([]model.ActivityLog, error)
-	ListActivityByBoard(ctx context.Context, boardID string, limit int) ([]model.ActivityLog, error)
+	ListActivityByCard(ctx context.Context, cardID string, page model.Page) ([]model.ActivityLog, string, error)
+	ListActivityByBoard(ctx context.Context, boardID string, page model.Page) ([]model.ActivityLog, string, error)
 }
@@ -0,0 +1,115 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aellingwood/cielo/internal/model"
+)
+
+const templateColumns = "id, name, kind, description, spec, created_by, created_at, updated_at"
+
+func scanTemplate(row interface{ Scan(...any) error }) (*model.Template, error) {
+	var t model.Template
+	var spec, createdAt, updatedAt string
+	if err := row.Scan(&t.ID, &t.Name, &t.Kind, &t.Description, &spec, &t.CreatedBy, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+	var err error
+	switch t.Kind {
+	case model.TemplateBoard:
+		err = json.Unmarshal([]byte(spec), &t.Board)
+	case model.TemplateCard:
+		err = json.Unmarshal([]byte(spec), &t.Card)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("template %s: invalid spec: %w", t.ID, err)
+	}
+	t.CreatedAt = parseTime(createdAt)
+	t.UpdatedAt = parseTime(updatedAt)
+	return &t, nil
+}
+
+// encodeTemplateSpec encodes the board or card template that t's kind
+// calls for.
+func encodeTemplateSpec(t *model.Template) string {
+	var data []byte
+	if t.Kind == model.TemplateBoard {
+		data, _ = json.Marshal(t.Board)
+	} else {
+		data, _ = json.Marshal(t.Card)
+	}
+	return string(data)
+}
+
+func (s *SQLiteStore) CreateTemplate(ctx context.Context, t *model.Template) error {
+	ts := now()
+	if _, err := s.db.ExecContext(ctx,
+		"INSERT INTO templates ("+templateColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		t.ID, t.Name, t.Kind, t.Description, encodeTemplateSpec(t), t.CreatedBy, ts, ts); err != nil {
+		return err
+	}
+	t.CreatedAt = parseTime(ts)
+	t.UpdatedAt = t.CreatedAt
+	return nil
+}
+
+func (s *SQLiteStore) GetTemplate(ctx context.Context, id string) (*model.Template, error) {
+	t, err := scanTemplate(s.db.QueryRowContext(ctx, "SELECT "+templateColumns+" FROM templates WHERE id = ?", id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("template not found: %s", id)
+	}
+	return t, err
+}
+
+// ListTemplates returns the templates of the given kind, or of every kind
+// when kind is empty, ordered by name.
+func (s *SQLiteStore) ListTemplates(ctx context.Context, kind string) ([]model.Template, error) {
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT "+templateColumns+" FROM templates WHERE ? IN ('', kind) ORDER BY name, id", kind)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	templates := []model.Template{}
+	for rows.Next() {
+		t, err := scanTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, *t)
+	}
+	return templates, rows.Err()
+}
+
+// UpdateTemplate saves the template's name, description, and spec. Its
+// kind cannot change.
+func (s *SQLiteStore) UpdateTemplate(ctx context.Context, t *model.Template) error {
+	ts := now()
+	res, err := s.db.ExecContext(ctx,
+		"UPDATE templates SET name = ?, description = ?, spec = ?, updated_at = ? WHERE id = ?",
+		t.Name, t.Description, encodeTemplateSpec(t), ts, t.ID)
+	if err != nil {
+		return err
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return fmt.Errorf("template not found: %s", t.ID)
+	}
+	t.UpdatedAt = parseTime(ts)
+	return nil
+}
+
+func (s *SQLiteStore) DeleteTemplate(ctx context.Context, id string) error {
+	res, err := s.db.ExecContext(ctx, "DELETE FROM templates WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return fmt.Errorf("template not found: %s", id)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aellingwood/cielo/internal/model"
+)
+
+const viewColumns = "id, board_id, name, filter, sort, columns, owner, created_by, created_at, updated_at"
+
+func scanView(row interface{ Scan(...any) error }) (*model.View, error) {
+	var v model.View
+	var columns, createdAt, updatedAt string
+	if err := row.Scan(&v.ID, &v.BoardID, &v.Name, &v.Filter, &v.Sort, &columns, &v.Owner, &v.CreatedBy, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(columns), &v.Columns); err != nil {
+		return nil, fmt.Errorf("view %s: invalid columns: %w", v.ID, err)
+	}
+	if v.Columns == nil {
+		v.Columns = []string{}
+	}
+	v.CreatedAt = parseTime(createdAt)
+	v.UpdatedAt = parseTime(updatedAt)
+	return &v, nil
+}
+
+func encodeColumns(columns []string) string {
+	if columns == nil {
+		columns = []string{}
+	}
+	data, _ := json.Marshal(columns)
+	return string(data)
+}
+
+func (s *SQLiteStore) CreateView(ctx context.Context, view *model.View) error {
+	ts := now()
+	_, err := s.db.ExecContext(ctx,
+		"INSERT INTO views ("+viewColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		view.ID, view.BoardID, view.Name, view.Filter, view.Sort, encodeColumns(view.Columns),
+		view.Owner, view.CreatedBy, ts, ts)
+	if err != nil {
+		return err
+	}
+	view.CreatedAt = parseTime(ts)
+	view.UpdatedAt = view.CreatedAt
+	return nil
+}
+
+func (s *SQLiteStore) GetView(ctx context.Context, id string) (*model.View, error) {
+	v, err := scanView(s.db.QueryRowContext(ctx, "SELECT "+viewColumns+" FROM views WHERE id = ?", id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("view not found: %s", id)
+	}
+	return v, err
+}
+
+// ListViewsByBoard returns the board's shared views and those private to
+// owner, ordered by name.
+func (s *SQLiteStore) ListViewsByBoard(ctx context.Context, boardID, owner string) ([]model.View, error) {
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT "+viewColumns+" FROM views WHERE board_id = ? AND owner IN ('', ?) ORDER BY name, owner",
+		boardID, owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var views []model.View
+	for rows.Next() {
+		v, err := scanView(rows)
+		if err != nil {
+			return nil, err
+		}
+		views = append(views, *v)
+	}
+	return views, rows.Err()
+}
+
+func (s *SQLiteStore) UpdateView(ctx context.Context, view *model.View) error {
+	ts := now()
+	res, err := s.db.ExecContext(ctx,
+		"UPDATE views SET name = ?, filter = ?, sort = ?, columns = ?, owner = ?, updated_at = ? WHERE id = ?",
+		view.Name, view.Filter, view.Sort, encodeColumns(view.Columns), view.Owner, ts, view.ID)
+	if err != nil {
+		return err
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return fmt.Errorf("view not found: %s", view.ID)
+	}
+	view.UpdatedAt = parseTime(ts)
+	return nil
+}
+
+func (s *SQLiteStore) DeleteView(ctx context.Context, id string) error {
+	_, err := s.db.ExecContext(ctx, "DELETE FROM views WHERE id = ?", id)
+	return err
+}
@@ -0,0 +1,32 @@
+package store
+
+import (
+	"context"
+)
+
+// AddWatcher makes watcher follow the card. Watching a card twice is not an
+// error.
+func (s *SQLiteStore) AddWatcher(ctx context.Context, cardID, watcher string) error {
+	_, err := s.db.ExecContext(ctx,
+		"INSERT INTO card_watchers (card_id, watcher, created_at) VALUES (?, ?, ?) ON CONFLICT DO NOTHING",
+		cardID, watcher, now())
+	return err
+}
+
+// RemoveWatcher stops watcher following the card.
+func (s *SQLiteStore) RemoveWatcher(ctx context.Context, cardID, watcher string) error {
+	_, err := s.db.ExecContext(ctx,
+		"DELETE FROM card_watchers WHERE card_id = ? AND watcher = ?", cardID, watcher)
+	return err
+}
+
+// ListWatchers returns the card's watchers in the order they started
+// watching.
+func (s *SQLiteStore) ListWatchers(ctx context.Context, cardID string) ([]string, error) {
+	watchers, err := s.queryIDs(ctx,
+		"SELECT watcher FROM card_watchers WHERE card_id = ? ORDER BY created_at, rowid", cardID)
+	if watchers == nil && err == nil {
+		watchers = []string{}
+	}
+	return watchers, err
+}
@@ -0,0 +1,33 @@
+-- Boards define their own status workflow, so the fixed status CHECK on
+-- cards has to go. SQLite cannot drop a constraint in place; rebuild the
+-- table with foreign keys disabled so dependent rows are not cascaded away.
+PRAGMA foreign_keys=OFF;
+
+BEGIN;
+
+ALTER TABLE boards ADD COLUMN workflow TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE cards_new (
+    id          TEXT PRIMARY KEY,
+    list_id     TEXT NOT NULL REFERENCES lists(id) ON DELETE CASCADE,
+    title       TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    position    INTEGER NOT NULL DEFAULT 0,
+    assignee    TEXT NOT NULL DEFAULT '',
+    status      TEXT NOT NULL DEFAULT 'unassigned',
+    priority    TEXT NOT NULL DEFAULT 'medium' CHECK(priority IN ('low','medium','high','critical')),
+    due_date    TEXT,
+    created_at  TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    updated_at  TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now'))
+);
+
+INSERT INTO cards_new (id, list_id, title, description, position, assignee, status, priority, due_date, created_at, updated_at)
+    SELECT id, list_id, title, description, position, assignee, status, priority, due_date, created_at, updated_at FROM cards;
+
+DROP TABLE cards;
+ALTER TABLE cards_new RENAME TO cards;
+CREATE INDEX IF NOT EXISTS idx_cards_list_id ON cards(list_id);
+
+COMMIT;
+
+PRAGMA foreign_keys=ON;
@@ -0,0 +1 @@
+ALTER TABLE lists ADD COLUMN status TEXT NOT NULL DEFAULT '';
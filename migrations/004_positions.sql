@@ -0,0 +1,14 @@
+-- Positions used to be written as given, leaving duplicates and gaps.
+-- Renumber every list and card to a contiguous 0..n-1 order, breaking ties
+-- by ID (creation order for UUIDv7).
+UPDATE lists SET position = (
+    SELECT r.rn - 1 FROM (
+        SELECT id, ROW_NUMBER() OVER (PARTITION BY board_id ORDER BY position, id) AS rn FROM lists
+    ) r WHERE r.id = lists.id
+);
+
+UPDATE cards SET position = (
+    SELECT r.rn - 1 FROM (
+        SELECT id, ROW_NUMBER() OVER (PARTITION BY list_id ORDER BY position, id) AS rn FROM cards
+    ) r WHERE r.id = cards.id
+);
@@ -0,0 +1,34 @@
+PRAGMA foreign_keys=OFF;
+
+BEGIN;
+
+-- Archive and trash support: rows are hidden by timestamp instead of being
+-- deleted, and only purged once they have been in the trash long enough.
+ALTER TABLE boards ADD COLUMN deleted_at TEXT;
+ALTER TABLE lists ADD COLUMN archived_at TEXT;
+ALTER TABLE lists ADD COLUMN deleted_at TEXT;
+ALTER TABLE cards ADD COLUMN archived_at TEXT;
+ALTER TABLE cards ADD COLUMN deleted_at TEXT;
+
+-- Activity actions are validated in Go; drop the fixed CHECK so new actions
+-- such as archived and restored can be recorded. Foreign keys are off so the
+-- rebuild does not cascade away dependent rows.
+CREATE TABLE activity_log_new (
+    id         TEXT PRIMARY KEY,
+    card_id    TEXT NOT NULL REFERENCES cards(id) ON DELETE CASCADE,
+    actor      TEXT NOT NULL,
+    action     TEXT NOT NULL,
+    detail     TEXT NOT NULL DEFAULT '{}',
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now'))
+);
+
+INSERT INTO activity_log_new (id, card_id, actor, action, detail, created_at)
+    SELECT id, card_id, actor, action, detail, created_at FROM activity_log;
+
+DROP TABLE activity_log;
+ALTER TABLE activity_log_new RENAME TO activity_log;
+CREATE INDEX IF NOT EXISTS idx_activity_card_id ON activity_log(card_id);
+
+COMMIT;
+
+PRAGMA foreign_keys=ON;
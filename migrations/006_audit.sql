@@ -0,0 +1,17 @@
+-- Board-wide audit trail. Rows carry no foreign keys so they outlive the
+-- boards, lists, and cards they describe.
+CREATE TABLE IF NOT EXISTS audit_log (
+    id          TEXT PRIMARY KEY,
+    board_id    TEXT NOT NULL DEFAULT '',
+    entity_type TEXT NOT NULL,
+    entity_id   TEXT NOT NULL,
+    action      TEXT NOT NULL,
+    actor       TEXT NOT NULL,
+    before      TEXT,
+    after       TEXT,
+    source      TEXT NOT NULL DEFAULT '',
+    created_at  TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now'))
+);
+CREATE INDEX IF NOT EXISTS idx_audit_board_created ON audit_log(board_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_entity ON audit_log(entity_type, entity_id);
+CREATE INDEX IF NOT EXISTS idx_audit_actor ON audit_log(actor);
@@ -0,0 +1,70 @@
+-- Full-text search over card titles, descriptions, and comments.
+-- card_search holds one document per card under a stable integer id and
+-- is the external content table for the cards_fts index. Triggers keep both
+-- in sync with cards and comment activity.
+CREATE TABLE IF NOT EXISTS card_search (
+    id          INTEGER PRIMARY KEY,
+    card_id     TEXT NOT NULL UNIQUE,
+    title       TEXT NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    comments    TEXT NOT NULL DEFAULT ''
+);
+
+CREATE VIRTUAL TABLE IF NOT EXISTS cards_fts USING fts5(
+    title, description, comments,
+    content = 'card_search',
+    content_rowid = 'id',
+    tokenize = 'porter unicode61'
+);
+
+CREATE TRIGGER IF NOT EXISTS card_search_ai AFTER INSERT ON card_search BEGIN
+    INSERT INTO cards_fts (rowid, title, description, comments)
+    VALUES (new.id, new.title, new.description, new.comments);
+END;
+
+CREATE TRIGGER IF NOT EXISTS card_search_ad AFTER DELETE ON card_search BEGIN
+    INSERT INTO cards_fts (cards_fts, rowid, title, description, comments)
+    VALUES ('delete', old.id, old.title, old.description, old.comments);
+END;
+
+CREATE TRIGGER IF NOT EXISTS card_search_au AFTER UPDATE ON card_search BEGIN
+    INSERT INTO cards_fts (cards_fts, rowid, title, description, comments)
+    VALUES ('delete', old.id, old.title, old.description, old.comments);
+    INSERT INTO cards_fts (rowid, title, description, comments)
+    VALUES (new.id, new.title, new.description, new.comments);
+END;
+
+CREATE TRIGGER IF NOT EXISTS cards_search_ai AFTER INSERT ON cards BEGIN
+    INSERT INTO card_search (card_id, title, description) VALUES (new.id, new.title, new.description);
+END;
+
+CREATE TRIGGER IF NOT EXISTS cards_search_au AFTER UPDATE OF title, description ON cards BEGIN
+    UPDATE card_search SET title = new.title, description = new.description WHERE card_id = new.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS cards_search_ad AFTER DELETE ON cards BEGIN
+    DELETE FROM card_search WHERE card_id = old.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS comments_search_ai AFTER INSERT ON activity_log
+WHEN new.action = 'comment' BEGIN
+    UPDATE card_search SET comments = (
+        SELECT COALESCE(group_concat(json_extract(detail, '$.text'), char(10)), '')
+        FROM activity_log WHERE card_id = new.card_id AND action = 'comment'
+    ) WHERE card_id = new.card_id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS comments_search_ad AFTER DELETE ON activity_log
+WHEN old.action = 'comment' BEGIN
+    UPDATE card_search SET comments = (
+        SELECT COALESCE(group_concat(json_extract(detail, '$.text'), char(10)), '')
+        FROM activity_log WHERE card_id = old.card_id AND action = 'comment'
+    ) WHERE card_id = old.card_id;
+END;
+
+INSERT INTO card_search (card_id, title, description, comments)
+SELECT c.id, c.title, c.description, COALESCE((
+    SELECT group_concat(json_extract(a.detail, '$.text'), char(10))
+    FROM activity_log a WHERE a.card_id = c.id AND a.action = 'comment'
+), '')
+FROM cards c WHERE c.id NOT IN (SELECT card_id FROM card_search);
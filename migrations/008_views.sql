@@ -0,0 +1,16 @@
+-- Saved card searches. An empty owner makes the view visible to everyone on
+-- the board; otherwise only the owner sees it.
+CREATE TABLE IF NOT EXISTS views (
+    id         TEXT PRIMARY KEY,
+    board_id   TEXT NOT NULL REFERENCES boards(id) ON DELETE CASCADE,
+    name       TEXT NOT NULL,
+    filter     TEXT NOT NULL DEFAULT '',
+    sort       TEXT NOT NULL DEFAULT '',
+    columns    TEXT NOT NULL DEFAULT '[]',
+    owner      TEXT NOT NULL DEFAULT '',
+    created_by TEXT NOT NULL DEFAULT '',
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    updated_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    UNIQUE (board_id, owner, name)
+);
+CREATE INDEX IF NOT EXISTS idx_views_board ON views(board_id);
@@ -0,0 +1,6 @@
+-- Records when a card's overdue notification was published so the overdue
+-- scheduler announces each due date once. Moving the due date later than the
+-- last notification re-arms it.
+ALTER TABLE cards ADD COLUMN overdue_notified_at TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_cards_due_date ON cards(due_date) WHERE due_date IS NOT NULL;
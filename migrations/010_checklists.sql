@@ -0,0 +1,14 @@
+-- Ordered checklist items on cards. completed_at records when an item was
+-- last marked done and is cleared when it is reopened.
+CREATE TABLE IF NOT EXISTS checklist_items (
+    id           TEXT PRIMARY KEY,
+    card_id      TEXT NOT NULL REFERENCES cards(id) ON DELETE CASCADE,
+    text         TEXT NOT NULL,
+    done         INTEGER NOT NULL DEFAULT 0,
+    assignee     TEXT NOT NULL DEFAULT '',
+    position     INTEGER NOT NULL DEFAULT 0,
+    completed_at TEXT,
+    created_at   TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    updated_at   TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now'))
+);
+CREATE INDEX IF NOT EXISTS idx_checklist_items_card ON checklist_items(card_id, position);
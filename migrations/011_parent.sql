@@ -0,0 +1,5 @@
+-- Optional parent card, for breaking an epic into child cards that may sit
+-- on other lists. Purging a parent detaches its children.
+ALTER TABLE cards ADD COLUMN parent_id TEXT REFERENCES cards(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_cards_parent_id ON cards(parent_id) WHERE parent_id IS NOT NULL;
@@ -0,0 +1,23 @@
+-- Board-level custom field definitions and the cards' values for them.
+-- Values are stored as text: numbers in their shortest decimal form and
+-- dates in the same layout as other timestamps, so that both compare
+-- correctly in filters. options lists the allowed values of enum fields.
+CREATE TABLE IF NOT EXISTS custom_fields (
+    id         TEXT PRIMARY KEY,
+    board_id   TEXT NOT NULL REFERENCES boards(id) ON DELETE CASCADE,
+    name       TEXT NOT NULL,
+    type       TEXT NOT NULL,
+    options    TEXT NOT NULL DEFAULT '[]',
+    position   INTEGER NOT NULL DEFAULT 0,
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    updated_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    UNIQUE (board_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS card_field_values (
+    card_id  TEXT NOT NULL REFERENCES cards(id) ON DELETE CASCADE,
+    field_id TEXT NOT NULL REFERENCES custom_fields(id) ON DELETE CASCADE,
+    value    TEXT NOT NULL,
+    PRIMARY KEY (card_id, field_id)
+);
+CREATE INDEX IF NOT EXISTS idx_card_field_values_field ON card_field_values(field_id, value);
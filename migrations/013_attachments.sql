@@ -0,0 +1,15 @@
+-- Files attached to cards. Contents live in the blob store under the
+-- attachment's ID; attachments with a url only reference an external
+-- resource and have no stored contents.
+CREATE TABLE IF NOT EXISTS attachments (
+    id          TEXT PRIMARY KEY,
+    card_id     TEXT NOT NULL REFERENCES cards(id) ON DELETE CASCADE,
+    name        TEXT NOT NULL,
+    size        INTEGER NOT NULL DEFAULT 0,
+    mime_type   TEXT NOT NULL DEFAULT '',
+    sha256      TEXT NOT NULL DEFAULT '',
+    url         TEXT NOT NULL DEFAULT '',
+    uploaded_by TEXT NOT NULL DEFAULT '',
+    created_at  TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now'))
+);
+CREATE INDEX IF NOT EXISTS idx_attachments_card ON attachments(card_id, created_at);
@@ -0,0 +1,55 @@
+-- Comments on cards. A comment may reply to another comment on the same
+-- card. Deleting a comment clears its body and sets deleted_at, so that its
+-- replies keep their place in the thread. mentions holds the names the body
+-- mentions with @name, as a JSON array.
+CREATE TABLE IF NOT EXISTS comments (
+    id         TEXT PRIMARY KEY,
+    card_id    TEXT NOT NULL REFERENCES cards(id) ON DELETE CASCADE,
+    parent_id  TEXT REFERENCES comments(id) ON DELETE CASCADE,
+    author     TEXT NOT NULL,
+    body       TEXT NOT NULL,
+    mentions   TEXT NOT NULL DEFAULT '[]',
+    edited_at  TEXT,
+    deleted_at TEXT,
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    updated_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now'))
+);
+CREATE INDEX IF NOT EXISTS idx_comments_card ON comments(card_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_comments_parent ON comments(parent_id);
+
+-- Comments used to live only in the activity log; carry them over under
+-- the IDs of their activity entries.
+INSERT INTO comments (id, card_id, author, body, created_at, updated_at)
+SELECT id, card_id, actor, COALESCE(json_extract(detail, '$.text'), ''), created_at, created_at
+FROM activity_log WHERE action = 'comment' AND id NOT IN (SELECT id FROM comments);
+
+-- Index comment bodies from the comments table instead of the activity log,
+-- leaving deleted comments out.
+DROP TRIGGER IF EXISTS comments_search_ai;
+DROP TRIGGER IF EXISTS comments_search_ad;
+
+CREATE TRIGGER IF NOT EXISTS comments_search_ai AFTER INSERT ON comments BEGIN
+    UPDATE card_search SET comments = (
+        SELECT COALESCE(group_concat(body, char(10)), '')
+        FROM (SELECT body FROM comments WHERE card_id = new.card_id AND deleted_at IS NULL ORDER BY created_at, id)
+    ) WHERE card_id = new.card_id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS comments_search_au AFTER UPDATE OF body, deleted_at ON comments BEGIN
+    UPDATE card_search SET comments = (
+        SELECT COALESCE(group_concat(body, char(10)), '')
+        FROM (SELECT body FROM comments WHERE card_id = new.card_id AND deleted_at IS NULL ORDER BY created_at, id)
+    ) WHERE card_id = new.card_id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS comments_search_ad AFTER DELETE ON comments BEGIN
+    UPDATE card_search SET comments = (
+        SELECT COALESCE(group_concat(body, char(10)), '')
+        FROM (SELECT body FROM comments WHERE card_id = old.card_id AND deleted_at IS NULL ORDER BY created_at, id)
+    ) WHERE card_id = old.card_id;
+END;
+
+UPDATE card_search SET comments = COALESCE((
+    SELECT group_concat(body, char(10))
+    FROM (SELECT body FROM comments WHERE card_id = card_search.card_id AND deleted_at IS NULL ORDER BY created_at, id)
+), '');
@@ -0,0 +1,16 @@
+-- Per-recipient notifications: mentions, assignments, status changes on
+-- watched cards, and cards whose dependencies are all done. read_at is set
+-- when the recipient acknowledges the notification.
+CREATE TABLE IF NOT EXISTS notifications (
+    id         TEXT PRIMARY KEY,
+    recipient  TEXT NOT NULL,
+    type       TEXT NOT NULL,
+    board_id   TEXT NOT NULL DEFAULT '',
+    card_id    TEXT NOT NULL REFERENCES cards(id) ON DELETE CASCADE,
+    comment_id TEXT NOT NULL DEFAULT '',
+    actor      TEXT NOT NULL DEFAULT '',
+    message    TEXT NOT NULL,
+    read_at    TEXT,
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now'))
+);
+CREATE INDEX IF NOT EXISTS idx_notifications_recipient ON notifications(recipient, created_at);
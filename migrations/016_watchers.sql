@@ -0,0 +1,9 @@
+-- Actors following a card beyond its assignee. Watchers are notified when
+-- the card is updated, moved, or commented on.
+CREATE TABLE IF NOT EXISTS card_watchers (
+    card_id    TEXT NOT NULL REFERENCES cards(id) ON DELETE CASCADE,
+    watcher    TEXT NOT NULL,
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    PRIMARY KEY (card_id, watcher)
+);
+CREATE INDEX IF NOT EXISTS idx_card_watchers_watcher ON card_watchers(watcher);
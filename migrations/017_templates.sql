@@ -0,0 +1,13 @@
+-- Reusable blueprints for new boards and cards. spec holds the board or
+-- card template as JSON, according to kind.
+CREATE TABLE IF NOT EXISTS templates (
+    id          TEXT PRIMARY KEY,
+    name        TEXT NOT NULL,
+    kind        TEXT NOT NULL CHECK (kind IN ('board', 'card')),
+    description TEXT NOT NULL DEFAULT '',
+    spec        TEXT NOT NULL,
+    created_by  TEXT NOT NULL DEFAULT '',
+    created_at  TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    updated_at  TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now'))
+);
+CREATE INDEX IF NOT EXISTS idx_templates_kind ON templates(kind, name);
@@ -0,0 +1,11 @@
+-- Where imported entities came from: the ID each had in the source tool, so
+-- that importing the same export again updates what the last import created
+-- instead of duplicating it.
+CREATE TABLE IF NOT EXISTS import_refs (
+    source      TEXT NOT NULL,
+    external_id TEXT NOT NULL,
+    entity_type TEXT NOT NULL,
+    entity_id   TEXT NOT NULL,
+    created_at  TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    PRIMARY KEY (source, external_id)
+);
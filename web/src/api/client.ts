@@ -1,4 +1,4 @@
-import type { Board, Card, Label, ActivityLog, List } from './types';
+import type { Attachment, Board, BoardSummary, Card, ChecklistItem, Comment, CustomField, Label, ActivityLog, List, Notification, Page, Rollup, Template, View, ViewSpec } from './types';
 
 const BASE = '/api/v1';
 
@@ -11,18 +11,35 @@ async function json<T>(res: Response): Promise<T> {
   return res.json();
 }
 
+// all follows next_cursor until every page of a listing has been fetched.
+async function all<T>(url: string): Promise<T[]> {
+  const items: T[] = [];
+  let cursor: string | undefined;
+  do {
+    const sep = url.includes('?') ? '&' : '?';
+    const page = await fetch(cursor ? `${url}${sep}cursor=${encodeURIComponent(cursor)}` : url).then(r => json<Page<T>>(r));
+    items.push(...page.items);
+    cursor = page.next_cursor;
+  } while (cursor);
+  return items;
+}
+
 export const api = {
   boards: {
     list: (): Promise<Board[]> =>
-      fetch(`${BASE}/boards`).then(r => json(r)),
+      all<Board>(`${BASE}/boards?limit=200`),
     create: (data: { name: string; description?: string }): Promise<Board> =>
       fetch(`${BASE}/boards`, { method: 'POST', headers: { 'Content-Type': 'application/json' }, body: JSON.stringify(data) }).then(r => json(r)),
     get: (id: string): Promise<Board & { lists: List[] }> =>
       fetch(`${BASE}/boards/${id}`).then(r => json(r)),
+    summary: (id: string, includeCards = false): Promise<BoardSummary> =>
+      fetch(`${BASE}/boards/${id}/summary?include_cards=${includeCards}`).then(r => json(r)),
     update: (id: string, data: Partial<Board>): Promise<Board> =>
       fetch(`${BASE}/boards/${id}`, { method: 'PUT', headers: { 'Content-Type': 'application/json' }, body: JSON.stringify(data) }).then(r => json(r)),
     delete: (id: string): Promise<void> =>
       fetch(`${BASE}/boards/${id}`, { method: 'DELETE' }).then(r => json(r)),
+    clone: (id: string, data: { name?: string; cards?: boolean; labels?: boolean; dependencies?: boolean; activity?: boolean }): Promise<Board> =>
+      fetch(`${BASE}/boards/${id}/clone`, { method: 'POST', headers: { 'Content-Type': 'application/json' }, body: JSON.stringify(data) }).then(r => json(r)),
   },
   lists: {
     create: (boardId: string, data: { name: string; position?: number }): Promise<List> =>
@@ -33,14 +50,20 @@ export const api = {
       fetch(`${BASE}/lists/${id}`, { method: 'DELETE' }).then(r => json(r)),
   },
   cards: {
-    create: (listId: string, data: { title: string; description?: string; assignee?: string; priority?: string; position?: number }): Promise<Card> =>
+    create: (listId: string, data: { title: string; description?: string; assignee?: string; priority?: string; parent_id?: string; due_date?: string; position?: number }): Promise<Card> =>
       fetch(`${BASE}/lists/${listId}/cards`, { method: 'POST', headers: { 'Content-Type': 'application/json' }, body: JSON.stringify(data) }).then(r => json(r)),
     get: (id: string): Promise<Card> =>
       fetch(`${BASE}/cards/${id}`).then(r => json(r)),
+    children: (id: string): Promise<Card[]> =>
+      fetch(`${BASE}/cards/${id}/children`).then(r => json(r)),
+    rollup: (id: string): Promise<Rollup> =>
+      fetch(`${BASE}/cards/${id}/rollup`).then(r => json(r)),
     update: (id: string, data: Record<string, unknown>): Promise<Card> =>
       fetch(`${BASE}/cards/${id}`, { method: 'PUT', headers: { 'Content-Type': 'application/json' }, body: JSON.stringify(data) }).then(r => json(r)),
     delete: (id: string): Promise<void> =>
       fetch(`${BASE}/cards/${id}`, { method: 'DELETE' }).then(r => json(r)),
+    clone: (id: string, data: { list_id: string; title?: string; label_map?: Record<string, string>; dependencies?: boolean; position?: number }): Promise<Card> =>
+      fetch(`${BASE}/cards/${id}/clone`, { method: 'POST', headers: { 'Content-Type': 'application/json' }, body: JSON.stringify(data) }).then(r => json(r)),
     move: (id: string, data: { list_id: string; position: number }): Promise<Card> =>
       fetch(`${BASE}/cards/${id}/move`, { method: 'PUT', headers: { 'Content-Type': 'application/json' }, body: JSON.stringify(data) }).then(r => json(r)),
     assign: (id: string, assignee: string): Promise<Card> =>
@@ -66,13 +89,91 @@ export const api = {
     removeFromCard: (cardId: string, labelId: string): Promise<void> =>
       fetch(`${BASE}/cards/${cardId}/labels/${labelId}`, { method: 'DELETE' }).then(r => json(r)),
   },
+  checklist: {
+    list: (cardId: string): Promise<ChecklistItem[]> =>
+      fetch(`${BASE}/cards/${cardId}/checklist`).then(r => json(r)),
+    add: (cardId: string, data: { text: string; assignee?: string; position?: number }): Promise<ChecklistItem> =>
+      fetch(`${BASE}/cards/${cardId}/checklist`, { method: 'POST', headers: { 'Content-Type': 'application/json' }, body: JSON.stringify(data) }).then(r => json(r)),
+    update: (id: string, data: { text?: string; done?: boolean; assignee?: string; position?: number }): Promise<ChecklistItem> =>
+      fetch(`${BASE}/checklist/${id}`, { method: 'PUT', headers: { 'Content-Type': 'application/json' }, body: JSON.stringify(data) }).then(r => json(r)),
+    delete: (id: string): Promise<void> =>
+      fetch(`${BASE}/checklist/${id}`, { method: 'DELETE' }).then(r => json(r)),
+  },
+  watchers: {
+    watch: (cardId: string, watcher?: string): Promise<string[]> =>
+      fetch(`${BASE}/cards/${cardId}/watchers`, { method: 'POST', headers: { 'Content-Type': 'application/json' }, body: JSON.stringify({ watcher }) }).then(r => json(r)),
+    unwatch: (cardId: string, watcher: string): Promise<string[]> =>
+      fetch(`${BASE}/cards/${cardId}/watchers/${encodeURIComponent(watcher)}`, { method: 'DELETE' }).then(r => json(r)),
+  },
+  comments: {
+    list: (cardId: string): Promise<Comment[]> =>
+      all<Comment>(`${BASE}/cards/${cardId}/comments?limit=200`),
+    add: (cardId: string, data: { body: string; parent_id?: string }): Promise<Comment> =>
+      fetch(`${BASE}/cards/${cardId}/comments`, { method: 'POST', headers: { 'Content-Type': 'application/json' }, body: JSON.stringify(data) }).then(r => json(r)),
+    edit: (id: string, body: string): Promise<Comment> =>
+      fetch(`${BASE}/comments/${id}`, { method: 'PUT', headers: { 'Content-Type': 'application/json' }, body: JSON.stringify({ body }) }).then(r => json(r)),
+    delete: (id: string): Promise<void> =>
+      fetch(`${BASE}/comments/${id}`, { method: 'DELETE' }).then(r => json(r)),
+  },
+  attachments: {
+    list: (cardId: string): Promise<Attachment[]> =>
+      fetch(`${BASE}/cards/${cardId}/attachments`).then(r => json(r)),
+    upload: (cardId: string, file: File): Promise<Attachment> => {
+      const form = new FormData();
+      form.append('file', file);
+      return fetch(`${BASE}/cards/${cardId}/attachments`, { method: 'POST', body: form }).then(r => json(r));
+    },
+    addURL: (cardId: string, data: { url: string; name?: string }): Promise<Attachment> =>
+      fetch(`${BASE}/cards/${cardId}/attachments`, { method: 'POST', headers: { 'Content-Type': 'application/json' }, body: JSON.stringify(data) }).then(r => json(r)),
+    delete: (id: string): Promise<void> =>
+      fetch(`${BASE}/attachments/${id}`, { method: 'DELETE' }).then(r => json(r)),
+    contentURL: (id: string): string => `${BASE}/attachments/${id}/content`,
+  },
+  fields: {
+    list: (boardId: string): Promise<CustomField[]> =>
+      fetch(`${BASE}/boards/${boardId}/fields`).then(r => json(r)),
+    create: (boardId: string, data: { name: string; type: string; options?: string[]; position?: number }): Promise<CustomField> =>
+      fetch(`${BASE}/boards/${boardId}/fields`, { method: 'POST', headers: { 'Content-Type': 'application/json' }, body: JSON.stringify(data) }).then(r => json(r)),
+    update: (id: string, data: { name?: string; options?: string[]; position?: number }): Promise<CustomField> =>
+      fetch(`${BASE}/fields/${id}`, { method: 'PUT', headers: { 'Content-Type': 'application/json' }, body: JSON.stringify(data) }).then(r => json(r)),
+    delete: (id: string): Promise<void> =>
+      fetch(`${BASE}/fields/${id}`, { method: 'DELETE' }).then(r => json(r)),
+  },
+  views: {
+    list: (boardId: string): Promise<View[]> =>
+      fetch(`${BASE}/boards/${boardId}/views`).then(r => json(r)),
+    create: (boardId: string, data: ViewSpec & { name: string }): Promise<View> =>
+      fetch(`${BASE}/boards/${boardId}/views`, { method: 'POST', headers: { 'Content-Type': 'application/json' }, body: JSON.stringify(data) }).then(r => json(r)),
+    update: (id: string, data: ViewSpec): Promise<View> =>
+      fetch(`${BASE}/views/${id}`, { method: 'PUT', headers: { 'Content-Type': 'application/json' }, body: JSON.stringify(data) }).then(r => json(r)),
+    delete: (id: string): Promise<void> =>
+      fetch(`${BASE}/views/${id}`, { method: 'DELETE' }).then(r => json(r)),
+    run: (id: string, limit = 50, cursor = ''): Promise<Page<Card>> =>
+      fetch(`${BASE}/views/${id}/cards?limit=${limit}&cursor=${encodeURIComponent(cursor)}`).then(r => json(r)),
+  },
+  templates: {
+    list: (kind = ''): Promise<Template[]> =>
+      fetch(`${BASE}/templates?kind=${kind}`).then(r => json(r)),
+    delete: (id: string): Promise<void> =>
+      fetch(`${BASE}/templates/${id}`, { method: 'DELETE' }).then(r => json(r)),
+    createBoard: (id: string, data: { name?: string; variables?: Record<string, string> }): Promise<Board> =>
+      fetch(`${BASE}/templates/${id}/boards`, { method: 'POST', headers: { 'Content-Type': 'application/json' }, body: JSON.stringify(data) }).then(r => json(r)),
+    createCard: (id: string, data: { list_id: string; variables?: Record<string, string>; assignee?: string; position?: number }): Promise<Card> =>
+      fetch(`${BASE}/templates/${id}/cards`, { method: 'POST', headers: { 'Content-Type': 'application/json' }, body: JSON.stringify(data) }).then(r => json(r)),
+  },
+  notifications: {
+    list: (unread = true, limit = 50): Promise<Page<Notification>> =>
+      fetch(`${BASE}/notifications?unread=${unread}&limit=${limit}`).then(r => json(r)),
+    ack: (ids?: string[]): Promise<{ acknowledged: number }> =>
+      fetch(`${BASE}/notifications/ack`, { method: 'POST', headers: { 'Content-Type': 'application/json' }, body: JSON.stringify({ ids }) }).then(r => json(r)),
+  },
   activity: {
-    byCard: (cardId: string, limit = 50): Promise<ActivityLog[]> =>
-      fetch(`${BASE}/cards/${cardId}/activity?limit=${limit}`).then(r => json(r)),
-    byBoard: (boardId: string, limit = 50): Promise<ActivityLog[]> =>
-      fetch(`${BASE}/boards/${boardId}/activity?limit=${limit}`).then(r => json(r)),
+    byCard: (cardId: string, limit = 50, cursor = ''): Promise<Page<ActivityLog>> =>
+      fetch(`${BASE}/cards/${cardId}/activity?limit=${limit}&cursor=${encodeURIComponent(cursor)}`).then(r => json(r)),
+    byBoard: (boardId: string, limit = 50, cursor = ''): Promise<Page<ActivityLog>> =>
+      fetch(`${BASE}/boards/${boardId}/activity?limit=${limit}&cursor=${encodeURIComponent(cursor)}`).then(r => json(r)),
   },
-  search: (boardId: string, params: { q?: string; assignee?: string; status?: string; label?: string }): Promise<Card[]> => {
+  search: (boardId: string, params: { q?: string; filter?: string; assignee?: string; status?: string; label?: string; cursor?: string; limit?: string }): Promise<Page<Card>> => {
     const qs = new URLSearchParams(params as Record<string, string>).toString();
     return fetch(`${BASE}/boards/${boardId}/search?${qs}`).then(r => json(r));
   },
@@ -22,6 +22,27 @@ export function useCreateBoard() {
   });
 }
 
+export function useTemplates(kind: 'board' | 'card') {
+  return useQuery({ queryKey: ['templates', kind], queryFn: () => api.templates.list(kind) });
+}
+
+export function useCreateBoardFromTemplate() {
+  const qc = useQueryClient();
+  return useMutation({
+    mutationFn: ({ templateId, ...data }: { templateId: string; name?: string; variables?: Record<string, string> }) =>
+      api.templates.createBoard(templateId, data),
+    onSuccess: () => qc.invalidateQueries({ queryKey: ['boards'] }),
+  });
+}
+
+export function useCloneBoard() {
+  const qc = useQueryClient();
+  return useMutation({
+    mutationFn: (id: string) => api.boards.clone(id, { cards: true, labels: true, dependencies: true }),
+    onSuccess: () => qc.invalidateQueries({ queryKey: ['boards'] }),
+  });
+}
+
 export function useDeleteBoard() {
   const qc = useQueryClient();
   return useMutation({
@@ -110,6 +131,131 @@ export function useCreateLabel(boardId: string) {
   });
 }
 
+export function useCustomFields(boardId: string) {
+  return useQuery({
+    queryKey: ['fields', boardId],
+    queryFn: () => api.fields.list(boardId),
+    enabled: !!boardId,
+  });
+}
+
+export function useAddChecklistItem(boardId: string) {
+  const qc = useQueryClient();
+  return useMutation({
+    mutationFn: (data: { cardId: string; text: string }) => api.checklist.add(data.cardId, { text: data.text }),
+    onSuccess: () => {
+      qc.invalidateQueries({ queryKey: ['board', boardId] });
+      qc.invalidateQueries({ queryKey: ['card'] });
+    },
+  });
+}
+
+export function useUpdateChecklistItem(boardId: string) {
+  const qc = useQueryClient();
+  return useMutation({
+    mutationFn: (data: { id: string; updates: { text?: string; done?: boolean } }) => api.checklist.update(data.id, data.updates),
+    onSuccess: () => {
+      qc.invalidateQueries({ queryKey: ['board', boardId] });
+      qc.invalidateQueries({ queryKey: ['card'] });
+    },
+  });
+}
+
+export function useDeleteChecklistItem(boardId: string) {
+  const qc = useQueryClient();
+  return useMutation({
+    mutationFn: (id: string) => api.checklist.delete(id),
+    onSuccess: () => {
+      qc.invalidateQueries({ queryKey: ['board', boardId] });
+      qc.invalidateQueries({ queryKey: ['card'] });
+    },
+  });
+}
+
+export function useNotifications() {
+  return useQuery({
+    queryKey: ['notifications'],
+    queryFn: () => api.notifications.list(),
+    refetchInterval: 30000,
+  });
+}
+
+export function useAckNotifications() {
+  const qc = useQueryClient();
+  return useMutation({
+    mutationFn: (ids?: string[]) => api.notifications.ack(ids),
+    onSuccess: () => qc.invalidateQueries({ queryKey: ['notifications'] }),
+  });
+}
+
+export function useToggleWatch(cardId: string) {
+  const qc = useQueryClient();
+  return useMutation({
+    mutationFn: (watching: boolean) => watching ? api.watchers.unwatch(cardId, 'user') : api.watchers.watch(cardId),
+    onSuccess: () => qc.invalidateQueries({ queryKey: ['card', cardId] }),
+  });
+}
+
+export function useComments(cardId: string) {
+  return useQuery({
+    queryKey: ['comments', cardId],
+    queryFn: () => api.comments.list(cardId),
+    enabled: !!cardId,
+  });
+}
+
+export function useAddComment(cardId: string) {
+  const qc = useQueryClient();
+  return useMutation({
+    mutationFn: (data: { body: string; parent_id?: string }) => api.comments.add(cardId, data),
+    onSuccess: () => {
+      qc.invalidateQueries({ queryKey: ['comments', cardId] });
+      qc.invalidateQueries({ queryKey: ['card', cardId] });
+    },
+  });
+}
+
+export function useEditComment(cardId: string) {
+  const qc = useQueryClient();
+  return useMutation({
+    mutationFn: (data: { id: string; body: string }) => api.comments.edit(data.id, data.body),
+    onSuccess: () => qc.invalidateQueries({ queryKey: ['comments', cardId] }),
+  });
+}
+
+export function useDeleteComment(cardId: string) {
+  const qc = useQueryClient();
+  return useMutation({
+    mutationFn: (id: string) => api.comments.delete(id),
+    onSuccess: () => {
+      qc.invalidateQueries({ queryKey: ['comments', cardId] });
+      qc.invalidateQueries({ queryKey: ['card', cardId] });
+    },
+  });
+}
+
+export function useUploadAttachment(boardId: string) {
+  const qc = useQueryClient();
+  return useMutation({
+    mutationFn: (data: { cardId: string; file: File }) => api.attachments.upload(data.cardId, data.file),
+    onSuccess: () => {
+      qc.invalidateQueries({ queryKey: ['board', boardId] });
+      qc.invalidateQueries({ queryKey: ['card'] });
+    },
+  });
+}
+
+export function useDeleteAttachment(boardId: string) {
+  const qc = useQueryClient();
+  return useMutation({
+    mutationFn: (id: string) => api.attachments.delete(id),
+    onSuccess: () => {
+      qc.invalidateQueries({ queryKey: ['board', boardId] });
+      qc.invalidateQueries({ queryKey: ['card'] });
+    },
+  });
+}
+
 export function useAddLabelToCard(boardId: string) {
   const qc = useQueryClient();
   return useMutation({
@@ -26,13 +26,94 @@ export interface Card {
   assignee: string;
   status: string;
   priority: string;
+  parent_id?: string;
   due_date?: string;
   created_at: string;
   updated_at: string;
   labels: Label[];
   dependencies: Card[];
   dependents: Card[];
+  dependency_count?: number;
+  dependent_count?: number;
+  parent?: CardHeadline;
+  children?: CardHeadline[];
+  checklist?: ChecklistItem[];
+  checklist_done?: number;
+  checklist_total?: number;
+  fields?: Record<string, string | number>;
+  attachments?: Attachment[];
+  watchers?: string[];
   activity: ActivityLog[];
+  // Set on dependencies and dependents on another board than their card.
+  board?: BoardRef;
+}
+
+export interface BoardRef {
+  id: string;
+  name: string;
+}
+
+export interface ChecklistItem {
+  id: string;
+  card_id: string;
+  text: string;
+  done: boolean;
+  assignee?: string;
+  position: number;
+  completed_at?: string;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface Attachment {
+  id: string;
+  card_id: string;
+  name: string;
+  size: number;
+  mime_type: string;
+  sha256?: string;
+  url?: string;
+  uploaded_by: string;
+  created_at: string;
+}
+
+export interface Comment {
+  id: string;
+  card_id: string;
+  parent_id?: string;
+  author: string;
+  body: string;
+  mentions?: string[];
+  edited_at?: string;
+  deleted_at?: string;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface Notification {
+  id: string;
+  recipient: string;
+  type: 'mention' | 'assigned' | 'status_changed' | 'unblocked';
+  board_id: string;
+  card_id: string;
+  comment_id?: string;
+  actor: string;
+  message: string;
+  read_at?: string;
+  created_at: string;
+}
+
+export type CustomFieldType = 'text' | 'number' | 'date' | 'enum' | 'url';
+
+export interface CustomField {
+  id: string;
+  board_id: string;
+  name: string;
+  type: CustomFieldType;
+  options?: string[];
+  position: number;
+  created_at: string;
+  updated_at: string;
 }
 
 export interface Label {
@@ -42,6 +123,101 @@ export interface Label {
   color: string;
 }
 
+export interface CardCounts {
+  card_count: number;
+  by_status: Record<string, number>;
+  by_priority: Record<string, number>;
+  overdue: number;
+  blocked: number;
+}
+
+export interface CardHeadline {
+  id: string;
+  title: string;
+  status: string;
+  priority: string;
+  assignee?: string;
+  due_date?: string;
+  labels?: string[];
+  checklist_done?: number;
+  checklist_total?: number;
+}
+
+export interface ListSummary extends CardCounts {
+  id: string;
+  name: string;
+  position: number;
+  status?: string;
+  cards?: CardHeadline[];
+  cards_next_cursor?: string;
+}
+
+export interface BoardSummary extends CardCounts {
+  id: string;
+  name: string;
+  description: string;
+  updated_at: string;
+  lists: ListSummary[];
+  etag: string;
+}
+
+export interface View {
+  id: string;
+  board_id: string;
+  name: string;
+  filter: string;
+  sort: string;
+  columns: string[];
+  owner?: string;
+  created_by: string;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface ViewSpec {
+  name?: string;
+  filter?: string;
+  sort?: string;
+  columns?: string[];
+  private?: boolean;
+}
+
+export interface CardTemplate {
+  title: string;
+  description?: string;
+  priority?: string;
+  labels?: string[];
+  checklist?: string[];
+}
+
+export interface Workflow {
+  statuses: string[];
+  transitions?: Record<string, string[]>;
+  guards?: Record<string, string[]>;
+  complete_parents?: boolean;
+}
+
+export interface BoardTemplate {
+  description?: string;
+  workflow?: Workflow;
+  lists: { name: string; status?: string }[];
+  labels?: { name: string; color?: string }[];
+  cards?: (CardTemplate & { list: string })[];
+}
+
+export interface Template {
+  id: string;
+  name: string;
+  kind: 'board' | 'card';
+  description: string;
+  board?: BoardTemplate;
+  card?: CardTemplate;
+  variables: string[];
+  created_by: string;
+  created_at: string;
+  updated_at: string;
+}
+
 export interface ActivityLog {
   id: string;
   card_id: string;
@@ -51,5 +227,19 @@ export interface ActivityLog {
   created_at: string;
 }
 
+export interface Page<T> {
+  items: T[];
+  next_cursor?: string;
+}
+
 export type CardStatus = 'unassigned' | 'assigned' | 'in_progress' | 'blocked' | 'done';
 export type CardPriority = 'low' | 'medium' | 'high' | 'critical';
+
+export interface Rollup {
+  card_id: string;
+  children: number;
+  total: number;
+  done: number;
+  by_status: Record<string, number>;
+  percent: number;
+}
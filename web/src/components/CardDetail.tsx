@@ -1,6 +1,7 @@
-import { useState, useEffect } from 'react';
-import { useCard, useUpdateCard, useDeleteCard, useLabels, useAddLabelToCard, useRemoveLabelFromCard, useAddDependency, useRemoveDependency } from '../api/hooks';
-import type { Card, Label } from '../api/types';
+import { useState, useEffect, type ReactNode } from 'react';
+import { useCard, useUpdateCard, useDeleteCard, useLabels, useAddLabelToCard, useRemoveLabelFromCard, useAddDependency, useRemoveDependency, useAddChecklistItem, useUpdateChecklistItem, useDeleteChecklistItem, useCustomFields, useUploadAttachment, useDeleteAttachment, useToggleWatch, useComments, useAddComment, useEditComment, useDeleteComment } from '../api/hooks';
+import { api } from '../api/client';
+import type { Attachment, Card, ChecklistItem, Comment, CustomField, Label } from '../api/types';
 
 interface Props {
   cardId: string;
@@ -11,20 +12,49 @@ interface Props {
 const statusOptions = ['unassigned', 'assigned', 'in_progress', 'blocked', 'done'];
 const priorityOptions = ['low', 'medium', 'high', 'critical'];
 
+// formatSize renders a byte count for display.
+function formatSize(n: number): string {
+  if (n < 1024) return `${n} B`;
+  if (n < 1024 * 1024) return `${(n / 1024).toFixed(1)} KB`;
+  return `${(n / (1024 * 1024)).toFixed(1)} MB`;
+}
+
+// toLocalInput formats an ISO timestamp for a datetime-local input.
+function toLocalInput(iso: string): string {
+  const d = new Date(iso);
+  const pad = (n: number) => String(n).padStart(2, '0');
+  return `${d.getFullYear()}-${pad(d.getMonth() + 1)}-${pad(d.getDate())}T${pad(d.getHours())}:${pad(d.getMinutes())}`;
+}
+
 export default function CardDetail({ cardId, boardId, onClose }: Props) {
   const { data: card, isLoading } = useCard(cardId);
   const { data: boardLabels } = useLabels(boardId);
+  const { data: boardFields } = useCustomFields(boardId);
   const updateCard = useUpdateCard(boardId);
   const deleteCard = useDeleteCard(boardId);
   const addLabel = useAddLabelToCard(boardId);
   const removeLabel = useRemoveLabelFromCard(boardId);
   const addDep = useAddDependency(boardId);
   const removeDep = useRemoveDependency(boardId);
+  const addItem = useAddChecklistItem(boardId);
+  const updateItem = useUpdateChecklistItem(boardId);
+  const deleteItem = useDeleteChecklistItem(boardId);
+  const uploadAttachment = useUploadAttachment(boardId);
+  const deleteAttachment = useDeleteAttachment(boardId);
+  const toggleWatch = useToggleWatch(cardId);
+  const { data: comments } = useComments(cardId);
+  const addComment = useAddComment(cardId);
+  const editComment = useEditComment(cardId);
+  const deleteComment = useDeleteComment(cardId);
 
   const [title, setTitle] = useState('');
   const [description, setDescription] = useState('');
   const [assignee, setAssignee] = useState('');
   const [depInput, setDepInput] = useState('');
+  const [itemInput, setItemInput] = useState('');
+  const [commentInput, setCommentInput] = useState('');
+  const [replyTo, setReplyTo] = useState('');
+  const [editing, setEditing] = useState<{ id: string; body: string } | null>(null);
 
   useEffect(() => {
     if (card) {
@@ -45,6 +75,54 @@ export default function CardDetail({ cardId, boardId, onClose }: Props) {
     onClose();
   };
 
+  const submitComment = () => {
+    if (!commentInput.trim()) return;
+    addComment.mutate({ body: commentInput, parent_id: replyTo || undefined });
+    setCommentInput('');
+    setReplyTo('');
+  };
+
+  // Replies are shown under their parent, in the order they were written.
+  const replies = new Map<string, Comment[]>();
+  for (const cm of comments || []) {
+    const key = cm.parent_id || '';
+    replies.set(key, [...(replies.get(key) || []), cm]);
+  }
+  const renderThread = (parentId: string, depth: number): ReactNode[] =>
+    (replies.get(parentId) || []).map(cm => (
+      <div key={cm.id} style={{ marginLeft: Math.min(depth, 4) * 16 }}>
+        <div className="text-sm group">
+          <div className="flex items-center gap-2 text-xs text-gray-500">
+            <span className="font-medium text-gray-700">{cm.author}</span>
+            <span>{new Date(cm.created_at).toLocaleString()}</span>
+            {cm.edited_at && !cm.deleted_at && <span className="italic">edited</span>}
+            {!cm.deleted_at && (
+              <span className="ml-auto flex gap-2 opacity-0 group-hover:opacity-100">
+                <button onClick={() => setReplyTo(cm.id)} className="text-sky-600 hover:text-sky-800">reply</button>
+                <button onClick={() => setEditing({ id: cm.id, body: cm.body })} className="text-gray-500 hover:text-gray-700">edit</button>
+                <button onClick={() => deleteComment.mutate(cm.id)} className="text-red-400 hover:text-red-600">delete</button>
+              </span>
+            )}
+          </div>
+          {editing?.id === cm.id ? (
+            <textarea
+              value={editing.body}
+              onChange={e => setEditing({ id: cm.id, body: e.target.value })}
+              onBlur={() => { if (editing.body.trim() && editing.body !== cm.body) editComment.mutate(editing); setEditing(null); }}
+              autoFocus
+              rows={2}
+              className="w-full text-sm border border-gray-200 rounded px-2 py-1 mt-1 focus:outline-none focus:ring-1 focus:ring-sky-400"
+            />
+          ) : (
+            <p className={`whitespace-pre-wrap ${cm.deleted_at ? 'text-gray-400 italic' : 'text-gray-700'}`}>
+              {cm.deleted_at ? 'Comment deleted' : cm.body}
+            </p>
+          )}
+        </div>
+        {renderThread(cm.id, depth + 1)}
+      </div>
+    ));
+
   const availableLabels = (boardLabels || []).filter(
     (l: Label) => !card.labels?.some((cl: Label) => cl.id === l.id)
   );
@@ -99,6 +177,51 @@ export default function CardDetail({ cardId, boardId, onClose }: Props) {
             />
           </div>
 
+          {/* Due date */}
+          <div className="mb-4">
+            <label className="text-xs text-gray-500 font-medium">Due date</label>
+            <input
+              type="datetime-local"
+              value={card.due_date ? toLocalInput(card.due_date) : ''}
+              onChange={e => handleSave({ due_date: e.target.value ? new Date(e.target.value).toISOString() : null })}
+              className="w-full mt-1 text-sm border border-gray-200 rounded-md px-2 py-1.5 focus:outline-none focus:ring-2 focus:ring-sky-400"
+            />
+          </div>
+
+          {/* Custom fields */}
+          {boardFields && boardFields.length > 0 && (
+            <div className="grid grid-cols-2 gap-3 mb-4">
+              {boardFields.map((f: CustomField) => {
+                const value = card.fields?.[f.name];
+                const current = value === undefined ? '' : f.type === 'date' ? String(value).slice(0, 10) : String(value);
+                const save = (v: string) => v !== current && handleSave({ fields: { [f.name]: v === '' ? null : v } });
+                return (
+                  <div key={f.id}>
+                    <label className="text-xs text-gray-500 font-medium">{f.name.replace(/_/g, ' ')}</label>
+                    {f.type === 'enum' ? (
+                      <select
+                        value={current}
+                        onChange={e => save(e.target.value)}
+                        className="w-full mt-1 text-sm border border-gray-200 rounded-md px-2 py-1.5 focus:outline-none focus:ring-2 focus:ring-sky-400"
+                      >
+                        <option value="">—</option>
+                        {f.options?.map(o => <option key={o} value={o}>{o}</option>)}
+                      </select>
+                    ) : (
+                      <input
+                        key={`${f.id}-${current}`}
+                        type={f.type === 'number' ? 'number' : f.type === 'date' ? 'date' : f.type === 'url' ? 'url' : 'text'}
+                        defaultValue={current}
+                        onBlur={e => save(e.target.value)}
+                        className="w-full mt-1 text-sm border border-gray-200 rounded-md px-2 py-1.5 focus:outline-none focus:ring-2 focus:ring-sky-400"
+                      />
+                    )}
+                  </div>
+                );
+              })}
+            </div>
+          )}
+
           {/* Description */}
           <div className="mb-4">
             <label className="text-xs text-gray-500 font-medium">Description</label>
@@ -112,6 +235,99 @@ export default function CardDetail({ cardId, boardId, onClose }: Props) {
             />
           </div>
 
+          {/* Checklist */}
+          <div className="mb-4">
+            <label className="text-xs text-gray-500 font-medium">
+              Checklist{card.checklist_total ? ` (${card.checklist_done ?? 0}/${card.checklist_total})` : ''}
+            </label>
+            <div className="space-y-1 mt-1">
+              {card.checklist?.map((it: ChecklistItem) => (
+                <div key={it.id} className="flex items-center gap-2 text-sm group">
+                  <input
+                    type="checkbox"
+                    checked={it.done}
+                    onChange={e => updateItem.mutate({ id: it.id, updates: { done: e.target.checked } })}
+                  />
+                  <span className={it.done ? 'text-gray-400 line-through' : 'text-gray-700'}>{it.text}</span>
+                  {it.assignee && <span className="text-[10px] text-gray-500 bg-gray-100 px-1.5 py-0.5 rounded">{it.assignee}</span>}
+                  <button
+                    onClick={() => deleteItem.mutate(it.id)}
+                    className="text-red-400 hover:text-red-600 text-xs ml-auto opacity-0 group-hover:opacity-100"
+                  >
+                    remove
+                  </button>
+                </div>
+              ))}
+              <div className="flex gap-1 mt-1">
+                <input
+                  value={itemInput}
+                  onChange={e => setItemInput(e.target.value)}
+                  onKeyDown={e => { if (e.key === 'Enter' && itemInput.trim()) { addItem.mutate({ cardId, text: itemInput }); setItemInput(''); } }}
+                  placeholder="Add an item"
+                  className="flex-1 text-xs border border-gray-200 rounded px-2 py-1 focus:outline-none focus:ring-1 focus:ring-sky-400"
+                />
+                <button
+                  onClick={() => { if (itemInput.trim()) { addItem.mutate({ cardId, text: itemInput }); setItemInput(''); } }}
+                  className="text-xs px-2 py-1 bg-sky-100 text-sky-700 rounded hover:bg-sky-200"
+                >
+                  Add
+                </button>
+              </div>
+            </div>
+          </div>
+
+          {/* Watchers */}
+          <div className="mb-4">
+            <label className="text-xs text-gray-500 font-medium">Watchers</label>
+            <div className="flex flex-wrap items-center gap-1.5 mt-1">
+              {card.watchers?.map(w => (
+                <span key={w} className="text-[10px] text-gray-600 bg-gray-100 px-1.5 py-0.5 rounded">{w}</span>
+              ))}
+              <button
+                onClick={() => toggleWatch.mutate(!!card.watchers?.includes('user'))}
+                className="text-xs px-2 py-0.5 bg-sky-100 text-sky-700 rounded hover:bg-sky-200"
+              >
+                {card.watchers?.includes('user') ? 'Unwatch' : 'Watch'}
+              </button>
+            </div>
+          </div>
+
+          {/* Attachments */}
+          <div className="mb-4">
+            <label className="text-xs text-gray-500 font-medium">Attachments</label>
+            <div className="space-y-1 mt-1">
+              {card.attachments?.map((a: Attachment) => (
+                <div key={a.id} className="flex items-center gap-2 text-sm group">
+                  <a
+                    href={a.url || api.attachments.contentURL(a.id)}
+                    target="_blank"
+                    rel="noreferrer"
+                    className="text-sky-600 hover:underline truncate"
+                  >
+                    {a.name}
+                  </a>
+                  <span className="text-[10px] text-gray-400">{a.url ? 'link' : formatSize(a.size)}</span>
+                  <span className="text-[10px] text-gray-500">{a.uploaded_by}</span>
+                  <button
+                    onClick={() => deleteAttachment.mutate(a.id)}
+                    className="text-red-400 hover:text-red-600 text-xs ml-auto opacity-0 group-hover:opacity-100"
+                  >
+                    remove
+                  </button>
+                </div>
+              ))}
+              <input
+                type="file"
+                onChange={e => {
+                  const file = e.target.files?.[0];
+                  if (file) uploadAttachment.mutate({ cardId, file });
+                  e.target.value = '';
+                }}
+                className="text-xs text-gray-500"
+              />
+            </div>
+          </div>
+
           {/* Labels */}
           <div className="mb-4">
             <label className="text-xs text-gray-500 font-medium">Labels</label>
@@ -148,6 +364,9 @@ export default function CardDetail({ cardId, boardId, onClose }: Props) {
               {card.dependencies?.map((dep: Card) => (
                 <div key={dep.id} className="flex items-center gap-2 text-sm">
                   <span className="text-gray-700">{dep.title}</span>
+                  {dep.board && (
+                    <span className="text-xs text-gray-400">on {dep.board.name}</span>
+                  )}
                   <button
                     onClick={() => removeDep.mutate({ cardId, depId: dep.id })}
                     className="text-red-400 hover:text-red-600 text-xs"
@@ -173,6 +392,33 @@ export default function CardDetail({ cardId, boardId, onClose }: Props) {
             </div>
           </div>
 
+          {/* Parent & children */}
+          {(card.parent || (card.children && card.children.length > 0)) && (
+            <div className="mb-4">
+              {card.parent && (
+                <div className="text-sm text-gray-600 mb-1">
+                  <span className="text-xs text-gray-500 font-medium mr-2">Parent</span>
+                  {card.parent.title}
+                </div>
+              )}
+              {card.children && card.children.length > 0 && (
+                <>
+                  <label className="text-xs text-gray-500 font-medium">
+                    Children ({card.children.filter(ch => ch.status === 'done').length}/{card.children.length} done)
+                  </label>
+                  <div className="space-y-1 mt-1">
+                    {card.children.map(ch => (
+                      <div key={ch.id} className="text-sm text-gray-600 flex gap-2">
+                        <span className={ch.status === 'done' ? 'line-through text-gray-400' : ''}>{ch.title}</span>
+                        <span className="text-xs text-gray-400 ml-auto">{ch.status.replace('_', ' ')}</span>
+                      </div>
+                    ))}
+                  </div>
+                </>
+              )}
+            </div>
+          )}
+
           {/* Dependents */}
           {card.dependents && card.dependents.length > 0 && (
             <div className="mb-4">
@@ -185,6 +431,36 @@ export default function CardDetail({ cardId, boardId, onClose }: Props) {
             </div>
           )}
 
+          {/* Comments */}
+          <div className="mb-4">
+            <label className="text-xs text-gray-500 font-medium">Comments</label>
+            <div className="mt-1 space-y-2">
+              {renderThread('', 0)}
+              {replyTo && (
+                <div className="text-xs text-gray-500">
+                  Replying to {comments?.find(cm => cm.id === replyTo)?.author}{' '}
+                  <button onClick={() => setReplyTo('')} className="text-sky-600 hover:text-sky-800">cancel</button>
+                </div>
+              )}
+              <div className="flex gap-1">
+                <textarea
+                  value={commentInput}
+                  onChange={e => setCommentInput(e.target.value)}
+                  onKeyDown={e => { if (e.key === 'Enter' && (e.metaKey || e.ctrlKey)) submitComment(); }}
+                  placeholder="Write a comment; @name to mention"
+                  rows={2}
+                  className="flex-1 text-xs border border-gray-200 rounded px-2 py-1 focus:outline-none focus:ring-1 focus:ring-sky-400"
+                />
+                <button
+                  onClick={submitComment}
+                  className="text-xs px-2 py-1 bg-sky-100 text-sky-700 rounded hover:bg-sky-200 self-end"
+                >
+                  Comment
+                </button>
+              </div>
+            </div>
+          </div>
+
           {/* Activity */}
           <div className="mb-4">
             <label className="text-xs text-gray-500 font-medium">Activity</label>
@@ -61,6 +61,9 @@ export default function CardTile({ card, onClick }: Props) {
               <span className="text-[10px] text-gray-500 bg-gray-100 px-1.5 py-0.5 rounded">{card.assignee}</span>
             )}
             {statusIcons[card.status] && <span className="text-xs">{statusIcons[card.status]}</span>}
+            {!!card.checklist_total && (
+              <span className="text-[10px] text-gray-500">☑ {card.checklist_done ?? 0}/{card.checklist_total}</span>
+            )}
           </div>
         </div>
       </div>
@@ -1,6 +1,13 @@
+import { useState } from 'react';
 import { Link, Outlet } from 'react-router-dom';
+import { useNotifications, useAckNotifications } from '../api/hooks';
 
 export default function Layout() {
+  const { data: inbox } = useNotifications();
+  const ack = useAckNotifications();
+  const [open, setOpen] = useState(false);
+  const unread = inbox?.items ?? [];
+
   return (
     <div className="min-h-screen bg-gradient-to-br from-sky-50 to-indigo-100">
       <nav className="bg-white/80 backdrop-blur border-b border-sky-200 px-6 py-3 flex items-center gap-4 shadow-sm">
@@ -8,6 +15,34 @@ export default function Layout() {
           Cielo
         </Link>
         <span className="text-xs text-sky-400 font-medium">AI Agent Orchestration</span>
+        <div className="ml-auto relative">
+          <button
+            onClick={() => setOpen(!open)}
+            className="text-sm text-sky-700 hover:text-sky-900 transition"
+          >
+            Inbox{unread.length > 0 && <span className="ml-1 text-xs bg-sky-600 text-white rounded-full px-1.5">{unread.length}</span>}
+          </button>
+          {open && (
+            <div className="absolute right-0 mt-2 w-80 bg-white rounded-lg shadow-lg border border-gray-100 p-3 z-50">
+              {unread.length === 0 && <p className="text-xs text-gray-400">No unread notifications</p>}
+              <div className="space-y-2 max-h-80 overflow-y-auto">
+                {unread.map(n => (
+                  <div key={n.id} className="text-xs text-gray-700 flex gap-2">
+                    <Link to={`/boards/${n.board_id}`} onClick={() => ack.mutate([n.id])} className="hover:text-sky-700">
+                      {n.message}
+                    </Link>
+                    <span className="text-gray-400 ml-auto shrink-0">{new Date(n.created_at).toLocaleString()}</span>
+                  </div>
+                ))}
+              </div>
+              {unread.length > 0 && (
+                <button onClick={() => ack.mutate(undefined)} className="mt-2 text-xs text-sky-600 hover:text-sky-800">
+                  Mark all read
+                </button>
+              )}
+            </div>
+          )}
+        </div>
       </nav>
       <Outlet />
     </div>
@@ -13,16 +13,39 @@ export function useSSE(boardId: string) {
       qc.invalidateQueries({ queryKey: ['board', boardId] });
     };
 
+    const commentsHandler = () => {
+      qc.invalidateQueries({ queryKey: ['comments'] });
+      qc.invalidateQueries({ queryKey: ['card'] });
+    };
+
+    const fieldsHandler = () => {
+      qc.invalidateQueries({ queryKey: ['fields', boardId] });
+      handler();
+    };
+
     es.addEventListener('card.created', handler);
     es.addEventListener('card.updated', handler);
     es.addEventListener('card.moved', handler);
     es.addEventListener('card.deleted', handler);
+    es.addEventListener('card.overdue', handler);
+    es.addEventListener('checklist_item.created', handler);
+    es.addEventListener('checklist_item.updated', handler);
+    es.addEventListener('checklist_item.deleted', handler);
+    es.addEventListener('comment.created', commentsHandler);
+    es.addEventListener('comment.updated', commentsHandler);
+    es.addEventListener('comment.deleted', commentsHandler);
+    es.addEventListener('notification.created', () => qc.invalidateQueries({ queryKey: ['notifications'] }));
+    es.addEventListener('attachment.created', handler);
+    es.addEventListener('attachment.deleted', handler);
     es.addEventListener('list.created', handler);
     es.addEventListener('list.updated', handler);
     es.addEventListener('list.deleted', handler);
     es.addEventListener('label.created', handler);
     es.addEventListener('label.updated', handler);
     es.addEventListener('label.deleted', handler);
+    es.addEventListener('custom_field.created', fieldsHandler);
+    es.addEventListener('custom_field.updated', fieldsHandler);
+    es.addEventListener('custom_field.deleted', fieldsHandler);
     es.addEventListener('activity.new', handler);
 
     return () => es.close();
@@ -1,20 +1,35 @@
 import { useState } from 'react';
-import { Link } from 'react-router-dom';
-import { useBoards, useCreateBoard, useDeleteBoard } from '../api/hooks';
+import { Link, useNavigate } from 'react-router-dom';
+import { useBoards, useCloneBoard, useCreateBoard, useCreateBoardFromTemplate, useDeleteBoard, useTemplates } from '../api/hooks';
 
 export default function BoardList() {
   const { data: boards, isLoading } = useBoards();
+  const { data: templates } = useTemplates('board');
   const createBoard = useCreateBoard();
+  const fromTemplate = useCreateBoardFromTemplate();
+  const cloneBoard = useCloneBoard();
   const deleteBoard = useDeleteBoard();
+  const navigate = useNavigate();
   const [showForm, setShowForm] = useState(false);
   const [name, setName] = useState('');
   const [desc, setDesc] = useState('');
+  const [templateId, setTemplateId] = useState('');
+  const [vars, setVars] = useState<Record<string, string>>({});
+  const template = templates?.find(t => t.id === templateId);
+
+  const reset = () => { setName(''); setDesc(''); setTemplateId(''); setVars({}); setShowForm(false); };
 
   const handleCreate = (e: React.FormEvent) => {
     e.preventDefault();
+    if (template) {
+      fromTemplate.mutate({ templateId: template.id, name: name.trim(), variables: vars }, {
+        onSuccess: board => { reset(); navigate(`/boards/${board.id}`); },
+      });
+      return;
+    }
     if (!name.trim()) return;
     createBoard.mutate({ name: name.trim(), description: desc.trim() }, {
-      onSuccess: () => { setName(''); setDesc(''); setShowForm(false); },
+      onSuccess: reset,
     });
   };
 
@@ -34,22 +49,43 @@ export default function BoardList() {
 
       {showForm && (
         <form onSubmit={handleCreate} className="mb-6 bg-white rounded-lg p-4 shadow-sm border border-sky-100">
+          {!!templates?.length && (
+            <select
+              value={templateId}
+              onChange={e => { setTemplateId(e.target.value); setVars({}); }}
+              className="w-full mb-2 px-3 py-2 border border-gray-200 rounded-md text-sm focus:outline-none focus:ring-2 focus:ring-sky-400"
+            >
+              <option value="">Blank board</option>
+              {templates.map(t => <option key={t.id} value={t.id}>{t.name}</option>)}
+            </select>
+          )}
           <input
             value={name}
             onChange={e => setName(e.target.value)}
-            placeholder="Board name"
+            placeholder={template ? `Board name (default: ${template.name})` : 'Board name'}
             className="w-full mb-2 px-3 py-2 border border-gray-200 rounded-md text-sm focus:outline-none focus:ring-2 focus:ring-sky-400"
             autoFocus
           />
-          <input
-            value={desc}
-            onChange={e => setDesc(e.target.value)}
-            placeholder="Description (optional)"
-            className="w-full mb-3 px-3 py-2 border border-gray-200 rounded-md text-sm focus:outline-none focus:ring-2 focus:ring-sky-400"
-          />
+          {template ? template.variables.map(v => (
+            <input
+              key={v}
+              value={vars[v] ?? ''}
+              onChange={e => setVars({ ...vars, [v]: e.target.value })}
+              placeholder={v}
+              className="w-full mb-2 px-3 py-2 border border-gray-200 rounded-md text-sm focus:outline-none focus:ring-2 focus:ring-sky-400"
+            />
+          )) : (
+            <input
+              value={desc}
+              onChange={e => setDesc(e.target.value)}
+              placeholder="Description (optional)"
+              className="w-full mb-3 px-3 py-2 border border-gray-200 rounded-md text-sm focus:outline-none focus:ring-2 focus:ring-sky-400"
+            />
+          )}
+          {fromTemplate.error && <p className="mb-2 text-sm text-red-500">{fromTemplate.error.message}</p>}
           <div className="flex gap-2">
             <button type="submit" className="px-4 py-1.5 bg-sky-600 text-white rounded-md text-sm hover:bg-sky-700">Create</button>
-            <button type="button" onClick={() => setShowForm(false)} className="px-4 py-1.5 text-gray-500 text-sm hover:text-gray-700">Cancel</button>
+            <button type="button" onClick={reset} className="px-4 py-1.5 text-gray-500 text-sm hover:text-gray-700">Cancel</button>
           </div>
         </form>
       )}
@@ -61,6 +97,13 @@ export default function BoardList() {
               <h2 className="font-semibold text-gray-800 mb-1">{board.name}</h2>
               {board.description && <p className="text-sm text-gray-500">{board.description}</p>}
             </Link>
+            <button
+              onClick={() => cloneBoard.mutate(board.id)}
+              className="absolute top-3 right-8 text-gray-300 hover:text-sky-600 transition opacity-0 group-hover:opacity-100 text-sm"
+              title="Duplicate board"
+            >
+              &#x2398;
+            </button>
             <button
               onClick={() => deleteBoard.mutate(board.id)}
               className="absolute top-3 right-3 text-gray-300 hover:text-red-500 transition opacity-0 group-hover:opacity-100 text-sm"